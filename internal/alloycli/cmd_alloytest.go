@@ -0,0 +1,166 @@
+package alloycli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/cobra"
+
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/runtime/logging"
+	"github.com/grafana/alloy/internal/runtime/pipelinetest"
+	"github.com/grafana/alloy/internal/runtime/tracing"
+	"github.com/grafana/alloy/internal/service"
+	httpservice "github.com/grafana/alloy/internal/service/http"
+	"github.com/grafana/alloy/internal/service/labelstore"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+	otel_service "github.com/grafana/alloy/internal/service/otel"
+)
+
+func testCommand() *cobra.Command {
+	t := &alloyTest{
+		minStability: featuregate.StabilityGenerallyAvailable,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "test [flags] directory",
+		Short: "Test a pipeline against fixture files",
+		Long: `The test subcommand runs the tests of the pipeline in a directory.
+
+The pipeline is made of the *.alloy files of the directory, loaded the same
+way the run subcommand loads them. Every *.alloy file in the tests
+subdirectory is a test. A test replaces source components of the pipeline
+with fixture files, runs the pipeline, and compares the data which reaches
+sink components with expected files.
+
+test exits with a non-zero exit code if at least one test failed.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+
+		RunE: func(_ *cobra.Command, args []string) error {
+			return t.Run(os.Stdout, args[0])
+		},
+	}
+
+	cmd.Flags().BoolVar(&t.update, "update", t.update, "Write the outputs of the pipeline to the expected files instead of comparing them.")
+	cmd.Flags().StringVar(&t.run, "run", t.run, "Only run the tests whose name matches the regular expression.")
+	cmd.Flags().Var(&t.minStability, "stability.level", fmt.Sprintf("Minimum stability level of features to enable. Supported values: %s", strings.Join(featuregate.AllowedValues(), ", ")))
+	cmd.Flags().BoolVar(&t.enableCommunityComps, "feature.community-components.enabled", t.enableCommunityComps, "Enable community components.")
+	return cmd
+}
+
+type alloyTest struct {
+	update               bool
+	run                  string
+	minStability         featuregate.Stability
+	enableCommunityComps bool
+}
+
+// Run runs the tests of the pipeline in dir, writing their results to w.
+func (t *alloyTest) Run(w io.Writer, dir string) error {
+	var filter *regexp.Regexp
+	if t.run != "" {
+		var err error
+		filter, err = regexp.Compile(t.run)
+		if err != nil {
+			return fmt.Errorf("invalid --run flag: %w", err)
+		}
+	}
+
+	suite, err := pipelinetest.Load(dir)
+	if err != nil {
+		return err
+	}
+
+	l, err := logging.New(io.Discard, logging.DefaultOptions)
+	if err != nil {
+		return fmt.Errorf("building logger: %w", err)
+	}
+	tr, err := tracing.New(tracing.DefaultOptions)
+	if err != nil {
+		return fmt.Errorf("building tracer: %w", err)
+	}
+
+	opts := pipelinetest.Options{
+		Logger:               l,
+		MinStability:         t.minStability,
+		EnableCommunityComps: t.enableCommunityComps,
+		Services: func(string) ([]service.Service, error) {
+			return testServices(l, tr)
+		},
+		Update: t.update,
+	}
+
+	var ran, failed int
+	for _, test := range suite.Tests {
+		if filter != nil && !filter.MatchString(test.Name) {
+			continue
+		}
+		ran++
+
+		start := time.Now()
+		err := suite.Run(context.Background(), test, opts)
+		elapsed := time.Since(start).Seconds()
+
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "--- FAIL: %s (%.2fs)\n", test.Name, elapsed)
+			for _, line := range strings.Split(strings.TrimRight(err.Error(), "\n"), "\n") {
+				fmt.Fprintf(w, "    %s\n", line)
+			}
+			continue
+		}
+		fmt.Fprintf(w, "--- PASS: %s (%.2fs)\n", test.Name, elapsed)
+	}
+
+	switch {
+	case ran == 0:
+		return fmt.Errorf("no tests to run")
+	case failed > 0:
+		return fmt.Errorf("%d of %d tests failed", failed, ran)
+	}
+	return nil
+}
+
+// testServices returns the services used by the components of a pipeline
+// under test. Clustering is disabled, and the remotecfg service isn't
+// included, so a pipeline under test doesn't connect to other instances.
+func testServices(l *logging.Logger, t *tracing.Tracer) ([]service.Service, error) {
+	reg := prometheus.NewRegistry()
+
+	clusterService, err := buildClusterService(clusterOptions{
+		Log:           log.With(l, "service", "cluster"),
+		Tracer:        t,
+		Metrics:       reg,
+		NodeName:      "test",
+		ListenAddress: "127.0.0.1:12345",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	otelService := otel_service.New(l)
+	if otelService == nil {
+		return nil, fmt.Errorf("failed to create otel service")
+	}
+
+	return []service.Service{
+		clusterService,
+		httpservice.New(httpservice.Options{
+			Logger:         log.With(l, "service", "http"),
+			Tracer:         t,
+			Gatherer:       reg,
+			HTTPListenAddr: "127.0.0.1:0",
+		}),
+		labelstore.New(l, reg),
+		livedebugging.New(),
+		otelService,
+	}, nil
+}
@@ -0,0 +1,23 @@
+package alloycli
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/featuregate"
+)
+
+func TestAlloyTest(t *testing.T) {
+	dir := filepath.Join("..", "runtime", "pipelinetest", "testdata", "metrics")
+
+	var buf bytes.Buffer
+	cmd := &alloyTest{minStability: featuregate.StabilityGenerallyAvailable}
+	require.NoError(t, cmd.Run(&buf, dir))
+	require.Regexp(t, `^--- PASS: relabel \(\d+\.\d+s\)\n$`, buf.String())
+
+	cmd.run = "^other$"
+	require.EqualError(t, cmd.Run(&buf, dir), "no tests to run")
+}
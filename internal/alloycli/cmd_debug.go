@@ -0,0 +1,187 @@
+package alloycli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/grafana/alloy/internal/service/livedebugging"
+)
+
+func debugCommand() *cobra.Command {
+	d := &alloyDebug{
+		url:        "http://localhost:12345",
+		format:     "text",
+		sampleProb: 1,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "debug [flags] component-id",
+		Short: "Stream the live debugging data of a component",
+		Long: `The debug subcommand streams the live debugging data of a component of a
+running instance to stdout, the same data which is shown by the live debugging
+page of the UI.
+
+Live debugging must be enabled in the configuration of the instance with the
+livedebugging block.
+
+The ID of a component in a module is prefixed with the ID of the module, for
+example foo.default/loki.process.bar.
+
+Events can be filtered by the instance with --match, a label selector which
+the labels of the data, or the resource attributes of OpenTelemetry data, must
+match. --sample-prob and --max-events-per-second limit how many events are
+sent.
+
+Events are written as a JSON object per line or as text, depending on
+--format, until the command is interrupted.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+
+		RunE: func(_ *cobra.Command, args []string) error {
+			ctx, cancel := interruptContext()
+			defer cancel()
+
+			err := d.Run(ctx, os.Stdout, args[0])
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&d.url, "url", d.url, "URL of the UI of the running instance")
+	cmd.Flags().StringVarP(&d.format, "format", "f", d.format, "Format of the events. Supported values: text, json")
+	cmd.Flags().StringVar(&d.match, "match", d.match, `Label selector which events must match, for example {job="a", level=~"warn|error"}`)
+	cmd.Flags().Float64Var(&d.sampleProb, "sample-prob", d.sampleProb, "Probability for an event to be sent, from 0 to 1")
+	cmd.Flags().Float64Var(&d.maxEventsPerSecond, "max-events-per-second", d.maxEventsPerSecond, "Maximum number of events sent per second. 0 disables the limit")
+	return cmd
+}
+
+type alloyDebug struct {
+	url                string
+	format             string
+	match              string
+	sampleProb         float64
+	maxEventsPerSecond float64
+}
+
+// Run streams the events of the component with the given ID to w until ctx
+// is canceled or the instance closes the stream.
+func (d *alloyDebug) Run(ctx context.Context, w io.Writer, componentID string) error {
+	var write func(io.Writer, livedebugging.Event) error
+	switch d.format {
+	case "text":
+		write = writeDebugEventText
+	case "json":
+		write = writeDebugEventJSON
+	default:
+		return fmt.Errorf("unsupported format %q", d.format)
+	}
+
+	u, err := d.streamURL(componentID)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bb, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s: %s", u, resp.Status, strings.TrimSpace(string(bb)))
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var event livedebugging.Event
+		if err := dec.Decode(&event); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("decoding the events of %s: %w", componentID, err)
+		}
+		if err := write(w, event); err != nil {
+			return err
+		}
+	}
+}
+
+// streamURL returns the URL of the live debugging stream of the component
+// with the given ID.
+func (d *alloyDebug) streamURL(componentID string) (string, error) {
+	u, err := url.Parse(d.url)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, "/api/v0/web/debug", componentID)
+
+	query := url.Values{}
+	query.Set("format", "json")
+	if d.match != "" {
+		query.Set("match", d.match)
+	}
+	query.Set("sampleProb", strconv.FormatFloat(d.sampleProb, 'g', -1, 64))
+	if d.maxEventsPerSecond > 0 {
+		query.Set("maxEventsPerSecond", strconv.FormatFloat(d.maxEventsPerSecond, 'g', -1, 64))
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func writeDebugEventJSON(w io.Writer, event livedebugging.Event) error {
+	return json.NewEncoder(w).Encode(event)
+}
+
+// writeDebugEventText writes a header line with the timestamp, type,
+// component and labels of event, followed by its body.
+func writeDebugEventText(w io.Writer, event livedebugging.Event) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s [%s] %s", event.Timestamp.Format(time.RFC3339Nano), event.Type, event.ComponentID)
+
+	if len(event.Labels) > 0 {
+		names := make([]string, 0, len(event.Labels))
+		for name := range event.Labels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		pairs := make([]string, 0, len(names))
+		for _, name := range names {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", name, strconv.Quote(event.Labels[name])))
+		}
+		fmt.Fprintf(&sb, " {%s}", strings.Join(pairs, ", "))
+	}
+	sb.WriteString("\n")
+
+	body := strings.TrimRight(event.Body, "\n")
+	for _, line := range strings.Split(body, "\n") {
+		sb.WriteString("  ")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,117 @@
+package alloycli
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/service/livedebugging"
+	"github.com/grafana/alloy/internal/web/api"
+)
+
+// fakeCallbackManager publishes events to the callbacks added to it.
+type fakeCallbackManager struct {
+	events []livedebugging.Event
+}
+
+func (m *fakeCallbackManager) AddCallback(_ livedebugging.CallbackID, componentID livedebugging.ComponentID, callback func(livedebugging.Event)) error {
+	go func() {
+		// Give the handler time to start streaming the events.
+		time.Sleep(100 * time.Millisecond)
+		for _, event := range m.events {
+			event.ComponentID = componentID
+			callback(event)
+		}
+	}()
+	return nil
+}
+
+func (m *fakeCallbackManager) DeleteCallback(livedebugging.CallbackID, livedebugging.ComponentID) {}
+
+// cancelWriter cancels a context once n writes were made.
+type cancelWriter struct {
+	mut    sync.Mutex
+	buf    bytes.Buffer
+	n      int
+	cancel context.CancelFunc
+}
+
+func (w *cancelWriter) Write(p []byte) (int, error) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	w.n--
+	if w.n == 0 {
+		defer w.cancel()
+	}
+	return w.buf.Write(p)
+}
+
+func TestDebug(t *testing.T) {
+	ts := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	manager := &fakeCallbackManager{events: []livedebugging.Event{
+		{Type: livedebugging.DataTypeLogs, Timestamp: ts, Labels: map[string]string{"job": "a", "level": "warn"}, Body: "first"},
+		{Type: livedebugging.DataTypeLogs, Timestamp: ts, Labels: map[string]string{"job": "b"}, Body: "filtered out"},
+		{Type: livedebugging.DataTypeMetrics, Timestamp: ts, Labels: map[string]string{"job": "a"}, Body: "second\nsecond line\n"},
+	}}
+
+	r := mux.NewRouter()
+	api.NewAlloyAPI(nil, manager).RegisterRoutes("/ui/api/v0/web", r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	tt := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "text",
+			expected: `2024-07-01T12:00:00Z [logs] foo.default/loki.process.bar {job="a", level="warn"}
+  first
+2024-07-01T12:00:00Z [metrics] foo.default/loki.process.bar {job="a"}
+  second
+  second line
+`,
+		},
+		{
+			format: "json",
+			expected: `{"component_id":"foo.default/loki.process.bar","type":"logs","timestamp":"2024-07-01T12:00:00Z","labels":{"job":"a","level":"warn"},"body":"first"}
+{"component_id":"foo.default/loki.process.bar","type":"metrics","timestamp":"2024-07-01T12:00:00Z","labels":{"job":"a"},"body":"second\nsecond line\n"}
+`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.format, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			w := &cancelWriter{n: 2, cancel: cancel}
+			d := &alloyDebug{
+				url:        srv.URL + "/ui",
+				format:     tc.format,
+				match:      `{job="a"}`,
+				sampleProb: 1,
+			}
+			err := d.Run(ctx, w, "foo.default/loki.process.bar")
+			require.ErrorIs(t, err, context.Canceled)
+			require.Equal(t, tc.expected, w.buf.String())
+		})
+	}
+}
+
+func TestDebug_InvalidFilter(t *testing.T) {
+	r := mux.NewRouter()
+	api.NewAlloyAPI(nil, &fakeCallbackManager{}).RegisterRoutes("/ui/api/v0/web", r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	d := &alloyDebug{url: srv.URL + "/ui", format: "text", sampleProb: 2}
+	err := d.Run(context.Background(), &bytes.Buffer{}, "loki.process.bar")
+	require.ErrorContains(t, err, "sample probability must be between 0 and 1")
+}
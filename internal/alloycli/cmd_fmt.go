@@ -16,7 +16,8 @@ import (
 
 func fmtCommand() *cobra.Command {
 	f := &alloyFmt{
-		write: false,
+		write:        false,
+		configFormat: formatAlloy,
 	}
 
 	cmd := &cobra.Command{
@@ -27,7 +28,11 @@ specified configuration file.
 
 If the file argument is not supplied or if the file argument is "-", then fmt will read from stdin.
 
-The -w flag can be used to write the formatted file back to disk. -w can not be provided when fmt is reading from stdin. When -w is not provided, fmt will write the result to stdout.`,
+The -w flag can be used to write the formatted file back to disk. -w can not be provided when fmt is reading from stdin. When -w is not provided, fmt will write the result to stdout.
+
+The --config.format flag can be set to "alloyjson" to read a file in the
+alloyjson encoding and print it in Alloy syntax. -w can not be provided when
+reading alloyjson.`,
 		Args:         cobra.RangeArgs(0, 1),
 		SilenceUsage: true,
 		Aliases:      []string{"format"},
@@ -55,20 +60,33 @@ The -w flag can be used to write the formatted file back to disk. -w can not be
 	}
 
 	cmd.Flags().BoolVarP(&f.write, "write", "w", f.write, "write result to (source) file instead of stdout")
+	cmd.Flags().StringVar(&f.configFormat, "config.format", f.configFormat, fmt.Sprintf("The format of the source file. Supported formats: %q, %q.", formatAlloy, formatAlloyJSON))
 	return cmd
 }
 
 type alloyFmt struct {
-	write bool
+	write        bool
+	configFormat string
 }
 
 func (ff *alloyFmt) Run(configFile string) error {
+	switch ff.configFormat {
+	case formatAlloy:
+		// Supported with all options.
+	case formatAlloyJSON:
+		if ff.write {
+			return fmt.Errorf("cannot use -w with --config.format=%s", formatAlloyJSON)
+		}
+	default:
+		return fmt.Errorf("unsupported config format %q", ff.configFormat)
+	}
+
 	switch configFile {
 	case "-":
 		if ff.write {
 			return fmt.Errorf("cannot use -w with standard input")
 		}
-		return format("<stdin>", nil, os.Stdin, false)
+		return format("<stdin>", nil, os.Stdin, false, ff.configFormat)
 
 	default:
 		fi, err := os.Stat(configFile)
@@ -84,15 +102,20 @@ func (ff *alloyFmt) Run(configFile string) error {
 			return err
 		}
 		defer f.Close()
-		return format(configFile, fi, f, ff.write)
+		return format(configFile, fi, f, ff.write, ff.configFormat)
 	}
 }
 
-func format(filename string, fi os.FileInfo, r io.Reader, write bool) error {
+func format(filename string, fi os.FileInfo, r io.Reader, write bool, configFormat string) error {
 	bb, err := io.ReadAll(r)
 	if err != nil {
 		return err
 	}
+	if configFormat == formatAlloyJSON {
+		if bb, err = alloyJSONToSource(bb); err != nil {
+			return fmt.Errorf("decoding %s: %w", filename, err)
+		}
+	}
 
 	f, err := parser.ParseFile(filename, bb)
 	if err != nil {
@@ -0,0 +1,382 @@
+package alloycli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/featuregate"
+)
+
+func graphCommand() *cobra.Command {
+	g := &alloyGraph{
+		format:       "dot",
+		minStability: featuregate.StabilityGenerallyAvailable,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "graph [flags] [path]",
+		Short: "Export the component graph of a configuration",
+		Long: `The graph subcommand exports the graph of the components of an Alloy
+configuration file or directory, without running it.
+
+If path is a directory, all *.alloy files in that directory will be combined
+into a single unit, the same way the run subcommand loads them.
+
+The configuration is loaded the same way the validate subcommand does, so
+the modules of import and declare blocks are included in the graph, nested in
+the component which runs them. Imported modules are still retrieved from
+their source.
+
+An edge goes from a component to each component it references, and lists the
+attributes of the component which make the reference.
+
+When --url is set, the graph of the instance whose UI is served at the URL is
+exported instead, and path must not be given.
+
+The graph is written to stdout as DOT, Mermaid or JSON, depending on
+--format.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+
+		RunE: func(_ *cobra.Command, args []string) error {
+			switch {
+			case g.url == "" && len(args) == 0:
+				return fmt.Errorf("either a path or --url must be given")
+			case g.url != "" && len(args) > 0:
+				return fmt.Errorf("a path can't be given with --url")
+			}
+
+			var configPath string
+			if len(args) > 0 {
+				configPath = args[0]
+			}
+			return g.Run(os.Stdout, configPath)
+		},
+	}
+
+	cmd.Flags().StringVarP(&g.format, "format", "f", g.format, "Format of the graph. Supported values: dot, mermaid, json")
+	cmd.Flags().StringVar(&g.url, "url", g.url, "URL of the UI of a running instance to export the graph of, for example http://localhost:12345")
+	cmd.Flags().Var(&g.minStability, "stability.level", fmt.Sprintf("Minimum stability level of features to enable. Supported values: %s", strings.Join(featuregate.AllowedValues(), ", ")))
+	cmd.Flags().BoolVar(&g.enableCommunityComps, "feature.community-components.enabled", g.enableCommunityComps, "Enable community components.")
+	return cmd
+}
+
+type alloyGraph struct {
+	format               string
+	url                  string
+	minStability         featuregate.Stability
+	enableCommunityComps bool
+}
+
+func (g *alloyGraph) Run(w io.Writer, configPath string) error {
+	var write func(io.Writer, *componentGraph) error
+	switch g.format {
+	case "dot":
+		write = writeGraphDOT
+	case "mermaid":
+		write = writeGraphMermaid
+	case "json":
+		write = writeGraphJSON
+	default:
+		return fmt.Errorf("unsupported format %q", g.format)
+	}
+
+	var (
+		graph *componentGraph
+		err   error
+	)
+	if g.url != "" {
+		graph, err = buildComponentGraph(&remoteComponentLister{baseURL: g.url, client: http.DefaultClient})
+	} else {
+		graph, err = g.buildLocal(configPath)
+	}
+	if err != nil {
+		return err
+	}
+	return write(w, graph)
+}
+
+// buildLocal builds the graph of the configuration at configPath.
+func (g *alloyGraph) buildLocal(configPath string) (*componentGraph, error) {
+	sources, err := readConfigSources(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	f, cleanup, err := loadValidateOnly(sources, g.minStability, g.enableCommunityComps)
+	defer cleanup()
+	if err != nil {
+		return nil, reportDiagnostics(sources, err, "loading")
+	}
+
+	return buildComponentGraph(componentListerFunc(func(moduleID string) ([]graphNode, error) {
+		infos, err := f.ListComponents(moduleID, component.InfoOptions{})
+		if err != nil {
+			return nil, err
+		}
+		nodes := make([]graphNode, 0, len(infos))
+		for _, info := range infos {
+			nodes = append(nodes, graphNode{
+				ModuleID:            info.ID.ModuleID,
+				LocalID:             info.ID.LocalID,
+				Name:                info.ComponentName,
+				Label:               info.Label,
+				References:          info.References,
+				ReferenceAttributes: info.ReferenceAttributes,
+				Modules:             info.ModuleIDs,
+			})
+		}
+		return nodes, nil
+	}))
+}
+
+// componentGraph is the graph of the components of a configuration.
+type componentGraph struct {
+	Components []graphNode `json:"components"`
+	Edges      []graphEdge `json:"edges"`
+}
+
+// graphNode is a component of a componentGraph.
+type graphNode struct {
+	ID       string   `json:"id"`                // Globally unique ID of the component.
+	ModuleID string   `json:"moduleID"`          // ID of the module of the component. Empty for the root module.
+	LocalID  string   `json:"localID"`           // ID of the component within its module.
+	Name     string   `json:"name"`              // Name of the component.
+	Label    string   `json:"label,omitempty"`   // Label of the component.
+	Modules  []string `json:"modules,omitempty"` // IDs of the modules run by the component.
+
+	References          []string            `json:"-"`
+	ReferenceAttributes map[string][]string `json:"-"`
+}
+
+// graphEdge is a reference from a component to another component of the
+// same module.
+type graphEdge struct {
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+	Attributes []string `json:"attributes,omitempty"` // Attributes of From which reference To.
+}
+
+// componentLister lists the components of a module.
+type componentLister interface {
+	ListComponents(moduleID string) ([]graphNode, error)
+}
+
+type componentListerFunc func(moduleID string) ([]graphNode, error)
+
+func (f componentListerFunc) ListComponents(moduleID string) ([]graphNode, error) {
+	return f(moduleID)
+}
+
+// buildComponentGraph builds the graph of the components of the root module
+// listed by l, and of the modules they run.
+func buildComponentGraph(l componentLister) (*componentGraph, error) {
+	var graph componentGraph
+
+	modules := []string{""}
+	for len(modules) > 0 {
+		moduleID := modules[0]
+		modules = modules[1:]
+
+		nodes, err := l.ListComponents(moduleID)
+		if err != nil {
+			return nil, fmt.Errorf("listing the components of module %q: %w", moduleID, err)
+		}
+
+		for _, n := range nodes {
+			n.ID = path.Join(n.ModuleID, n.LocalID)
+			sort.Strings(n.Modules)
+			modules = append(modules, n.Modules...)
+			graph.Components = append(graph.Components, n)
+
+			for _, ref := range n.References {
+				graph.Edges = append(graph.Edges, graphEdge{
+					From:       n.ID,
+					To:         path.Join(n.ModuleID, ref),
+					Attributes: n.ReferenceAttributes[ref],
+				})
+			}
+		}
+	}
+
+	sort.Slice(graph.Components, func(i, j int) bool { return graph.Components[i].ID < graph.Components[j].ID })
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return &graph, nil
+}
+
+// remoteComponentLister lists the components of a running instance through
+// the API of its UI.
+type remoteComponentLister struct {
+	baseURL string
+	client  *http.Client
+}
+
+func (l *remoteComponentLister) ListComponents(moduleID string) ([]graphNode, error) {
+	u, err := url.Parse(l.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if moduleID == "" {
+		u.Path = path.Join(u.Path, "/api/v0/web/components")
+	} else {
+		u.Path = path.Join(u.Path, "/api/v0/web/modules", moduleID, "components")
+	}
+
+	resp, err := l.client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bb, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s: %s: %s", u, resp.Status, strings.TrimSpace(string(bb)))
+	}
+
+	var infos []struct {
+		Name                string              `json:"name"`
+		LocalID             string              `json:"localID"`
+		ModuleID            string              `json:"moduleID"`
+		Label               string              `json:"label"`
+		References          []string            `json:"referencesTo"`
+		ReferenceAttributes map[string][]string `json:"referenceAttributes"`
+		CreatedModuleIDs    []string            `json:"createdModuleIDs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&infos); err != nil {
+		return nil, fmt.Errorf("decoding the components of %s: %w", u, err)
+	}
+
+	nodes := make([]graphNode, 0, len(infos))
+	for _, info := range infos {
+		nodes = append(nodes, graphNode{
+			ModuleID:            info.ModuleID,
+			LocalID:             info.LocalID,
+			Name:                info.Name,
+			Label:               info.Label,
+			References:          info.References,
+			ReferenceAttributes: info.ReferenceAttributes,
+			Modules:             info.CreatedModuleIDs,
+		})
+	}
+	return nodes, nil
+}
+
+func writeGraphJSON(w io.Writer, graph *componentGraph) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(graph)
+}
+
+// graphModules groups the components of graph by module, and returns the
+// modules run by each component.
+func graphModules(graph *componentGraph) (components map[string][]graphNode, modules map[string][]string) {
+	components = make(map[string][]graphNode)
+	modules = make(map[string][]string)
+	for _, n := range graph.Components {
+		components[n.ModuleID] = append(components[n.ModuleID], n)
+		modules[n.ID] = n.Modules
+	}
+	return components, modules
+}
+
+// writeGraphDOT writes graph in the DOT language of Graphviz. Modules are
+// written as clusters nested in the cluster of their parent module.
+func writeGraphDOT(w io.Writer, graph *componentGraph) error {
+	components, modules := graphModules(graph)
+
+	var sb strings.Builder
+	sb.WriteString("digraph {\n")
+	sb.WriteString("\tnode [shape=box];\n")
+
+	var writeModule func(moduleID, indent string)
+	writeModule = func(moduleID, indent string) {
+		for _, n := range components[moduleID] {
+			fmt.Fprintf(&sb, "%s%s [label=%s];\n", indent, strconv.Quote(n.ID), strconv.Quote(n.LocalID))
+			for _, child := range modules[n.ID] {
+				fmt.Fprintf(&sb, "%ssubgraph %s {\n", indent, strconv.Quote("cluster_"+child))
+				fmt.Fprintf(&sb, "%s\tlabel=%s;\n", indent, strconv.Quote(child))
+				writeModule(child, indent+"\t")
+				fmt.Fprintf(&sb, "%s}\n", indent)
+			}
+		}
+	}
+	writeModule("", "\t")
+
+	for _, e := range graph.Edges {
+		fmt.Fprintf(&sb, "\t%s -> %s", strconv.Quote(e.From), strconv.Quote(e.To))
+		if len(e.Attributes) > 0 {
+			fmt.Fprintf(&sb, " [label=%s]", strconv.Quote(strings.Join(e.Attributes, ", ")))
+		}
+		sb.WriteString(";\n")
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeGraphMermaid writes graph as a Mermaid flowchart. Modules are written
+// as subgraphs nested in the subgraph of their parent module.
+func writeGraphMermaid(w io.Writer, graph *componentGraph) error {
+	components, modules := graphModules(graph)
+
+	// Mermaid IDs can't contain most punctuation, so components and modules
+	// are given generated IDs.
+	ids := make(map[string]string)
+	id := func(key string) string {
+		if _, ok := ids[key]; !ok {
+			ids[key] = fmt.Sprintf("n%d", len(ids))
+		}
+		return ids[key]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+
+	var writeModule func(moduleID, indent string)
+	writeModule = func(moduleID, indent string) {
+		for _, n := range components[moduleID] {
+			fmt.Fprintf(&sb, "%s%s[%s]\n", indent, id("component:"+n.ID), mermaidString(n.LocalID))
+			for _, child := range modules[n.ID] {
+				fmt.Fprintf(&sb, "%ssubgraph %s[%s]\n", indent, id("module:"+child), mermaidString(child))
+				writeModule(child, indent+"  ")
+				fmt.Fprintf(&sb, "%send\n", indent)
+			}
+		}
+	}
+	writeModule("", "  ")
+
+	for _, e := range graph.Edges {
+		from, to := id("component:"+e.From), id("component:"+e.To)
+		if len(e.Attributes) > 0 {
+			fmt.Fprintf(&sb, "  %s -->|%s| %s\n", from, mermaidString(strings.Join(e.Attributes, ", ")), to)
+		} else {
+			fmt.Fprintf(&sb, "  %s --> %s\n", from, to)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// mermaidString quotes s to be used as the text of a Mermaid node or edge.
+func mermaidString(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
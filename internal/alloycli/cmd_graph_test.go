@@ -0,0 +1,117 @@
+package alloycli
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/web/api"
+)
+
+const graphTestConfig = `
+declare "reader" {
+	argument "path" {}
+
+	local.file "file" {
+		filename = argument.path.value
+	}
+
+	export "content" {
+		value = local.file.file.content
+	}
+}
+
+local.file "a" {
+	filename = "/does/not/exist"
+}
+
+reader "default" {
+	path = local.file.a.content
+}
+
+local.file "b" {
+	filename = reader.default.content
+	detector = local.file.a.content
+}
+`
+
+func TestGraph(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.alloy")
+	require.NoError(t, os.WriteFile(path, []byte(graphTestConfig), 0o644))
+
+	tt := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "dot",
+			expected: `digraph {
+	node [shape=box];
+	"local.file.a" [label="local.file.a"];
+	"local.file.b" [label="local.file.b"];
+	"reader.default" [label="reader.default"];
+	subgraph "cluster_reader.default" {
+		label="reader.default";
+		"reader.default/local.file.file" [label="local.file.file"];
+	}
+	"local.file.b" -> "local.file.a" [label="detector"];
+	"local.file.b" -> "reader.default" [label="filename"];
+	"reader.default" -> "local.file.a" [label="path"];
+}
+`,
+		},
+		{
+			format: "mermaid",
+			expected: `flowchart LR
+  n0["local.file.a"]
+  n1["local.file.b"]
+  n2["reader.default"]
+  subgraph n3["reader.default"]
+    n4["local.file.file"]
+  end
+  n1 -->|"detector"| n0
+  n1 -->|"filename"| n2
+  n2 -->|"path"| n0
+`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.format, func(t *testing.T) {
+			g := &alloyGraph{format: tc.format, minStability: featuregate.StabilityGenerallyAvailable}
+
+			var buf bytes.Buffer
+			require.NoError(t, g.Run(&buf, path))
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestGraph_Remote(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.alloy")
+	require.NoError(t, os.WriteFile(path, []byte(graphTestConfig), 0o644))
+
+	sources := map[string][]byte{path: []byte(graphTestConfig)}
+	f, cleanup, err := loadValidateOnly(sources, featuregate.StabilityGenerallyAvailable, false)
+	defer cleanup()
+	require.NoError(t, err)
+
+	r := mux.NewRouter()
+	api.NewAlloyAPI(f, nil).RegisterRoutes("/ui/api/v0/web", r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	var local, remote bytes.Buffer
+	g := &alloyGraph{format: "json", minStability: featuregate.StabilityGenerallyAvailable}
+	require.NoError(t, g.Run(&local, path))
+
+	g = &alloyGraph{format: "json", url: srv.URL + "/ui"}
+	require.NoError(t, g.Run(&remote, ""))
+	require.JSONEq(t, local.String(), remote.String())
+}
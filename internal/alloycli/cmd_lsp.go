@@ -0,0 +1,65 @@
+package alloycli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/grafana/alloy/internal/lsp"
+	"github.com/grafana/alloy/internal/runtime/logging"
+	"github.com/grafana/alloy/internal/runtime/tracing"
+	"github.com/grafana/alloy/internal/service"
+)
+
+func lspCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lsp",
+		Short: "Run a language server for Alloy configuration files",
+		Long: `The lsp subcommand runs a language server for Alloy configuration files,
+communicating with an editor over stdin and stdout using the Language Server
+Protocol.
+
+The language server reports syntax errors and unknown components or arguments
+when a file is saved, completes component names, arguments and exports, shows
+the documentation of components on hover, jumps to the definition of
+component references, and formats files.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runLSP(os.Stdin, os.Stdout)
+		},
+	}
+	return cmd
+}
+
+func runLSP(r io.Reader, w io.Writer) error {
+	// Services are only built to find the blocks they can be configured with.
+	dataPath, err := os.MkdirTemp("", "alloy-lsp")
+	if err != nil {
+		return fmt.Errorf("creating data path: %w", err)
+	}
+	defer os.RemoveAll(dataPath)
+
+	l, err := logging.New(io.Discard, logging.DefaultOptions)
+	if err != nil {
+		return fmt.Errorf("building logger: %w", err)
+	}
+	t, err := tracing.New(tracing.DefaultOptions)
+	if err != nil {
+		return fmt.Errorf("building tracer: %w", err)
+	}
+
+	services, err := validateServices(l, t, dataPath)
+	if err != nil {
+		return err
+	}
+	defs := make([]service.Definition, 0, len(services))
+	for _, svc := range services {
+		defs = append(defs, svc.Definition())
+	}
+
+	return lsp.NewServer(lsp.Options{Services: defs}).Serve(r, w)
+}
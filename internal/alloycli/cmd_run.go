@@ -1,15 +1,19 @@
 package alloycli
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -33,6 +37,7 @@ import (
 	convert_diag "github.com/grafana/alloy/internal/converter/diag"
 	"github.com/grafana/alloy/internal/featuregate"
 	alloy_runtime "github.com/grafana/alloy/internal/runtime"
+	"github.com/grafana/alloy/internal/runtime/lastgood"
 	"github.com/grafana/alloy/internal/runtime/logging"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/internal/runtime/tracing"
@@ -45,7 +50,10 @@ import (
 	uiservice "github.com/grafana/alloy/internal/service/ui"
 	"github.com/grafana/alloy/internal/static/config/instrumentation"
 	"github.com/grafana/alloy/internal/usagestats"
+	"github.com/grafana/alloy/syntax/ast"
 	"github.com/grafana/alloy/syntax/diag"
+	"github.com/grafana/alloy/syntax/encoding/alloyjson"
+	"github.com/grafana/alloy/syntax/printer"
 
 	// Install Components
 	_ "github.com/grafana/alloy/internal/component/all"
@@ -60,10 +68,13 @@ func runCommand() *cobra.Command {
 		uiPrefix:              "/",
 		disableReporting:      false,
 		enablePprof:           true,
-		configFormat:          "alloy",
+		configFormat:          formatAlloy,
+		lastKnownGoodGrace:    lastgood.DefaultGracePeriod,
+		configWatchDebounce:   time.Second,
 		clusterAdvInterfaces:  advertise.DefaultInterfaces,
 		clusterMaxJoinPeers:   5,
 		clusterRejoinInterval: 60 * time.Second,
+		restartPolicy:         alloy_runtime.DefaultRestartPolicy,
 	}
 
 	cmd := &cobra.Command{
@@ -90,7 +101,14 @@ debugging UI can be changed by providing a different value to
 
 Additionally, the HTTP server exposes the following debug endpoints:
 
-  /debug/pprof   Go performance profiling tools
+  /debug/pprof             Go performance profiling tools
+  /debug/pprof/components  CPU time and goroutines attributed to components
+  /-/reload/dry-run        Report how reloading would change the running components
+
+When --config.dry-run is set, run doesn't start Grafana Alloy. Instead, it
+sends the configuration to the /-/reload/dry-run endpoint of the instance
+listening on --server.http.listen-addr and prints how loading it would change
+the components of that instance.
 
 If reloading the config dir/file-path fails, Grafana Alloy will continue running in
 its last valid state. Components which failed may be be listed as unhealthy,
@@ -137,9 +155,22 @@ depending on the nature of the reload error.
 		BoolVar(&r.clusterUseDiscoveryV1, "cluster.use-discovery-v1", r.clusterUseDiscoveryV1, "Use the older, v1 version of cluster peers discovery. Note that this flag will be deprecated in the future and eventually removed.")
 
 	// Config flags
-	cmd.Flags().StringVar(&r.configFormat, "config.format", r.configFormat, fmt.Sprintf("The format of the source file. Supported formats: %s.", supportedFormatsList()))
+	cmd.Flags().StringVar(&r.configFormat, "config.format", r.configFormat, fmt.Sprintf("The format of the source file. Supported formats: %q, %q, %s.", formatAlloy, formatAlloyJSON, supportedFormatsList()))
 	cmd.Flags().BoolVar(&r.configBypassConversionErrors, "config.bypass-conversion-errors", r.configBypassConversionErrors, "Enable bypassing errors when converting")
 	cmd.Flags().StringVar(&r.configExtraArgs, "config.extra-args", r.configExtraArgs, "Extra arguments from the original format used by the converter. Multiple arguments can be passed by separating them with a space.")
+	cmd.Flags().DurationVar(&r.lastKnownGoodGrace, "config.last-known-good.grace-period", r.lastKnownGoodGrace, "Time the components of a loaded configuration have to become healthy for it to be persisted as the last known good configuration. 0 disables persisting the last known good configuration.")
+	cmd.Flags().BoolVar(&r.lastKnownGoodRollback, "config.last-known-good.rollback", r.lastKnownGoodRollback, "Roll back to the last known good configuration when the components of a loaded configuration don't become healthy within the grace period, or when the configuration can't be loaded at startup.")
+	cmd.Flags().BoolVar(&r.configWatchEnabled, "config.watch.enabled", r.configWatchEnabled, "Reload the configuration when the configuration file or directory changes.")
+	cmd.Flags().DurationVar(&r.configWatchDebounce, "config.watch.debounce", r.configWatchDebounce, "Time to wait for the configuration to stop changing before reloading it.")
+	cmd.Flags().BoolVar(&r.configDryRun, "config.dry-run", r.configDryRun, "Print how the configuration would change the components of the instance listening on --server.http.listen-addr, without applying it.")
+
+	// Component flags
+	cmd.Flags().
+		IntVar(&r.restartPolicy.MaxRestarts, "component.restart.max-restarts", r.restartPolicy.MaxRestarts, "Maximum number of consecutive restarts of components which exit with an error. 0 disables restarts; a negative value restarts components indefinitely.")
+	cmd.Flags().
+		DurationVar(&r.restartPolicy.InitialBackoff, "component.restart.initial-backoff", r.restartPolicy.InitialBackoff, "Time to wait before restarting a component which exited with an error for the first time")
+	cmd.Flags().
+		DurationVar(&r.restartPolicy.MaxBackoff, "component.restart.max-backoff", r.restartPolicy.MaxBackoff, "Maximum time to wait between consecutive restarts of a component")
 
 	// Misc flags
 	cmd.Flags().
@@ -171,7 +202,13 @@ type alloyRun struct {
 	configFormat                 string
 	configBypassConversionErrors bool
 	configExtraArgs              string
+	configDryRun                 bool
+	lastKnownGoodGrace           time.Duration
+	lastKnownGoodRollback        bool
+	configWatchEnabled           bool
+	configWatchDebounce          time.Duration
 	enableCommunityComps         bool
+	restartPolicy                alloy_runtime.RestartPolicy
 }
 
 func (fr *alloyRun) Run(configPath string) error {
@@ -184,6 +221,15 @@ func (fr *alloyRun) Run(configPath string) error {
 	if configPath == "" {
 		return fmt.Errorf("path argument not provided")
 	}
+	if err := fr.restartPolicy.Validate(); err != nil {
+		return fmt.Errorf("invalid --component.restart flags: %w", err)
+	}
+	if fr.configDryRun {
+		return fr.dryRun(os.Stdout, configPath)
+	}
+	if fr.lastKnownGoodRollback && fr.lastKnownGoodGrace <= 0 {
+		return fmt.Errorf("--config.last-known-good.rollback requires --config.last-known-good.grace-period to be greater than 0")
+	}
 
 	// Buffer logs until log format has been determined
 	l, err := logging.NewDeferred(os.Stderr)
@@ -237,6 +283,7 @@ func (fr *alloyRun) Run(configPath string) error {
 	// service needs and set them after the Alloy controller exists.
 	var (
 		reload func() (*alloy_runtime.Source, error)
+		dryRun func(*alloy_runtime.Source) (*alloy_runtime.DryRunReport, error)
 		ready  func() bool
 	)
 
@@ -270,6 +317,7 @@ func (fr *alloyRun) Run(configPath string) error {
 
 		ReadyFunc:  func() bool { return ready() },
 		ReloadFunc: func() (*alloy_runtime.Source, error) { return reload() },
+		DryRunFunc: func(s *alloy_runtime.Source) (*alloy_runtime.DryRunReport, error) { return dryRun(s) },
 
 		HTTPListenAddr:   fr.httpListenAddr,
 		MemoryListenAddr: fr.inMemoryAddr,
@@ -307,6 +355,7 @@ func (fr *alloyRun) Run(configPath string) error {
 		Reg:                  reg,
 		MinStability:         fr.minStability,
 		EnableCommunityComps: fr.enableCommunityComps,
+		RestartPolicy:        fr.restartPolicy,
 		Services: []service.Service{
 			clusterService,
 			httpService,
@@ -318,6 +367,27 @@ func (fr *alloyRun) Run(configPath string) error {
 		},
 	})
 
+	// Configs are loaded through the last known good tracker when it's enabled,
+	// so that configs whose components become healthy are persisted.
+	loadSource := func(source *alloy_runtime.Source) error { return f.LoadSource(source, nil) }
+	var lastKnownGood *lastgood.Tracker
+	if fr.lastKnownGoodGrace > 0 {
+		lastKnownGood, err = lastgood.New(lastgood.Options{
+			Logger:      l,
+			StoragePath: fr.storagePath,
+			GracePeriod: fr.lastKnownGoodGrace,
+			Rollback:    fr.lastKnownGoodRollback,
+			Metrics:     reg,
+			Components:  f,
+			Load:        loadSource,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create the last known good config tracker: %w", err)
+		}
+		defer lastKnownGood.Stop()
+		loadSource = lastKnownGood.LoadSource
+	}
+
 	ready = f.Ready
 	reload = func() (*alloy_runtime.Source, error) {
 		alloySource, err := loadAlloySource(configPath, fr.configFormat, fr.configBypassConversionErrors, fr.configExtraArgs)
@@ -327,12 +397,22 @@ func (fr *alloyRun) Run(configPath string) error {
 		if err != nil {
 			return nil, fmt.Errorf("reading config path %q: %w", configPath, err)
 		}
-		if err := f.LoadSource(alloySource, nil); err != nil {
+		if err := loadSource(alloySource); err != nil {
 			return alloySource, fmt.Errorf("error during the initial load: %w", err)
 		}
 
 		return alloySource, nil
 	}
+	dryRun = func(source *alloy_runtime.Source) (*alloy_runtime.DryRunReport, error) {
+		if source == nil {
+			var err error
+			source, err = loadAlloySource(configPath, fr.configFormat, fr.configBypassConversionErrors, fr.configExtraArgs)
+			if err != nil {
+				return nil, fmt.Errorf("reading config path %q: %w", configPath, err)
+			}
+		}
+		return f.DryRun(source, nil)
+	}
 
 	// Alloy controller
 	{
@@ -357,10 +437,35 @@ func (fr *alloyRun) Run(configPath string) error {
 		}()
 	}
 
+	reloadAndLog := func() {
+		if _, err := reload(); err != nil {
+			level.Error(l).Log("msg", "failed to reload config", "err", err)
+		} else {
+			level.Info(l).Log("msg", "config reloaded")
+		}
+	}
+
+	// The watcher must be created before the initial load so that changes made
+	// during the initial load are detected.
+	var watcher *configWatcher
+	if fr.configWatchEnabled {
+		watcher, err = newConfigWatcher(l, configPath, fr.configWatchDebounce, reloadAndLog)
+		if err != nil {
+			return fmt.Errorf("failed to watch the config: %w", err)
+		}
+	}
+
 	// Perform the initial reload. This is done after starting the HTTP server so
 	// that /metric and pprof endpoints are available while the Alloy controller
 	// is loading.
-	if source, err := reload(); err != nil {
+	source, err := reload()
+	if err != nil && fr.lastKnownGoodRollback {
+		level.Error(l).Log("msg", "failed to load the config, rolling back to the last known good config", "err", err)
+		if lastKnownGood.Rollback() == nil {
+			err = nil
+		}
+	}
+	if err != nil {
 		var diags diag.Diagnostics
 		if errors.As(err, &diags) {
 			p := diag.NewPrinter(diag.PrinterConfig{
@@ -389,6 +494,16 @@ func (fr *alloyRun) Run(configPath string) error {
 		return fmt.Errorf("failed to set clusterer state to Participant after initial load")
 	}
 
+	if watcher != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := watcher.Run(ctx); err != nil {
+				level.Error(l).Log("msg", "failed to watch the config, it won't be reloaded on change", "err", err)
+			}
+		}()
+	}
+
 	reloadSignal := make(chan os.Signal, 1)
 	signal.Notify(reloadSignal, syscall.SIGHUP)
 	defer signal.Stop(reloadSignal)
@@ -398,15 +513,47 @@ func (fr *alloyRun) Run(configPath string) error {
 		case <-ctx.Done():
 			return nil
 		case <-reloadSignal:
-			if _, err := reload(); err != nil {
-				level.Error(l).Log("msg", "failed to reload config", "err", err)
-			} else {
-				level.Info(l).Log("msg", "config reloaded")
-			}
+			reloadAndLog()
 		}
 	}
 }
 
+// dryRun sends the config at configPath to the /-/reload/dry-run endpoint of
+// the instance listening on the HTTP listen address, and writes the report of
+// the instance to w.
+func (fr *alloyRun) dryRun(w io.Writer, configPath string) error {
+	source, err := loadAlloySource(configPath, fr.configFormat, fr.configBypassConversionErrors, fr.configExtraArgs)
+	if err != nil {
+		return fmt.Errorf("reading config path %q: %w", configPath, err)
+	}
+
+	// The files of a config directory are combined into a single unit, so they
+	// can be sent as a single file.
+	var (
+		files = source.RawConfigs()
+		names = maps.Keys(files)
+		body  bytes.Buffer
+	)
+	sort.Strings(names)
+	for _, name := range names {
+		body.Write(files[name])
+		body.WriteString("\n")
+	}
+
+	resp, err := http.Post(fmt.Sprintf("http://%s/-/reload/dry-run", fr.httpListenAddr), "text/plain", &body)
+	if err != nil {
+		return fmt.Errorf("sending config: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("dry run failed: %s", strings.TrimSpace(string(msg)))
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
 // getEnabledComponentsFunc returns a function that gets the current enabled components
 func getEnabledComponentsFunc(f *alloy_runtime.Runtime) func() map[string]interface{} {
 	return func() map[string]interface{} {
@@ -422,6 +569,12 @@ func getEnabledComponentsFunc(f *alloy_runtime.Runtime) func() map[string]interf
 	}
 }
 
+// Formats of configuration files which don't need a converter.
+const (
+	formatAlloy     = "alloy"
+	formatAlloyJSON = "alloyjson"
+)
+
 func loadAlloySource(path string, converterSourceFormat string, converterBypassErrors bool, configExtraArgs string) (*alloy_runtime.Source, error) {
 	fi, err := os.Stat(path)
 	if err != nil {
@@ -429,27 +582,10 @@ func loadAlloySource(path string, converterSourceFormat string, converterBypassE
 	}
 
 	if fi.IsDir() {
-		sources := map[string][]byte{}
-		err := filepath.WalkDir(path, func(curPath string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
-			// Skip all directories and don't recurse into child dirs that aren't at top-level
-			if d.IsDir() {
-				if curPath != path {
-					return filepath.SkipDir
-				}
-				return nil
-			}
-			// Ignore files not ending in .alloy extension
-			if !strings.HasSuffix(curPath, ".alloy") {
-				return nil
-			}
-
-			bb, err := os.ReadFile(curPath)
-			sources[curPath] = bb
-			return err
-		})
+		if converterSourceFormat == formatAlloyJSON {
+			return nil, fmt.Errorf("the %s format is only supported for a single file, but %s is a directory", formatAlloyJSON, path)
+		}
+		sources, err := readAlloySources(path)
 		if err != nil {
 			return nil, err
 		}
@@ -461,7 +597,15 @@ func loadAlloySource(path string, converterSourceFormat string, converterBypassE
 	if err != nil {
 		return nil, err
 	}
-	if converterSourceFormat != "alloy" {
+	switch converterSourceFormat {
+	case formatAlloy:
+		// Nothing to convert.
+	case formatAlloyJSON:
+		bb, err = alloyJSONToSource(bb)
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", path, err)
+		}
+	default:
 		var diags convert_diag.Diagnostics
 		ea, err := parseExtraArgs(configExtraArgs)
 		if err != nil {
@@ -481,6 +625,53 @@ func loadAlloySource(path string, converterSourceFormat string, converterBypassE
 	return alloy_runtime.ParseSource(path, bb)
 }
 
+// readAlloySources reads all the *.alloy files at the top level of the
+// directory dir. The returned map is keyed by file path.
+func readAlloySources(dir string) (map[string][]byte, error) {
+	sources := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(curPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Skip all directories and don't recurse into child dirs that aren't at top-level
+		if d.IsDir() {
+			if curPath != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Ignore files not ending in .alloy extension
+		if !strings.HasSuffix(curPath, ".alloy") {
+			return nil
+		}
+
+		bb, err := os.ReadFile(curPath)
+		sources[curPath] = bb
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// alloyJSONToSource converts a configuration encoded with alloyjson into
+// Alloy syntax.
+func alloyJSONToSource(bb []byte) ([]byte, error) {
+	body, err := alloyjson.UnmarshalBody(bb)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, &ast.File{Body: body}); err != nil {
+		return nil, err
+	}
+	// Add a newline at the end of the file.
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
 func interruptContext() (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -0,0 +1,58 @@
+package alloycli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAlloySource_AlloyJSON(t *testing.T) {
+	config := `[
+		{ "name": "logging", "type": "block", "body": [
+			{ "name": "level", "type": "attr", "value": { "type": "string", "value": "debug" } }
+		] },
+		{ "name": "local.file", "type": "block", "label": "a", "body": [
+			{ "name": "filename", "type": "attr", "value": { "type": "string", "value": "/tmp/a" } }
+		] },
+		{ "name": "local.file", "type": "block", "label": "b", "body": [
+			{ "name": "filename", "type": "attr", "value": { "type": "expr", "value": "local.file.a.content" } }
+		] }
+	]`
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(config), 0o644))
+
+	source, err := loadAlloySource(path, formatAlloyJSON, false, "")
+	require.NoError(t, err)
+
+	expect := `logging {
+	level = "debug"
+}
+
+local.file "a" {
+	filename = "/tmp/a"
+}
+
+local.file "b" {
+	filename = local.file.a.content
+}
+`
+	require.Equal(t, map[string][]byte{path: []byte(expect)}, source.RawConfigs())
+}
+
+func TestLoadAlloySource_AlloyJSONError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{ "name": "local.file", "type": "blocks" }]`), 0o644))
+
+	_, err := loadAlloySource(path, formatAlloyJSON, false, "")
+	require.EqualError(t, err, "decoding "+path+`: 1:34: unrecognized statement type "blocks"`)
+}
+
+func TestLoadAlloySource_AlloyJSONDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(`[]`), 0o644))
+
+	_, err := loadAlloySource(dir, formatAlloyJSON, false, "")
+	require.EqualError(t, err, "the alloyjson format is only supported for a single file, but "+dir+" is a directory")
+}
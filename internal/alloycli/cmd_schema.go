@@ -0,0 +1,191 @@
+package alloycli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/syntax"
+	"github.com/grafana/alloy/syntax/encoding/alloyjson"
+	"github.com/grafana/alloy/syntax/schema"
+)
+
+func schemaCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema [component ...]",
+		Short: "Print the schema of components as JSON",
+		Long: `The schema subcommand prints a JSON description of the arguments and
+exports of components, generated from the registered components.
+
+For every component, the description includes its stability level and the
+attributes and blocks of its arguments and exports, with their type, whether
+they're required and their default value.
+
+If no component names are given, all components are described.`,
+		SilenceUsage: true,
+
+		RunE: func(_ *cobra.Command, args []string) error {
+			return writeSchema(os.Stdout, args)
+		},
+	}
+	return cmd
+}
+
+// componentsSchema is the output of the schema command.
+type componentsSchema struct {
+	Components []componentSchema `json:"components"`
+}
+
+// componentSchema describes a component.
+type componentSchema struct {
+	Name      string        `json:"name"`
+	Stability string        `json:"stability"`
+	Community bool          `json:"community,omitempty"`
+	Arguments []fieldSchema `json:"arguments"`
+	Exports   []fieldSchema `json:"exports"`
+}
+
+// fieldSchema describes an attribute or a block. Blocks of a kind "enum"
+// field are mutually exclusive blocks which can be set in any order, such as
+// the stages of loki.process.
+type fieldSchema struct {
+	Name     string          `json:"name"`
+	Kind     string          `json:"kind"`
+	Type     string          `json:"type,omitempty"`
+	Required bool            `json:"required"`
+	Multiple bool            `json:"multiple,omitempty"`
+	Labeled  bool            `json:"labeled,omitempty"`
+	Default  json.RawMessage `json:"default,omitempty"` // Value in the alloyjson encoding.
+	Fields   []fieldSchema   `json:"fields,omitempty"`
+}
+
+// writeSchema writes the schema of the named components as indented JSON to
+// w. All registered components are described if names is empty.
+func writeSchema(w io.Writer, names []string) error {
+	if len(names) == 0 {
+		names = component.AllNames()
+	}
+	sort.Strings(names)
+
+	out := componentsSchema{Components: []componentSchema{}}
+	for _, name := range names {
+		reg, ok := component.Get(name)
+		if !ok {
+			return fmt.Errorf("unrecognized component name %q", name)
+		}
+		cs, err := describeComponent(reg)
+		if err != nil {
+			return fmt.Errorf("describing %s: %w", name, err)
+		}
+		out.Components = append(out.Components, cs)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func describeComponent(reg component.Registration) (componentSchema, error) {
+	stability, _ := strconv.Unquote(reg.Stability.String())
+	cs := componentSchema{
+		Name:      reg.Name,
+		Stability: stability,
+		Community: reg.Community,
+		Arguments: []fieldSchema{},
+		Exports:   []fieldSchema{},
+	}
+
+	if reg.Args != nil {
+		argsType := reflect.TypeOf(reg.Args)
+		args, err := describeFields(schema.Fields(argsType), defaultValue(argsType))
+		if err != nil {
+			return cs, err
+		}
+		cs.Arguments = args
+	}
+	if reg.Exports != nil {
+		// Exports are set by components, so they don't have defaults.
+		exports, err := describeFields(schema.Fields(reflect.TypeOf(reg.Exports)), reflect.Value{})
+		if err != nil {
+			return cs, err
+		}
+		cs.Exports = exports
+	}
+	return cs, nil
+}
+
+// describeFields describes fields. Defaults are read from defaults, a struct
+// value of the type fields were generated from; no defaults are reported if
+// defaults is the zero Value.
+func describeFields(fields []schema.Field, defaults reflect.Value) ([]fieldSchema, error) {
+	res := make([]fieldSchema, 0, len(fields))
+	for _, f := range fields {
+		fs := fieldSchema{
+			Name:     f.Name,
+			Kind:     f.Kind.String(),
+			Required: !f.Optional,
+			Multiple: f.Multiple,
+			Labeled:  f.Labeled,
+		}
+
+		var fieldDefault reflect.Value
+		if defaults.IsValid() {
+			fieldDefault, _ = defaults.FieldByIndexErr(f.Index)
+		}
+
+		var err error
+		switch f.Kind {
+		case schema.KindAttr:
+			fs.Type = schema.TypeName(f.Type)
+			if f.Optional && fieldDefault.IsValid() && !fieldDefault.IsZero() {
+				fs.Default, err = alloyjson.MarshalValue(fieldDefault.Interface())
+			}
+
+		case schema.KindBlock:
+			var blockDefaults reflect.Value
+			switch {
+			case !defaults.IsValid():
+			case f.Multiple || !fieldDefault.IsValid() || fieldDefault.IsZero():
+				// Blocks which aren't set by the defaults of their parent are
+				// decoded into a new value.
+				blockDefaults = defaultValue(f.Type)
+			default:
+				blockDefaults = reflect.Indirect(fieldDefault)
+			}
+			fs.Fields, err = describeFields(f.Fields, blockDefaults)
+
+		case schema.KindEnum:
+			var blockDefaults reflect.Value
+			if defaults.IsValid() {
+				blockDefaults = defaultValue(f.Type)
+			}
+			fs.Fields, err = describeFields(f.Fields, blockDefaults)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+
+		res = append(res, fs)
+	}
+	return res, nil
+}
+
+// defaultValue returns a new value of t, with pointers dereferenced, set to
+// its defaults if it implements syntax.Defaulter.
+func defaultValue(t reflect.Type) reflect.Value {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	rv := reflect.New(t)
+	if d, ok := rv.Interface().(syntax.Defaulter); ok {
+		d.SetToDefault()
+	}
+	return rv.Elem()
+}
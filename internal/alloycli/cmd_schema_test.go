@@ -0,0 +1,86 @@
+package alloycli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeSchema(&buf, []string{"local.file", "discovery.relabel"}))
+
+	var out componentsSchema
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	require.Len(t, out.Components, 2)
+
+	relabel := out.Components[0]
+	require.Equal(t, "discovery.relabel", relabel.Name)
+	require.Equal(t, "generally-available", relabel.Stability)
+
+	// Defaults of nested blocks are read from the block type.
+	rule := relabel.Arguments[1]
+	require.Equal(t, "rule", rule.Name)
+	require.Equal(t, "block", rule.Kind)
+	require.False(t, rule.Required)
+	require.True(t, rule.Multiple)
+	require.Equal(t, fieldSchema{
+		Name:    "action",
+		Kind:    "attr",
+		Type:    "string",
+		Default: json.RawMessage(`{"type":"string","value":"replace"}`),
+	}, compactDefault(t, rule.Fields[6]))
+
+	file := out.Components[1]
+	require.Equal(t, "local.file", file.Name)
+	require.Equal(t, []fieldSchema{
+		{Name: "filename", Kind: "attr", Type: "string", Required: true},
+		{Name: "detector", Kind: "attr", Type: "string", Default: json.RawMessage(`{"type":"string","value":"fsnotify"}`)},
+		{Name: "poll_frequency", Kind: "attr", Type: "duration", Default: json.RawMessage(`{"type":"string","value":"1m0s"}`)},
+		{Name: "is_secret", Kind: "attr", Type: "bool"},
+	}, compactDefaults(t, file.Arguments))
+	require.Equal(t, []fieldSchema{
+		{Name: "content", Kind: "attr", Type: "secret", Required: true},
+	}, file.Exports)
+}
+
+func TestSchema_AllComponents(t *testing.T) {
+	names := component.AllNames()
+	require.NotEmpty(t, names)
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			reg, ok := component.Get(name)
+			require.True(t, ok)
+			cs, err := describeComponent(reg)
+			require.NoError(t, err)
+			require.Equal(t, name, cs.Name)
+		})
+	}
+}
+
+func TestSchema_UnknownComponent(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeSchema(&buf, []string{"local.fil"})
+	require.EqualError(t, err, `unrecognized component name "local.fil"`)
+}
+
+// compactDefaults removes the indentation of the defaults of fields.
+func compactDefaults(t *testing.T, fields []fieldSchema) []fieldSchema {
+	for i := range fields {
+		fields[i] = compactDefault(t, fields[i])
+	}
+	return fields
+}
+
+func compactDefault(t *testing.T, f fieldSchema) fieldSchema {
+	if f.Default != nil {
+		var buf bytes.Buffer
+		require.NoError(t, json.Compact(&buf, f.Default))
+		f.Default = buf.Bytes()
+	}
+	return f
+}
@@ -0,0 +1,222 @@
+package alloycli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/cobra"
+
+	"github.com/grafana/alloy/internal/featuregate"
+	alloy_runtime "github.com/grafana/alloy/internal/runtime"
+	"github.com/grafana/alloy/internal/runtime/logging"
+	"github.com/grafana/alloy/internal/runtime/tracing"
+	"github.com/grafana/alloy/internal/service"
+	httpservice "github.com/grafana/alloy/internal/service/http"
+	"github.com/grafana/alloy/internal/service/labelstore"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+	otel_service "github.com/grafana/alloy/internal/service/otel"
+	remotecfgservice "github.com/grafana/alloy/internal/service/remotecfg"
+	uiservice "github.com/grafana/alloy/internal/service/ui"
+	"github.com/grafana/alloy/syntax/diag"
+)
+
+func validateCommand() *cobra.Command {
+	v := &alloyValidate{
+		minStability: featuregate.StabilityGenerallyAvailable,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "validate [flags] path",
+		Short: "Validate a configuration file or directory",
+		Long: `The validate subcommand checks an Alloy configuration file or directory
+for errors without running it.
+
+If path is a directory, all *.alloy files in that directory will be combined
+into a single unit, the same way the run subcommand loads them.
+
+validate parses the configuration, resolves the modules of import and declare
+blocks, checks that every referenced component exists and decodes the
+arguments of every block into the type expected by its component. Components
+are never built or run, so validate doesn't need access to the systems the
+configuration interacts with. Imported modules are still retrieved from their
+source.
+
+All errors found in the configuration are reported, and validate exits with
+a non-zero exit code if there was at least one error.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+
+		RunE: func(_ *cobra.Command, args []string) error {
+			return v.Run(args[0])
+		},
+	}
+
+	cmd.Flags().Var(&v.minStability, "stability.level", fmt.Sprintf("Minimum stability level of features to enable. Supported values: %s", strings.Join(featuregate.AllowedValues(), ", ")))
+	cmd.Flags().BoolVar(&v.enableCommunityComps, "feature.community-components.enabled", v.enableCommunityComps, "Enable community components.")
+	return cmd
+}
+
+type alloyValidate struct {
+	minStability         featuregate.Stability
+	enableCommunityComps bool
+}
+
+func (v *alloyValidate) Run(configPath string) error {
+	sources, err := readConfigSources(configPath)
+	if err != nil {
+		return err
+	}
+	return reportDiagnostics(sources, v.validate(sources), "validation")
+}
+
+// readConfigSources reads the config file at path, or the *.alloy files of
+// the directory at path.
+func readConfigSources(path string) (map[string][]byte, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return readAlloySources(path)
+	}
+
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{path: bb}, nil
+}
+
+// reportDiagnostics prints err to stderr if it contains diagnostics for
+// sources, and returns an error mentioning the operation which failed
+// instead.
+func reportDiagnostics(sources map[string][]byte, err error, operation string) error {
+	var diags diag.Diagnostics
+	if errors.As(err, &diags) {
+		p := diag.NewPrinter(diag.PrinterConfig{
+			Color:              !color.NoColor,
+			ContextLinesBefore: 1,
+			ContextLinesAfter:  1,
+		})
+		_ = p.Fprint(os.Stderr, sources, diags)
+
+		// Print newline after the diagnostics.
+		fmt.Fprintln(os.Stderr)
+
+		return fmt.Errorf("encountered errors during %s", operation)
+	}
+	return err
+}
+
+// validate parses and loads sources into a controller which only decodes the
+// arguments of components. Errors in the configuration are returned as
+// diag.Diagnostics.
+func (v *alloyValidate) validate(sources map[string][]byte) error {
+	_, cleanup, err := loadValidateOnly(sources, v.minStability, v.enableCommunityComps)
+	cleanup()
+	return err
+}
+
+// loadValidateOnly parses and loads sources into a controller which only
+// decodes the arguments of components. The controller is returned even if
+// loading sources failed, with the components which could be loaded. cleanup
+// must be called once the controller isn't used anymore.
+func loadValidateOnly(sources map[string][]byte, minStability featuregate.Stability, enableCommunityComps bool) (f *alloy_runtime.Runtime, cleanup func(), err error) {
+	cleanup = func() {}
+
+	source, err := alloy_runtime.ParseSources(sources)
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	// Imported modules and services may write to the data path; use a
+	// temporary one so that validating doesn't alter the data of a running
+	// instance.
+	dataPath, err := os.MkdirTemp("", "alloy-validate")
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("creating data path: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(dataPath) }
+
+	l, err := logging.New(io.Discard, logging.DefaultOptions)
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("building logger: %w", err)
+	}
+	t, err := tracing.New(tracing.DefaultOptions)
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("building tracer: %w", err)
+	}
+
+	services, err := validateServices(l, t, dataPath)
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	f = alloy_runtime.New(alloy_runtime.Options{
+		Logger:               l,
+		Tracer:               t,
+		DataPath:             dataPath,
+		Reg:                  prometheus.NewRegistry(),
+		MinStability:         minStability,
+		EnableCommunityComps: enableCommunityComps,
+		ValidateOnly:         true,
+		Services:             services,
+	})
+	return f, cleanup, f.LoadSource(source, nil)
+}
+
+// validateServices returns the services which can be configured from an
+// Alloy configuration. The services are only used to decode the arguments of
+// their blocks; they are never started.
+func validateServices(l *logging.Logger, t *tracing.Tracer, dataPath string) ([]service.Service, error) {
+	reg := prometheus.NewRegistry()
+
+	clusterService, err := buildClusterService(clusterOptions{
+		Log:           log.With(l, "service", "cluster"),
+		Tracer:        t,
+		Metrics:       reg,
+		NodeName:      "validate",
+		ListenAddress: "127.0.0.1:12345",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	remoteCfgService, err := remotecfgservice.New(remotecfgservice.Options{
+		Logger:      log.With(l, "service", "remotecfg"),
+		StoragePath: dataPath,
+		Metrics:     reg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the remotecfg service: %w", err)
+	}
+
+	liveDebuggingService := livedebugging.New()
+
+	otelService := otel_service.New(l)
+	if otelService == nil {
+		return nil, fmt.Errorf("failed to create otel service")
+	}
+
+	return []service.Service{
+		clusterService,
+		httpservice.New(httpservice.Options{
+			Logger:   log.With(l, "service", "http"),
+			Tracer:   t,
+			Gatherer: reg,
+		}),
+		labelstore.New(l, reg),
+		liveDebuggingService,
+		otelService,
+		remoteCfgService,
+		uiservice.New(uiservice.Options{
+			CallbackManager: liveDebuggingService.Data().(livedebugging.CallbackManager),
+		}),
+	}, nil
+}
@@ -0,0 +1,123 @@
+package alloycli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/featuregate"
+)
+
+func TestValidate(t *testing.T) {
+	type testCase struct {
+		name          string
+		config        string
+		expectedError string
+	}
+
+	var testCases = []testCase{
+		{
+			name: "valid",
+			config: `
+				prometheus.remote_write "default" {
+					endpoint {
+						url = "http://localhost:9009/api/v1/push"
+					}
+				}
+
+				prometheus.scrape "default" {
+					targets    = [{"__address__" = "localhost:12345"}]
+					forward_to = [prometheus.remote_write.default.receiver]
+				}
+
+				logging {
+					level = "debug"
+				}
+			`,
+		},
+		{
+			name: "valid declare",
+			config: `
+				declare "reader" {
+					argument "path" {}
+
+					local.file "file" {
+						filename = argument.path.value
+					}
+
+					export "content" {
+						value = local.file.file.content
+					}
+				}
+
+				reader "default" {
+					path = "/does/not/exist"
+				}
+
+				local.file "other" {
+					filename = reader.default.content
+				}
+			`,
+		},
+		{
+			name: "unknown component",
+			config: `
+				prometheus.does_not_exist "default" {}
+			`,
+			expectedError: `cannot find the definition of component name "prometheus.does_not_exist"`,
+		},
+		{
+			name: "unknown reference",
+			config: `
+				prometheus.scrape "default" {
+					targets    = []
+					forward_to = [prometheus.remote_write.missing.receiver]
+				}
+			`,
+			expectedError: `component "prometheus.remote_write.missing.receiver" does not exist or is out of scope`,
+		},
+		{
+			name: "invalid argument type",
+			config: `
+				local.file "default" {
+					filename = ["a", "b"]
+				}
+			`,
+			expectedError: `should be string, got array`,
+		},
+		{
+			name: "invalid service block",
+			config: `
+				logging {
+					level = "verbose"
+				}
+			`,
+			expectedError: `unrecognized log level "verbose"`,
+		},
+		{
+			name: "invalid declare argument",
+			config: `
+				declare "reader" {
+					argument "path" {}
+				}
+
+				reader "default" {
+					filename = "/does/not/exist"
+				}
+			`,
+			expectedError: `Provided argument "filename" is not defined in the module`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := &alloyValidate{minStability: featuregate.StabilityGenerallyAvailable}
+			err := v.validate(map[string][]byte{"config.alloy": []byte(tc.config)})
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package alloycli
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-kit/log"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// configWatchPollFrequency is how often the config is checked for changes in
+// case a filesystem event was missed.
+const configWatchPollFrequency = time.Minute
+
+// configWatcher watches the config file or directory of the run command, and
+// calls reload when its content changes.
+//
+// The directory holding the config is watched rather than the config itself,
+// so that atomic symlink swaps, such as the ones done when a Kubernetes
+// ConfigMap mounted as a volume is updated, are detected. Events are
+// debounced, and reload is only called if the content of the config changed.
+type configWatcher struct {
+	log      log.Logger
+	path     string
+	debounce time.Duration
+	reload   func()
+
+	lastHash [sha256.Size]byte
+}
+
+// newConfigWatcher creates a configWatcher for the config at path. The
+// current content of the config is used to detect changes, so
+// newConfigWatcher must be called before the config is first loaded.
+func newConfigWatcher(l log.Logger, path string, debounce time.Duration, reload func()) (*configWatcher, error) {
+	w := &configWatcher{
+		log:      l,
+		path:     path,
+		debounce: debounce,
+		reload:   reload,
+	}
+
+	var err error
+	w.lastHash, err = hashConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Run watches the config until ctx is canceled.
+func (w *configWatcher) Run(ctx context.Context) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fsw.Close()
+
+	dir := w.path
+	if fi, err := os.Stat(w.path); err != nil {
+		return err
+	} else if !fi.IsDir() {
+		dir = filepath.Dir(w.path)
+	}
+	if err := fsw.Add(dir); err != nil {
+		return fmt.Errorf("watching %s: %w", dir, err)
+	}
+
+	poll := time.NewTicker(configWatchPollFrequency)
+	defer poll.Stop()
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case ev := <-fsw.Events:
+			level.Debug(w.log).Log("msg", "got fsnotify event for config", "name", ev.Name, "op", ev.Op.String())
+			debounce = time.After(w.debounce)
+
+		case err := <-fsw.Errors:
+			// Errors may be caused by missed events, so the config is checked as
+			// if it changed.
+			level.Warn(w.log).Log("msg", "got error from config watcher", "err", err)
+			debounce = time.After(w.debounce)
+
+		case <-poll.C:
+			// The watch of the directory stops if it's deleted and recreated; try
+			// to re-establish it. This is a no-op if the watch is still active.
+			if err := fsw.Add(dir); err != nil {
+				level.Warn(w.log).Log("msg", "failed to re-watch config", "dir", dir, "err", err)
+			}
+			w.check()
+
+		case <-debounce:
+			debounce = nil
+			w.check()
+		}
+	}
+}
+
+// check calls reload if the content of the config changed.
+func (w *configWatcher) check() {
+	hash, err := hashConfig(w.path)
+	if err != nil {
+		level.Warn(w.log).Log("msg", "failed to read config", "path", w.path, "err", err)
+		return
+	}
+	if hash == w.lastHash {
+		return
+	}
+	w.lastHash = hash
+
+	level.Info(w.log).Log("msg", "config changed, reloading", "path", w.path)
+	w.reload()
+}
+
+// hashConfig returns the hash of the content of the config file at path, or
+// of the *.alloy files of the directory at path.
+func hashConfig(path string) ([sha256.Size]byte, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	if !fi.IsDir() {
+		bb, err := os.ReadFile(path)
+		if err != nil {
+			return [sha256.Size]byte{}, err
+		}
+		return sha256.Sum256(bb), nil
+	}
+
+	sources, err := readAlloySources(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(sources[name]))
+		h.Write(sources[name])
+	}
+
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
@@ -0,0 +1,101 @@
+package alloycli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigWatcher(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.alloy")
+		require.NoError(t, os.WriteFile(path, []byte(`logging {}`), 0o644))
+
+		reloads := runConfigWatcher(t, path)
+
+		// Writes which don't change the content don't reload the config.
+		require.NoError(t, os.WriteFile(path, []byte(`logging {}`), 0o644))
+		require.Never(t, func() bool { return reloads() > 0 }, 200*time.Millisecond, 10*time.Millisecond)
+
+		// Successive writes are debounced.
+		require.NoError(t, os.WriteFile(path, []byte(`logging { level = "debug" }`), 0o644))
+		require.NoError(t, os.WriteFile(path, []byte(`logging { level = "warn" }`), 0o644))
+		require.Eventually(t, func() bool { return reloads() == 1 }, time.Second, 10*time.Millisecond)
+		require.Never(t, func() bool { return reloads() > 1 }, 200*time.Millisecond, 10*time.Millisecond)
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.alloy"), []byte(`logging {}`), 0o644))
+
+		reloads := runConfigWatcher(t, dir)
+
+		// Files without the .alloy extension aren't part of the config.
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte(`# Config`), 0o644))
+		require.Never(t, func() bool { return reloads() > 0 }, 200*time.Millisecond, 10*time.Millisecond)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "b.alloy"), []byte(`tracing {}`), 0o644))
+		require.Eventually(t, func() bool { return reloads() == 1 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("symlink swap", func(t *testing.T) {
+		// Mimic the layout of a Kubernetes ConfigMap mounted as a volume, whose
+		// files are updated by swapping the ..data symlink.
+		dir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "v1"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "v1", "config.alloy"), []byte(`logging {}`), 0o644))
+		require.NoError(t, os.Symlink("v1", filepath.Join(dir, "..data")))
+		require.NoError(t, os.Symlink(filepath.Join("..data", "config.alloy"), filepath.Join(dir, "config.alloy")))
+
+		reloads := runConfigWatcher(t, filepath.Join(dir, "config.alloy"))
+
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "v2"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "v2", "config.alloy"), []byte(`tracing {}`), 0o644))
+		require.NoError(t, os.Symlink("v2", filepath.Join(dir, "..data_tmp")))
+		require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+		require.NoError(t, os.RemoveAll(filepath.Join(dir, "v1")))
+
+		require.Eventually(t, func() bool { return reloads() == 1 }, time.Second, 10*time.Millisecond)
+	})
+}
+
+// runConfigWatcher runs a configWatcher for path until the end of the test,
+// and returns a function which returns the number of reloads.
+func runConfigWatcher(t *testing.T, path string) func() int {
+	var (
+		mut     sync.Mutex
+		reloads int
+	)
+	w, err := newConfigWatcher(log.NewNopLogger(), path, 50*time.Millisecond, func() {
+		mut.Lock()
+		defer mut.Unlock()
+		reloads++
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		require.NoError(t, w.Run(ctx))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	// Let the watcher start watching before changing the config.
+	time.Sleep(50 * time.Millisecond)
+
+	return func() int {
+		mut.Lock()
+		defer mut.Unlock()
+		return reloads
+	}
+}
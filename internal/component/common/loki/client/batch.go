@@ -40,6 +40,9 @@ type batch struct {
 
 	// segmentCounter tracks the amount of entries for each segment present in this batch.
 	segmentCounter map[int]int
+
+	// deliveries holds the deliveries of the entries of the batch which track it.
+	deliveries []*loki.Delivery
 }
 
 func newBatch(maxStreams int, entries ...loki.Entry) *batch {
@@ -54,7 +57,9 @@ func newBatch(maxStreams int, entries ...loki.Entry) *batch {
 	// Add entries to the batch
 	for _, entry := range entries {
 		//never error here
-		_ = b.add(entry)
+		if err := b.add(entry); err != nil {
+			entry.Delivery.Done(err)
+		}
 	}
 
 	return b
@@ -68,6 +73,7 @@ func (b *batch) add(entry loki.Entry) error {
 	labels := labelsMapToString(entry.Labels, ReservedLabelTenantID)
 	if stream, ok := b.streams[labels]; ok {
 		stream.Entries = append(stream.Entries, entry.Entry)
+		b.trackDelivery(entry.Delivery)
 		return nil
 	}
 
@@ -80,9 +86,25 @@ func (b *batch) add(entry loki.Entry) error {
 		Labels:  labels,
 		Entries: []logproto.Entry{entry.Entry},
 	}
+	b.trackDelivery(entry.Delivery)
 	return nil
 }
 
+func (b *batch) trackDelivery(d *loki.Delivery) {
+	if d != nil {
+		b.deliveries = append(b.deliveries, d)
+	}
+}
+
+// reportDelivery reports to the entries of the batch whether it was sent. err
+// is nil if the batch was sent.
+func (b *batch) reportDelivery(err error) {
+	for _, d := range b.deliveries {
+		d.Done(err)
+	}
+	b.deliveries = nil
+}
+
 // addFromWAL adds an entry to the batch, tracking that the data being added comes from segment segmentNum read from the
 // WAL.
 func (b *batch) addFromWAL(lbs model.LabelSet, entry logproto.Entry, segmentNum int) error {
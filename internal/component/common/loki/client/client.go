@@ -290,6 +290,7 @@ func (c *client) run() {
 				if !c.maxLineSizeTruncate {
 					c.metrics.droppedEntries.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonLineTooLong).Inc()
 					c.metrics.droppedBytes.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonLineTooLong).Add(float64(len(e.Line)))
+					e.Delivery.Done(fmt.Errorf("entry dropped: %s", ReasonLineTooLong))
 					break
 				}
 
@@ -326,6 +327,7 @@ func (c *client) run() {
 				}
 				c.metrics.droppedBytes.WithLabelValues(c.cfg.URL.Host, tenantID, reason).Add(float64(len(e.Line)))
 				c.metrics.droppedEntries.WithLabelValues(c.cfg.URL.Host, tenantID, reason).Inc()
+				e.Delivery.Done(err)
 				return
 			}
 		case <-maxWaitCheck.C:
@@ -354,6 +356,7 @@ func (c *client) sendBatch(tenantID string, batch *batch) {
 	buf, entriesCount, err := batch.encode()
 	if err != nil {
 		level.Error(c.logger).Log("msg", "error encoding batch", "error", err)
+		batch.reportDelivery(err)
 		return
 	}
 	bufBytes := float64(len(buf))
@@ -373,12 +376,14 @@ func (c *client) sendBatch(tenantID string, batch *batch) {
 			level.Warn(c.logger).Log("msg", "dropping batch due to rate limiting applied at ingester")
 			c.metrics.droppedBytes.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonRateLimited).Add(bufBytes)
 			c.metrics.droppedEntries.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonRateLimited).Add(float64(entriesCount))
+			batch.reportDelivery(fmt.Errorf("batch dropped: %s", ReasonRateLimited))
 			return
 		}
 
 		if err == nil {
 			c.metrics.sentBytes.WithLabelValues(c.cfg.URL.Host).Add(bufBytes)
 			c.metrics.sentEntries.WithLabelValues(c.cfg.URL.Host).Add(float64(entriesCount))
+			batch.reportDelivery(nil)
 
 			return
 		}
@@ -408,6 +413,7 @@ func (c *client) sendBatch(tenantID string, batch *batch) {
 		}
 		c.metrics.droppedBytes.WithLabelValues(c.cfg.URL.Host, tenantID, dropReason).Add(bufBytes)
 		c.metrics.droppedEntries.WithLabelValues(c.cfg.URL.Host, tenantID, dropReason).Add(float64(entriesCount))
+		batch.reportDelivery(err)
 	}
 }
 
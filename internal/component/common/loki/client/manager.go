@@ -191,9 +191,11 @@ func (m *Manager) startWithForward() {
 	go func() {
 		defer m.wg.Done()
 		for e := range m.entries {
+			e.Delivery.Add(len(m.clients))
 			for _, c := range m.clients {
 				c.Chan() <- e
 			}
+			e.Delivery.Done(nil)
 		}
 	}()
 }
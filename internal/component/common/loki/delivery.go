@@ -0,0 +1,221 @@
+package loki
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrStopped is the error of the deliveries of entries which are dropped
+// because the component which held them stopped.
+var ErrStopped = errors.New("entry dropped: component stopped")
+
+// maxPendingCommits is the maximum number of entries a CommitQueue tracks
+// whose position wasn't committed yet.
+const maxPendingCommits = 100_000
+
+// Delivery tracks whether a log entry was delivered by every component it
+// was forwarded to. Sources which commit their read positions only once
+// entries are delivered attach a Delivery to the entries they send; other
+// entries have a nil Delivery.
+//
+// A Delivery counts the pending deliveries of an entry, like a
+// sync.WaitGroup. It starts with one pending delivery, held by the component
+// which created it. Components which forward an entry to several receivers
+// call Add with the number of receivers before sending it, and Done for the
+// delivery they hold once it was sent. Components which write an entry, or
+// intentionally drop it, call Done.
+//
+// All the methods of Delivery can be called on a nil Delivery, in which case
+// they do nothing.
+type Delivery struct {
+	mut     sync.Mutex
+	pending int
+	err     error
+	done    func(err error)
+}
+
+// NewDelivery returns a Delivery with one pending delivery. done is called
+// once every pending delivery is done, with the first error passed to Done.
+func NewDelivery(done func(err error)) *Delivery {
+	return &Delivery{pending: 1, done: done}
+}
+
+// Add adds n pending deliveries to d.
+func (d *Delivery) Add(n int) {
+	if d == nil {
+		return
+	}
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	d.pending += n
+}
+
+// Done marks a pending delivery as done. err is non-nil if the entry failed to
+// be delivered.
+func (d *Delivery) Done(err error) {
+	if d == nil {
+		return
+	}
+
+	d.mut.Lock()
+	if d.pending <= 0 {
+		d.mut.Unlock()
+		return
+	}
+	d.pending--
+	if d.err == nil {
+		d.err = err
+	}
+	finished := d.pending == 0
+	d.mut.Unlock()
+
+	if finished {
+		d.done(d.err)
+	}
+}
+
+// MergeDeliveries returns a Delivery which is done once it's done, and then
+// marks each of ds as done. It's used when entries are merged into a single
+// entry. It returns nil if all of ds are nil.
+func MergeDeliveries(ds []*Delivery) *Delivery {
+	var merged []*Delivery
+	for _, d := range ds {
+		if d != nil {
+			merged = append(merged, d)
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return NewDelivery(func(err error) {
+		for _, d := range merged {
+			d.Done(err)
+		}
+	})
+}
+
+// SendToAll sends entry to each of the receivers, and tracks its delivery to
+// each of them. It returns false if stop was closed before the entry was sent
+// to every receiver.
+//
+// The delivery held by the caller is done once the entry was sent. The
+// deliveries to the receivers it wasn't sent to fail with ErrStopped, so that
+// positions aren't committed past an entry which wasn't delivered.
+func SendToAll(stop <-chan struct{}, entry Entry, receivers []LogsReceiver) bool {
+	entry.Delivery.Add(len(receivers))
+	for i, r := range receivers {
+		select {
+		case <-stop:
+			for range receivers[i:] {
+				entry.Delivery.Done(ErrStopped)
+			}
+			entry.Delivery.Done(nil)
+			return false
+		case r.Chan() <- entry:
+		}
+	}
+	entry.Delivery.Done(nil)
+	return true
+}
+
+// CommitQueue commits the positions of the entries of a source in the order
+// they were read, once they were delivered. Positions are committed once
+// every entry read before them was delivered too.
+//
+// When an entry fails to be delivered, its position and all the following
+// ones are never committed, so that the entries are read again when the
+// source restarts. This is also the case once too many entries are pending,
+// since an entry which is never delivered would otherwise make the queue grow
+// without bound.
+type CommitQueue[T any] struct {
+	mut        sync.Mutex
+	commit     func(T)
+	pending    []*commitItem[T]
+	maxPending int
+	failed     bool
+	onFail     func(err error)
+}
+
+type commitItem[T any] struct {
+	pos  T
+	done bool
+	err  error
+}
+
+// NewCommitQueue returns a CommitQueue which calls commit with the positions
+// of the delivered entries, and onFail with the error of the first entry
+// which fails to be delivered. onFail may be nil.
+func NewCommitQueue[T any](commit func(T), onFail func(err error)) *CommitQueue[T] {
+	return &CommitQueue[T]{commit: commit, onFail: onFail, maxPending: maxPendingCommits}
+}
+
+// Track returns the Delivery of an entry read at the position pos. It returns
+// nil once an entry failed to be delivered or too many entries are pending,
+// since no position is committed anymore, or if q is nil.
+func (q *CommitQueue[T]) Track(pos T) *Delivery {
+	if q == nil {
+		return nil
+	}
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	if q.failed {
+		return nil
+	}
+	if len(q.pending) >= q.maxPending {
+		q.fail(fmt.Errorf("%d entries are pending delivery", len(q.pending)))
+		return nil
+	}
+
+	item := &commitItem[T]{pos: pos}
+	q.pending = append(q.pending, item)
+	return NewDelivery(func(err error) { q.complete(item, err) })
+}
+
+// Pending returns the number of tracked entries whose position wasn't
+// committed yet.
+func (q *CommitQueue[T]) Pending() int {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	return len(q.pending)
+}
+
+func (q *CommitQueue[T]) complete(item *commitItem[T], err error) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	if q.failed {
+		return
+	}
+	item.done, item.err = true, err
+
+	var (
+		last      *commitItem[T]
+		committed int
+	)
+	for _, it := range q.pending {
+		if !it.done {
+			break
+		}
+		if it.err != nil {
+			q.fail(it.err)
+			break
+		}
+		last = it
+		committed++
+	}
+	if last != nil {
+		q.commit(last.pos)
+	}
+	if !q.failed {
+		q.pending = q.pending[committed:]
+	}
+}
+
+// fail stops committing positions. It must be called with mut held.
+func (q *CommitQueue[T]) fail(err error) {
+	q.failed = true
+	q.pending = nil
+	if q.onFail != nil {
+		q.onFail(err)
+	}
+}
@@ -0,0 +1,144 @@
+package loki
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelivery(t *testing.T) {
+	var (
+		calls int
+		got   error
+	)
+	d := NewDelivery(func(err error) {
+		calls++
+		got = err
+	})
+
+	d.Add(2)
+	d.Done(nil)
+	d.Done(errors.New("first"))
+	require.Equal(t, 0, calls)
+
+	d.Done(errors.New("second"))
+	require.Equal(t, 1, calls)
+	require.EqualError(t, got, "first")
+
+	// Calls after the delivery is done are ignored.
+	d.Done(nil)
+	require.Equal(t, 1, calls)
+}
+
+func TestDelivery_Nil(t *testing.T) {
+	var d *Delivery
+	d.Add(1)
+	d.Done(nil)
+	require.Nil(t, MergeDeliveries([]*Delivery{nil, nil}))
+}
+
+func TestMergeDeliveries(t *testing.T) {
+	var results []error
+	a := NewDelivery(func(err error) { results = append(results, err) })
+	b := NewDelivery(func(err error) { results = append(results, err) })
+
+	merged := MergeDeliveries([]*Delivery{a, nil, b})
+	require.Empty(t, results)
+
+	merged.Done(errors.New("failed"))
+	require.Len(t, results, 2)
+	require.EqualError(t, results[0], "failed")
+	require.EqualError(t, results[1], "failed")
+}
+
+func TestSendToAll(t *testing.T) {
+	var done bool
+	d := NewDelivery(func(error) { done = true })
+	receivers := []LogsReceiver{NewLogsReceiver(), NewLogsReceiver()}
+
+	go func() {
+		for _, r := range receivers {
+			e := <-r.Chan()
+			e.Delivery.Done(nil)
+		}
+	}()
+	require.True(t, SendToAll(make(chan struct{}), Entry{Delivery: d}, receivers))
+	require.Eventually(t, func() bool { return done }, time.Second, 10*time.Millisecond)
+}
+
+func TestSendToAll_Stopped(t *testing.T) {
+	var got error
+	d := NewDelivery(func(err error) { got = err })
+	stop := make(chan struct{})
+	close(stop)
+
+	require.False(t, SendToAll(stop, Entry{Delivery: d}, []LogsReceiver{NewLogsReceiver()}))
+	require.ErrorIs(t, got, ErrStopped)
+}
+
+func TestCommitQueue(t *testing.T) {
+	var committed []int
+	q := NewCommitQueue(func(pos int) { committed = append(committed, pos) }, nil)
+
+	d1, d2, d3 := q.Track(1), q.Track(2), q.Track(3)
+	require.Equal(t, 3, q.Pending())
+
+	// Positions are only committed once every previous entry is delivered.
+	d2.Done(nil)
+	require.Empty(t, committed)
+
+	d1.Done(nil)
+	require.Equal(t, []int{2}, committed)
+	require.Equal(t, 1, q.Pending())
+
+	d3.Done(nil)
+	require.Equal(t, []int{2, 3}, committed)
+	require.Equal(t, 0, q.Pending())
+}
+
+func TestCommitQueue_Failure(t *testing.T) {
+	var (
+		committed []int
+		failure   error
+	)
+	q := NewCommitQueue(func(pos int) { committed = append(committed, pos) }, func(err error) { failure = err })
+
+	d1, d2, d3 := q.Track(1), q.Track(2), q.Track(3)
+	d3.Done(nil)
+	d2.Done(errors.New("failed"))
+	d1.Done(nil)
+
+	// The position before the failed entry is committed, but no position is
+	// committed anymore after it.
+	require.Equal(t, []int{1}, committed)
+	require.EqualError(t, failure, "failed")
+	require.Nil(t, q.Track(4))
+	require.Equal(t, 0, q.Pending())
+}
+
+func TestCommitQueue_MaxPending(t *testing.T) {
+	var (
+		committed []int
+		failure   error
+	)
+	q := NewCommitQueue(func(pos int) { committed = append(committed, pos) }, func(err error) { failure = err })
+	q.maxPending = 2
+
+	// The first entry is never delivered, so the queue stops committing
+	// positions rather than tracking entries without bound.
+	_, d2 := q.Track(1), q.Track(2)
+	require.Nil(t, q.Track(3))
+	require.EqualError(t, failure, "2 entries are pending delivery")
+	require.Equal(t, 0, q.Pending())
+
+	d2.Done(nil)
+	require.Empty(t, committed)
+	require.Nil(t, q.Track(4))
+}
+
+func TestCommitQueue_Nil(t *testing.T) {
+	var q *CommitQueue[int]
+	require.Nil(t, q.Track(1))
+}
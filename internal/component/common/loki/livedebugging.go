@@ -0,0 +1,42 @@
+package loki
+
+import (
+	"fmt"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/service/livedebugging"
+)
+
+// NewDebugEvent returns the live debugging event of entry. The body of the
+// event shows the line and the labels of the entry.
+func NewDebugEvent(entry Entry) livedebugging.Event {
+	return NewDebugEventWithBody(entry, fmt.Sprintf("entry: %s, labels: %s", entry.Line, entry.Labels.String()))
+}
+
+// NewDebugEventWithBody returns the live debugging event of entry with a
+// custom body.
+func NewDebugEventWithBody(entry Entry, body string) livedebugging.Event {
+	return livedebugging.Event{
+		Type:      livedebugging.DataTypeLogs,
+		Timestamp: entry.Timestamp,
+		Labels:    labelsMap(entry.Labels),
+		Body:      body,
+	}
+}
+
+func labelsMap(ls model.LabelSet) map[string]string {
+	m := make(map[string]string, len(ls))
+	for k, v := range ls {
+		m[string(k)] = string(v)
+	}
+	return m
+}
+
+// PublishDebugEvent publishes the live debugging event of entry for the
+// component with the given ID, if live debugging is active for it.
+func PublishDebugEvent(publisher livedebugging.DebugDataPublisher, componentID string, entry Entry) {
+	if id := livedebugging.ComponentID(componentID); publisher.IsActive(id) {
+		publisher.Publish(id, NewDebugEvent(entry))
+	}
+}
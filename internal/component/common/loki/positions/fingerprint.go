@@ -0,0 +1,130 @@
+package positions
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Fingerprint identifies a file by a hash of its first bytes, and by its
+// device and inode. Unlike its path, the fingerprint of a file doesn't change
+// when the file is renamed, and changes when the path is reused by another
+// file or when the file is truncated.
+type Fingerprint struct {
+	Device uint64
+	Inode  uint64
+	// Size is the number of bytes which were hashed. It's smaller than the
+	// requested size for files which are smaller than it.
+	Size int
+	Hash uint64
+
+	// prefix holds the first bytes of the file the fingerprint was read from,
+	// to compare them with fingerprints of fewer bytes.
+	prefix []byte
+}
+
+// ReadFingerprint returns the fingerprint of the first size bytes of the file
+// at path.
+func ReadFingerprint(path string, size int) (Fingerprint, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return Fingerprint{}, err
+	}
+
+	prefix := make([]byte, size)
+	n, err := io.ReadFull(f, prefix)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return Fingerprint{}, err
+	}
+	prefix = prefix[:n]
+
+	device, inode := fileID(fi)
+	return Fingerprint{
+		Device: device,
+		Inode:  inode,
+		Size:   n,
+		Hash:   hashPrefix(prefix),
+		prefix: prefix,
+	}, nil
+}
+
+// ParseFingerprint parses the string representation of a fingerprint.
+func ParseFingerprint(s string) (Fingerprint, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 4 {
+		return Fingerprint{}, fmt.Errorf("invalid fingerprint %q", s)
+	}
+
+	var (
+		fp  Fingerprint
+		err error
+	)
+	if fp.Device, err = strconv.ParseUint(parts[0], 10, 64); err != nil {
+		return Fingerprint{}, fmt.Errorf("invalid device in fingerprint %q: %w", s, err)
+	}
+	if fp.Inode, err = strconv.ParseUint(parts[1], 10, 64); err != nil {
+		return Fingerprint{}, fmt.Errorf("invalid inode in fingerprint %q: %w", s, err)
+	}
+	if fp.Size, err = strconv.Atoi(parts[2]); err != nil {
+		return Fingerprint{}, fmt.Errorf("invalid size in fingerprint %q: %w", s, err)
+	}
+	if fp.Hash, err = strconv.ParseUint(parts[3], 16, 64); err != nil {
+		return Fingerprint{}, fmt.Errorf("invalid hash in fingerprint %q: %w", s, err)
+	}
+	return fp, nil
+}
+
+// String returns the representation of fp stored in the positions file.
+func (fp Fingerprint) String() string {
+	return fmt.Sprintf("%d:%d:%d:%016x", fp.Device, fp.Inode, fp.Size, fp.Hash)
+}
+
+// SameFile reports whether fp and other have the same device and inode. It
+// returns false on platforms where files don't have an inode.
+func (fp Fingerprint) SameFile(other Fingerprint) bool {
+	return fp.HasFileID() && fp.Device == other.Device && fp.Inode == other.Inode
+}
+
+// HasFileID reports whether fp has the device and inode of its file, which
+// files don't have on some platforms.
+func (fp Fingerprint) HasFileID() bool {
+	return fp.Device != 0 || fp.Inode != 0
+}
+
+// IsFile reports whether fi describes the file fp was read from, by comparing
+// their device and inode. It returns false on platforms where files don't have
+// an inode.
+func (fp Fingerprint) IsFile(fi os.FileInfo) bool {
+	device, inode := fileID(fi)
+	return fp.SameFile(Fingerprint{Device: device, Inode: inode})
+}
+
+// Matches reports whether the file fp was read from is the file stored was
+// computed from, when it was smaller or had the same content.
+//
+// The first bytes of the file must have the hash of stored. Since a few bytes
+// don't identify a file, when stored was computed from fewer than size bytes
+// the file must also have the same device and inode.
+func (fp Fingerprint) Matches(stored Fingerprint, size int) bool {
+	if stored.Size > len(fp.prefix) || hashPrefix(fp.prefix[:stored.Size]) != stored.Hash {
+		return false
+	}
+	return stored.Size >= size || fp.SameFile(stored)
+}
+
+func hashPrefix(prefix []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(prefix)
+	return h.Sum64()
+}
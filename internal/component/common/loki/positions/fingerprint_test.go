@@ -0,0 +1,68 @@
+package positions
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFingerprint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	require.NoError(t, os.WriteFile(path, []byte("first line\n"), 0644))
+
+	small, err := ReadFingerprint(path, 32)
+	require.NoError(t, err)
+	require.Equal(t, len("first line\n"), small.Size)
+
+	parsed, err := ParseFingerprint(small.String())
+	require.NoError(t, err)
+	require.Equal(t, small.String(), parsed.String())
+	require.True(t, small.SameFile(parsed))
+
+	// The fingerprint of the file matches once it grows, as long as it's the
+	// same file.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("second line, which is long enough\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	grown, err := ReadFingerprint(path, 32)
+	require.NoError(t, err)
+	require.Equal(t, 32, grown.Size)
+	require.True(t, grown.Matches(parsed, 32))
+	require.False(t, parsed.Matches(grown, 32))
+
+	// A copy of a file matches its fingerprint once it was computed from
+	// enough bytes.
+	copied := filepath.Join(t.TempDir(), "copied.log")
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(copied, buf, 0644))
+	copiedFingerprint, err := ReadFingerprint(copied, 32)
+	require.NoError(t, err)
+	require.False(t, copiedFingerprint.SameFile(grown))
+	require.True(t, copiedFingerprint.Matches(grown, 32))
+	require.False(t, copiedFingerprint.Matches(parsed, 32))
+
+	// A truncated file doesn't match its fingerprint anymore.
+	require.NoError(t, os.WriteFile(path, []byte("another line\n"), 0644))
+	truncated, err := ReadFingerprint(path, 32)
+	require.NoError(t, err)
+	require.False(t, truncated.Matches(grown, 32))
+	require.False(t, truncated.Matches(parsed, 32))
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, runtime.GOOS != "windows", grown.IsFile(fi))
+}
+
+func TestParseFingerprint_Invalid(t *testing.T) {
+	for _, s := range []string{"", "1:2:3", "a:2:3:ff", "1:2:3:zz"} {
+		_, err := ParseFingerprint(s)
+		require.Error(t, err, s)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package positions
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileID returns the device and inode of a file.
+func fileID(fi os.FileInfo) (device, inode uint64) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0
+	}
+	return uint64(st.Dev), uint64(st.Ino) //nolint:unconvert // The types of the fields depend on the platform.
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -63,9 +64,15 @@ type positions struct {
 // the order and structure of the passed string representation is reproducible,
 // and maintains the same format for both reading and writing from/to the
 // positions file.
+//
+// Files which are identified by their content rather than by their path also
+// have the string representation of their Fingerprint, so that several files
+// can be tracked for the same path and labels, such as a file which was
+// rotated and the file which replaced it.
 type Entry struct {
-	Path   string `yaml:"path"`
-	Labels string `yaml:"labels"`
+	Path        string `yaml:"path"`
+	Labels      string `yaml:"labels"`
+	Fingerprint string `yaml:"fingerprint,omitempty"`
 }
 
 // File format for the positions data.
@@ -90,6 +97,15 @@ type Positions interface {
 	Put(path, labels string, pos int64)
 	// Remove removes the position tracking for a filepath
 	Remove(path, labels string)
+	// GetEntry returns how far we've read through the file of an entry.
+	GetEntry(e Entry) (int64, error)
+	// PutEntry records (asynchronously) how far we've read through the file
+	// of an entry.
+	PutEntry(e Entry, pos int64)
+	// RemoveEntry removes the position tracking for an entry.
+	RemoveEntry(e Entry)
+	// Entries returns the entries whose position is tracked.
+	Entries() []Entry
 	// SyncPeriod returns how often the positions file gets resynced
 	SyncPeriod() time.Duration
 	// Stop the Position tracker.
@@ -182,23 +198,33 @@ func (p *positions) Stop() {
 func (p *positions) PutString(path, labels string, pos string) {
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
-	p.positions[Entry{path, labels}] = pos
+	p.positions[Entry{Path: path, Labels: labels}] = pos
 }
 
 func (p *positions) Put(path, labels string, pos int64) {
-	p.PutString(path, labels, strconv.FormatInt(pos, 10))
+	p.PutEntry(Entry{Path: path, Labels: labels}, pos)
+}
+
+func (p *positions) PutEntry(e Entry, pos int64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.positions[e] = strconv.FormatInt(pos, 10)
 }
 
 func (p *positions) GetString(path, labels string) string {
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
-	return p.positions[Entry{path, labels}]
+	return p.positions[Entry{Path: path, Labels: labels}]
 }
 
 func (p *positions) Get(path, labels string) (int64, error) {
+	return p.GetEntry(Entry{Path: path, Labels: labels})
+}
+
+func (p *positions) GetEntry(e Entry) (int64, error) {
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
-	pos, ok := p.positions[Entry{path, labels}]
+	pos, ok := p.positions[e]
 	if !ok {
 		return 0, nil
 	}
@@ -206,13 +232,37 @@ func (p *positions) Get(path, labels string) (int64, error) {
 }
 
 func (p *positions) Remove(path, labels string) {
+	p.RemoveEntry(Entry{Path: path, Labels: labels})
+}
+
+func (p *positions) RemoveEntry(e Entry) {
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
-	p.remove(path, labels)
+	p.remove(e)
 }
 
-func (p *positions) remove(path, labels string) {
-	delete(p.positions, Entry{path, labels})
+func (p *positions) remove(e Entry) {
+	delete(p.positions, e)
+}
+
+func (p *positions) Entries() []Entry {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	entries := make([]Entry, 0, len(p.positions))
+	for e := range p.positions {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Labels != b.Labels {
+			return a.Labels < b.Labels
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+	return entries
 }
 
 func (p *positions) SyncPeriod() time.Duration {
@@ -283,7 +333,7 @@ func (p *positions) cleanup() {
 		}
 	}
 	for _, tr := range toRemove {
-		p.remove(tr.Path, tr.Labels)
+		p.remove(tr)
 	}
 }
 
@@ -365,3 +365,51 @@ positions:
 		Labels: ``,
 	}])
 }
+
+func TestFingerprintEntries(t *testing.T) {
+	temp := tempFilename(t)
+	defer func() {
+		_ = os.Remove(temp)
+	}()
+	yaml := []byte(`
+positions:
+  ? path: /tmp/initial.log
+    labels: '{job="tmp"}'
+  : "10030"
+`)
+	err := os.WriteFile(temp, yaml, 0644)
+	require.NoError(t, err)
+	p, err := New(util_log.Logger, Config{
+		SyncPeriod:    20 * time.Second,
+		PositionsFile: temp,
+	})
+	require.NoError(t, err)
+	defer p.Stop()
+
+	var (
+		legacy  = Entry{Path: "/tmp/initial.log", Labels: `{job="tmp"}`}
+		rotated = Entry{Path: "/tmp/initial.log", Labels: `{job="tmp"}`, Fingerprint: "1:2:10:00000000000000ff"}
+		current = Entry{Path: "/tmp/initial.log", Labels: `{job="tmp"}`, Fingerprint: "1:3:10:00000000000000fe"}
+	)
+	p.PutEntry(rotated, 10040)
+	p.PutEntry(current, 10050)
+	require.Equal(t, []Entry{legacy, rotated, current}, p.Entries())
+
+	pos, err := p.GetEntry(rotated)
+	require.NoError(t, err)
+	require.Equal(t, int64(10040), pos)
+	pos, err = p.Get(legacy.Path, legacy.Labels)
+	require.NoError(t, err)
+	require.Equal(t, int64(10030), pos)
+
+	p.RemoveEntry(legacy)
+	p.(*positions).save()
+	out, err := readPositionsFile(Config{
+		PositionsFile: temp,
+	}, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, map[Entry]string{
+		rotated: "10040",
+		current: "10050",
+	}, out)
+}
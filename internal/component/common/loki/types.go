@@ -51,13 +51,19 @@ func NewLogsReceiverWithChannel(c chan Entry) LogsReceiver {
 type Entry struct {
 	Labels model.LabelSet
 	logproto.Entry
+
+	// Delivery tracks the delivery of the entry, for sources which commit
+	// their positions once entries are delivered. It's nil otherwise.
+	Delivery *Delivery `json:"-" yaml:"-"`
 }
 
-// Clone returns a copy of the entry so that it can be safely fanned out.
+// Clone returns a copy of the entry so that it can be safely fanned out. The
+// copy shares the Delivery of the entry.
 func (e *Entry) Clone() Entry {
 	return Entry{
-		Labels: e.Labels.Clone(),
-		Entry:  e.Entry,
+		Labels:   e.Labels.Clone(),
+		Entry:    e.Entry,
+		Delivery: e.Delivery,
 	}
 }
 
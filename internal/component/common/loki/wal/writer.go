@@ -122,9 +122,12 @@ func (wrt *Writer) start(maxSegmentAge time.Duration) {
 		for e := range wrt.entries {
 			if err := wrt.entryWriter.WriteEntry(e, wrt.wal, wrt.log); err != nil {
 				level.Error(wrt.log).Log("msg", "failed to write entry", "err", err)
+				e.Delivery.Done(err)
 				// if an error occurred while writing the wal, go to next entry and don't notify write subscribers
 				continue
 			}
+			// the entry is delivered once it's written to the WAL, since the watchers read it from there
+			e.Delivery.Done(nil)
 
 			// emit metric with latest written timestamp, to be able to track delay from writer to watcher
 			wrt.lastWrittenTimestamp.WithLabelValues().Set(float64(e.Timestamp.Unix()))
@@ -59,6 +59,10 @@ const (
 
 	// HealthTypeExited represents a component which has stopped running.
 	HealthTypeExited
+
+	// HealthTypeCrashLoop represents a component which exited with an error
+	// and is waiting to be restarted by its restart policy.
+	HealthTypeCrashLoop
 )
 
 // String returns the string representation of ht.
@@ -70,6 +74,8 @@ func (ht HealthType) String() string {
 		return "unhealthy"
 	case HealthTypeExited:
 		return "exited"
+	case HealthTypeCrashLoop:
+		return "crashloop"
 	default:
 		return "unknown"
 	}
@@ -91,6 +97,8 @@ func (ht *HealthType) UnmarshalText(text []byte) error {
 		*ht = HealthTypeUnknown
 	case "exited":
 		*ht = HealthTypeExited
+	case "crashloop":
+		*ht = HealthTypeCrashLoop
 	default:
 		return fmt.Errorf("invalid health type %q", string(text))
 	}
@@ -101,7 +109,8 @@ func (ht *HealthType) UnmarshalText(text []byte) error {
 // considered to be the least healthy.
 //
 // Health types are first prioritized by [HealthTypeExited], followed by
-// [HealthTypeUnhealthy], [HealthTypeUnknown], and [HealthTypeHealthy].
+// [HealthTypeCrashLoop], [HealthTypeUnhealthy], [HealthTypeUnknown], and
+// [HealthTypeHealthy].
 //
 // If multiple arguments have the same Health type, the Health with the most
 // recent timestamp is returned.
@@ -137,5 +146,6 @@ var healthPriority = [...]int{
 	HealthTypeHealthy:   0,
 	HealthTypeUnknown:   1,
 	HealthTypeUnhealthy: 2,
-	HealthTypeExited:    3,
+	HealthTypeCrashLoop: 3,
+	HealthTypeExited:    4,
 }
@@ -40,6 +40,28 @@ func TestMergeHealth(t *testing.T) {
 			}},
 			expectIndex: 1,
 		},
+		{
+			name: "exited > crashloop",
+			healths: []component.Health{{
+				Health:     component.HealthTypeExited,
+				UpdateTime: jan1,
+			}, {
+				Health:     component.HealthTypeCrashLoop,
+				UpdateTime: jan2,
+			}},
+			expectIndex: 0,
+		},
+		{
+			name: "crashloop > unhealthy",
+			healths: []component.Health{{
+				Health:     component.HealthTypeUnhealthy,
+				UpdateTime: jan2,
+			}, {
+				Health:     component.HealthTypeCrashLoop,
+				UpdateTime: jan1,
+			}},
+			expectIndex: 1,
+		},
 		{
 			name: "unhealthy > healthy",
 			healths: []component.Health{{
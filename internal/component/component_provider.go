@@ -120,6 +120,12 @@ type Info struct {
 	// this component depends on, or is depended on by, respectively.
 	References, ReferencedBy []string
 
+	// ReferenceAttributes maps the IDs in References to the names of the
+	// attributes of the component which reference them. Attributes of nested
+	// blocks are named after their path in the component, for example
+	// "rule.source_labels".
+	ReferenceAttributes map[string][]string
+
 	ComponentName string // Name of the component.
 	Health        Health // Current component health.
 
@@ -139,19 +145,20 @@ func (info *Info) MarshalJSON() ([]byte, error) {
 		}
 
 		componentDetailJSON struct {
-			Name             string               `json:"name"`
-			Type             string               `json:"type,omitempty"`
-			LocalID          string               `json:"localID"`
-			ModuleID         string               `json:"moduleID"`
-			Label            string               `json:"label,omitempty"`
-			References       []string             `json:"referencesTo"`
-			ReferencedBy     []string             `json:"referencedBy"`
-			Health           *componentHealthJSON `json:"health"`
-			Original         string               `json:"original"`
-			Arguments        json.RawMessage      `json:"arguments,omitempty"`
-			Exports          json.RawMessage      `json:"exports,omitempty"`
-			DebugInfo        json.RawMessage      `json:"debugInfo,omitempty"`
-			CreatedModuleIDs []string             `json:"createdModuleIDs,omitempty"`
+			Name                string               `json:"name"`
+			Type                string               `json:"type,omitempty"`
+			LocalID             string               `json:"localID"`
+			ModuleID            string               `json:"moduleID"`
+			Label               string               `json:"label,omitempty"`
+			References          []string             `json:"referencesTo"`
+			ReferencedBy        []string             `json:"referencedBy"`
+			ReferenceAttributes map[string][]string  `json:"referenceAttributes,omitempty"`
+			Health              *componentHealthJSON `json:"health"`
+			Original            string               `json:"original"`
+			Arguments           json.RawMessage      `json:"arguments,omitempty"`
+			Exports             json.RawMessage      `json:"exports,omitempty"`
+			DebugInfo           json.RawMessage      `json:"debugInfo,omitempty"`
+			CreatedModuleIDs    []string             `json:"createdModuleIDs,omitempty"`
 		}
 	)
 
@@ -184,13 +191,14 @@ func (info *Info) MarshalJSON() ([]byte, error) {
 	}
 
 	return json.Marshal(&componentDetailJSON{
-		Name:         info.ComponentName,
-		Type:         "block",
-		ModuleID:     info.ID.ModuleID,
-		LocalID:      info.ID.LocalID,
-		Label:        info.Label,
-		References:   references,
-		ReferencedBy: referencedBy,
+		Name:                info.ComponentName,
+		Type:                "block",
+		ModuleID:            info.ID.ModuleID,
+		LocalID:             info.ID.LocalID,
+		Label:               info.Label,
+		References:          references,
+		ReferencedBy:        referencedBy,
+		ReferenceAttributes: info.ReferenceAttributes,
 		Health: &componentHealthJSON{
 			State:       info.Health.Health.String(),
 			Message:     info.Health.Message,
@@ -5,6 +5,7 @@ import (
 	"github.com/grafana/ckit/shard"
 
 	"github.com/grafana/alloy/internal/service/cluster"
+	"github.com/grafana/alloy/internal/service/cluster/crdt"
 )
 
 // DistributedTargets uses the node's Lookup method to distribute discovery
@@ -90,3 +91,15 @@ func (l disabledCluster) Lookup(key shard.Key, replicationFactor int, op shard.O
 func (l disabledCluster) Peers() []peer.Peer {
 	return nil
 }
+
+func (l disabledCluster) Leader(name string) (peer.Peer, error) {
+	return cluster.LeaderOf(l, name)
+}
+
+func (l disabledCluster) Changed() <-chan struct{} {
+	return nil
+}
+
+func (l disabledCluster) State() *crdt.Store {
+	return nil
+}
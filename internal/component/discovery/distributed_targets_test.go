@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/alloy/internal/service/cluster"
+	"github.com/grafana/alloy/internal/service/cluster/crdt"
 )
 
 var (
@@ -314,3 +315,15 @@ func (f *fakeCluster) Lookup(key shard.Key, _ int, _ shard.Op) ([]peer.Peer, err
 func (f *fakeCluster) Peers() []peer.Peer {
 	return f.peers
 }
+
+func (f *fakeCluster) Leader(name string) (peer.Peer, error) {
+	return cluster.LeaderOf(f, name)
+}
+
+func (f *fakeCluster) Changed() <-chan struct{} {
+	return nil
+}
+
+func (f *fakeCluster) State() *crdt.Store {
+	return nil
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/component/loki/process/stages"
 	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/service/cluster"
+	"github.com/grafana/alloy/internal/service/cluster/crdt"
 	"github.com/grafana/alloy/internal/service/livedebugging"
 )
 
@@ -66,6 +68,11 @@ type Component struct {
 	fanout    []loki.LogsReceiver
 
 	debugDataPublisher livedebugging.DebugDataPublisher
+
+	// sharedState is the state shared with the peers of the cluster, used by
+	// stages which support a cluster-wide mode. sharedState is nil if the
+	// cluster service isn't available or clustering is disabled.
+	sharedState *crdt.Store
 }
 
 // New creates a new loki.process component.
@@ -79,6 +86,9 @@ func New(o component.Options, args Arguments) (*Component, error) {
 		opts:               o,
 		debugDataPublisher: debugDataPublisher.(livedebugging.DebugDataPublisher),
 	}
+	if data, err := o.GetServiceData(cluster.ServiceName); err == nil {
+		c.sharedState = data.(cluster.Cluster).State()
+	}
 
 	// Create and immediately export the receiver which remains the same for
 	// the component's lifetime.
@@ -134,7 +144,7 @@ func (c *Component) Update(args component.Arguments) error {
 			c.entryHandler.Stop()
 		}
 
-		pipeline, err := stages.NewPipeline(c.opts.Logger, newArgs.Stages, &c.opts.ID, c.opts.Registerer)
+		pipeline, err := stages.NewClusterPipeline(c.opts.Logger, newArgs.Stages, &c.opts.ID, c.opts.Registerer, c.sharedState)
 		if err != nil {
 			return err
 		}
@@ -160,7 +170,7 @@ func (c *Component) handleIn(ctx context.Context, wg *sync.WaitGroup) {
 				return
 			case c.processIn <- entry.Clone():
 				if c.debugDataPublisher.IsActive(componentID) {
-					c.debugDataPublisher.Publish(componentID, fmt.Sprintf("[IN]: entry: %s, labels: %s", entry.Line, entry.Labels.String()))
+					c.debugDataPublisher.Publish(componentID, loki.NewDebugEventWithBody(entry, fmt.Sprintf("[IN]: entry: %s, labels: %s", entry.Line, entry.Labels.String())))
 				}
 				// TODO(@tpaschalis) Instead of calling Clone() at the
 				// component's entrypoint here, we can try a copy-on-write
@@ -183,16 +193,22 @@ func (c *Component) handleOut(ctx context.Context, wg *sync.WaitGroup) {
 			c.fanoutMut.RLock()
 			fanout := c.fanout
 			c.fanoutMut.RUnlock()
-			for _, f := range fanout {
+			entry.Delivery.Add(len(fanout))
+			for i, f := range fanout {
 				select {
 				case <-ctx.Done():
+					for range fanout[i:] {
+						entry.Delivery.Done(loki.ErrStopped)
+					}
+					entry.Delivery.Done(nil)
 					return
 				case f.Chan() <- entry:
 					if c.debugDataPublisher.IsActive(componentID) {
-						c.debugDataPublisher.Publish(componentID, fmt.Sprintf("[OUT]: entry: %s, labels: %s", entry.Line, entry.Labels.String()))
+						c.debugDataPublisher.Publish(componentID, loki.NewDebugEventWithBody(entry, fmt.Sprintf("[OUT]: entry: %s, labels: %s", entry.Line, entry.Labels.String())))
 					}
 				}
 			}
+			entry.Delivery.Done(nil)
 		}
 	}
 }
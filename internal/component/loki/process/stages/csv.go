@@ -0,0 +1,211 @@
+package stages
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/prometheus/common/model"
+)
+
+// Config Errors.
+var (
+	ErrCSVColumnsRequired      = errors.New("csv columns are required")
+	ErrCSVDuplicateColumn      = errors.New("duplicate csv column")
+	ErrCSVUnknownColumn        = errors.New("csv expression refers to an unknown column")
+	ErrCSVInvalidDelimiter     = errors.New("csv delimiter must be a single character")
+	ErrCSVInvalidQuote         = errors.New("csv quote must be empty or a single character different from the delimiter")
+	ErrEmptyCSVStageSource     = errors.New("empty source")
+	errCSVUnterminatedQuote    = errors.New("unterminated quoted field")
+	errCSVUnexpectedAfterQuote = errors.New("unexpected character after quoted field")
+)
+
+// CSVConfig configures a processing stage which extracts values from the
+// fields of CSV log lines into the shared values map.
+type CSVConfig struct {
+	Columns     []string          `alloy:"columns,attr"`
+	Expressions map[string]string `alloy:"expressions,attr,optional"`
+	Source      *string           `alloy:"source,attr,optional"`
+	Delimiter   string            `alloy:"delimiter,attr,optional"`
+	Quote       string            `alloy:"quote,attr,optional"`
+}
+
+// DefaultCSVConfig holds the default values of CSVConfig.
+var DefaultCSVConfig = CSVConfig{
+	Delimiter: ",",
+	Quote:     `"`,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *CSVConfig) SetToDefault() {
+	*c = DefaultCSVConfig
+}
+
+// validateCSVConfig validates the config and returns the index of the column
+// of each extracted value, by its name.
+func validateCSVConfig(c CSVConfig) (map[string]int, error) {
+	if len(c.Columns) == 0 {
+		return nil, ErrCSVColumnsRequired
+	}
+
+	if c.Source != nil && *c.Source == "" {
+		return nil, ErrEmptyCSVStageSource
+	}
+
+	if utf8.RuneCountInString(c.Delimiter) != 1 {
+		return nil, ErrCSVInvalidDelimiter
+	}
+	if c.Quote != "" && (utf8.RuneCountInString(c.Quote) != 1 || c.Quote == c.Delimiter) {
+		return nil, ErrCSVInvalidQuote
+	}
+
+	indexes := make(map[string]int, len(c.Columns))
+	for i, col := range c.Columns {
+		if col == "" {
+			continue
+		}
+		if _, ok := indexes[col]; ok {
+			return nil, fmt.Errorf("%w: %q", ErrCSVDuplicateColumn, col)
+		}
+		indexes[col] = i
+	}
+	// Without expressions, every named column is extracted.
+	if len(c.Expressions) == 0 {
+		return indexes, nil
+	}
+
+	columns := make(map[string]int, len(c.Expressions))
+	for n, col := range c.Expressions {
+		if col == "" {
+			col = n
+		}
+		i, ok := indexes[col]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrCSVUnknownColumn, col)
+		}
+		columns[n] = i
+	}
+	return columns, nil
+}
+
+// csvStage sets extracted data from the fields of CSV lines.
+type csvStage struct {
+	config  *CSVConfig
+	columns map[string]int
+	logger  log.Logger
+}
+
+// newCSVStage creates a new csv pipeline stage from a config.
+func newCSVStage(logger log.Logger, config CSVConfig) (Stage, error) {
+	columns, err := validateCSVConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return toStage(&csvStage{
+		config:  &config,
+		columns: columns,
+		logger:  log.With(logger, "component", "stage", "type", "csv"),
+	}), nil
+}
+
+// Process implements Stage
+func (c *csvStage) Process(labels model.LabelSet, extracted map[string]interface{}, t *time.Time, entry *string) {
+	// If a source key is provided, the csv stage should process it
+	// from the extracted map, otherwise should fall back to the entry
+	input := entry
+
+	if c.config.Source != nil {
+		if _, ok := extracted[*c.config.Source]; !ok {
+			level.Debug(c.logger).Log("msg", "source does not exist in the set of extracted values", "source", *c.config.Source)
+			return
+		}
+
+		value, err := getString(extracted[*c.config.Source])
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "failed to convert source value to string", "source", *c.config.Source, "err", err, "type", reflect.TypeOf(extracted[*c.config.Source]))
+			return
+		}
+
+		input = &value
+	}
+
+	if input == nil {
+		level.Debug(c.logger).Log("msg", "cannot parse a nil entry")
+		return
+	}
+
+	fields, err := splitCSV(*input, c.config.Delimiter, c.config.Quote)
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "failed to parse CSV", "err", err)
+		return
+	}
+	// Header lines, such as the first line of CSV files, aren't records.
+	if slices.Equal(fields, c.config.Columns) {
+		level.Debug(c.logger).Log("msg", "skipping CSV header")
+		return
+	}
+	if len(fields) != len(c.config.Columns) {
+		level.Debug(c.logger).Log("msg", fmt.Sprintf("found %d fields for %d columns in csv stage", len(fields), len(c.config.Columns)))
+	}
+
+	for n, i := range c.columns {
+		if i < len(fields) {
+			extracted[n] = fields[i]
+		}
+	}
+	level.Debug(c.logger).Log("msg", "extracted data debug in csv stage", "extracted data", fmt.Sprintf("%v", extracted))
+}
+
+// Name implements Stage
+func (c *csvStage) Name() string {
+	return StageTypeCSV
+}
+
+// splitCSV returns the fields of a CSV line separated by delim. Fields which
+// start with quote may contain delim and quote, which is escaped by doubling
+// it. Quoting is disabled when quote is empty.
+func splitCSV(line, delim, quote string) ([]string, error) {
+	var fields []string
+	for {
+		if quote == "" || !strings.HasPrefix(line, quote) {
+			i := strings.Index(line, delim)
+			if i < 0 {
+				return append(fields, line), nil
+			}
+			fields = append(fields, line[:i])
+			line = line[i+len(delim):]
+			continue
+		}
+
+		var field strings.Builder
+		line = line[len(quote):]
+		for {
+			i := strings.Index(line, quote)
+			if i < 0 {
+				return nil, errCSVUnterminatedQuote
+			}
+			field.WriteString(line[:i])
+			line = line[i+len(quote):]
+			if !strings.HasPrefix(line, quote) {
+				break
+			}
+			field.WriteString(quote)
+			line = line[len(quote):]
+		}
+		fields = append(fields, field.String())
+
+		if line == "" {
+			return fields, nil
+		}
+		if !strings.HasPrefix(line, delim) {
+			return nil, errCSVUnexpectedAfterQuote
+		}
+		line = line[len(delim):]
+	}
+}
@@ -0,0 +1,209 @@
+package stages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/internal/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCSVAlloyMultiStageWithSource = `
+stage.csv {
+    columns     = ["time", "level", "message", "tags"]
+    expressions = { level = "", msg = "message", tags = "" }
+}
+stage.csv {
+    columns   = ["first", "second"]
+    delimiter = ";"
+    quote     = ""
+    source    = "tags"
+}
+`
+
+func TestPipeline_CSV(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testCSVAlloyMultiStageWithSource), nil, prometheus.DefaultRegisterer)
+	require.NoError(t, err)
+
+	out := processEntries(pl, newEntry(nil, nil, `2024-05-01T10:00:00Z,warn,"disk ""/data"" is 95% full",disk;"storage"`, time.Now()))[0]
+	assert.Equal(t, map[string]interface{}{
+		"level":  "warn",
+		"msg":    `disk "/data" is 95% full`,
+		"tags":   `disk;"storage"`,
+		"first":  "disk",
+		"second": `"storage"`,
+	}, out.Extracted)
+}
+
+func TestCSVConfig_validate(t *testing.T) {
+	t.Parallel()
+	empty := ""
+	tests := map[string]struct {
+		config func(c *CSVConfig)
+		err    error
+	}{
+		"missing columns": {
+			func(c *CSVConfig) { c.Columns = nil },
+			ErrCSVColumnsRequired,
+		},
+		"empty source": {
+			func(c *CSVConfig) { c.Source = &empty },
+			ErrEmptyCSVStageSource,
+		},
+		"duplicate column": {
+			func(c *CSVConfig) { c.Columns = []string{"a", "b", "a"} },
+			ErrCSVDuplicateColumn,
+		},
+		"unknown column": {
+			func(c *CSVConfig) { c.Expressions = map[string]string{"c": ""} },
+			ErrCSVUnknownColumn,
+		},
+		"empty delimiter": {
+			func(c *CSVConfig) { c.Delimiter = "" },
+			ErrCSVInvalidDelimiter,
+		},
+		"long delimiter": {
+			func(c *CSVConfig) { c.Delimiter = "||" },
+			ErrCSVInvalidDelimiter,
+		},
+		"quote same as delimiter": {
+			func(c *CSVConfig) { c.Quote = "," },
+			ErrCSVInvalidQuote,
+		},
+		"valid": {
+			func(c *CSVConfig) {
+				c.Expressions = map[string]string{"a": "", "c": "b"}
+				c.Delimiter = "\t"
+				c.Quote = "'"
+			},
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			c := DefaultCSVConfig
+			c.Columns = []string{"a", "b"}
+			tt.config(&c)
+			_, err := validateCSVConfig(c)
+			if tt.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestCSVParser_Parse(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		config          func(c *CSVConfig)
+		extracted       map[string]interface{}
+		entry           string
+		expectedExtract map[string]interface{}
+	}{
+		"successfully extract every named column": {
+			func(c *CSVConfig) { c.Columns = []string{"ip", "", "status"} },
+			map[string]interface{}{},
+			`10.0.0.1,"GET /index.html, HTTP/1.1",200`,
+			map[string]interface{}{
+				"ip":     "10.0.0.1",
+				"status": "200",
+			},
+		},
+		"successfully extract with custom delimiter and quote": {
+			func(c *CSVConfig) {
+				c.Columns = []string{"user", "comment"}
+				c.Delimiter = "|"
+				c.Quote = "'"
+			},
+			map[string]interface{}{},
+			`alice|'it''s|fine'`,
+			map[string]interface{}{
+				"user":    "alice",
+				"comment": "it's|fine",
+			},
+		},
+		"successfully extract empty fields": {
+			func(c *CSVConfig) { c.Columns = []string{"a", "b", "c"} },
+			map[string]interface{}{},
+			`,"",`,
+			map[string]interface{}{
+				"a": "",
+				"b": "",
+				"c": "",
+			},
+		},
+		"missing fields": {
+			func(c *CSVConfig) { c.Columns = []string{"a", "b", "c"} },
+			map[string]interface{}{},
+			`1,2`,
+			map[string]interface{}{
+				"a": "1",
+				"b": "2",
+			},
+		},
+		"header line": {
+			func(c *CSVConfig) { c.Columns = []string{"a", "b", "c"} },
+			map[string]interface{}{},
+			`a,"b",c`,
+			map[string]interface{}{},
+		},
+		"unterminated quote": {
+			func(c *CSVConfig) { c.Columns = []string{"a", "b"} },
+			map[string]interface{}{},
+			`1,"2`,
+			map[string]interface{}{},
+		},
+		"unexpected character after quote": {
+			func(c *CSVConfig) { c.Columns = []string{"a", "b"} },
+			map[string]interface{}{},
+			`"1"2,3`,
+			map[string]interface{}{},
+		},
+		"successfully extract from extracted[source]": {
+			func(c *CSVConfig) {
+				c.Columns = []string{"name", "version"}
+				c.Delimiter = "/"
+				c.Source = &protocolStr
+			},
+			map[string]interface{}{
+				"protocol": "HTTP/1.1",
+			},
+			"unknown",
+			map[string]interface{}{
+				"protocol": "HTTP/1.1",
+				"name":     "HTTP",
+				"version":  "1.1",
+			},
+		},
+		"missing extracted[source]": {
+			func(c *CSVConfig) {
+				c.Columns = []string{"name", "version"}
+				c.Source = &protocolStr
+			},
+			map[string]interface{}{},
+			"unknown",
+			map[string]interface{}{},
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			config := DefaultCSVConfig
+			tt.config(&config)
+
+			logger := util.TestAlloyLogger(t)
+			p, err := New(logger, nil, StageConfig{CSVConfig: &config}, nil)
+			require.NoError(t, err)
+
+			out := processEntries(p, newEntry(tt.extracted, nil, tt.entry, time.Now()))[0]
+			assert.Equal(t, tt.expectedExtract, out.Extracted)
+		})
+	}
+}
@@ -51,6 +51,7 @@ func (m *eventLogMessageStage) Run(in chan Entry) chan Entry {
 		for e := range in {
 			err := m.processEntry(e.Extracted, key)
 			if err != nil {
+				e.Delivery.Done(nil)
 				continue
 			}
 			out <- e
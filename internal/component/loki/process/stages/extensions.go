@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/go-kit/log"
+	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/syntax"
 	"github.com/prometheus/client_golang/prometheus"
@@ -136,6 +137,7 @@ func (c *cri) Run(entry chan Entry) chan Entry {
 				builder.WriteString(prev.Line)
 				builder.WriteString(e.Line)
 				e.Line = builder.String()
+				e.Delivery = loki.MergeDeliveries([]*loki.Delivery{prev.Delivery, e.Delivery})
 			}
 			c.ensureTruncateIfRequired(&e)
 			c.partialLines[fingerprint] = e
@@ -152,6 +154,7 @@ func (c *cri) Run(entry chan Entry) chan Entry {
 			builder.WriteString(prev.Line)
 			builder.WriteString(e.Line)
 			e.Line = builder.String()
+			e.Delivery = loki.MergeDeliveries([]*loki.Delivery{prev.Delivery, e.Delivery})
 			c.ensureTruncateIfRequired(&e)
 			delete(c.partialLines, fingerprint)
 		}
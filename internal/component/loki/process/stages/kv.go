@@ -0,0 +1,151 @@
+package stages
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/prometheus/common/model"
+)
+
+// Config Errors.
+var (
+	ErrKVEmptyDelimiter       = errors.New("kv pair_delimiter and field_delimiter must not be empty")
+	ErrKVSameDelimiters       = errors.New("kv pair_delimiter and field_delimiter must be different")
+	ErrKVPrefixAndExpressions = errors.New("kv prefix can't be used with expressions")
+	ErrEmptyKVStageSource     = errors.New("empty source")
+)
+
+// KVConfig configures a processing stage which extracts values from log lines
+// made of key/value pairs into the shared values map.
+type KVConfig struct {
+	Expressions    map[string]string `alloy:"expressions,attr,optional"`
+	Source         *string           `alloy:"source,attr,optional"`
+	PairDelimiter  string            `alloy:"pair_delimiter,attr,optional"`
+	FieldDelimiter string            `alloy:"field_delimiter,attr,optional"`
+	TrimChars      string            `alloy:"trim_chars,attr,optional"`
+	Prefix         string            `alloy:"prefix,attr,optional"`
+}
+
+// DefaultKVConfig holds the default values of KVConfig.
+var DefaultKVConfig = KVConfig{
+	PairDelimiter:  " ",
+	FieldDelimiter: "=",
+	TrimChars:      " \t",
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *KVConfig) SetToDefault() {
+	*c = DefaultKVConfig
+}
+
+// validateKVConfig validates the config and returns an inverse mapping of
+// the configured expressions, from each key to the names of the extracted
+// values it sets. It returns a nil mapping when every key is extracted.
+func validateKVConfig(c KVConfig) (map[string][]string, error) {
+	if c.PairDelimiter == "" || c.FieldDelimiter == "" {
+		return nil, ErrKVEmptyDelimiter
+	}
+	if c.PairDelimiter == c.FieldDelimiter {
+		return nil, ErrKVSameDelimiters
+	}
+
+	if c.Source != nil && *c.Source == "" {
+		return nil, ErrEmptyKVStageSource
+	}
+
+	if len(c.Expressions) == 0 {
+		return nil, nil
+	}
+	if c.Prefix != "" {
+		return nil, ErrKVPrefixAndExpressions
+	}
+
+	inverseMapping := make(map[string][]string, len(c.Expressions))
+	for n, k := range c.Expressions {
+		// If there is no key, use the name as the key.
+		if k == "" {
+			k = n
+		}
+		inverseMapping[k] = append(inverseMapping[k], n)
+	}
+	return inverseMapping, nil
+}
+
+// kvStage sets extracted data from key/value pairs.
+type kvStage struct {
+	config         *KVConfig
+	inverseMapping map[string][]string
+	logger         log.Logger
+}
+
+// newKVStage creates a new kv pipeline stage from a config.
+func newKVStage(logger log.Logger, config KVConfig) (Stage, error) {
+	inverseMapping, err := validateKVConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return toStage(&kvStage{
+		config:         &config,
+		inverseMapping: inverseMapping,
+		logger:         log.With(logger, "component", "stage", "type", "kv"),
+	}), nil
+}
+
+// Process implements Stage
+func (k *kvStage) Process(labels model.LabelSet, extracted map[string]interface{}, t *time.Time, entry *string) {
+	// If a source key is provided, the kv stage should process it
+	// from the extracted map, otherwise should fall back to the entry
+	input := entry
+
+	if k.config.Source != nil {
+		if _, ok := extracted[*k.config.Source]; !ok {
+			level.Debug(k.logger).Log("msg", "source does not exist in the set of extracted values", "source", *k.config.Source)
+			return
+		}
+
+		value, err := getString(extracted[*k.config.Source])
+		if err != nil {
+			level.Debug(k.logger).Log("msg", "failed to convert source value to string", "source", *k.config.Source, "err", err, "type", reflect.TypeOf(extracted[*k.config.Source]))
+			return
+		}
+
+		input = &value
+	}
+
+	if input == nil {
+		level.Debug(k.logger).Log("msg", "cannot parse a nil entry")
+		return
+	}
+
+	for _, pair := range strings.Split(*input, k.config.PairDelimiter) {
+		// Values may contain the field delimiter, keys can't.
+		key, value, ok := strings.Cut(pair, k.config.FieldDelimiter)
+		if !ok {
+			continue
+		}
+		key = strings.Trim(key, k.config.TrimChars)
+		if key == "" {
+			continue
+		}
+		value = strings.Trim(value, k.config.TrimChars)
+
+		if k.inverseMapping == nil {
+			extracted[k.config.Prefix+key] = value
+		} else {
+			for _, name := range k.inverseMapping[key] {
+				extracted[name] = value
+			}
+		}
+	}
+	level.Debug(k.logger).Log("msg", "extracted data debug in kv stage", "extracted data", fmt.Sprintf("%v", extracted))
+}
+
+// Name implements Stage
+func (k *kvStage) Name() string {
+	return StageTypeKV
+}
@@ -0,0 +1,187 @@
+package stages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/internal/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKVAlloyMultiStageWithSource = `
+stage.kv {
+    pair_delimiter  = "|"
+    field_delimiter = ":"
+    prefix          = "appliance_"
+}
+stage.kv {
+    expressions     = { src = "", dst = "" }
+    pair_delimiter  = ","
+    field_delimiter = "->"
+    source          = "appliance_flow"
+}
+`
+
+func TestPipeline_KV(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testKVAlloyMultiStageWithSource), nil, prometheus.DefaultRegisterer)
+	require.NoError(t, err)
+
+	out := processEntries(pl, newEntry(nil, nil, "severity: high | action:drop|flow:src->10.0.0.1, dst->10.0.0.2", time.Now()))[0]
+	assert.Equal(t, map[string]interface{}{
+		"appliance_severity": "high",
+		"appliance_action":   "drop",
+		"appliance_flow":     "src->10.0.0.1, dst->10.0.0.2",
+		"src":                "10.0.0.1",
+		"dst":                "10.0.0.2",
+	}, out.Extracted)
+}
+
+func TestKVConfig_validate(t *testing.T) {
+	t.Parallel()
+	empty := ""
+	tests := map[string]struct {
+		config func(c *KVConfig)
+		err    error
+	}{
+		"empty pair delimiter": {
+			func(c *KVConfig) { c.PairDelimiter = "" },
+			ErrKVEmptyDelimiter,
+		},
+		"empty field delimiter": {
+			func(c *KVConfig) { c.FieldDelimiter = "" },
+			ErrKVEmptyDelimiter,
+		},
+		"same delimiters": {
+			func(c *KVConfig) { c.FieldDelimiter = " " },
+			ErrKVSameDelimiters,
+		},
+		"empty source": {
+			func(c *KVConfig) { c.Source = &empty },
+			ErrEmptyKVStageSource,
+		},
+		"prefix with expressions": {
+			func(c *KVConfig) {
+				c.Expressions = map[string]string{"level": ""}
+				c.Prefix = "kv_"
+			},
+			ErrKVPrefixAndExpressions,
+		},
+		"valid with expressions": {
+			func(c *KVConfig) { c.Expressions = map[string]string{"level": "", "msg": "message"} },
+			nil,
+		},
+		"valid with prefix": {
+			func(c *KVConfig) { c.Prefix = "kv_" },
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			c := DefaultKVConfig
+			tt.config(&c)
+			_, err := validateKVConfig(c)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestKVConfig_validateSameKey(t *testing.T) {
+	c := DefaultKVConfig
+	c.Expressions = map[string]string{"a": "x", "b": "x", "x": ""}
+	inverseMapping, err := validateKVConfig(c)
+	require.NoError(t, err)
+	require.Len(t, inverseMapping, 1)
+	require.ElementsMatch(t, []string{"a", "b", "x"}, inverseMapping["x"])
+}
+
+func TestKVParser_Parse(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		config          func(c *KVConfig)
+		extracted       map[string]interface{}
+		entry           string
+		expectedExtract map[string]interface{}
+	}{
+		"successfully extract every key": {
+			func(c *KVConfig) {},
+			map[string]interface{}{},
+			"level=info  msg=started addr=:8080=http empty= =ignored noise",
+			map[string]interface{}{
+				"level": "info",
+				"msg":   "started",
+				"addr":  ":8080=http",
+				"empty": "",
+			},
+		},
+		"successfully extract configured keys": {
+			func(c *KVConfig) { c.Expressions = map[string]string{"level": "", "msg": "message"} },
+			map[string]interface{}{},
+			"level=info message=started addr=:8080",
+			map[string]interface{}{
+				"level": "info",
+				"msg":   "started",
+			},
+		},
+		"successfully extract a key into several values": {
+			func(c *KVConfig) { c.Expressions = map[string]string{"a": "x", "b": "x"} },
+			map[string]interface{}{},
+			"x=1 y=2",
+			map[string]interface{}{
+				"a": "1",
+				"b": "1",
+			},
+		},
+		"successfully trim characters": {
+			func(c *KVConfig) {
+				c.PairDelimiter = ";"
+				c.TrimChars = ` "`
+			},
+			map[string]interface{}{},
+			`user = "alice" ; "role": "admin"`,
+			map[string]interface{}{
+				"user": "alice",
+			},
+		},
+		"successfully extract from extracted[source]": {
+			func(c *KVConfig) {
+				c.PairDelimiter = "&"
+				c.Source = &protocolStr
+				c.Prefix = "query_"
+			},
+			map[string]interface{}{
+				"protocol": "a=1&b=2",
+			},
+			"unknown",
+			map[string]interface{}{
+				"protocol": "a=1&b=2",
+				"query_a":  "1",
+				"query_b":  "2",
+			},
+		},
+		"missing extracted[source]": {
+			func(c *KVConfig) { c.Source = &protocolStr },
+			map[string]interface{}{},
+			"level=info",
+			map[string]interface{}{},
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			config := DefaultKVConfig
+			tt.config(&config)
+
+			logger := util.TestAlloyLogger(t)
+			p, err := New(logger, nil, StageConfig{KVConfig: &config}, nil)
+			require.NoError(t, err)
+
+			out := processEntries(p, newEntry(tt.extracted, nil, tt.entry, time.Now()))[0]
+			assert.Equal(t, tt.expectedExtract, out.Extracted)
+		})
+	}
+}
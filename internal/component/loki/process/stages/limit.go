@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
@@ -14,9 +15,11 @@ import (
 
 // Configuration errors.
 var (
-	ErrLimitStageInvalidRateOrBurst = errors.New("limit stage failed to parse rate or burst")
-	ErrLimitStageByLabelMustDrop    = errors.New("When ratelimiting by label, drop must be true")
-	ratelimitDropReason             = "ratelimit_drop_stage"
+	ErrLimitStageInvalidRateOrBurst  = errors.New("limit stage failed to parse rate or burst")
+	ErrLimitStageByLabelMustDrop     = errors.New("When ratelimiting by label, drop must be true")
+	ErrLimitStageClusterWideMustDrop = errors.New("when ratelimiting cluster wide, drop must be true")
+	ErrLimitStageClusterWideNoState  = errors.New("ratelimiting cluster wide requires clustering to be enabled")
+	ratelimitDropReason              = "ratelimit_drop_stage"
 )
 
 // MinReasonableMaxDistinctLabels provides a sensible default.
@@ -29,13 +32,17 @@ type LimitConfig struct {
 	Drop              bool    `alloy:"drop,attr,optional"`
 	ByLabelName       string  `alloy:"by_label_name,attr,optional"`
 	MaxDistinctLabels int     `alloy:"max_distinct_labels,attr,optional"`
+	ClusterWide       bool    `alloy:"cluster_wide,attr,optional"`
 }
 
-func newLimitStage(logger log.Logger, cfg LimitConfig, registerer prometheus.Registerer) (Stage, error) {
+func newLimitStage(logger log.Logger, cfg LimitConfig, registerer prometheus.Registerer, state sharedState) (Stage, error) {
 	err := validateLimitConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.ClusterWide && state.store == nil {
+		return nil, ErrLimitStageClusterWideNoState
+	}
 
 	logger = log.With(logger, "component", "stage", "type", "limit")
 	if cfg.ByLabelName != "" && cfg.MaxDistinctLabels < MinReasonableMaxDistinctLabels {
@@ -54,6 +61,11 @@ func newLimitStage(logger log.Logger, cfg LimitConfig, registerer prometheus.Reg
 
 	if cfg.ByLabelName != "" {
 		r.dropCountByLabel = getDropCountByLabelMetric(registerer)
+	}
+
+	if cfg.ClusterWide {
+		r.clusterLimiter = newClusterLimiter(cfg, state)
+	} else if cfg.ByLabelName != "" {
 		newRateLimiter := func() *rate.Limiter { return rate.NewLimiter(rate.Limit(cfg.Rate), cfg.Burst) }
 		gcCb := func() { r.dropCountByLabel.Reset() }
 		r.rateLimiterByLabel = NewGenMap[model.LabelValue, *rate.Limiter](cfg.MaxDistinctLabels, newRateLimiter, gcCb)
@@ -72,15 +84,83 @@ func validateLimitConfig(cfg LimitConfig) error {
 	if cfg.ByLabelName != "" && !cfg.Drop {
 		return ErrLimitStageByLabelMustDrop
 	}
+	if cfg.ClusterWide && !cfg.Drop {
+		return ErrLimitStageClusterWideMustDrop
+	}
 	return nil
 }
 
+// clusterLimiter limits the rate of log lines across the peers of a cluster.
+//
+// Lines are counted in fixed windows in which burst lines are allowed, which
+// are long enough to allow rate lines per second on average. Every window is
+// at least one second long, in which case more than burst lines are allowed.
+// Counters are shared with other peers through gossip, so peers may accept
+// more lines than allowed in total until they learn about lines accepted by
+// other peers.
+//
+// At most maxKeys label values are limited in a window, so that the number
+// of counters stays bounded. Lines with other label values are accepted.
+type clusterLimiter struct {
+	state  sharedState
+	name   string        // Prefix of the counters of the limiter.
+	window time.Duration // Length of a window.
+	limit  uint64        // Number of lines allowed in a window.
+	now    func() time.Time
+
+	maxKeys    int                 // Maximum number of label values in a window, or 0 for no maximum.
+	keys       map[string]struct{} // Label values limited in the current window.
+	keysWindow int64               // Index of the current window.
+}
+
+func newClusterLimiter(cfg LimitConfig, state sharedState) *clusterLimiter {
+	window := max(time.Duration(float64(cfg.Burst)/cfg.Rate*float64(time.Second)), time.Second)
+	l := &clusterLimiter{
+		state:  state,
+		name:   fmt.Sprintf("%s/limit/%g/%d", state.prefix, cfg.Rate, cfg.Burst),
+		window: window,
+		limit:  uint64(max(float64(cfg.Burst), cfg.Rate*window.Seconds())),
+		now:    time.Now,
+		keys:   make(map[string]struct{}),
+	}
+	if cfg.ByLabelName != "" {
+		l.maxKeys = cfg.MaxDistinctLabels
+	}
+	return l
+}
+
+// Allow reports whether a line for key can be accepted, and counts it if it
+// can. key is the value of the label lines are limited by, if any.
+func (l *clusterLimiter) Allow(key string) bool {
+	start := l.now().UnixNano() / int64(l.window)
+	if start != l.keysWindow {
+		clear(l.keys)
+		l.keysWindow = start
+	}
+	if _, ok := l.keys[key]; !ok {
+		if l.maxKeys > 0 && len(l.keys) >= l.maxKeys {
+			return true
+		}
+		l.keys[key] = struct{}{}
+	}
+
+	counter := l.state.store.Counter(fmt.Sprintf("%s/%s/%d", l.name, key, start))
+	if counter.Value() >= l.limit {
+		return false
+	}
+	// Counters are kept for an extra window so that peers which are behind
+	// still count lines in the same window.
+	counter.Add(1, 2*l.window)
+	return true
+}
+
 // limitStage applies Label matchers to determine if the include stages should be run
 type limitStage struct {
 	logger             log.Logger
 	cfg                LimitConfig
 	rateLimiter        *rate.Limiter
 	rateLimiterByLabel GenerationalMap[model.LabelValue, *rate.Limiter]
+	clusterLimiter     *clusterLimiter
 	dropCount          *prometheus.CounterVec
 	dropCountByLabel   *prometheus.CounterVec
 }
@@ -94,12 +174,32 @@ func (m *limitStage) Run(in chan Entry) chan Entry {
 				out <- e
 				continue
 			}
+			e.Delivery.Done(nil)
 		}
 	}()
 	return out
 }
 
 func (m *limitStage) shouldThrottle(labels model.LabelSet) bool {
+	if m.clusterLimiter != nil {
+		var labelValue model.LabelValue
+		if m.cfg.ByLabelName != "" {
+			var ok bool
+			labelValue, ok = labels[model.LabelName(m.cfg.ByLabelName)]
+			if !ok {
+				return false // if no label found, dont ratelimit
+			}
+		}
+		if m.clusterLimiter.Allow(string(labelValue)) {
+			return false
+		}
+		m.dropCount.WithLabelValues(ratelimitDropReason).Inc()
+		if m.cfg.ByLabelName != "" {
+			m.dropCountByLabel.WithLabelValues(m.cfg.ByLabelName, string(labelValue)).Inc()
+		}
+		return true
+	}
+
 	if m.cfg.ByLabelName != "" {
 		labelValue, ok := labels[model.LabelName(m.cfg.ByLabelName)]
 		if !ok {
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/alloy/internal/service/cluster/crdt"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 )
 
@@ -143,3 +144,103 @@ func TestLimitByLabelPipeline(t *testing.T) {
 	assert.True(t, hasTotal)
 	assert.True(t, hasByLabel)
 }
+
+var testLimitClusterWideAlloy = `
+stage.limit {
+		rate  = 1
+		burst = 2
+		drop  = true
+
+		by_label_name = "app"
+		cluster_wide  = true
+}`
+
+func TestLimitClusterWide(t *testing.T) {
+	var (
+		nodeA = crdt.NewStore("a")
+		nodeB = crdt.NewStore("b")
+		now   = time.Now()
+	)
+
+	newStage := func(store *crdt.Store) *limitStage {
+		cfg := loadConfig(testLimitClusterWideAlloy)[0]
+		s, err := newLimitStage(util_log.Logger, *cfg.LimitConfig, prometheus.NewRegistry(), sharedState{store: store, prefix: plName})
+		require.NoError(t, err)
+		ls := s.(*limitStage)
+		ls.clusterLimiter.now = func() time.Time { return now }
+		return ls
+	}
+	a, b := newStage(nodeA), newStage(nodeB)
+
+	loki := model.LabelSet{"app": "loki"}
+	require.False(t, a.shouldThrottle(loki))
+	require.False(t, a.shouldThrottle(loki))
+	require.True(t, a.shouldThrottle(loki))
+
+	// Node b isn't aware of the lines accepted by a until the state is merged.
+	require.False(t, b.shouldThrottle(model.LabelSet{"app": "poki"}))
+	nodeB.Merge(nodeA.Snapshot())
+	require.True(t, b.shouldThrottle(loki))
+	require.False(t, b.shouldThrottle(model.LabelSet{"app": "poki"}))
+	require.False(t, b.shouldThrottle(model.LabelSet{}))
+
+	// Lines are accepted again in the next window.
+	now = now.Add(2 * time.Second)
+	require.False(t, b.shouldThrottle(loki))
+}
+
+func TestLimitClusterWide_Prune(t *testing.T) {
+	store := crdt.NewStore("a")
+	cfg := LimitConfig{Rate: 10, Burst: 1, Drop: true, ByLabelName: "app", ClusterWide: true}
+	s, err := newLimitStage(util_log.Logger, cfg, prometheus.NewRegistry(), sharedState{store: store, prefix: plName})
+	require.NoError(t, err)
+	ls := s.(*limitStage)
+
+	for _, app := range []string{"loki", "mimir", "tempo"} {
+		require.False(t, ls.shouldThrottle(model.LabelSet{"app": model.LabelValue(app)}))
+	}
+	require.Equal(t, 3, store.Len())
+
+	// Counters are removed from the store once they expire, after two
+	// windows of one second.
+	require.Eventually(t, func() bool {
+		store.Prune()
+		return store.Len() == 0
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+func TestLimitClusterWide_MaxDistinctLabels(t *testing.T) {
+	store := crdt.NewStore("a")
+	cfg := LimitConfig{Rate: 1, Burst: 1, Drop: true, ByLabelName: "app", ClusterWide: true}
+	s, err := newLimitStage(util_log.Logger, cfg, prometheus.NewRegistry(), sharedState{store: store, prefix: plName})
+	require.NoError(t, err)
+	ls := s.(*limitStage)
+	now := time.Now()
+	ls.clusterLimiter.now = func() time.Time { return now }
+	ls.clusterLimiter.maxKeys = 2
+
+	loki, mimir, tempo := model.LabelSet{"app": "loki"}, model.LabelSet{"app": "mimir"}, model.LabelSet{"app": "tempo"}
+	require.False(t, ls.shouldThrottle(loki))
+	require.False(t, ls.shouldThrottle(mimir))
+	require.True(t, ls.shouldThrottle(loki))
+
+	// Lines of label values beyond the maximum aren't limited nor counted.
+	require.False(t, ls.shouldThrottle(tempo))
+	require.False(t, ls.shouldThrottle(tempo))
+	require.Equal(t, 2, store.Len())
+
+	// The label values limited are reset in the next window.
+	now = now.Add(time.Second)
+	require.False(t, ls.shouldThrottle(tempo))
+	require.True(t, ls.shouldThrottle(tempo))
+}
+
+func TestLimitClusterWide_Validation(t *testing.T) {
+	cfg := LimitConfig{Rate: 1, Burst: 1, ClusterWide: true}
+	_, err := newLimitStage(util_log.Logger, cfg, prometheus.NewRegistry(), sharedState{store: crdt.NewStore("a")})
+	require.ErrorIs(t, err, ErrLimitStageClusterWideMustDrop)
+
+	cfg.Drop = true
+	_, err = newLimitStage(util_log.Logger, cfg, prometheus.NewRegistry(), sharedState{})
+	require.ErrorIs(t, err, ErrLimitStageClusterWideNoState)
+}
@@ -62,7 +62,7 @@ func validateMatcherConfig(cfg *MatchConfig) (logql.Expr, error) {
 }
 
 // newMatcherStage creates a new matcherStage from config
-func newMatcherStage(logger log.Logger, jobName *string, config MatchConfig, registerer prometheus.Registerer) (Stage, error) {
+func newMatcherStage(logger log.Logger, jobName *string, config MatchConfig, registerer prometheus.Registerer, state sharedState) (Stage, error) {
 	selector, err := validateMatcherConfig(&config)
 	if err != nil {
 		return nil, err
@@ -77,7 +77,7 @@ func newMatcherStage(logger log.Logger, jobName *string, config MatchConfig, reg
 	var pl *Pipeline
 	if config.Action == MatchActionKeep {
 		var err error
-		pl, err = NewPipeline(logger, config.Stages, nPtr, registerer)
+		pl, err = newPipeline(logger, config.Stages, nPtr, registerer, state)
 		if err != nil {
 			return nil, fmt.Errorf("%v: %w", err, fmt.Errorf("match stage failed to create pipeline from config: %v", config))
 		}
@@ -174,6 +174,7 @@ func (m *matcherStage) runDrop(in chan Entry) chan Entry {
 				continue
 			}
 			m.dropCount.WithLabelValues(m.dropReason).Inc()
+			e.Delivery.Done(nil)
 		}
 	}()
 	return out
@@ -160,7 +160,7 @@ func TestMatcher(t *testing.T) {
 				"",
 			}
 			logger := util.TestAlloyLogger(t)
-			s, err := newMatcherStage(logger, nil, matchConfig, prometheus.DefaultRegisterer)
+			s, err := newMatcherStage(logger, nil, matchConfig, prometheus.DefaultRegisterer, sharedState{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("withMatcher() error = %v, wantErr %v", err, tt.wantErr)
 				return
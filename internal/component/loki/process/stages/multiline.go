@@ -73,9 +73,10 @@ type multilineStage struct {
 
 // multilineState captures the internal state of a running multiline stage.
 type multilineState struct {
-	buffer         *bytes.Buffer // The lines of the current multiline block.
-	startLineEntry Entry         // The entry of the start line of a multiline block.
-	currentLines   uint64        // The number of lines of the current multiline block.
+	buffer         *bytes.Buffer    // The lines of the current multiline block.
+	startLineEntry Entry            // The entry of the start line of a multiline block.
+	currentLines   uint64           // The number of lines of the current multiline block.
+	deliveries     []*loki.Delivery // The deliveries of the lines of the current multiline block.
 }
 
 // newMultilineStage creates a MulitlineStage from config
@@ -168,6 +169,7 @@ func (m *multilineStage) runMultiline(in chan Entry, out chan Entry, wg *sync.Wa
 			}
 			state.buffer.WriteString(e.Line)
 			state.currentLines++
+			state.deliveries = append(state.deliveries, e.Delivery)
 
 			if state.currentLines == m.cfg.MaxLines {
 				m.flush(out, state)
@@ -196,8 +198,10 @@ func (m *multilineStage) flush(out chan Entry, s *multilineState) {
 			},
 		},
 	}
+	collapsed.Delivery = loki.MergeDeliveries(s.deliveries)
 	s.buffer.Reset()
 	s.currentLines = 0
+	s.deliveries = nil
 
 	out <- collapsed
 }
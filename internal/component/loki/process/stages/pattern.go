@@ -0,0 +1,171 @@
+package stages
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/loki/v3/pkg/logql/log/pattern"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+// Config Errors.
+var (
+	ErrCouldNotCompilePattern  = errors.New("could not compile pattern")
+	ErrEmptyPatternStageSource = errors.New("empty source")
+)
+
+// PatternConfig configures a processing stage which uses LogQL pattern
+// expressions to extract values from log lines into the shared values map.
+type PatternConfig struct {
+	Expression string  `alloy:"expression,attr"`
+	Source     *string `alloy:"source,attr,optional"`
+}
+
+// validatePatternConfig validates the config and returns a pattern matcher.
+func validatePatternConfig(c PatternConfig) (*pattern.Matcher, error) {
+	if c.Expression == "" {
+		return nil, ErrExpressionRequired
+	}
+
+	if c.Source != nil && *c.Source == "" {
+		return nil, ErrEmptyPatternStageSource
+	}
+
+	matcher, err := pattern.New(c.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ErrCouldNotCompilePattern, err)
+	}
+	// Capture names are checked like in Loki so that the same expressions are
+	// valid in both.
+	for _, name := range matcher.Names() {
+		if !model.LabelName(name).IsValid() {
+			return nil, fmt.Errorf("%v: invalid capture label name '%s'", ErrCouldNotCompilePattern, name)
+		}
+	}
+
+	return matcher, nil
+}
+
+// patternStage sets extracted data using LogQL pattern expressions.
+type patternStage struct {
+	config         *PatternConfig
+	logger         log.Logger
+	unmatchedLines *prometheus.CounterVec
+
+	// mut protects matcher, which reuses its captures between matches.
+	mut     sync.Mutex
+	matcher *pattern.Matcher
+	names   []string
+
+	// literals are the literals of the expression in order, and
+	// leadingLiteral is whether the expression starts with the first one.
+	literals       [][]byte
+	leadingLiteral bool
+}
+
+// newPatternStage creates a new pattern stage.
+func newPatternStage(logger log.Logger, config PatternConfig, registerer prometheus.Registerer) (Stage, error) {
+	matcher, err := validatePatternConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	literals, err := pattern.ParseLiterals(config.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ErrCouldNotCompilePattern, err)
+	}
+	return toStage(&patternStage{
+		config:         &config,
+		logger:         log.With(logger, "component", "stage", "type", "pattern"),
+		unmatchedLines: getPatternUnmatchedLinesMetric(registerer),
+		matcher:        matcher,
+		names:          matcher.Names(),
+		literals:       literals,
+		// Literals are the text between captures, so the expression starts
+		// with a literal exactly when it starts with the first one.
+		leadingLiteral: len(literals) > 0 && strings.HasPrefix(config.Expression, string(literals[0])),
+	}), nil
+}
+
+// Process implements Stage
+func (p *patternStage) Process(labels model.LabelSet, extracted map[string]interface{}, t *time.Time, entry *string) {
+	// If a source key is provided, the pattern stage should process it
+	// from the extracted map, otherwise should fall back to the entry
+	input := entry
+
+	if p.config.Source != nil {
+		if _, ok := extracted[*p.config.Source]; !ok {
+			level.Debug(p.logger).Log("msg", "source does not exist in the set of extracted values", "source", *p.config.Source)
+			return
+		}
+
+		value, err := getString(extracted[*p.config.Source])
+		if err != nil {
+			level.Debug(p.logger).Log("msg", "failed to convert source value to string", "source", *p.config.Source, "err", err, "type", reflect.TypeOf(extracted[*p.config.Source]))
+			return
+		}
+
+		input = &value
+	}
+
+	if input == nil {
+		level.Debug(p.logger).Log("msg", "cannot parse a nil entry")
+		return
+	}
+
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	// As in Loki, a line which matches the expression only partially still
+	// populates the captures up to where it stopped matching, the last of
+	// which holds the rest of the line. It is counted as unmatched all the
+	// same.
+	line := []byte(*input)
+	matches := p.matcher.Matches(line)
+	if !p.matchesFully(line) {
+		p.unmatchedLines.WithLabelValues().Inc()
+		level.Debug(p.logger).Log("msg", "pattern did not match", "input", *input, "pattern", p.config.Expression)
+	}
+
+	for i, m := range matches {
+		extracted[p.names[i]] = string(m)
+	}
+	level.Debug(p.logger).Log("msg", "extracted data debug in pattern stage", "extracted data", fmt.Sprintf("%v", extracted))
+}
+
+// matchesFully reports whether every literal of the expression, including a
+// trailing one, is found in line in order, the way the matcher looks for
+// them. The captures returned by the matcher can't tell, since it returns
+// the rest of the line as a capture when a literal is missing.
+func (p *patternStage) matchesFully(line []byte) bool {
+	if len(line) == 0 {
+		return false
+	}
+	var off int
+	for i, lit := range p.literals {
+		j := bytes.Index(line[off:], lit)
+		if j == -1 || (i == 0 && p.leadingLiteral && j != 0) {
+			return false
+		}
+		off += j + len(lit)
+	}
+	return true
+}
+
+// Name implements Stage
+func (p *patternStage) Name() string {
+	return StageTypePattern
+}
+
+func getPatternUnmatchedLinesMetric(registerer prometheus.Registerer) *prometheus.CounterVec {
+	return registerCounterVec(registerer, "loki_process", "pattern_unmatched_lines_total",
+		"A count of all log lines which didn't match the expression of a pattern stage",
+		nil)
+}
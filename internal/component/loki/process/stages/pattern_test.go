@@ -0,0 +1,236 @@
+package stages
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/internal/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPatternAlloyMultiStageWithSource = `
+stage.pattern {
+    expression = "<ip> - <user> [<timestamp>] \"<action> <path> <protocol>\" <status> <size> <_>"
+}
+stage.pattern {
+    expression = "HTTP/<protocol_version>"
+    source     = "protocol"
+}
+`
+
+func TestPipeline_Pattern(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testPatternAlloyMultiStageWithSource), nil, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	out := processEntries(pl, newEntry(nil, nil, testRegexLogLine, time.Now()))[0]
+	assert.Equal(t, map[string]interface{}{
+		"ip":               "11.11.11.11",
+		"user":             "frank",
+		"timestamp":        "25/Jan/2000:14:00:01 -0500",
+		"action":           "GET",
+		"path":             "/1986.js",
+		"protocol":         "HTTP/1.1",
+		"protocol_version": "1.1",
+		"status":           "200",
+		"size":             "932",
+	}, out.Extracted)
+}
+
+func TestPatternConfig_validate(t *testing.T) {
+	t.Parallel()
+	source := "log"
+	empty := ""
+	tests := map[string]struct {
+		config PatternConfig
+		err    string
+	}{
+		"missing expression": {
+			PatternConfig{},
+			ErrExpressionRequired.Error(),
+		},
+		"empty source": {
+			PatternConfig{Expression: "<ts> <msg>", Source: &empty},
+			ErrEmptyPatternStageSource.Error(),
+		},
+		"no capture": {
+			PatternConfig{Expression: "foo"},
+			ErrCouldNotCompilePattern.Error(),
+		},
+		"consecutive captures": {
+			PatternConfig{Expression: "<ts><msg>"},
+			ErrCouldNotCompilePattern.Error(),
+		},
+		"duplicate captures": {
+			PatternConfig{Expression: "<ts> <ts>"},
+			ErrCouldNotCompilePattern.Error(),
+		},
+		"valid without source": {
+			PatternConfig{Expression: "<ts> <_> <msg>"},
+			"",
+		},
+		"valid with source": {
+			PatternConfig{Expression: "<ts> <msg>", Source: &source},
+			"",
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			_, err := validatePatternConfig(tt.config)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.True(t, strings.HasPrefix(err.Error(), tt.err), "unexpected error: %s", err)
+		})
+	}
+}
+
+func TestPatternParser_Parse(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		config          PatternConfig
+		extracted       map[string]interface{}
+		entry           string
+		expectedExtract map[string]interface{}
+		unmatched       float64
+	}{
+		"successfully match expression on entry": {
+			PatternConfig{
+				Expression: `<ip> <_> <user> [<timestamp>] "<action> <path> <_>" <status> <_>`,
+			},
+			map[string]interface{}{},
+			regexLogFixture,
+			map[string]interface{}{
+				"ip":        "11.11.11.11",
+				"user":      "frank",
+				"timestamp": "25/Jan/2000:14:00:01 -0500",
+				"action":    "GET",
+				"path":      "/1986.js",
+				"status":    "200",
+			},
+			0,
+		},
+		"successfully match expression on extracted[source]": {
+			PatternConfig{
+				Expression: "HTTP/<protocol_version>",
+				Source:     &protocolStr,
+			},
+			map[string]interface{}{
+				"protocol": "HTTP/1.1",
+			},
+			regexLogFixture,
+			map[string]interface{}{
+				"protocol":         "HTTP/1.1",
+				"protocol_version": "1.1",
+			},
+			0,
+		},
+		"partially match expression on entry": {
+			PatternConfig{
+				Expression: "<level> msg=<msg> duration=<duration>",
+			},
+			map[string]interface{}{},
+			"info msg=starting up",
+			map[string]interface{}{
+				"level": "info",
+				"msg":   "starting up",
+			},
+			1,
+		},
+		"partially match expression starting with a capture on entry": {
+			PatternConfig{
+				Expression: "<level> msg=<msg>",
+			},
+			map[string]interface{}{},
+			"blahblahblah",
+			map[string]interface{}{
+				"level": "blahblahblah",
+			},
+			1,
+		},
+		"missing trailing literal on entry": {
+			PatternConfig{
+				Expression: `<ip> - - [<ts>] "<method> <path> <_>"`,
+			},
+			map[string]interface{}{},
+			`1.2.3.4 - - [now] "GET /x HTTP/1.1`,
+			map[string]interface{}{
+				"ip":     "1.2.3.4",
+				"ts":     "now",
+				"method": "GET",
+				"path":   "/x",
+			},
+			1,
+		},
+		"missing literal before the last capture on entry": {
+			PatternConfig{
+				Expression: `<ip> - - [<ts>] "<method> <path> <_>"`,
+			},
+			map[string]interface{}{},
+			`1.2.3.4 - - [now] "GET /x`,
+			map[string]interface{}{
+				"ip":     "1.2.3.4",
+				"ts":     "now",
+				"method": "GET",
+				"path":   "/x",
+			},
+			1,
+		},
+		"failed to match expression on entry": {
+			PatternConfig{
+				Expression: "level=<level> <_>",
+			},
+			map[string]interface{}{},
+			"blahblahblah",
+			map[string]interface{}{},
+			1,
+		},
+		"missing extracted[source]": {
+			PatternConfig{
+				Expression: "HTTP/<protocol_version>",
+				Source:     &protocolStr,
+			},
+			map[string]interface{}{},
+			"blahblahblah",
+			map[string]interface{}{},
+			0,
+		},
+		"non-string data type in extracted[source]": {
+			PatternConfig{
+				Expression: "HTTP/<protocol_version>",
+				Source:     &protocolStr,
+			},
+			map[string]interface{}{
+				"protocol": true,
+			},
+			"unknown/unknown",
+			map[string]interface{}{
+				"protocol": true,
+			},
+			1,
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			logger := util.TestAlloyLogger(t)
+			registry := prometheus.NewRegistry()
+			p, err := New(logger, nil, StageConfig{PatternConfig: &tt.config}, registry)
+			require.NoError(t, err)
+
+			out := processEntries(p, newEntry(tt.extracted, nil, tt.entry, time.Now()))[0]
+			assert.Equal(t, tt.expectedExtract, out.Extracted)
+
+			unmatched := getPatternUnmatchedLinesMetric(registry).WithLabelValues()
+			assert.Equal(t, tt.unmatched, testutil.ToFloat64(unmatched))
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/service/cluster/crdt"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/time/rate"
 )
@@ -17,12 +18,14 @@ import (
 type StageConfig struct {
 	//TODO(thampiotr): sync these with new stages
 	CRIConfig             *CRIConfig             `alloy:"cri,block,optional"`
+	CSVConfig             *CSVConfig             `alloy:"csv,block,optional"`
 	DecolorizeConfig      *DecolorizeConfig      `alloy:"decolorize,block,optional"`
 	DockerConfig          *DockerConfig          `alloy:"docker,block,optional"`
 	DropConfig            *DropConfig            `alloy:"drop,block,optional"`
 	EventLogMessageConfig *EventLogMessageConfig `alloy:"eventlogmessage,block,optional"`
 	GeoIPConfig           *GeoIPConfig           `alloy:"geoip,block,optional"`
 	JSONConfig            *JSONConfig            `alloy:"json,block,optional"`
+	KVConfig              *KVConfig              `alloy:"kv,block,optional"`
 	LabelAllowConfig      *LabelAllowConfig      `alloy:"label_keep,block,optional"`
 	LabelDropConfig       *LabelDropConfig       `alloy:"label_drop,block,optional"`
 	LabelsConfig          *LabelsConfig          `alloy:"labels,block,optional"`
@@ -34,6 +37,7 @@ type StageConfig struct {
 	MultilineConfig       *MultilineConfig       `alloy:"multiline,block,optional"`
 	OutputConfig          *OutputConfig          `alloy:"output,block,optional"`
 	PackConfig            *PackConfig            `alloy:"pack,block,optional"`
+	PatternConfig         *PatternConfig         `alloy:"pattern,block,optional"`
 	RegexConfig           *RegexConfig           `alloy:"regex,block,optional"`
 	ReplaceConfig         *ReplaceConfig         `alloy:"replace,block,optional"`
 	StaticLabelsConfig    *StaticLabelsConfig    `alloy:"static_labels,block,optional"`
@@ -42,6 +46,7 @@ type StageConfig struct {
 	TemplateConfig        *TemplateConfig        `alloy:"template,block,optional"`
 	TenantConfig          *TenantConfig          `alloy:"tenant,block,optional"`
 	TimestampConfig       *TimestampConfig       `alloy:"timestamp,block,optional"`
+	XMLConfig             *XMLConfig             `alloy:"xml,block,optional"`
 }
 
 var rateLimiter *rate.Limiter
@@ -58,9 +63,32 @@ type Pipeline struct {
 
 // NewPipeline creates a new log entry pipeline from a configuration
 func NewPipeline(logger log.Logger, stages []StageConfig, jobName *string, registerer prometheus.Registerer) (*Pipeline, error) {
+	return newPipeline(logger, stages, jobName, registerer, sharedState{})
+}
+
+// NewClusterPipeline creates a new log entry pipeline from a configuration,
+// whose stages can use the state shared by the peers of a cluster. Names of
+// the state start with the job name. state may be nil, in which case stages
+// which require it fail to be created.
+func NewClusterPipeline(logger log.Logger, stages []StageConfig, jobName *string, registerer prometheus.Registerer, state *crdt.Store) (*Pipeline, error) {
+	shared := sharedState{store: state}
+	if jobName != nil {
+		shared.prefix = *jobName
+	}
+	return newPipeline(logger, stages, jobName, registerer, shared)
+}
+
+// sharedState is the state shared by the peers of a cluster, which stages
+// use under names starting with prefix.
+type sharedState struct {
+	store  *crdt.Store
+	prefix string
+}
+
+func newPipeline(logger log.Logger, stages []StageConfig, jobName *string, registerer prometheus.Registerer, state sharedState) (*Pipeline, error) {
 	st := []Stage{}
 	for _, stage := range stages {
-		newStage, err := New(logger, jobName, stage, registerer)
+		newStage, err := newStage(logger, jobName, stage, registerer, state)
 		if err != nil {
 			return nil, fmt.Errorf("invalid stage config %w", err)
 		}
@@ -166,6 +194,7 @@ func (p *Pipeline) Wrap(next loki.EntryHandler) loki.EntryHandler {
 				if rateLimiterDrop {
 					if !rateLimiter.Allow() {
 						p.dropCount.WithLabelValues(rateLimiterDropReason).Inc()
+						e.Delivery.Done(nil)
 						continue
 					}
 				} else {
@@ -16,6 +16,7 @@ import (
 // TODO(@tpaschalis) Let's use this as the list of stages we need to port over.
 const (
 	StageTypeCRI        = "cri"
+	StageTypeCSV        = "csv"
 	StageTypeDecolorize = "decolorize"
 	StageTypeDocker     = "docker"
 	StageTypeDrop       = "drop"
@@ -23,6 +24,7 @@ const (
 	StageTypeEventLogMessage    = "eventlogmessage"
 	StageTypeGeoIP              = "geoip"
 	StageTypeJSON               = "json"
+	StageTypeKV                 = "kv"
 	StageTypeLabel              = "labels"
 	StageTypeLabelAllow         = "labelallow"
 	StageTypeLabelDrop          = "labeldrop"
@@ -34,6 +36,7 @@ const (
 	StageTypeMultiline          = "multiline"
 	StageTypeOutput             = "output"
 	StageTypePack               = "pack"
+	StageTypePattern            = "pattern"
 	StageTypePipeline           = "pipeline"
 	StageTypeRegex              = "regex"
 	StageTypeReplace            = "replace"
@@ -43,6 +46,7 @@ const (
 	StageTypeTemplate           = "template"
 	StageTypeTenant             = "tenant"
 	StageTypeTimestamp          = "timestamp"
+	StageTypeXML                = "xml"
 )
 
 // Processor takes an existing set of labels, timestamp and log entry and returns either a possibly mutated
@@ -113,6 +117,10 @@ func toStage(p Processor) Stage {
 
 // New creates a new stage for the given type and configuration.
 func New(logger log.Logger, jobName *string, cfg StageConfig, registerer prometheus.Registerer) (Stage, error) {
+	return newStage(logger, jobName, cfg, registerer, sharedState{})
+}
+
+func newStage(logger log.Logger, jobName *string, cfg StageConfig, registerer prometheus.Registerer, state sharedState) (Stage, error) {
 	var (
 		s   Stage
 		err error
@@ -133,6 +141,21 @@ func New(logger log.Logger, jobName *string, cfg StageConfig, registerer prometh
 		if err != nil {
 			return nil, err
 		}
+	case cfg.KVConfig != nil:
+		s, err = newKVStage(logger, *cfg.KVConfig)
+		if err != nil {
+			return nil, err
+		}
+	case cfg.CSVConfig != nil:
+		s, err = newCSVStage(logger, *cfg.CSVConfig)
+		if err != nil {
+			return nil, err
+		}
+	case cfg.XMLConfig != nil:
+		s, err = newXMLStage(logger, *cfg.XMLConfig)
+		if err != nil {
+			return nil, err
+		}
 	case cfg.LogfmtConfig != nil:
 		s, err = newLogfmtStage(logger, *cfg.LogfmtConfig)
 		if err != nil {
@@ -163,6 +186,11 @@ func New(logger log.Logger, jobName *string, cfg StageConfig, registerer prometh
 		if err != nil {
 			return nil, err
 		}
+	case cfg.PatternConfig != nil:
+		s, err = newPatternStage(logger, *cfg.PatternConfig, registerer)
+		if err != nil {
+			return nil, err
+		}
 	case cfg.TimestampConfig != nil:
 		s, err = newTimestampStage(logger, *cfg.TimestampConfig)
 		if err != nil {
@@ -174,7 +202,7 @@ func New(logger log.Logger, jobName *string, cfg StageConfig, registerer prometh
 			return nil, err
 		}
 	case cfg.MatchConfig != nil:
-		s, err = newMatcherStage(logger, jobName, *cfg.MatchConfig, registerer)
+		s, err = newMatcherStage(logger, jobName, *cfg.MatchConfig, registerer, state)
 		if err != nil {
 			return nil, err
 		}
@@ -194,7 +222,7 @@ func New(logger log.Logger, jobName *string, cfg StageConfig, registerer prometh
 			return nil, err
 		}
 	case cfg.LimitConfig != nil:
-		s, err = newLimitStage(logger, *cfg.LimitConfig, registerer)
+		s, err = newLimitStage(logger, *cfg.LimitConfig, registerer, state)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,168 @@
+package stages
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/go-kit/log"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/prometheus/common/model"
+)
+
+// Config Errors.
+var (
+	ErrXMLExpressionsRequired = errors.New("xml expressions are required")
+	ErrCouldNotCompileXPath   = errors.New("could not compile XPath expression")
+	ErrEmptyXMLStageSource    = errors.New("empty source")
+)
+
+// xmlAttributeStep matches the last step of an expression which selects an
+// attribute of the elements selected by the previous steps.
+var xmlAttributeStep = regexp.MustCompile(`/@([\w.:-]+)$`)
+
+// XMLConfig configures a processing stage which uses XPath-style expressions
+// to extract values from XML log lines into the shared values map.
+type XMLConfig struct {
+	Expressions map[string]string `alloy:"expressions,attr"`
+	Source      *string           `alloy:"source,attr,optional"`
+}
+
+// xmlExpression selects the text of an element, or the value of one of its
+// attributes when attr isn't empty.
+type xmlExpression struct {
+	path etree.Path
+	attr string
+}
+
+// validateXMLConfig validates the config and returns the compiled
+// expressions by the name of the extracted values they set.
+func validateXMLConfig(c XMLConfig) (map[string]xmlExpression, error) {
+	if len(c.Expressions) == 0 {
+		return nil, ErrXMLExpressionsRequired
+	}
+
+	if c.Source != nil && *c.Source == "" {
+		return nil, ErrEmptyXMLStageSource
+	}
+
+	expressions := make(map[string]xmlExpression, len(c.Expressions))
+	for n, e := range c.Expressions {
+		// If there is no expression, select the first element named after
+		// the extracted value.
+		if e == "" {
+			e = "//" + n
+		}
+		expr, err := compileXMLExpression(e)
+		if err != nil {
+			return nil, fmt.Errorf("%v %q: %w", ErrCouldNotCompileXPath, e, err)
+		}
+		expressions[n] = expr
+	}
+	return expressions, nil
+}
+
+func compileXMLExpression(e string) (xmlExpression, error) {
+	var expr xmlExpression
+	if m := xmlAttributeStep.FindStringSubmatchIndex(e); m != nil {
+		expr.attr = e[m[2]:m[3]]
+		e = e[:m[0]]
+		if e == "" || strings.HasSuffix(e, "/") {
+			return xmlExpression{}, fmt.Errorf("attribute %q must be selected from elements", expr.attr)
+		}
+	}
+
+	path, err := etree.CompilePath(e)
+	if err != nil {
+		return xmlExpression{}, err
+	}
+	expr.path = path
+	return expr, nil
+}
+
+// find returns the text of the first element selected by e, or the value of
+// the attribute of the first selected element which has it.
+func (e xmlExpression) find(doc *etree.Document) (string, bool) {
+	if e.attr == "" {
+		elem := doc.FindElementPath(e.path)
+		if elem == nil {
+			return "", false
+		}
+		return elem.Text(), true
+	}
+	for _, elem := range doc.FindElementsPath(e.path) {
+		if attr := elem.SelectAttr(e.attr); attr != nil {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// xmlStage sets extracted data using XPath-style expressions.
+type xmlStage struct {
+	config      *XMLConfig
+	expressions map[string]xmlExpression
+	logger      log.Logger
+}
+
+// newXMLStage creates a new xml pipeline stage from a config.
+func newXMLStage(logger log.Logger, config XMLConfig) (Stage, error) {
+	expressions, err := validateXMLConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return toStage(&xmlStage{
+		config:      &config,
+		expressions: expressions,
+		logger:      log.With(logger, "component", "stage", "type", "xml"),
+	}), nil
+}
+
+// Process implements Stage
+func (x *xmlStage) Process(labels model.LabelSet, extracted map[string]interface{}, t *time.Time, entry *string) {
+	// If a source key is provided, the xml stage should process it
+	// from the extracted map, otherwise should fall back to the entry
+	input := entry
+
+	if x.config.Source != nil {
+		if _, ok := extracted[*x.config.Source]; !ok {
+			level.Debug(x.logger).Log("msg", "source does not exist in the set of extracted values", "source", *x.config.Source)
+			return
+		}
+
+		value, err := getString(extracted[*x.config.Source])
+		if err != nil {
+			level.Debug(x.logger).Log("msg", "failed to convert source value to string", "source", *x.config.Source, "err", err, "type", reflect.TypeOf(extracted[*x.config.Source]))
+			return
+		}
+
+		input = &value
+	}
+
+	if input == nil {
+		level.Debug(x.logger).Log("msg", "cannot parse a nil entry")
+		return
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(*input); err != nil || doc.Root() == nil {
+		level.Debug(x.logger).Log("msg", "failed to parse XML", "err", err)
+		return
+	}
+
+	for n, e := range x.expressions {
+		if value, ok := e.find(doc); ok {
+			extracted[n] = value
+		}
+	}
+	level.Debug(x.logger).Log("msg", "extracted data debug in xml stage", "extracted data", fmt.Sprintf("%v", extracted))
+}
+
+// Name implements Stage
+func (x *xmlStage) Name() string {
+	return StageTypeXML
+}
@@ -0,0 +1,179 @@
+package stages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/internal/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testXMLAlloyMultiStageWithSource = `
+stage.xml {
+    expressions = {
+        provider = "/Event/System/Provider/@Name",
+        event_id = "/Event/System/EventID",
+    }
+}
+stage.xml {
+    expressions = {
+        service = "/EventData/Data[@Name='param1']",
+        state   = "/EventData/Data[@Name='param2']",
+    }
+    source = "data_xml"
+}
+`
+
+var testXMLLogLine = `<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'>
+  <System>
+    <Provider Name='Service Control Manager' Guid='{555908d1-a6d7-4695-8e1e-26931d2012f4}'/>
+    <EventID Qualifiers='16384'>7036</EventID>
+    <Level>4</Level>
+  </System>
+  <EventData>
+    <Data Name='param1'>Windows Update</Data>
+    <Data Name='param2'>running</Data>
+  </EventData>
+</Event>`
+
+func TestPipeline_XML(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testXMLAlloyMultiStageWithSource), nil, prometheus.DefaultRegisterer)
+	require.NoError(t, err)
+
+	data := `<EventData><Data Name='param1'>Windows Update</Data><Data Name='param2'>stopped</Data></EventData>`
+	out := processEntries(pl, newEntry(map[string]interface{}{"data_xml": data}, nil, testXMLLogLine, time.Now()))[0]
+	assert.Equal(t, map[string]interface{}{
+		"provider": "Service Control Manager",
+		"event_id": "7036",
+		"data_xml": data,
+		"service":  "Windows Update",
+		"state":    "stopped",
+	}, out.Extracted)
+}
+
+func TestXMLConfig_validate(t *testing.T) {
+	t.Parallel()
+	empty := ""
+	tests := map[string]struct {
+		config XMLConfig
+		err    error
+	}{
+		"missing expressions": {
+			XMLConfig{},
+			ErrXMLExpressionsRequired,
+		},
+		"empty source": {
+			XMLConfig{Expressions: map[string]string{"level": ""}, Source: &empty},
+			ErrEmptyXMLStageSource,
+		},
+		"invalid expression": {
+			XMLConfig{Expressions: map[string]string{"level": "//Level["}},
+			ErrCouldNotCompileXPath,
+		},
+		"attribute without elements": {
+			XMLConfig{Expressions: map[string]string{"name": "//@Name"}},
+			ErrCouldNotCompileXPath,
+		},
+		"valid": {
+			XMLConfig{Expressions: map[string]string{
+				"level":    "",
+				"provider": "/Event/System/Provider/@Name",
+				"service":  "//Data[@Name='param1']",
+			}},
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			_, err := validateXMLConfig(tt.config)
+			if tt.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.err.Error())
+		})
+	}
+}
+
+func TestXMLParser_Parse(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		config          XMLConfig
+		extracted       map[string]interface{}
+		entry           string
+		expectedExtract map[string]interface{}
+	}{
+		"successfully extract elements and attributes": {
+			XMLConfig{
+				Expressions: map[string]string{
+					"Level":      "",
+					"event_id":   "./Event/System/EventID",
+					"qualifiers": "//EventID/@Qualifiers",
+					"guid":       "//System/*/@Guid",
+					"state":      "//EventData/Data[2]",
+				},
+			},
+			map[string]interface{}{},
+			testXMLLogLine,
+			map[string]interface{}{
+				"Level":      "4",
+				"event_id":   "7036",
+				"qualifiers": "16384",
+				"guid":       "{555908d1-a6d7-4695-8e1e-26931d2012f4}",
+				"state":      "running",
+			},
+		},
+		"missing elements and attributes": {
+			XMLConfig{
+				Expressions: map[string]string{
+					"task":     "",
+					"provider": "//EventID/@Name",
+				},
+			},
+			map[string]interface{}{},
+			testXMLLogLine,
+			map[string]interface{}{},
+		},
+		"malformed XML": {
+			XMLConfig{
+				Expressions: map[string]string{"level": ""},
+			},
+			map[string]interface{}{},
+			"<Event><Level>4</Level>",
+			map[string]interface{}{},
+		},
+		"not XML": {
+			XMLConfig{
+				Expressions: map[string]string{"level": ""},
+			},
+			map[string]interface{}{},
+			"level=4",
+			map[string]interface{}{},
+		},
+		"missing extracted[source]": {
+			XMLConfig{
+				Expressions: map[string]string{"level": ""},
+				Source:      &protocolStr,
+			},
+			map[string]interface{}{},
+			testXMLLogLine,
+			map[string]interface{}{},
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			logger := util.TestAlloyLogger(t)
+			p, err := New(logger, nil, StageConfig{XMLConfig: &tt.config}, nil)
+			require.NoError(t, err)
+
+			out := processEntries(p, newEntry(tt.extracted, nil, tt.entry, time.Now()))[0]
+			assert.Equal(t, tt.expectedExtract, out.Extracted)
+		})
+	}
+}
@@ -126,22 +126,19 @@ func (c *Component) Run(ctx context.Context) error {
 			lbls := c.relabel(entry)
 
 			if c.debugDataPublisher.IsActive(componentID) {
-				c.debugDataPublisher.Publish(componentID, fmt.Sprintf("entry: %s, labels: %s => %s", entry.Line, entry.Labels.String(), lbls.String()))
+				c.debugDataPublisher.Publish(componentID, loki.NewDebugEventWithBody(entry, fmt.Sprintf("entry: %s, labels: %s => %s", entry.Line, entry.Labels.String(), lbls.String())))
 			}
 
 			if len(lbls) == 0 {
 				level.Debug(c.opts.Logger).Log("msg", "dropping entry after relabeling", "labels", entry.Labels.String())
+				entry.Delivery.Done(nil)
 				continue
 			}
 
 			c.metrics.entriesOutgoing.Inc()
 			entry.Labels = lbls
-			for _, f := range c.fanout {
-				select {
-				case <-ctx.Done():
-					return nil
-				case f.Chan() <- entry:
-				}
+			if !loki.SendToAll(ctx.Done(), entry, c.fanout) {
+				return nil
 			}
 		}
 	}
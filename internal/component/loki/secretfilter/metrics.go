@@ -0,0 +1,40 @@
+package secretfilter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	entriesProcessed prometheus.Counter
+	secretsRedacted  *prometheus.CounterVec
+	secretsAllowed   *prometheus.CounterVec
+}
+
+// newMetrics creates a new set of metrics. If reg is non-nil, the metrics
+// will also be registered.
+func newMetrics(reg prometheus.Registerer) *metrics {
+	var m metrics
+
+	m.entriesProcessed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "loki_secretfilter_entries_processed_total",
+		Help: "Total number of log entries processed",
+	})
+	m.secretsRedacted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "loki_secretfilter_secrets_redacted_total",
+		Help: "Total number of secrets redacted, by rule",
+	}, []string{"rule"})
+	m.secretsAllowed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "loki_secretfilter_secrets_allowlisted_total",
+		Help: "Total number of secrets which weren't redacted because they matched an allowlist, by rule",
+	}, []string{"rule"})
+
+	if reg != nil {
+		reg.MustRegister(
+			m.entriesProcessed,
+			m.secretsRedacted,
+			m.secretsAllowed,
+		)
+	}
+
+	return &m
+}
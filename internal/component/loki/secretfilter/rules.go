@@ -0,0 +1,333 @@
+package secretfilter
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// RuleConfig configures a custom rule which detects secrets with a regular
+// expression.
+type RuleConfig struct {
+	Name        string   `alloy:"name,attr"`
+	Regex       string   `alloy:"regex,attr"`
+	SecretGroup int      `alloy:"secret_group,attr,optional"`
+	Keywords    []string `alloy:"keywords,attr,optional"`
+	Entropy     float64  `alloy:"entropy,attr,optional"`
+	Allowlist   []string `alloy:"allowlist,attr,optional"`
+}
+
+// Validate implements syntax.Validator.
+func (rc *RuleConfig) Validate() error {
+	_, err := rc.compile()
+	return err
+}
+
+func (rc RuleConfig) compile() (*rule, error) {
+	if rc.Name == "" {
+		return nil, fmt.Errorf("rule name must not be empty")
+	}
+	re, err := regexp.Compile(rc.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex for rule %q: %w", rc.Name, err)
+	}
+	if rc.SecretGroup < 0 || rc.SecretGroup > re.NumSubexp() {
+		return nil, fmt.Errorf("invalid secret_group %d for rule %q: the regex has %d capture groups", rc.SecretGroup, rc.Name, re.NumSubexp())
+	}
+	allowlist, err := compileAllowlist(rc.Allowlist)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowlist for rule %q: %w", rc.Name, err)
+	}
+
+	keywords := make([]string, 0, len(rc.Keywords))
+	for _, k := range rc.Keywords {
+		keywords = append(keywords, strings.ToLower(k))
+	}
+	return &rule{
+		name:        rc.Name,
+		regex:       re,
+		secretGroup: rc.SecretGroup,
+		keywords:    keywords,
+		entropy:     rc.Entropy,
+		allowlist:   allowlist,
+	}, nil
+}
+
+func compileAllowlist(patterns []string) ([]*regexp.Regexp, error) {
+	allowlist := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		allowlist = append(allowlist, re)
+	}
+	return allowlist, nil
+}
+
+// rule detects secrets in log lines.
+type rule struct {
+	name  string
+	regex *regexp.Regexp
+	// secretGroup is the capture group of the regex which holds the secret.
+	// When it's 0, the secret is the first capture group which matched, or
+	// the whole match if the regex has no capture group.
+	secretGroup int
+	// keywords are lowercase strings of which lines must contain at least one
+	// for the regex to be evaluated. Rules without keywords are evaluated for
+	// every line.
+	keywords []string
+	// entropy is the minimum Shannon entropy of secrets. Matches with a lower
+	// entropy, such as placeholders, aren't secrets.
+	entropy   float64
+	allowlist []*regexp.Regexp
+}
+
+// secret is a secret found in a line, at line[start:end], by the rule at
+// index rule of the rules of a filter.
+type secret struct {
+	rule       int
+	start, end int
+}
+
+// find returns the secrets found by r in line, and the number of matches
+// which weren't secrets because they matched an allowlist. lowerLine is line
+// in lowercase.
+func (r *rule) find(line, lowerLine string, allowlist []*regexp.Regexp) (secrets []secret, allowed int) {
+	if !r.hasKeyword(lowerLine) {
+		return nil, 0
+	}
+
+	for _, m := range r.regex.FindAllStringSubmatchIndex(line, -1) {
+		start, end := r.secretIndex(m)
+		if start < 0 || start == end {
+			continue
+		}
+		value := line[start:end]
+		if r.entropy > 0 && shannonEntropy(value) < r.entropy {
+			continue
+		}
+		if isAllowed(value, r.allowlist) || isAllowed(value, allowlist) {
+			allowed++
+			continue
+		}
+		secrets = append(secrets, secret{start: start, end: end})
+	}
+	return secrets, allowed
+}
+
+func (r *rule) hasKeyword(lowerLine string) bool {
+	if len(r.keywords) == 0 {
+		return true
+	}
+	for _, k := range r.keywords {
+		if strings.Contains(lowerLine, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretIndex returns the indexes of the secret in a match of the regex.
+func (r *rule) secretIndex(m []int) (int, int) {
+	if r.secretGroup > 0 {
+		return m[2*r.secretGroup], m[2*r.secretGroup+1]
+	}
+	for g := 1; g < len(m)/2; g++ {
+		if m[2*g] >= 0 {
+			return m[2*g], m[2*g+1]
+		}
+	}
+	return m[0], m[1]
+}
+
+func isAllowed(value string, allowlist []*regexp.Regexp) bool {
+	for _, re := range allowlist {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy returns the Shannon entropy of s, in bits per byte.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	var (
+		entropy float64
+		length  = float64(len(s))
+	)
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// builtinRules are the rules used unless use_builtin_rules is false. They
+// are adapted from the rules of gitleaks.
+var builtinRules = []RuleConfig{
+	{
+		Name:     "aws-access-token",
+		Regex:    `\b((?:A3T[A-Z0-9]|AKIA|ASIA|ABIA|ACCA)[A-Z2-7]{16})\b`,
+		Keywords: []string{"a3t", "akia", "asia", "abia", "acca"},
+		Entropy:  3,
+	},
+	{
+		Name:     "gcp-api-key",
+		Regex:    `\b(AIza[\w-]{35})(?:[^\w-]|$)`,
+		Keywords: []string{"aiza"},
+		Entropy:  3,
+	},
+	{
+		Name:     "github-pat",
+		Regex:    `\b(ghp_[0-9a-zA-Z]{36})\b`,
+		Keywords: []string{"ghp_"},
+		Entropy:  3,
+	},
+	{
+		Name:     "github-fine-grained-pat",
+		Regex:    `\b(github_pat_\w{82})\b`,
+		Keywords: []string{"github_pat_"},
+		Entropy:  3,
+	},
+	{
+		Name:     "github-oauth",
+		Regex:    `\b(gho_[0-9a-zA-Z]{36})\b`,
+		Keywords: []string{"gho_"},
+		Entropy:  3,
+	},
+	{
+		Name:     "github-app-token",
+		Regex:    `\b((?:ghu|ghs)_[0-9a-zA-Z]{36})\b`,
+		Keywords: []string{"ghu_", "ghs_"},
+		Entropy:  3,
+	},
+	{
+		Name:     "github-refresh-token",
+		Regex:    `\b(ghr_[0-9a-zA-Z]{36})\b`,
+		Keywords: []string{"ghr_"},
+		Entropy:  3,
+	},
+	{
+		Name:     "gitlab-pat",
+		Regex:    `\b(glpat-[\w-]{20})(?:[^\w-]|$)`,
+		Keywords: []string{"glpat-"},
+		Entropy:  3,
+	},
+	{
+		Name:     "grafana-cloud-api-token",
+		Regex:    `\b(glc_[A-Za-z0-9+/]{32,400}={0,2})`,
+		Keywords: []string{"glc_"},
+		Entropy:  3,
+	},
+	{
+		Name:     "grafana-service-account-token",
+		Regex:    `\b(glsa_[A-Za-z0-9]{32}_[A-Fa-f0-9]{8})\b`,
+		Keywords: []string{"glsa_"},
+		Entropy:  3,
+	},
+	{
+		Name:     "slack-bot-token",
+		Regex:    `\b(xoxb-[0-9]{10,13}-[0-9]{10,13}[a-zA-Z0-9-]*)`,
+		Keywords: []string{"xoxb"},
+		Entropy:  3,
+	},
+	{
+		Name:     "slack-user-token",
+		Regex:    `\b(xox[pe](?:-[0-9]{10,13}){3}-[a-zA-Z0-9-]{28,34})`,
+		Keywords: []string{"xoxp-", "xoxe-"},
+		Entropy:  2,
+	},
+	{
+		Name:     "slack-webhook-url",
+		Regex:    `(?:https?://)?hooks\.slack\.com/(?:services|workflows)/[A-Za-z0-9+/]{43,46}`,
+		Keywords: []string{"hooks.slack.com"},
+	},
+	{
+		Name:     "stripe-access-token",
+		Regex:    `\b((?:sk|rk)_(?:test|live|prod)_[a-zA-Z0-9]{10,99})\b`,
+		Keywords: []string{"sk_test", "sk_live", "sk_prod", "rk_test", "rk_live", "rk_prod"},
+		Entropy:  2,
+	},
+	{
+		Name:     "npm-access-token",
+		Regex:    `(?i)\b(npm_[a-z0-9]{36})\b`,
+		Keywords: []string{"npm_"},
+		Entropy:  2,
+	},
+	{
+		Name:     "pypi-upload-token",
+		Regex:    `pypi-AgEIcHlwaS5vcmc[\w-]{50,1000}`,
+		Keywords: []string{"pypi-ageichlwas5vcmc"},
+	},
+	{
+		Name:     "sendgrid-api-token",
+		Regex:    `\b(SG\.[a-zA-Z0-9=_\-.]{66})\b`,
+		Keywords: []string{"sg."},
+		Entropy:  2,
+	},
+	{
+		Name:     "twilio-api-key",
+		Regex:    `\bSK[0-9a-fA-F]{32}\b`,
+		Keywords: []string{"sk"},
+		Entropy:  3,
+	},
+	{
+		Name:     "openai-api-key",
+		Regex:    `\b(sk-(?:proj-|svcacct-|admin-)?[A-Za-z0-9_-]{20,74}T3BlbkFJ[A-Za-z0-9_-]{20,74})\b`,
+		Keywords: []string{"t3blbkfj"},
+		Entropy:  3,
+	},
+	{
+		Name:     "digitalocean-pat",
+		Regex:    `\b(dop_v1_[a-f0-9]{64})\b`,
+		Keywords: []string{"dop_v1_"},
+		Entropy:  3,
+	},
+	{
+		Name:     "shopify-access-token",
+		Regex:    `\b(shpat_[a-fA-F0-9]{32})\b`,
+		Keywords: []string{"shpat_"},
+		Entropy:  2,
+	},
+	{
+		Name:     "hashicorp-tf-api-token",
+		Regex:    `(?i)\b([a-z0-9]{14}\.atlasv1\.[a-z0-9\-_=]{60,70})\b`,
+		Keywords: []string{"atlasv1"},
+		Entropy:  3.5,
+	},
+	{
+		Name:     "jwt",
+		Regex:    `\b(ey[a-zA-Z0-9]{17,}\.ey[a-zA-Z0-9/\\_-]{17,}\.(?:[a-zA-Z0-9/\\_-]{10,}={0,2})?)(?:[^.a-zA-Z0-9/\\_-]|$)`,
+		Keywords: []string{"ey"},
+		Entropy:  3,
+	},
+	{
+		Name:     "private-key",
+		Regex:    `(?i)-----BEGIN[ A-Z0-9_-]{0,100}PRIVATE KEY(?: BLOCK)?-----[\s\S-]{64,}?KEY(?: BLOCK)?-----`,
+		Keywords: []string{"-----begin"},
+	},
+	{
+		Name:     "url-password",
+		Regex:    `\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:@/]+:([^\s:@/]{3,})@`,
+		Keywords: []string{"://"},
+		Entropy:  1.5,
+	},
+	{
+		Name:     "generic-api-key",
+		Regex:    `(?i)\b(?:access|auth|api|credential|creds|key|passw(?:or)?d|pwd|secret|token)[\w.-]{0,25}["']?\s{0,5}(?:=|:|:=|=>|\|\|:|<=)\s{0,5}["']?([\w.=~@/+-]{10,150})(?:["'\s,;]|$)`,
+		Keywords: []string{"access", "auth", "api", "credential", "creds", "key", "passw", "pwd", "secret", "token"},
+		Entropy:  3.5,
+	},
+}
@@ -0,0 +1,253 @@
+package secretfilter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "loki.secretfilter",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   Exports{},
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+const (
+	// secretNamePlaceholder is replaced in redact_with by the name of the rule
+	// which found a secret.
+	secretNamePlaceholder = "$SECRET_NAME"
+	// secretHashPlaceholder is replaced in redact_with by the first
+	// secretHashLength hexadecimal characters of the SHA-256 hash of a secret.
+	secretHashPlaceholder = "$SECRET_HASH"
+	secretHashLength      = 12
+)
+
+// Arguments holds values which are used to configure the loki.secretfilter
+// component.
+type Arguments struct {
+	// Where the redacted log entries should be forwarded to.
+	ForwardTo []loki.LogsReceiver `alloy:"forward_to,attr"`
+
+	// Custom rules. They replace the built-in rules with the same name.
+	Rules []RuleConfig `alloy:"rule,block,optional"`
+
+	UseBuiltinRules bool     `alloy:"use_builtin_rules,attr,optional"`
+	DisabledRules   []string `alloy:"disabled_rules,attr,optional"`
+	Allowlist       []string `alloy:"allowlist,attr,optional"`
+	RedactWith      string   `alloy:"redact_with,attr,optional"`
+}
+
+// DefaultArguments provides the default arguments for the loki.secretfilter
+// component.
+var DefaultArguments = Arguments{
+	UseBuiltinRules: true,
+	RedactWith:      "<REDACTED-SECRET:" + secretNamePlaceholder + ">",
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (a *Arguments) SetToDefault() {
+	*a = DefaultArguments
+}
+
+// Validate implements syntax.Validator.
+func (a *Arguments) Validate() error {
+	_, err := a.newFilter()
+	return err
+}
+
+// newFilter returns the filter which redacts secrets with the rules of a.
+func (a Arguments) newFilter() (*filter, error) {
+	var configs []RuleConfig
+	names := make(map[string]bool, len(a.Rules))
+	for _, rc := range a.Rules {
+		if names[rc.Name] {
+			return nil, fmt.Errorf("duplicate rule name %q", rc.Name)
+		}
+		names[rc.Name] = true
+		configs = append(configs, rc)
+	}
+	if a.UseBuiltinRules {
+		for _, rc := range builtinRules {
+			if !names[rc.Name] && !slices.Contains(a.DisabledRules, rc.Name) {
+				configs = append(configs, rc)
+			}
+		}
+	}
+
+	rules := make([]*rule, 0, len(configs))
+	for _, rc := range configs {
+		r, err := rc.compile()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	allowlist, err := compileAllowlist(a.Allowlist)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowlist: %w", err)
+	}
+	return &filter{rules: rules, allowlist: allowlist, redactWith: a.RedactWith}, nil
+}
+
+// Exports holds values which are exported by the loki.secretfilter component.
+type Exports struct {
+	Receiver loki.LogsReceiver `alloy:"receiver,attr"`
+}
+
+// Component implements the loki.secretfilter component.
+type Component struct {
+	opts    component.Options
+	metrics *metrics
+
+	mut      sync.RWMutex
+	filter   *filter
+	receiver loki.LogsReceiver
+	fanout   []loki.LogsReceiver
+
+	debugDataPublisher livedebugging.DebugDataPublisher
+}
+
+var (
+	_ component.Component     = (*Component)(nil)
+	_ component.LiveDebugging = (*Component)(nil)
+)
+
+// New creates a new loki.secretfilter component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts:               o,
+		metrics:            newMetrics(o.Registerer),
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+	}
+
+	// Create and immediately export the receiver which remains the same for
+	// the component's lifetime.
+	c.receiver = loki.NewLogsReceiver()
+	o.OnStateChange(Exports{Receiver: c.receiver})
+
+	// Call to Update() to set the rules once at the start.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case entry := <-c.receiver.Chan():
+			c.mut.RLock()
+			filter, fanout := c.filter, c.fanout
+			c.mut.RUnlock()
+
+			c.metrics.entriesProcessed.Inc()
+			entry.Line = filter.redact(entry.Line, c.metrics)
+			loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, entry)
+
+			if !loki.SendToAll(ctx.Done(), entry, fanout) {
+				return nil
+			}
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+	filter, err := newArgs.newFilter()
+	if err != nil {
+		return err
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.filter = filter
+	c.fanout = newArgs.ForwardTo
+
+	return nil
+}
+
+func (c *Component) LiveDebugging(_ int) {}
+
+// filter redacts the secrets found by rules in log lines.
+type filter struct {
+	rules      []*rule
+	allowlist  []*regexp.Regexp
+	redactWith string
+}
+
+// redact returns line with the secrets found by the rules of f redacted.
+// Secrets which overlap are redacted together, as a secret of the rule which
+// comes first.
+func (f *filter) redact(line string, m *metrics) string {
+	var (
+		secrets   []secret
+		lowerLine = strings.ToLower(line)
+	)
+	for i, r := range f.rules {
+		found, allowed := r.find(line, lowerLine, f.allowlist)
+		if allowed > 0 {
+			m.secretsAllowed.WithLabelValues(r.name).Add(float64(allowed))
+		}
+		for _, s := range found {
+			s.rule = i
+			secrets = append(secrets, s)
+		}
+	}
+	if len(secrets) == 0 {
+		return line
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].start < secrets[j].start })
+
+	var (
+		sb   strings.Builder
+		last int
+	)
+	for i := 0; i < len(secrets); {
+		merged := secrets[i]
+		for i++; i < len(secrets) && secrets[i].start < merged.end; i++ {
+			merged.end = max(merged.end, secrets[i].end)
+			merged.rule = min(merged.rule, secrets[i].rule)
+		}
+
+		name := f.rules[merged.rule].name
+		m.secretsRedacted.WithLabelValues(name).Inc()
+		sb.WriteString(line[last:merged.start])
+		sb.WriteString(f.replacement(name, line[merged.start:merged.end]))
+		last = merged.end
+	}
+	sb.WriteString(line[last:])
+	return sb.String()
+}
+
+// replacement returns the text which replaces a secret found by the rule
+// named ruleName.
+func (f *filter) replacement(ruleName, value string) string {
+	replacement := strings.ReplaceAll(f.redactWith, secretNamePlaceholder, ruleName)
+	if strings.Contains(replacement, secretHashPlaceholder) {
+		sum := sha256.Sum256([]byte(value))
+		replacement = strings.ReplaceAll(replacement, secretHashPlaceholder, hex.EncodeToString(sum[:])[:secretHashLength])
+	}
+	return replacement
+}
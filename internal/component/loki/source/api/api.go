@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/alloy/internal/component/common/relabel"
 	"github.com/grafana/alloy/internal/component/loki/source/api/internal/lokipush"
 	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/grafana/alloy/internal/util"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
@@ -51,8 +52,11 @@ func (a *Arguments) labelSet() model.LabelSet {
 	return labelSet
 }
 
+var _ component.LiveDebugging = (*Component)(nil)
+
 type Component struct {
 	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
 	entriesChan        chan loki.Entry
 	uncheckedCollector *util.UncheckedCollector
 
@@ -67,6 +71,7 @@ type Component struct {
 
 func New(opts component.Options, args Arguments) (*Component, error) {
 	c := &Component{
+		debugDataPublisher: livedebugging.GetPublisher(opts.GetServiceData),
 		opts:               opts,
 		entriesChan:        make(chan loki.Entry),
 		receivers:          args.ForwardTo,
@@ -90,6 +95,7 @@ func (c *Component) Run(ctx context.Context) (err error) {
 			receivers := c.receivers
 			c.receiversMut.RUnlock()
 
+			loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, entry)
 			for _, receiver := range receivers {
 				select {
 				case receiver.Chan() <- entry:
@@ -167,3 +173,5 @@ func (c *Component) stop() {
 		c.server = nil
 	}
 }
+
+func (c *Component) LiveDebugging(_ int) {}
@@ -18,6 +18,7 @@ import (
 	fnet "github.com/grafana/alloy/internal/component/common/net"
 	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 	"github.com/grafana/alloy/internal/component/loki/source/aws_firehose/internal"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/grafana/alloy/internal/util"
 )
 
@@ -60,8 +61,9 @@ type Component struct {
 
 	server *fnet.TargetServer
 
-	opts component.Options
-	args Arguments
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
+	args               Arguments
 
 	// utils
 	serverMetrics  *util.UncheckedCollector
@@ -69,14 +71,17 @@ type Component struct {
 	logger         log.Logger
 }
 
+var _ component.LiveDebugging = (*Component)(nil)
+
 // New creates a new Component.
 func New(o component.Options, args Arguments) (*Component, error) {
 	c := &Component{
-		opts:           o,
-		destination:    loki.NewLogsReceiver(),
-		fanout:         args.ForwardTo,
-		serverMetrics:  util.NewUncheckedCollector(nil),
-		handlerMetrics: internal.NewMetrics(o.Registerer),
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		opts:               o,
+		destination:        loki.NewLogsReceiver(),
+		fanout:             args.ForwardTo,
+		serverMetrics:      util.NewUncheckedCollector(nil),
+		handlerMetrics:     internal.NewMetrics(o.Registerer),
 
 		logger: log.With(o.Logger, "component", "aws_firehose_logs"),
 	}
@@ -104,6 +109,7 @@ func (c *Component) Run(ctx context.Context) error {
 			return nil
 		case entry := <-c.destination.Chan():
 			c.mut.RLock()
+			loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, entry)
 			for _, receiver := range c.fanout {
 				receiver.Chan() <- entry
 			}
@@ -190,3 +196,5 @@ func (c *Component) shutdownServer() {
 		c.server = nil
 	}
 }
+
+func (c *Component) LiveDebugging(_ int) {}
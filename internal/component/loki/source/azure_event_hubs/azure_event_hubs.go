@@ -14,6 +14,7 @@ import (
 	kt "github.com/grafana/alloy/internal/component/loki/source/internal/kafkatarget"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/grafana/dskit/flagext"
 
 	"github.com/prometheus/common/model"
@@ -73,13 +74,16 @@ func (a *Arguments) Validate() error {
 	return a.validateAssignor()
 }
 
+var _ component.LiveDebugging = (*Component)(nil)
+
 // New creates a new loki.source.azure_event_hubs component.
 func New(o component.Options, args Arguments) (*Component, error) {
 	c := &Component{
-		mut:     sync.RWMutex{},
-		opts:    o,
-		handler: loki.NewLogsReceiver(),
-		fanout:  args.ForwardTo,
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		mut:                sync.RWMutex{},
+		opts:               o,
+		handler:            loki.NewLogsReceiver(),
+		fanout:             args.ForwardTo,
 	}
 
 	// Call to Update() to start readers and set receivers once at the start.
@@ -92,11 +96,12 @@ func New(o component.Options, args Arguments) (*Component, error) {
 
 // Component implements the loki.source.azure_event_hubs component.
 type Component struct {
-	opts    component.Options
-	mut     sync.RWMutex
-	fanout  []loki.LogsReceiver
-	handler loki.LogsReceiver
-	target  *kt.TargetSyncer
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
+	mut                sync.RWMutex
+	fanout             []loki.LogsReceiver
+	handler            loki.LogsReceiver
+	target             *kt.TargetSyncer
 }
 
 // Run implements component.Component.
@@ -117,6 +122,7 @@ func (c *Component) Run(ctx context.Context) error {
 			return nil
 		case entry := <-c.handler.Chan():
 			c.mut.RLock()
+			loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, entry)
 			for _, receiver := range c.fanout {
 				receiver.Chan() <- entry
 			}
@@ -223,3 +229,5 @@ func (a *Arguments) validateAssignor() error {
 	}
 	return fmt.Errorf("assignor value %s is invalid, must be one of: %v", a.Assignor, validAssignors)
 }
+
+func (c *Component) LiveDebugging(_ int) {}
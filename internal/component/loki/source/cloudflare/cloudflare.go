@@ -19,6 +19,7 @@ import (
 	cft "github.com/grafana/alloy/internal/component/loki/source/cloudflare/internal/cloudflaretarget"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/grafana/alloy/syntax/alloytypes"
 	"github.com/prometheus/common/model"
 )
@@ -91,8 +92,9 @@ func (c *Arguments) Validate() error {
 
 // Component implements the loki.source.cloudflare component.
 type Component struct {
-	opts    component.Options
-	metrics *cft.Metrics
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
+	metrics            *cft.Metrics
 
 	mut    sync.RWMutex
 	fanout []loki.LogsReceiver
@@ -102,6 +104,8 @@ type Component struct {
 	handler loki.LogsReceiver
 }
 
+var _ component.LiveDebugging = (*Component)(nil)
+
 // New creates a new loki.source.cloudflare component.
 func New(o component.Options, args Arguments) (*Component, error) {
 	err := os.MkdirAll(o.DataPath, 0750)
@@ -119,11 +123,12 @@ func New(o component.Options, args Arguments) (*Component, error) {
 	}
 
 	c := &Component{
-		opts:    o,
-		metrics: cft.NewMetrics(o.Registerer),
-		handler: loki.NewLogsReceiver(),
-		fanout:  args.ForwardTo,
-		posFile: positionsFile,
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		opts:               o,
+		metrics:            cft.NewMetrics(o.Registerer),
+		handler:            loki.NewLogsReceiver(),
+		fanout:             args.ForwardTo,
+		posFile:            positionsFile,
 	}
 
 	// Call to Update() to start readers and set receivers once at the start.
@@ -149,6 +154,7 @@ func (c *Component) Run(ctx context.Context) error {
 			return nil
 		case entry := <-c.handler.Chan():
 			c.mut.RLock()
+			loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, entry)
 			for _, receiver := range c.fanout {
 				receiver.Chan() <- entry
 			}
@@ -199,3 +205,5 @@ type targetDebugInfo struct {
 	Ready   bool              `alloy:"ready,attr"`
 	Details map[string]string `alloy:"target_info,attr"`
 }
+
+func (c *Component) LiveDebugging(_ int) {}
@@ -25,6 +25,7 @@ import (
 	dt "github.com/grafana/alloy/internal/component/loki/source/docker/internal/dockertarget"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/grafana/alloy/internal/useragent"
 	"github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
@@ -95,13 +96,15 @@ func (a *Arguments) Validate() error {
 
 var (
 	_ component.Component      = (*Component)(nil)
+	_ component.LiveDebugging  = (*Component)(nil)
 	_ component.DebugComponent = (*Component)(nil)
 )
 
 // Component implements the loki.source.file component.
 type Component struct {
-	opts    component.Options
-	metrics *dt.Metrics
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
+	metrics            *dt.Metrics
 
 	mut           sync.RWMutex
 	args          Arguments
@@ -133,8 +136,9 @@ func New(o component.Options, args Arguments) (*Component, error) {
 	}
 
 	c := &Component{
-		opts:    o,
-		metrics: dt.NewMetrics(o.Registerer),
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		opts:               o,
+		metrics:            dt.NewMetrics(o.Registerer),
 
 		handler:   loki.NewLogsReceiver(),
 		manager:   newManager(o.Logger, nil),
@@ -173,6 +177,7 @@ func (c *Component) Run(ctx context.Context) error {
 			c.receiversMut.RLock()
 			receivers := c.receivers
 			c.receiversMut.RUnlock()
+			loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, entry)
 			for _, receiver := range receivers {
 				receiver.Chan() <- entry
 			}
@@ -355,3 +360,5 @@ type targetInfo struct {
 	IsRunning  string `alloy:"is_running,attr"`
 	ReadOffset string `alloy:"read_offset,attr"`
 }
+
+func (c *Component) LiveDebugging(_ int) {}
@@ -46,8 +46,8 @@ type decompressor struct {
 	handler   loki.EntryHandler
 	positions positions.Positions
 
-	path   string
-	labels string
+	path string
+	key  positions.Entry
 
 	posAndSizeMtx sync.Mutex
 	stopOnce      sync.Once
@@ -62,6 +62,10 @@ type decompressor struct {
 	position int64
 	size     int64
 	cfg      DecompressionConfig
+
+	// commits commits the number of the lines once they're delivered. It's
+	// nil when the position is saved as soon as lines are read.
+	commits *loki.CommitQueue[int64]
 }
 
 func newDecompressor(
@@ -69,15 +73,16 @@ func newDecompressor(
 	logger log.Logger,
 	handler loki.EntryHandler,
 	positions positions.Positions,
-	path string,
-	labels string,
+	key positions.Entry,
 	encodingFormat string,
 	cfg DecompressionConfig,
+	waitForDelivery bool,
 ) (*decompressor, error) {
 
 	logger = log.With(logger, "component", "decompressor")
 
-	pos, err := positions.Get(path, labels)
+	path := key.Path
+	pos, err := positions.GetEntry(key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get positions: %w", err)
 	}
@@ -98,7 +103,7 @@ func newDecompressor(
 		handler:   loki.AddLabelsMiddleware(model.LabelSet{filenameLabel: model.LabelValue(path)}).Wrap(handler),
 		positions: positions,
 		path:      path,
-		labels:    labels,
+		key:       key,
 		running:   atomic.NewBool(false),
 		posquit:   make(chan struct{}),
 		posdone:   make(chan struct{}),
@@ -107,6 +112,11 @@ func newDecompressor(
 		decoder:   decoder,
 		cfg:       cfg,
 	}
+	if waitForDelivery {
+		decompressor.commits = newPositionCommits(logger, path, func(pos int64) {
+			positions.PutEntry(key, pos)
+		})
+	}
 
 	go decompressor.readLines()
 	go decompressor.updatePosition()
@@ -254,6 +264,7 @@ func (d *decompressor) readLines() {
 				Timestamp: time.Now(),
 				Line:      finalText,
 			},
+			Delivery: d.commits.Track(int64(line)),
 		}
 
 		d.size = int64(unsafe.Sizeof(finalText))
@@ -268,7 +279,9 @@ func (d *decompressor) MarkPositionAndSize() error {
 
 	d.metrics.totalBytes.WithLabelValues(d.path).Set(float64(d.size))
 	d.metrics.readBytes.WithLabelValues(d.path).Set(float64(d.position))
-	d.positions.Put(d.path, d.labels, d.position)
+	if d.commits == nil {
+		d.positions.PutEntry(d.key, d.position)
+	}
 
 	return nil
 }
@@ -318,3 +331,7 @@ func (d *decompressor) cleanupMetrics() {
 func (d *decompressor) Path() string {
 	return d.path
 }
+
+func (d *decompressor) PositionEntry() positions.Entry {
+	return d.key
+}
@@ -9,12 +9,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/go-kit/log"
 	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/component/common/loki/positions"
 	"github.com/grafana/alloy/internal/component/discovery"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/grafana/tail/watch"
 	"github.com/prometheus/common/model"
 )
@@ -44,8 +46,10 @@ type Arguments struct {
 	Encoding            string              `alloy:"encoding,attr,optional"`
 	DecompressionConfig DecompressionConfig `alloy:"decompression,block,optional"`
 	FileWatch           FileWatch           `alloy:"file_watch,block,optional"`
+	FingerprintConfig   FingerprintConfig   `alloy:"fingerprint,block,optional"`
 	TailFromEnd         bool                `alloy:"tail_from_end,attr,optional"`
 	LegacyPositionsFile string              `alloy:"legacy_positions_file,attr,optional"`
+	WaitForDelivery     bool                `alloy:"wait_for_delivery,attr,optional"`
 }
 
 type FileWatch struct {
@@ -58,6 +62,7 @@ var DefaultArguments = Arguments{
 		MinPollFrequency: 250 * time.Millisecond,
 		MaxPollFrequency: 250 * time.Millisecond,
 	},
+	FingerprintConfig: DefaultFingerprintConfig,
 }
 
 // SetToDefault implements syntax.Defaulter.
@@ -71,12 +76,49 @@ type DecompressionConfig struct {
 	Format       CompressionFormat `alloy:"format,attr"`
 }
 
-var _ component.Component = (*Component)(nil)
+// FingerprintConfig configures the identification of files by a fingerprint
+// of their first bytes, device and inode rather than by their path.
+type FingerprintConfig struct {
+	Enabled bool `alloy:"enabled,attr"`
+	Size    int  `alloy:"size,attr,optional"`
+}
+
+var DefaultFingerprintConfig = FingerprintConfig{
+	Size: 1024,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *FingerprintConfig) SetToDefault() {
+	*c = DefaultFingerprintConfig
+}
+
+// Validate implements syntax.Validator.
+func (c *FingerprintConfig) Validate() error {
+	if c.Size <= 0 {
+		return fmt.Errorf("fingerprint size must be greater than 0, got %d", c.Size)
+	}
+	return nil
+}
+
+// fingerprintSize returns the size of the fingerprints of files, or 0 if
+// files are identified by their path.
+func (a Arguments) fingerprintSize() int {
+	if !a.FingerprintConfig.Enabled {
+		return 0
+	}
+	return a.FingerprintConfig.Size
+}
+
+var (
+	_ component.Component     = (*Component)(nil)
+	_ component.LiveDebugging = (*Component)(nil)
+)
 
 // Component implements the loki.source.file component.
 type Component struct {
-	opts    component.Options
-	metrics *metrics
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
+	metrics            *metrics
 
 	updateMut sync.Mutex
 
@@ -110,8 +152,9 @@ func New(o component.Options, args Arguments) (*Component, error) {
 	}
 
 	c := &Component{
-		opts:    o,
-		metrics: newMetrics(o.Registerer),
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		opts:               o,
+		metrics:            newMetrics(o.Registerer),
 
 		handler:   loki.NewLogsReceiver(),
 		receivers: args.ForwardTo,
@@ -143,15 +186,28 @@ func (c *Component) Run(ctx context.Context) error {
 		c.mut.RUnlock()
 	}()
 
+	// Wait for stopped readers to be restarted before the remaining readers
+	// are stopped.
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.syncReaders(ctx)
+	}()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		case entry := <-c.handler.Chan():
 			c.mut.RLock()
+			loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, entry)
+			entry.Delivery.Add(len(c.receivers))
 			for _, receiver := range c.receivers {
 				receiver.Chan() <- entry
 			}
+			entry.Delivery.Done(nil)
 			c.mut.RUnlock()
 		}
 	}
@@ -198,6 +254,10 @@ func (c *Component) Update(args component.Arguments) error {
 		return nil
 	}
 
+	var (
+		targets []fileTarget
+		seen    = make(map[positions.Entry]bool, len(newArgs.Targets))
+	)
 	for _, target := range newArgs.Targets {
 		path := target[pathLabel]
 
@@ -211,38 +271,81 @@ func (c *Component) Update(args component.Arguments) error {
 
 		// Deduplicate targets which have the same public label set.
 		readersKey := positions.Entry{Path: path, Labels: labels.String()}
-		if _, exist := c.readers[readersKey]; exist {
+		if seen[readersKey] {
 			continue
 		}
+		seen[readersKey] = true
+		targets = append(targets, fileTarget{key: readersKey, labels: labels})
+	}
 
-		c.reportSize(path, labels.String())
+	keys, unassigned := c.positionEntries(targets, nil)
+	targetLabels := make(map[string]model.LabelSet, len(targets))
+	for _, t := range targets {
+		targetLabels[t.key.Labels] = t.labels
+		c.reportSize(t.key.Path, t.key.Labels)
 
-		handler := loki.AddLabelsMiddleware(labels).Wrap(loki.NewEntryHandler(c.handler.Chan(), func() {}))
-		reader, err := c.startTailing(path, labels, handler)
-		if err != nil {
+		key, ok := keys[t.key]
+		if !ok {
+			level.Error(c.opts.Logger).Log("msg", "failed to tail file, failed to read its fingerprint", "filename", t.key.Path)
+			c.metrics.totalBytes.DeleteLabelValues(t.key.Path)
 			continue
 		}
+		c.startReader(t, key, c.args.TailFromEnd, true)
+	}
 
-		c.readers[readersKey] = readerWithHandler{
-			reader:  reader,
-			handler: handler,
-		}
+	// Read the rest of the files which were rotated before they were read
+	// until their end.
+	for _, e := range unassigned {
+		c.resumeRotatedFile(e, targetLabels[e.Labels])
 	}
 
 	// Remove from the positions file any entries that had a Reader before, but
 	// are no longer in the updated set of Targets.
 	for r := range missing(c.readers, oldPaths) {
-		c.posFile.Remove(r.Path, r.Labels)
+		c.removePositions(r)
 	}
 
 	return nil
 }
 
+// startReader starts a reader for the file of target, which reads from the
+// position of key. Readers which don't follow their file read the rest of a
+// file which was rotated. It must be called with c.mut held.
+func (c *Component) startReader(t fileTarget, key positions.Entry, tailFromEnd bool, follow bool) bool {
+	handler := loki.AddLabelsMiddleware(t.labels).Wrap(loki.NewEntryHandler(c.handler.Chan(), func() {}))
+	reader, err := c.startTailing(key, handler, tailFromEnd, follow)
+	if err != nil {
+		return false
+	}
+
+	c.readers[t.key] = readerWithHandler{
+		reader:  reader,
+		handler: handler,
+		labels:  t.labels,
+		rotated: !follow,
+	}
+	return true
+}
+
+// removePositions removes the positions of the file at the path of e with
+// the labels of e, whatever its fingerprint.
+func (c *Component) removePositions(e positions.Entry) {
+	for _, stored := range c.posFile.Entries() {
+		if stored.Path == e.Path && stored.Labels == e.Labels {
+			c.posFile.RemoveEntry(stored)
+		}
+	}
+}
+
 // readerWithHandler combines a reader with an entry handler associated with
 // it. Closing the reader will also close the handler.
 type readerWithHandler struct {
 	reader
 	handler loki.EntryHandler
+	labels  model.LabelSet
+	// rotated is true for readers which read the rest of a file which was
+	// rotated, and stop at its end.
+	rotated bool
 }
 
 func (r readerWithHandler) Stop() {
@@ -272,12 +375,14 @@ func (c *Component) stopReaders() map[positions.Entry]struct{} {
 func (c *Component) DebugInfo() interface{} {
 	var res readerDebugInfo
 	for e, reader := range c.readers {
-		offset, _ := c.posFile.Get(e.Path, e.Labels)
+		key := reader.PositionEntry()
+		offset, _ := c.posFile.GetEntry(key)
 		res.TargetsInfo = append(res.TargetsInfo, targetInfo{
-			Path:       e.Path,
-			Labels:     e.Labels,
-			IsRunning:  reader.IsRunning(),
-			ReadOffset: offset,
+			Path:        e.Path,
+			Labels:      e.Labels,
+			Fingerprint: key.Fingerprint,
+			IsRunning:   reader.IsRunning(),
+			ReadOffset:  offset,
 		})
 	}
 	return res
@@ -288,10 +393,11 @@ type readerDebugInfo struct {
 }
 
 type targetInfo struct {
-	Path       string `alloy:"path,attr"`
-	Labels     string `alloy:"labels,attr"`
-	IsRunning  bool   `alloy:"is_running,attr"`
-	ReadOffset int64  `alloy:"read_offset,attr"`
+	Path        string `alloy:"path,attr"`
+	Labels      string `alloy:"labels,attr"`
+	Fingerprint string `alloy:"fingerprint,attr,optional"`
+	IsRunning   bool   `alloy:"is_running,attr"`
+	ReadOffset  int64  `alloy:"read_offset,attr"`
 }
 
 // Returns the elements from set b which are missing from set a
@@ -305,10 +411,11 @@ func missing(as map[positions.Entry]reader, bs map[positions.Entry]struct{}) map
 	return c
 }
 
-// startTailing starts and returns a reader for the given path. For most files,
+// startTailing starts and returns a reader for the file of key. For most files,
 // this will be a tailer implementation. If the file suffix alludes to it being
 // a compressed file, then a decompressor will be started instead.
-func (c *Component) startTailing(path string, labels model.LabelSet, handler loki.EntryHandler) (reader, error) {
+func (c *Component) startTailing(key positions.Entry, handler loki.EntryHandler, tailFromEnd bool, follow bool) (reader, error) {
+	path := key.Path
 	fi, err := os.Stat(path)
 	if err != nil {
 		level.Error(c.opts.Logger).Log("msg", "failed to tail file, stat failed", "error", err, "filename", path)
@@ -330,10 +437,10 @@ func (c *Component) startTailing(path string, labels model.LabelSet, handler lok
 			c.opts.Logger,
 			handler,
 			c.posFile,
-			path,
-			labels.String(),
+			key,
 			c.args.Encoding,
 			c.args.DecompressionConfig,
+			c.args.WaitForDelivery,
 		)
 		if err != nil {
 			level.Error(c.opts.Logger).Log("msg", "failed to start decompressor", "error", err, "filename", path)
@@ -351,11 +458,13 @@ func (c *Component) startTailing(path string, labels model.LabelSet, handler lok
 			c.opts.Logger,
 			handler,
 			c.posFile,
-			path,
-			labels.String(),
+			key,
 			c.args.Encoding,
 			pollOptions,
-			c.args.TailFromEnd,
+			tailFromEnd,
+			c.args.WaitForDelivery,
+			c.args.fingerprintSize(),
+			follow,
 		)
 		if err != nil {
 			level.Error(c.opts.Logger).Log("msg", "failed to start tailer", "error", err, "filename", path)
@@ -385,3 +494,13 @@ func (c *Component) reportSize(path, labels string) {
 		c.metrics.totalBytes.WithLabelValues(path).Set(float64(fi.Size()))
 	}
 }
+
+// newPositionCommits returns a CommitQueue which saves the position of the
+// file at path with put once the lines read before it are delivered.
+func newPositionCommits(logger log.Logger, path string, put func(pos int64)) *loki.CommitQueue[int64] {
+	return loki.NewCommitQueue(put, func(err error) {
+		level.Warn(logger).Log("msg", "failed to deliver a line, the position of the file won't be updated until the component restarts", "path", path, "err", err)
+	})
+}
+
+func (c *Component) LiveDebugging(_ int) {}
@@ -314,3 +314,159 @@ func TestEncoding(t *testing.T) {
 		"expected positions.yml file to be written eventually",
 	)
 }
+
+func TestWaitForDelivery(t *testing.T) {
+	opts := component.Options{
+		Logger:        util.TestAlloyLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) {},
+		DataPath:      t.TempDir(),
+	}
+
+	f, err := os.CreateTemp(opts.DataPath, "example")
+	require.NoError(t, err)
+	defer f.Close()
+
+	ch1 := loki.NewLogsReceiver()
+	args := Arguments{
+		Targets:         []discovery.Target{{"__path__": f.Name(), "foo": "bar"}},
+		ForwardTo:       []loki.LogsReceiver{ch1},
+		WaitForDelivery: true,
+	}
+
+	c, err := New(opts, args)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+	require.Eventually(t, func() bool { return c.DebugInfo() != nil }, 500*time.Millisecond, 20*time.Millisecond)
+
+	_, err = f.Write([]byte("first\nsecond\n"))
+	require.NoError(t, err)
+
+	var entries []loki.Entry
+	for i := 0; i < 2; i++ {
+		select {
+		case logEntry := <-ch1.Chan():
+			entries = append(entries, logEntry)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "failed waiting for log line")
+		}
+	}
+
+	labels := model.LabelSet{"foo": "bar"}.String()
+	position := func() int64 {
+		pos, err := c.posFile.Get(f.Name(), labels)
+		require.NoError(t, err)
+		return pos
+	}
+
+	// The position is only stored once the lines are delivered, in order.
+	require.Equal(t, int64(0), position())
+	entries[1].Delivery.Done(nil)
+	require.Equal(t, int64(0), position())
+	entries[0].Delivery.Done(nil)
+	require.Eventually(t, func() bool { return position() == int64(len("first\nsecond\n")) }, time.Second, 10*time.Millisecond)
+}
+
+func TestWaitForDelivery_Truncation(t *testing.T) {
+	f, ch1, position := runWaitForDelivery(t, "")
+
+	_, err := f.Write([]byte("first\nsecond\n"))
+	require.NoError(t, err)
+	deliverLines(t, ch1, "first", "second")
+	require.Eventually(t, func() bool { return position() == int64(len("first\nsecond\n")) }, time.Second, 10*time.Millisecond)
+
+	// The file is copied and truncated, and the tail reopens it: the offset of
+	// the lines starts again from the start of the file.
+	require.NoError(t, f.Truncate(0))
+	_, err = f.WriteAt([]byte("third\n"), 0)
+	require.NoError(t, err)
+	deliverLines(t, ch1, "third")
+	require.Eventually(t, func() bool { return position() == int64(len("third\n")) }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWaitForDelivery_EncodedNewlines(t *testing.T) {
+	t.Run("CRLF", func(t *testing.T) {
+		f, ch1, position := runWaitForDelivery(t, "")
+
+		_, err := f.Write([]byte("first\r\nsecond\r\n"))
+		require.NoError(t, err)
+		deliverLines(t, ch1, "first\r", "second\r")
+		require.Eventually(t, func() bool { return position() == int64(len("first\r\nsecond\r\n")) }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("UTF-16BE", func(t *testing.T) {
+		f, ch1, position := runWaitForDelivery(t, "UTF-16BE")
+
+		utf16Bytes, err := unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder().Bytes([]byte("first\nsecond\n"))
+		require.NoError(t, err)
+		_, err = f.Write(utf16Bytes)
+		require.NoError(t, err)
+		deliverLines(t, ch1, "first�", "second�")
+		require.Eventually(t, func() bool { return position() == int64(len(utf16Bytes)) }, time.Second, 10*time.Millisecond)
+	})
+}
+
+// runWaitForDelivery runs a component which waits for the delivery of the
+// lines of a file. It returns the file, the receiver of its lines and a
+// function returning its stored position.
+func runWaitForDelivery(t *testing.T, encoding string) (*os.File, loki.LogsReceiver, func() int64) {
+	opts := component.Options{
+		Logger:        util.TestAlloyLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) {},
+		DataPath:      t.TempDir(),
+	}
+
+	f, err := os.CreateTemp(opts.DataPath, "example")
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+
+	ch1 := loki.NewLogsReceiver()
+	args := Arguments{
+		Targets:         []discovery.Target{{"__path__": f.Name(), "foo": "bar"}},
+		ForwardTo:       []loki.LogsReceiver{ch1},
+		Encoding:        encoding,
+		WaitForDelivery: true,
+	}
+
+	c, err := New(opts, args)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Run(ctx)
+	}()
+	// Wait for the component to save its positions before the temporary
+	// directory is removed.
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	require.Eventually(t, func() bool { return c.DebugInfo() != nil }, 500*time.Millisecond, 20*time.Millisecond)
+
+	labels := model.LabelSet{"foo": "bar"}.String()
+	return f, ch1, func() int64 {
+		pos, err := c.posFile.Get(f.Name(), labels)
+		require.NoError(t, err)
+		return pos
+	}
+}
+
+// deliverLines requires lines to be received in order, and marks them as
+// delivered.
+func deliverLines(t *testing.T, ch loki.LogsReceiver, lines ...string) {
+	for _, line := range lines {
+		select {
+		case logEntry := <-ch.Chan():
+			require.Equal(t, line, logEntry.Line)
+			logEntry.Delivery.Done(nil)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "failed waiting for log line", line)
+		}
+	}
+}
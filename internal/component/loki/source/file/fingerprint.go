@@ -0,0 +1,334 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/grafana/alloy/internal/component/common/loki/positions"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/prometheus/common/model"
+)
+
+// readersSyncPeriod is the period at which stopped readers of files identified
+// by their fingerprint are restarted.
+const readersSyncPeriod = time.Second
+
+// fileTarget is a file to read, with the labels of its target.
+type fileTarget struct {
+	// key identifies the target by the path of the file and the string
+	// representation of labels.
+	key    positions.Entry
+	labels model.LabelSet
+}
+
+// fingerprintedFile is a file identified by its fingerprint.
+type fingerprintedFile struct {
+	key         positions.Entry
+	fingerprint positions.Fingerprint
+}
+
+// assignPositions returns the entry of the positions file each of the files
+// resumes reading from, by the key of the file. Files without an entry are
+// new files.
+//
+// A file is assigned the entry of a fingerprint it matches with the same
+// labels, first with the same device and inode so that renamed files keep
+// their position, then with the same first bytes so that files which were
+// copied keep their position. Otherwise, a file is assigned the entry of its
+// path stored before files were identified by their fingerprint, if any.
+func assignPositions(files []fingerprintedFile, entries []positions.Entry, size int) map[positions.Entry]positions.Entry {
+	type candidate struct {
+		entry       positions.Entry
+		fingerprint positions.Fingerprint
+	}
+	var candidates []candidate
+	for _, e := range entries {
+		if e.Fingerprint == "" {
+			continue
+		}
+		fp, err := positions.ParseFingerprint(e.Fingerprint)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, candidate{entry: e, fingerprint: fp})
+	}
+
+	var (
+		assigned = make(map[positions.Entry]positions.Entry, len(files))
+		claimed  = make(map[positions.Entry]bool, len(files))
+	)
+	for _, sameFile := range []bool{true, false} {
+		for _, f := range files {
+			if _, ok := assigned[f.key]; ok {
+				continue
+			}
+			for _, c := range candidates {
+				switch {
+				case claimed[c.entry] || c.entry.Labels != f.key.Labels:
+					continue
+				case f.fingerprint.SameFile(c.fingerprint) != sameFile:
+					continue
+				case !sameFile && c.entry.Path == f.key.Path && f.fingerprint.HasFileID():
+					// A file which replaced another one at the same path isn't
+					// a copy of it, even if their first bytes are the same.
+					continue
+				case !f.fingerprint.Matches(c.fingerprint, size):
+					continue
+				}
+				assigned[f.key] = c.entry
+				claimed[c.entry] = true
+				break
+			}
+		}
+	}
+
+	for _, f := range files {
+		if _, ok := assigned[f.key]; ok {
+			continue
+		}
+		legacy := positions.Entry{Path: f.key.Path, Labels: f.key.Labels}
+		if slices.Contains(entries, legacy) {
+			assigned[f.key] = legacy
+		}
+	}
+	return assigned
+}
+
+// positionEntries returns the entries of the positions file of targets, by
+// their key, except for files whose fingerprint can't be read. Entries in held
+// belong to running readers and are left untouched. It moves the positions of files which were renamed, copied or
+// which weren't identified by their fingerprint yet to their entry.
+//
+// It also returns the entries identified by a fingerprint with the labels of
+// one of targets which weren't assigned to any of them, which may be the
+// entries of files which were rotated.
+//
+// When files are identified by their path, positions stored for the file at
+// the same path while files were identified by their fingerprint are moved to
+// the entry of the path.
+func (c *Component) positionEntries(targets []fileTarget, held map[positions.Entry]bool) (map[positions.Entry]positions.Entry, []positions.Entry) {
+	size := c.args.fingerprintSize()
+	entries := slices.DeleteFunc(c.posFile.Entries(), func(e positions.Entry) bool { return held[e] })
+
+	keys := make(map[positions.Entry]positions.Entry, len(targets))
+	if size == 0 {
+		for _, t := range targets {
+			keys[t.key] = t.key
+			if slices.Contains(entries, t.key) {
+				continue
+			}
+			for _, e := range entries {
+				if e.Fingerprint == "" || e.Path != t.key.Path || e.Labels != t.key.Labels {
+					continue
+				}
+				stored, err := positions.ParseFingerprint(e.Fingerprint)
+				if err != nil {
+					continue
+				}
+				fp, err := positions.ReadFingerprint(e.Path, stored.Size)
+				if err != nil || !fp.Matches(stored, stored.Size) {
+					continue
+				}
+				c.movePosition(e, t.key)
+				break
+			}
+		}
+		return keys, nil
+	}
+
+	files := make([]fingerprintedFile, 0, len(targets))
+	labels := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		labels[t.key.Labels] = true
+		fp, err := positions.ReadFingerprint(t.key.Path, size)
+		if err != nil {
+			continue
+		}
+		files = append(files, fingerprintedFile{key: t.key, fingerprint: fp})
+	}
+
+	assigned := assignPositions(files, entries, size)
+	claimed := make(map[positions.Entry]bool, len(assigned))
+	for _, f := range files {
+		key := f.key
+		key.Fingerprint = f.fingerprint.String()
+		keys[f.key] = key
+
+		if e, ok := assigned[f.key]; ok {
+			claimed[e] = true
+			if e != key {
+				c.movePosition(e, key)
+			}
+		}
+	}
+
+	var unassigned []positions.Entry
+	for _, e := range entries {
+		if e.Fingerprint != "" && labels[e.Labels] && !claimed[e] {
+			unassigned = append(unassigned, e)
+		}
+	}
+	return keys, unassigned
+}
+
+// movePosition moves the position of the entry from to the entry to.
+func (c *Component) movePosition(from, to positions.Entry) {
+	pos, err := c.posFile.GetEntry(from)
+	if err != nil {
+		return
+	}
+	c.posFile.RemoveEntry(from)
+	c.posFile.PutEntry(to, pos)
+}
+
+// findRotatedFile returns the path and the fingerprint of the file stored was
+// computed from, if it was renamed in the directory of path and its first
+// bytes didn't change.
+func findRotatedFile(path string, stored positions.Fingerprint, size int) (string, positions.Fingerprint, bool) {
+	dir := filepath.Dir(path)
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", positions.Fingerprint{}, false
+	}
+	for _, de := range dirEntries {
+		if !de.Type().IsRegular() {
+			continue
+		}
+		fi, err := de.Info()
+		if err != nil || !stored.IsFile(fi) {
+			continue
+		}
+		rotated := filepath.Join(dir, de.Name())
+		fp, err := positions.ReadFingerprint(rotated, size)
+		if err != nil || !fp.Matches(stored, size) {
+			return "", positions.Fingerprint{}, false
+		}
+		return rotated, fp, true
+	}
+	return "", positions.Fingerprint{}, false
+}
+
+// resumeRotatedFile moves the position of an entry which wasn't assigned to
+// any target to the file it was computed from if that file was rotated, and
+// reads the rest of the file if it wasn't read until its end. Positions of
+// files which weren't found are removed. Positions of files which were read
+// until their end are kept in case the files become targets later.
+//
+// It must be called with c.mut held.
+func (c *Component) resumeRotatedFile(e positions.Entry, labels model.LabelSet) {
+	size := c.args.fingerprintSize()
+	stored, err := positions.ParseFingerprint(e.Fingerprint)
+	if err != nil {
+		c.posFile.RemoveEntry(e)
+		return
+	}
+
+	rotated, fp, ok := findRotatedFile(e.Path, stored, size)
+	if !ok {
+		c.posFile.RemoveEntry(e)
+		return
+	}
+	readersKey := positions.Entry{Path: rotated, Labels: e.Labels}
+	if _, exist := c.readers[readersKey]; exist {
+		return
+	}
+
+	key := readersKey
+	key.Fingerprint = fp.String()
+	pos, err := c.posFile.GetEntry(e)
+	if err != nil {
+		return
+	}
+	c.movePosition(e, key)
+	fi, err := os.Stat(rotated)
+	if err != nil || pos >= fi.Size() {
+		return
+	}
+
+	level.Info(c.opts.Logger).Log("msg", "reading the rest of a rotated file", "filename", rotated, "rotated_from", e.Path)
+	c.startReader(fileTarget{key: readersKey, labels: labels}, key, false, false)
+}
+
+// syncReaders periodically restarts the readers of files identified by their
+// fingerprint which stopped, until ctx is canceled. Since they don't reopen
+// their file, they stop once their file is rotated and read until its end, or
+// once it's truncated.
+func (c *Component) syncReaders(ctx context.Context) {
+	ticker := time.NewTicker(readersSyncPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.restartStoppedReaders()
+		}
+	}
+}
+
+// restartStoppedReaders restarts the readers of targets which stopped, from
+// the start of their new file, and removes the readers of rotated files which
+// were read until their end. Readers of targets whose file doesn't exist yet
+// are restarted on the next call.
+func (c *Component) restartStoppedReaders() {
+	c.updateMut.Lock()
+	defer c.updateMut.Unlock()
+
+	stopped := make(map[positions.Entry]readerWithHandler)
+	c.mut.RLock()
+	if c.args.fingerprintSize() > 0 && !c.args.DecompressionConfig.Enabled {
+		for k, r := range c.readers {
+			if !r.IsRunning() {
+				stopped[k] = r.(readerWithHandler)
+			}
+		}
+	}
+	c.mut.RUnlock()
+	if len(stopped) == 0 {
+		return
+	}
+
+	// Readers are stopped before c.mut is held for the same reason as in
+	// Update.
+	for _, r := range stopped {
+		r.Stop()
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	held := make(map[positions.Entry]bool, len(c.readers))
+	for k, r := range c.readers {
+		if _, ok := stopped[k]; !ok {
+			held[r.PositionEntry()] = true
+		}
+	}
+
+	var (
+		targets []fileTarget
+		labels  = make(map[string]model.LabelSet)
+	)
+	for k, r := range stopped {
+		delete(c.readers, k)
+		if r.rotated {
+			continue
+		}
+		targets = append(targets, fileTarget{key: k, labels: r.labels})
+		labels[k.Labels] = r.labels
+	}
+
+	keys, unassigned := c.positionEntries(targets, held)
+	for _, t := range targets {
+		key, ok := keys[t.key]
+		if !ok || !c.startReader(t, key, false, true) {
+			c.readers[t.key] = stopped[t.key]
+		}
+	}
+	for _, e := range unassigned {
+		c.resumeRotatedFile(e, labels[e.Labels])
+	}
+}
@@ -0,0 +1,176 @@
+//go:build !race
+
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/common/loki/positions"
+	"github.com/grafana/alloy/internal/component/discovery"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssignPositions(t *testing.T) {
+	const size = 16
+	var (
+		dir    = t.TempDir()
+		labels = model.LabelSet{"foo": "bar"}.String()
+	)
+	fingerprint := func(name, content string) fingerprintedFile {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		fp, err := positions.ReadFingerprint(path, size)
+		require.NoError(t, err)
+		return fingerprintedFile{key: positions.Entry{Path: path, Labels: labels}, fingerprint: fp}
+	}
+	entry := func(path string, fp positions.Fingerprint) positions.Entry {
+		return positions.Entry{Path: path, Labels: labels, Fingerprint: fp.String()}
+	}
+
+	// renamed was rotated from current.log, and its fingerprint was computed
+	// before it was renamed.
+	renamed := fingerprint("current.log.1", "a rotated file with long lines\n")
+	// copied is a copy of a file which was truncated.
+	original := fingerprint("copied.log", "a copied file with long lines\n")
+	copied := fingerprint("copied.log.1", "a copied file with long lines\n")
+	// reused is a new file at the path of a file which was removed, which
+	// starts with the same bytes. It's created before the file is removed so
+	// that it doesn't reuse its inode.
+	removed := fingerprint("reused.log", "a reused file with long lines\n")
+	reused := fingerprint("reused.log.new", "a reused file with long lines\n")
+	require.NoError(t, os.Rename(reused.key.Path, removed.key.Path))
+	reused.key.Path = removed.key.Path
+	// legacy was read before files were identified by their fingerprint.
+	legacy := fingerprint("legacy.log", "a legacy file\n")
+
+	entries := []positions.Entry{
+		entry(filepath.Join(dir, "current.log"), renamed.fingerprint),
+		entry(original.key.Path, original.fingerprint),
+		entry(removed.key.Path, removed.fingerprint),
+		legacy.key,
+	}
+	assigned := assignPositions([]fingerprintedFile{renamed, copied, reused, legacy}, entries, size)
+	require.Equal(t, map[positions.Entry]positions.Entry{
+		renamed.key: entries[0],
+		copied.key:  entries[1],
+		legacy.key:  entries[3],
+	}, assigned)
+}
+
+func TestFingerprint_Rotation(t *testing.T) {
+	opts := component.Options{
+		Logger:        util.TestAlloyLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) {},
+		DataPath:      t.TempDir(),
+	}
+	path := filepath.Join(t.TempDir(), "example.log")
+	require.NoError(t, os.WriteFile(path, []byte("first\n"), 0644))
+
+	ch1 := loki.NewLogsReceiver()
+	args := DefaultArguments
+	args.Targets = []discovery.Target{{"__path__": path, "foo": "bar"}}
+	args.ForwardTo = []loki.LogsReceiver{ch1}
+	args.FingerprintConfig.Enabled = true
+
+	c, err := New(opts, args)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+
+	requireLines(t, ch1, "first")
+
+	// Lines written before the file is rotated are read, and the new file is
+	// read from its start.
+	appendLines(t, path, "second\n")
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, os.WriteFile(path, []byte("third\n"), 0644))
+	requireLines(t, ch1, "second", "third")
+}
+
+func TestFingerprint_Restart(t *testing.T) {
+	opts := component.Options{
+		Logger:        util.TestAlloyLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) {},
+		DataPath:      t.TempDir(),
+	}
+	path := filepath.Join(t.TempDir(), "example.log")
+	require.NoError(t, os.WriteFile(path, []byte("first\n"), 0644))
+
+	ch1 := loki.NewLogsReceiver()
+	args := DefaultArguments
+	args.Targets = []discovery.Target{{"__path__": path, "foo": "bar"}}
+	args.ForwardTo = []loki.LogsReceiver{ch1}
+	args.FingerprintConfig.Enabled = true
+
+	run := func() context.CancelFunc {
+		opts.Registerer = prometheus.NewRegistry()
+		c, err := New(opts, args)
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = c.Run(ctx)
+		}()
+		return func() {
+			cancel()
+			<-done
+		}
+	}
+
+	stop := run()
+	requireLines(t, ch1, "first")
+	stop()
+
+	// The file is rotated and the path is reused while the component isn't
+	// running. The rest of the rotated file is read, and the new file is
+	// read from its start although it starts with the same bytes.
+	appendLines(t, path, "second\n")
+	require.NoError(t, os.WriteFile(path+".new", []byte("first\n"), 0644))
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, os.Rename(path+".new", path))
+
+	stop = run()
+	defer stop()
+	requireLines(t, ch1, "second", "first")
+}
+
+func appendLines(t *testing.T, path string, lines string) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(lines)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+// requireLines requires lines to be received in any order, and no other line.
+func requireLines(t *testing.T, ch loki.LogsReceiver, lines ...string) {
+	var got []string
+	for range lines {
+		select {
+		case logEntry := <-ch.Chan():
+			got = append(got, logEntry.Line)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "failed waiting for log line", "got %v", got)
+		}
+	}
+	require.ElementsMatch(t, lines, got)
+
+	select {
+	case logEntry := <-ch.Chan():
+		require.FailNow(t, "unexpected log line", logEntry.Line)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
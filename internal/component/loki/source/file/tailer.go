@@ -5,6 +5,7 @@ package file
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -34,9 +35,8 @@ type tailer struct {
 	handler   loki.EntryHandler
 	positions positions.Positions
 
-	path   string
-	labels string
-	tail   *tail.Tail
+	path string
+	tail *tail.Tail
 
 	posAndSizeMtx sync.Mutex
 	stopOnce      sync.Once
@@ -47,23 +47,60 @@ type tailer struct {
 	done    chan struct{}
 
 	decoder *encoding.Decoder
+
+	// commits commits the offset of the lines once they're delivered. It's
+	// nil when the offset is saved as soon as lines are read.
+	commits *loki.CommitQueue[int64]
+	// offset is the offset of the end of the last line read.
+	offset int64
+
+	// key is the entry of the file in the positions file. It's protected by
+	// posAndSizeMtx since the fingerprint of the file is updated as it grows.
+	key positions.Entry
+	// fingerprint is the fingerprint of the file, and fingerprintSize the
+	// size of fingerprints. fingerprintSize is 0 when files are identified by
+	// their path.
+	fingerprint     positions.Fingerprint
+	fingerprintSize int
+	// truncated is set once the file was truncated, after which its position
+	// isn't saved anymore.
+	truncated bool
 }
 
-func newTailer(metrics *metrics, logger log.Logger, handler loki.EntryHandler, positions positions.Positions, path string,
-	labels string, encoding string, pollOptions watch.PollingFileWatcherOptions, tailFromEnd bool) (*tailer, error) {
+// errFileTruncated is returned by MarkPositionAndSize when a file identified
+// by its fingerprint was truncated, to stop its tailer so that the file is
+// read again as a new file.
+var errFileTruncated = errors.New("file was truncated")
+
+// newTailer returns a tailer for the file of key. When fingerprintSize isn't
+// 0, the file is identified by its fingerprint: it's read until its end when
+// it's rotated, and follow is false for files which were already rotated.
+func newTailer(metrics *metrics, logger log.Logger, handler loki.EntryHandler, posFile positions.Positions, key positions.Entry,
+	encoding string, pollOptions watch.PollingFileWatcherOptions, tailFromEnd bool, waitForDelivery bool, fingerprintSize int, follow bool) (*tailer, error) {
+	path := key.Path
+
+	var fp positions.Fingerprint
+	if fingerprintSize > 0 {
+		var err error
+		fp, err = positions.ParseFingerprint(key.Fingerprint)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Simple check to make sure the file we are tailing doesn't
 	// have a position already saved which is past the end of the file.
 	fi, err := os.Stat(path)
 	if err != nil {
 		return nil, err
 	}
-	pos, err := positions.Get(path, labels)
+	pos, err := posFile.GetEntry(key)
 	if err != nil {
 		return nil, err
 	}
 
 	if fi.Size() < pos {
-		positions.Remove(path, labels)
+		posFile.RemoveEntry(key)
 	}
 
 	// If no cached position is found and the tailFromEnd option is enabled.
@@ -72,15 +109,17 @@ func newTailer(metrics *metrics, logger log.Logger, handler loki.EntryHandler, p
 		if err != nil {
 			level.Error(logger).Log("msg", "failed to get a position from the end of the file, default to start of file", err)
 		} else {
-			positions.Put(path, labels, pos)
+			posFile.PutEntry(key, pos)
 			level.Info(logger).Log("msg", "retrieved and stored the position of the last line")
 		}
 	}
 
+	// Files identified by their fingerprint are followed when they're renamed
+	// rather than reopened, so that they're read until their end.
 	tail, err := tail.TailFile(path, tail.Config{
-		Follow:    true,
+		Follow:    follow,
 		Poll:      true,
-		ReOpen:    true,
+		ReOpen:    follow && fingerprintSize == 0,
 		MustExist: true,
 		Location: &tail.SeekInfo{
 			Offset: pos,
@@ -98,14 +137,21 @@ func newTailer(metrics *metrics, logger log.Logger, handler loki.EntryHandler, p
 		metrics:   metrics,
 		logger:    logger,
 		handler:   loki.AddLabelsMiddleware(model.LabelSet{filenameLabel: model.LabelValue(path)}).Wrap(handler),
-		positions: positions,
+		positions: posFile,
 		path:      path,
-		labels:    labels,
 		tail:      tail,
 		running:   atomic.NewBool(false),
 		posquit:   make(chan struct{}),
 		posdone:   make(chan struct{}),
 		done:      make(chan struct{}),
+		offset:    pos,
+
+		key:             key,
+		fingerprint:     fp,
+		fingerprintSize: fingerprintSize,
+	}
+	if waitForDelivery {
+		tailer.commits = newPositionCommits(logger, path, tailer.putPosition)
 	}
 
 	if encoding != "" {
@@ -200,6 +246,14 @@ func (t *tailer) updatePosition() {
 		select {
 		case <-positionWait.C:
 			err := t.MarkPositionAndSize()
+			if errors.Is(err, errFileTruncated) {
+				level.Info(t.logger).Log("msg", "position timer: file was truncated, stopping tailer", "path", t.path)
+				err := t.tail.Stop()
+				if err != nil {
+					level.Error(t.logger).Log("msg", "position timer: error stopping tailer", "path", t.path, "error", err)
+				}
+				return
+			}
 			if err != nil {
 				level.Error(t.logger).Log("msg", "position timer: error getting tail position and/or size, stopping tailer", "path", t.path, "error", err)
 				err := t.tail.Stop()
@@ -228,6 +282,12 @@ func (t *tailer) readLines() {
 	// This function runs in a goroutine, if it exits this tailer will never do any more tailing.
 	// Clean everything up.
 	defer func() {
+		// The tail can't tell the position of a file identified by its
+		// fingerprint once it was rotated and read until its end, so the
+		// position of the last line read is saved.
+		if t.fingerprintSize > 0 && t.commits == nil {
+			t.putPosition(t.offset)
+		}
 		t.running.Store(false)
 		level.Info(t.logger).Log("msg", "tail routine: exited", "path", t.path)
 		close(t.done)
@@ -262,16 +322,43 @@ func (t *tailer) readLines() {
 		}
 
 		t.metrics.readLines.WithLabelValues(t.path).Inc()
+		t.offset = t.nextOffset(line)
 		entries <- loki.Entry{
 			Labels: model.LabelSet{},
 			Entry: logproto.Entry{
 				Timestamp: line.Time,
 				Line:      text,
 			},
+			Delivery: t.commits.Track(t.offset),
 		}
 	}
 }
 
+// nextOffset returns the offset of the end of line. The offset is computed
+// from the length of the lines read, since the position of the tail may be
+// ahead of the lines it sent. The tail splits lines at the '\n' byte and only
+// trims that byte, so the rest of an encoded newline such as "\r\n" or the
+// other byte of a UTF-16 newline is part of the text of a line.
+//
+// The position of the tail bounds the offset: it's behind the start of line
+// when the tail reopened the file after it was truncated, in which case line
+// was read from the start of the file, and it's behind the end of line when
+// the last line of the file has no newline.
+func (t *tailer) nextOffset(line *tail.Line) int64 {
+	offset := t.offset
+	pos, err := t.tail.Tell()
+	if err == nil && pos < offset {
+		level.Debug(t.logger).Log("msg", "tail routine: file was reopened, reading it from its start", "path", t.path)
+		offset = 0
+	}
+
+	offset += int64(len(line.Text)) + 1
+	if err == nil && pos >= t.offset && pos < offset {
+		offset = pos
+	}
+	return offset
+}
+
 func (t *tailer) MarkPositionAndSize() error {
 	// Lock this update as there are 2 timers calling this routine, the sync in filetarget and the positions sync in this file.
 	t.posAndSizeMtx.Lock()
@@ -295,18 +382,67 @@ func (t *tailer) MarkPositionAndSize() error {
 	// Update metrics and positions file all together to avoid race conditions when `t.tail` is stopped.
 	t.metrics.totalBytes.WithLabelValues(t.path).Set(float64(size))
 	t.metrics.readBytes.WithLabelValues(t.path).Set(float64(pos))
-	t.positions.Put(t.path, t.labels, pos)
+	if t.fingerprintSize > 0 {
+		if err := t.updateFingerprint(); err != nil {
+			return err
+		}
+	}
+	if t.commits == nil {
+		t.positions.PutEntry(t.key, pos)
+	}
 
 	return nil
 }
 
+// updateFingerprint updates the fingerprint of the file as it grows. It
+// returns errFileTruncated if the file was truncated, since its position
+// isn't valid for its new content. It must be called with posAndSizeMtx held.
+func (t *tailer) updateFingerprint() error {
+	if t.truncated {
+		return errFileTruncated
+	}
+
+	fp, err := positions.ReadFingerprint(t.path, t.fingerprintSize)
+	if err != nil || !fp.SameFile(t.fingerprint) {
+		// The file was rotated, and is read until its end.
+		return nil
+	}
+	if !fp.Matches(t.fingerprint, t.fingerprintSize) {
+		t.truncated = true
+		return errFileTruncated
+	}
+	if fp.Size == t.fingerprint.Size {
+		return nil
+	}
+
+	key := t.key
+	key.Fingerprint = fp.String()
+	pos, err := t.positions.GetEntry(t.key)
+	if err != nil {
+		return err
+	}
+	t.positions.RemoveEntry(t.key)
+	t.positions.PutEntry(key, pos)
+	t.key, t.fingerprint = key, fp
+	return nil
+}
+
+// putPosition saves the position of the file, unless it was truncated.
+func (t *tailer) putPosition(pos int64) {
+	t.posAndSizeMtx.Lock()
+	defer t.posAndSizeMtx.Unlock()
+	if !t.truncated {
+		t.positions.PutEntry(t.key, pos)
+	}
+}
+
 func (t *tailer) Stop() {
 	// stop can be called by two separate threads in filetarget, to avoid a panic closing channels more than once
 	// we wrap the stop in a sync.Once.
 	t.stopOnce.Do(func() {
 		// Save the current position before shutting down tailer
 		err := t.MarkPositionAndSize()
-		if err != nil {
+		if err != nil && !errors.Is(err, errFileTruncated) {
 			level.Error(t.logger).Log("msg", "error marking file position when stopping tailer", "path", t.path, "error", err)
 		}
 
@@ -356,3 +492,9 @@ func (t *tailer) cleanupMetrics() {
 func (t *tailer) Path() string {
 	return t.path
 }
+
+func (t *tailer) PositionEntry() positions.Entry {
+	t.posAndSizeMtx.Lock()
+	defer t.posAndSizeMtx.Unlock()
+	return t.key
+}
@@ -16,6 +16,7 @@ import (
 	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 	"github.com/grafana/alloy/internal/component/loki/source/gcplog/gcptypes"
 	gt "github.com/grafana/alloy/internal/component/loki/source/gcplog/internal/gcplogtarget"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/grafana/alloy/internal/util"
 )
 
@@ -55,9 +56,10 @@ func (a *Arguments) Validate() error {
 
 // Component implements the loki.source.gcplog component.
 type Component struct {
-	opts          component.Options
-	metrics       *gt.Metrics
-	serverMetrics *util.UncheckedCollector
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
+	metrics            *gt.Metrics
+	serverMetrics      *util.UncheckedCollector
 
 	mut    sync.RWMutex
 	fanout []loki.LogsReceiver
@@ -66,14 +68,17 @@ type Component struct {
 	handler loki.LogsReceiver
 }
 
+var _ component.LiveDebugging = (*Component)(nil)
+
 // New creates a new loki.source.gcplog component.
 func New(o component.Options, args Arguments) (*Component, error) {
 	c := &Component{
-		opts:          o,
-		metrics:       gt.NewMetrics(o.Registerer),
-		handler:       loki.NewLogsReceiver(),
-		fanout:        args.ForwardTo,
-		serverMetrics: util.NewUncheckedCollector(nil),
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		opts:               o,
+		metrics:            gt.NewMetrics(o.Registerer),
+		handler:            loki.NewLogsReceiver(),
+		fanout:             args.ForwardTo,
+		serverMetrics:      util.NewUncheckedCollector(nil),
 	}
 
 	o.Registerer.MustRegister(c.serverMetrics)
@@ -104,6 +109,7 @@ func (c *Component) Run(ctx context.Context) error {
 			return nil
 		case entry := <-c.handler.Chan():
 			c.mut.RLock()
+			loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, entry)
 			for _, receiver := range c.fanout {
 				receiver.Chan() <- entry
 			}
@@ -173,3 +179,5 @@ func (c *Component) DebugInfo() interface{} {
 type targetDebugInfo struct {
 	Details map[string]string `alloy:"target_info,attr"`
 }
+
+func (c *Component) LiveDebugging(_ int) {}
@@ -13,6 +13,7 @@ import (
 	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 	"github.com/grafana/alloy/internal/component/loki/source/gelf/internal/target"
 	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 )
 
 func init() {
@@ -27,16 +28,20 @@ func init() {
 	})
 }
 
-var _ component.Component = (*Component)(nil)
+var (
+	_ component.Component     = (*Component)(nil)
+	_ component.LiveDebugging = (*Component)(nil)
+)
 
 // Component is a receiver for graylog formatted log files.
 type Component struct {
-	mut       sync.RWMutex
-	target    *target.Target
-	o         component.Options
-	metrics   *target.Metrics
-	handler   *handler
-	receivers []loki.LogsReceiver
+	mut                sync.RWMutex
+	target             *target.Target
+	o                  component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
+	metrics            *target.Metrics
+	handler            *handler
+	receivers          []loki.LogsReceiver
 }
 
 // Run starts the component.
@@ -57,6 +62,7 @@ func (c *Component) Run(ctx context.Context) error {
 			if lokiEntry.Labels["job"] == "" {
 				lokiEntry.Labels["job"] = model.LabelValue(c.o.ID)
 			}
+			loki.PublishDebugEvent(c.debugDataPublisher, c.o.ID, lokiEntry)
 			for _, r := range c.receivers {
 				r.Chan() <- lokiEntry
 			}
@@ -123,9 +129,10 @@ func convertConfig(a Arguments) *scrapeconfig.GelfTargetConfig {
 func New(o component.Options, args Arguments) (*Component, error) {
 	metrics := target.NewMetrics(o.Registerer)
 	c := &Component{
-		o:       o,
-		metrics: metrics,
-		handler: &handler{c: make(chan loki.Entry)},
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		o:                  o,
+		metrics:            metrics,
+		handler:            &handler{c: make(chan loki.Entry)},
 	}
 	// Call to Update() to start readers and set receivers once at the start.
 	if err := c.Update(args); err != nil {
@@ -144,3 +151,5 @@ func (h *handler) Chan() chan<- loki.Entry {
 func (handler) Stop() {
 	// noop.
 }
+
+func (c *Component) LiveDebugging(_ int) {}
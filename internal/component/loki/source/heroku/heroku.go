@@ -12,6 +12,7 @@ import (
 	ht "github.com/grafana/alloy/internal/component/loki/source/heroku/internal/herokutarget"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/grafana/alloy/internal/util"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
@@ -49,9 +50,10 @@ func (a *Arguments) SetToDefault() {
 
 // Component implements the loki.source.heroku component.
 type Component struct {
-	opts          component.Options
-	metrics       *ht.Metrics              // Metrics about Heroku entries.
-	serverMetrics *util.UncheckedCollector // Metircs about the HTTP server managed by the component.
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
+	metrics            *ht.Metrics              // Metrics about Heroku entries.
+	serverMetrics      *util.UncheckedCollector // Metircs about the HTTP server managed by the component.
 
 	mut    sync.RWMutex
 	args   Arguments
@@ -61,17 +63,20 @@ type Component struct {
 	handler loki.LogsReceiver
 }
 
+var _ component.LiveDebugging = (*Component)(nil)
+
 // New creates a new loki.source.heroku component.
 func New(o component.Options, args Arguments) (*Component, error) {
 	c := &Component{
-		opts:          o,
-		metrics:       ht.NewMetrics(o.Registerer),
-		mut:           sync.RWMutex{},
-		args:          Arguments{},
-		fanout:        args.ForwardTo,
-		target:        nil,
-		handler:       loki.NewLogsReceiver(),
-		serverMetrics: util.NewUncheckedCollector(nil),
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		opts:               o,
+		metrics:            ht.NewMetrics(o.Registerer),
+		mut:                sync.RWMutex{},
+		args:               Arguments{},
+		fanout:             args.ForwardTo,
+		target:             nil,
+		handler:            loki.NewLogsReceiver(),
+		serverMetrics:      util.NewUncheckedCollector(nil),
 	}
 
 	o.Registerer.MustRegister(c.serverMetrics)
@@ -105,6 +110,7 @@ func (c *Component) Run(ctx context.Context) error {
 			return nil
 		case entry := <-c.handler.Chan():
 			c.mut.RLock()
+			loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, entry)
 			for _, receiver := range c.fanout {
 				receiver.Chan() <- entry
 			}
@@ -194,3 +200,5 @@ type readerDebugInfo struct {
 func changed(prev, next any) bool {
 	return !reflect.DeepEqual(prev, next)
 }
+
+func (c *Component) LiveDebugging(_ int) {}
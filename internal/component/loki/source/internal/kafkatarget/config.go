@@ -42,6 +42,10 @@ type TargetConfig struct {
 	// Authentication strategy with Kafka brokers
 	Authentication Authentication `yaml:"authentication"`
 
+	// WaitForDelivery marks messages as consumed only once their entries are
+	// delivered.
+	WaitForDelivery bool `yaml:"wait_for_delivery"`
+
 	MessageParser MessageParser
 }
 
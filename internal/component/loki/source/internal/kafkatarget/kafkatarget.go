@@ -39,6 +39,10 @@ type KafkaTarget struct {
 	relabelConfig        []*relabel.Config
 	useIncomingTimestamp bool
 	messageParser        MessageParser
+
+	// commits marks messages once their entries are delivered. It's nil when
+	// messages are marked as soon as they're read.
+	commits *loki.CommitQueue[*sarama.ConsumerMessage]
 }
 
 func NewKafkaTarget(
@@ -91,16 +95,24 @@ func (t *KafkaTarget) run() {
 		if len(lbs) > 0 {
 			out = out.Merge(lbs)
 		}
+		// delivery is nil unless messages are marked once delivered.
+		delivery := t.commits.Track(message)
+
 		entries, err := t.messageParser.Parse(message, out, t.relabelConfig, t.useIncomingTimestamp)
 		if err != nil {
 			level.Error(t.logger).Log("msg", "message parsing error", "err", err)
 		} else {
+			delivery.Add(len(entries))
 			for _, entry := range entries {
+				entry.Delivery = delivery
 				t.client.Chan() <- entry
 			}
 		}
 
-		t.session.MarkMessage(message, "")
+		if t.commits == nil {
+			t.session.MarkMessage(message, "")
+		}
+		delivery.Done(nil)
 	}
 }
 
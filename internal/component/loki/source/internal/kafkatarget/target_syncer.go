@@ -301,6 +301,13 @@ func (ts *TargetSyncer) NewTarget(session sarama.ConsumerGroupSession, claim sar
 		ts.cfg.KafkaConfig.UseIncomingTimestamp,
 		ts.messageParser,
 	)
+	if ts.cfg.KafkaConfig.WaitForDelivery {
+		t.commits = loki.NewCommitQueue(func(message *sarama.ConsumerMessage) {
+			session.MarkMessage(message, "")
+		}, func(err error) {
+			level.Warn(ts.logger).Log("msg", "failed to deliver a message, the offset of the partition won't be marked until the next rebalance", "details", details, "err", err)
+		})
+	}
 
 	return t, nil
 }
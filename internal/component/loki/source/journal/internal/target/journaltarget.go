@@ -110,6 +110,10 @@ type JournalTarget struct {
 	config        *scrapeconfig.JournalTargetConfig
 	labels        model.LabelSet
 
+	// commits stores the cursors of the entries once they're delivered. It's
+	// nil when cursors are stored as soon as entries are read.
+	commits *loki.CommitQueue[string]
+
 	r     journalReader
 	until chan time.Time
 }
@@ -123,6 +127,7 @@ func NewJournalTarget(
 	jobName string,
 	relabelConfig []*relabel.Config,
 	targetConfig *scrapeconfig.JournalTargetConfig,
+	waitForDelivery bool,
 ) (*JournalTarget, error) {
 
 	return journalTargetWithReader(
@@ -133,6 +138,7 @@ func NewJournalTarget(
 		jobName,
 		relabelConfig,
 		targetConfig,
+		waitForDelivery,
 		defaultJournalReaderFunc,
 		defaultJournalEntryFunc,
 	)
@@ -146,6 +152,7 @@ func journalTargetWithReader(
 	jobName string,
 	relabelConfig []*relabel.Config,
 	targetConfig *scrapeconfig.JournalTargetConfig,
+	waitForDelivery bool,
 	readerFunc journalReaderFunc,
 	entryFunc journalEntryFunc,
 ) (*JournalTarget, error) {
@@ -173,6 +180,13 @@ func journalTargetWithReader(
 
 		until: until,
 	}
+	if waitForDelivery {
+		t.commits = loki.NewCommitQueue(func(cursor string) {
+			pos.PutString(positionPath, "", cursor)
+		}, func(err error) {
+			level.Warn(logger).Log("msg", "failed to deliver a journal entry, the cursor won't be updated until the component restarts", "err", err)
+		})
+	}
 
 	var maxAge time.Duration
 	var err error
@@ -335,13 +349,16 @@ func (t *JournalTarget) formatter(entry *sdjournal.JournalEntry) (string, error)
 	}
 
 	t.metrics.journalLines.Inc()
-	t.positions.PutString(t.positionPath, "", entry.Cursor)
+	if t.commits == nil {
+		t.positions.PutString(t.positionPath, "", entry.Cursor)
+	}
 	t.handler.Chan() <- loki.Entry{
 		Labels: lbls,
 		Entry: logproto.Entry{
 			Line:      msg,
 			Timestamp: ts,
 		},
+		Delivery: t.commits.Track(entry.Cursor),
 	}
 	return journalEmptyStr, nil
 }
@@ -105,7 +105,7 @@ func TestJournalTarget(t *testing.T) {
 
 	registry := prometheus.NewRegistry()
 	jt, err := journalTargetWithReader(NewMetrics(registry), logger, client, ps, "test", relabels,
-		&scrapeconfig.JournalTargetConfig{}, newMockJournalReader, newMockJournalEntry(nil))
+		&scrapeconfig.JournalTargetConfig{}, false, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -159,7 +159,7 @@ func TestJournalTargetParsingErrors(t *testing.T) {
 
 	registry := prometheus.NewRegistry()
 	jt, err := journalTargetWithReader(NewMetrics(registry), logger, client, ps, "test", relabels,
-		&scrapeconfig.JournalTargetConfig{}, newMockJournalReader, newMockJournalEntry(nil))
+		&scrapeconfig.JournalTargetConfig{}, false, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -236,7 +236,7 @@ func TestJournalTarget_JSON(t *testing.T) {
 	cfg := &scrapeconfig.JournalTargetConfig{JSON: true}
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", relabels,
-		cfg, newMockJournalReader, newMockJournalEntry(nil))
+		cfg, false, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -287,7 +287,7 @@ func TestJournalTarget_Since(t *testing.T) {
 	}
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", nil,
-		&cfg, newMockJournalReader, newMockJournalEntry(nil))
+		&cfg, false, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -327,7 +327,7 @@ func TestJournalTarget_Cursor_TooOld(t *testing.T) {
 	})
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", nil,
-		&cfg, newMockJournalReader, journalEntry)
+		&cfg, false, newMockJournalReader, journalEntry)
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -367,7 +367,7 @@ func TestJournalTarget_Cursor_NotTooOld(t *testing.T) {
 	})
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", nil,
-		&cfg, newMockJournalReader, journalEntry)
+		&cfg, false, newMockJournalReader, journalEntry)
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -418,7 +418,7 @@ func TestJournalTarget_Matches(t *testing.T) {
 	}
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", nil,
-		&cfg, newMockJournalReader, newMockJournalEntry(nil))
+		&cfg, false, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
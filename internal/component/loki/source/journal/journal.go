@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/alloy/internal/featuregate"
 
 	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 )
 
 func init() {
@@ -33,17 +34,21 @@ func init() {
 	})
 }
 
-var _ component.Component = (*Component)(nil)
+var (
+	_ component.Component     = (*Component)(nil)
+	_ component.LiveDebugging = (*Component)(nil)
+)
 
 // Component represents reading from a journal
 type Component struct {
-	mut       sync.RWMutex
-	t         *target.JournalTarget
-	metrics   *target.Metrics
-	o         component.Options
-	handler   chan loki.Entry
-	positions positions.Positions
-	receivers []loki.LogsReceiver
+	mut                sync.RWMutex
+	t                  *target.JournalTarget
+	metrics            *target.Metrics
+	o                  component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
+	handler            chan loki.Entry
+	positions          positions.Positions
+	receivers          []loki.LogsReceiver
 }
 
 // New creates a new  component.
@@ -64,11 +69,12 @@ func New(o component.Options, args Arguments) (*Component, error) {
 	}
 
 	c := &Component{
-		metrics:   target.NewMetrics(o.Registerer),
-		o:         o,
-		handler:   make(chan loki.Entry),
-		positions: positionsFile,
-		receivers: args.Receivers,
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		metrics:            target.NewMetrics(o.Registerer),
+		o:                  o,
+		handler:            make(chan loki.Entry),
+		positions:          positionsFile,
+		receivers:          args.Receivers,
 	}
 	err = c.Update(args)
 	return c, err
@@ -91,12 +97,16 @@ func (c *Component) Run(ctx context.Context) error {
 		case entry := <-c.handler:
 			c.mut.RLock()
 			lokiEntry := loki.Entry{
-				Labels: entry.Labels,
-				Entry:  entry.Entry,
+				Labels:   entry.Labels,
+				Entry:    entry.Entry,
+				Delivery: entry.Delivery,
 			}
+			loki.PublishDebugEvent(c.debugDataPublisher, c.o.ID, lokiEntry)
+			lokiEntry.Delivery.Add(len(c.receivers))
 			for _, r := range c.receivers {
 				r.Chan() <- lokiEntry
 			}
+			lokiEntry.Delivery.Done(nil)
 			c.mut.RUnlock()
 		}
 	}
@@ -116,7 +126,7 @@ func (c *Component) Update(args component.Arguments) error {
 	rcs := alloy_relabel.ComponentToPromRelabelConfigs(newArgs.RelabelRules)
 	entryHandler := loki.NewEntryHandler(c.handler, func() {})
 
-	newTarget, err := target.NewJournalTarget(c.metrics, c.o.Logger, entryHandler, c.positions, c.o.ID, rcs, convertArgs(c.o.ID, newArgs))
+	newTarget, err := target.NewJournalTarget(c.metrics, c.o.Logger, entryHandler, c.positions, c.o.ID, rcs, convertArgs(c.o.ID, newArgs), newArgs.WaitForDelivery)
 	if err != nil {
 		return err
 	}
@@ -141,3 +151,5 @@ func convertArgs(job string, a Arguments) *scrapeconfig.JournalTargetConfig {
 		Matches: a.Matches,
 	}
 }
+
+func (c *Component) LiveDebugging(_ int) {}
@@ -9,13 +9,14 @@ import (
 
 // Arguments are the arguments for the component.
 type Arguments struct {
-	FormatAsJson bool                `alloy:"format_as_json,attr,optional"`
-	MaxAge       time.Duration       `alloy:"max_age,attr,optional"`
-	Path         string              `alloy:"path,attr,optional"`
-	RelabelRules alloy_relabel.Rules `alloy:"relabel_rules,attr,optional"`
-	Matches      string              `alloy:"matches,attr,optional"`
-	Receivers    []loki.LogsReceiver `alloy:"forward_to,attr"`
-	Labels       map[string]string   `alloy:"labels,attr,optional"`
+	FormatAsJson    bool                `alloy:"format_as_json,attr,optional"`
+	MaxAge          time.Duration       `alloy:"max_age,attr,optional"`
+	Path            string              `alloy:"path,attr,optional"`
+	RelabelRules    alloy_relabel.Rules `alloy:"relabel_rules,attr,optional"`
+	Matches         string              `alloy:"matches,attr,optional"`
+	Receivers       []loki.LogsReceiver `alloy:"forward_to,attr"`
+	Labels          map[string]string   `alloy:"labels,attr,optional"`
+	WaitForDelivery bool                `alloy:"wait_for_delivery,attr,optional"`
 }
 
 func defaultArgs() Arguments {
@@ -12,6 +12,7 @@ import (
 	kt "github.com/grafana/alloy/internal/component/loki/source/internal/kafkatarget"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/grafana/alloy/syntax/alloytypes"
 	"github.com/grafana/dskit/flagext"
 	"github.com/prometheus/common/model"
@@ -40,6 +41,7 @@ type Arguments struct {
 	Authentication       KafkaAuthentication `alloy:"authentication,block,optional"`
 	UseIncomingTimestamp bool                `alloy:"use_incoming_timestamp,attr,optional"`
 	Labels               map[string]string   `alloy:"labels,attr,optional"`
+	WaitForDelivery      bool                `alloy:"wait_for_delivery,attr,optional"`
 
 	ForwardTo    []loki.LogsReceiver `alloy:"forward_to,attr"`
 	RelabelRules alloy_relabel.Rules `alloy:"relabel_rules,attr,optional"`
@@ -89,7 +91,8 @@ func (a *Arguments) SetToDefault() {
 
 // Component implements the loki.source.kafka component.
 type Component struct {
-	opts component.Options
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
 
 	mut    sync.RWMutex
 	fanout []loki.LogsReceiver
@@ -98,14 +101,17 @@ type Component struct {
 	handler loki.LogsReceiver
 }
 
+var _ component.LiveDebugging = (*Component)(nil)
+
 // New creates a new loki.source.kafka component.
 func New(o component.Options, args Arguments) (*Component, error) {
 	c := &Component{
-		opts:    o,
-		mut:     sync.RWMutex{},
-		fanout:  args.ForwardTo,
-		target:  nil,
-		handler: loki.NewLogsReceiver(),
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		opts:               o,
+		mut:                sync.RWMutex{},
+		fanout:             args.ForwardTo,
+		target:             nil,
+		handler:            loki.NewLogsReceiver(),
 	}
 
 	// Call to Update() to start readers and set receivers once at the start.
@@ -137,9 +143,12 @@ func (c *Component) Run(ctx context.Context) error {
 			return nil
 		case entry := <-c.handler.Chan():
 			c.mut.RLock()
+			loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, entry)
+			entry.Delivery.Add(len(c.fanout))
 			for _, receiver := range c.fanout {
 				receiver.Chan() <- entry
 			}
+			entry.Delivery.Done(nil)
 			c.mut.RUnlock()
 		}
 	}
@@ -189,6 +198,7 @@ func (args *Arguments) Convert() kt.Config {
 			Version:              args.Version,
 			Assignor:             args.Assignor,
 			Authentication:       args.Authentication.Convert(),
+			WaitForDelivery:      args.WaitForDelivery,
 		},
 		RelabelConfigs: alloy_relabel.ComponentToPromRelabelConfigs(args.RelabelRules),
 	}
@@ -211,3 +221,5 @@ func (auth KafkaAuthentication) Convert() kt.Authentication {
 		},
 	}
 }
+
+func (c *Component) LiveDebugging(_ int) {}
@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/internal/service/cluster"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -59,10 +60,11 @@ func (args *Arguments) SetToDefault() {
 
 // Component implements the loki.source.kubernetes component.
 type Component struct {
-	log       log.Logger
-	opts      component.Options
-	positions positions.Positions
-	cluster   cluster.Cluster
+	log                log.Logger
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
+	positions          positions.Positions
+	cluster            cluster.Cluster
 
 	mut         sync.Mutex
 	args        Arguments
@@ -77,6 +79,7 @@ type Component struct {
 
 var (
 	_ component.Component      = (*Component)(nil)
+	_ component.LiveDebugging  = (*Component)(nil)
 	_ component.DebugComponent = (*Component)(nil)
 	_ cluster.Component        = (*Component)(nil)
 )
@@ -102,11 +105,12 @@ func New(o component.Options, args Arguments) (*Component, error) {
 	}
 
 	c := &Component{
-		cluster:   data.(cluster.Cluster),
-		log:       o.Logger,
-		opts:      o,
-		handler:   loki.NewLogsReceiver(),
-		positions: positionsFile,
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		cluster:            data.(cluster.Cluster),
+		log:                o.Logger,
+		opts:               o,
+		handler:            loki.NewLogsReceiver(),
+		positions:          positionsFile,
 	}
 	if err := c.Update(args); err != nil {
 		return nil, err
@@ -138,6 +142,7 @@ func (c *Component) Run(ctx context.Context) error {
 			receivers := c.receivers
 			c.receiversMut.RUnlock()
 
+			loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, entry)
 			for _, receiver := range receivers {
 				receiver.Chan() <- entry
 			}
@@ -280,3 +285,5 @@ type DebugInfoTarget struct {
 	LastError       string            `alloy:"last_error,attr,optional"`
 	UpdateTime      time.Time         `alloy:"update_time,attr,optional"`
 }
+
+func (c *Component) LiveDebugging(_ int) {}
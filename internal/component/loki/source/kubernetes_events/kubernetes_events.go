@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runner"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/oklog/run"
 	"k8s.io/client-go/rest"
 )
@@ -79,12 +80,13 @@ func (args *Arguments) Validate() error {
 // watches events from Kubernetes and forwards received events to other Loki
 // components.
 type Component struct {
-	log        log.Logger
-	opts       component.Options
-	positions  positions.Positions
-	handler    loki.LogsReceiver
-	runner     *runner.Runner[eventControllerTask]
-	newTasksCh chan struct{}
+	log                log.Logger
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
+	positions          positions.Positions
+	handler            loki.LogsReceiver
+	runner             *runner.Runner[eventControllerTask]
+	newTasksCh         chan struct{}
 
 	mut        sync.Mutex
 	args       Arguments
@@ -99,6 +101,7 @@ type Component struct {
 
 var (
 	_ component.Component      = (*Component)(nil)
+	_ component.LiveDebugging  = (*Component)(nil)
 	_ component.DebugComponent = (*Component)(nil)
 )
 
@@ -117,10 +120,11 @@ func New(o component.Options, args Arguments) (*Component, error) {
 	}
 
 	c := &Component{
-		log:       o.Logger,
-		opts:      o,
-		positions: positionsFile,
-		handler:   loki.NewLogsReceiver(),
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		log:                o.Logger,
+		opts:               o,
+		positions:          positionsFile,
+		handler:            loki.NewLogsReceiver(),
 		runner: runner.New(func(t eventControllerTask) runner.Worker {
 			return newEventController(t)
 		}),
@@ -173,6 +177,7 @@ func (c *Component) Run(ctx context.Context) error {
 				receivers := c.receivers
 				c.receiversMut.RUnlock()
 
+				loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, entry)
 				for _, receiver := range receivers {
 					receiver.Chan() <- entry
 				}
@@ -257,3 +262,5 @@ func (c *Component) DebugInfo() interface{} {
 	}
 	return info
 }
+
+func (c *Component) LiveDebugging(_ int) {}
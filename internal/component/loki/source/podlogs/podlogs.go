@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/internal/service/cluster"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/oklog/run"
 	kubeclient "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -63,8 +64,9 @@ func (args *Arguments) SetToDefault() {
 
 // Component implements the loki.source.podlogs component.
 type Component struct {
-	log  log.Logger
-	opts component.Options
+	log                log.Logger
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
 
 	tailer     *kubetail.Manager
 	reconciler *reconciler
@@ -84,6 +86,7 @@ type Component struct {
 
 var (
 	_ component.Component      = (*Component)(nil)
+	_ component.LiveDebugging  = (*Component)(nil)
 	_ component.DebugComponent = (*Component)(nil)
 	_ cluster.Component        = (*Component)(nil)
 )
@@ -114,8 +117,9 @@ func New(o component.Options, args Arguments) (*Component, error) {
 	)
 
 	c := &Component{
-		log:  o.Logger,
-		opts: o,
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		log:                o.Logger,
+		opts:               o,
 
 		tailer:     tailer,
 		reconciler: reconciler,
@@ -180,6 +184,7 @@ func (c *Component) runHandler(ctx context.Context) {
 			receivers := c.receivers
 			c.receiversMut.RUnlock()
 
+			loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, entry)
 			for _, receiver := range receivers {
 				receiver.Chan() <- entry
 			}
@@ -329,3 +334,5 @@ type DebugInfo struct {
 	DiscoveredPodLogs []DiscoveredPodLogs          `alloy:"pod_logs,block"`
 	Targets           []kubernetes.DebugInfoTarget `alloy:"target,block,optional"`
 }
+
+func (c *Component) LiveDebugging(_ int) {}
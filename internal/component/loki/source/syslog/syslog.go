@@ -11,6 +11,7 @@ import (
 	st "github.com/grafana/alloy/internal/component/loki/source/syslog/internal/syslogtarget"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/prometheus/prometheus/model/relabel"
 )
 
@@ -36,8 +37,9 @@ type Arguments struct {
 
 // Component implements the loki.source.syslog component.
 type Component struct {
-	opts    component.Options
-	metrics *st.Metrics
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
+	metrics            *st.Metrics
 
 	mut     sync.RWMutex
 	args    Arguments
@@ -47,13 +49,16 @@ type Component struct {
 	handler loki.LogsReceiver
 }
 
+var _ component.LiveDebugging = (*Component)(nil)
+
 // New creates a new loki.source.syslog component.
 func New(o component.Options, args Arguments) (*Component, error) {
 	c := &Component{
-		opts:    o,
-		metrics: st.NewMetrics(o.Registerer),
-		handler: loki.NewLogsReceiver(),
-		fanout:  args.ForwardTo,
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		opts:               o,
+		metrics:            st.NewMetrics(o.Registerer),
+		handler:            loki.NewLogsReceiver(),
+		fanout:             args.ForwardTo,
 
 		targets: []*st.SyslogTarget{},
 	}
@@ -84,6 +89,7 @@ func (c *Component) Run(ctx context.Context) error {
 			return nil
 		case entry := <-c.handler.Chan():
 			c.mut.RLock()
+			loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, entry)
 			for _, receiver := range c.fanout {
 				receiver.Chan() <- entry
 			}
@@ -162,3 +168,5 @@ func listenersChanged(prev, next []ListenerConfig) bool {
 func relabelRulesChanged(prev, next alloy_relabel.Rules) bool {
 	return !reflect.DeepEqual(prev, next)
 }
+
+func (c *Component) LiveDebugging(_ int) {}
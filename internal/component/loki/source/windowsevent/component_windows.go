@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/component/common/loki/utils"
 	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 )
 
 func init() {
@@ -28,12 +29,14 @@ func init() {
 }
 
 var (
-	_ component.Component = (*Component)(nil)
+	_ component.Component     = (*Component)(nil)
+	_ component.LiveDebugging = (*Component)(nil)
 )
 
 // Component implements the loki.source.windowsevent component.
 type Component struct {
-	opts component.Options
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
 
 	mut       sync.RWMutex
 	args      Arguments
@@ -58,10 +61,11 @@ func (h *handler) Stop() {
 func New(o component.Options, args Arguments) (*Component, error) {
 
 	c := &Component{
-		opts:      o,
-		receivers: args.ForwardTo,
-		handle:    &handler{handler: make(chan api.Entry)},
-		args:      args,
+		debugDataPublisher: livedebugging.GetPublisher(o.GetServiceData),
+		opts:               o,
+		receivers:          args.ForwardTo,
+		handle:             &handler{handler: make(chan api.Entry)},
+		args:               args,
 	}
 
 	// Call to Update() to start readers and set receivers once at the start.
@@ -90,6 +94,7 @@ func (c *Component) Run(ctx context.Context) error {
 				Labels: entry.Labels,
 				Entry:  entry.Entry,
 			}
+			loki.PublishDebugEvent(c.debugDataPublisher, c.opts.ID, lokiEntry)
 			for _, receiver := range c.receivers {
 				receiver.Chan() <- lokiEntry
 			}
@@ -177,3 +182,5 @@ func convertConfig(arg Arguments) *scrapeconfig.WindowsEventsTargetConfig {
 		Labels:               utils.ToLabelSet(arg.Labels),
 	}
 }
+
+func (c *Component) LiveDebugging(_ int) {}
@@ -137,6 +137,7 @@ func (c *Component) Run(ctx context.Context) error {
 			select {
 			case <-ctx.Done():
 				c.mut.RUnlock()
+				entry.Delivery.Done(loki.ErrStopped)
 				return nil
 			case c.sink.Chan() <- entry:
 			}
@@ -502,7 +502,9 @@ type leadership interface {
 }
 
 // componentLeadership implements leadership based on checking ownership of a specific
-// key using a cluster.Cluster service.
+// key using a cluster.Cluster service. It doesn't use the leader election of
+// the cluster, whose keys differ, so that peers running older versions agree
+// on the leader during a rolling upgrade.
 type componentLeadership struct {
 	id      string
 	logger  log.Logger
@@ -17,6 +17,7 @@ import (
 
 	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/service/cluster"
+	"github.com/grafana/alloy/internal/service/cluster/crdt"
 	"github.com/grafana/alloy/syntax"
 )
 
@@ -58,6 +59,18 @@ func (f fakeCluster) Peers() []peer.Peer {
 	return nil
 }
 
+func (f fakeCluster) Leader(name string) (peer.Peer, error) {
+	return cluster.LeaderOf(f, name)
+}
+
+func (f fakeCluster) Changed() <-chan struct{} {
+	return nil
+}
+
+func (f fakeCluster) State() *crdt.Store {
+	return nil
+}
+
 type fakeLeadership struct {
 	leader    bool
 	changed   bool
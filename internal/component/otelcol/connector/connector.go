@@ -14,7 +14,9 @@ import (
 	"github.com/grafana/alloy/internal/component/otelcol/internal/fanoutconsumer"
 	"github.com/grafana/alloy/internal/component/otelcol/internal/lazycollector"
 	"github.com/grafana/alloy/internal/component/otelcol/internal/lazyconsumer"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/livedebuggingconsumer"
 	"github.com/grafana/alloy/internal/component/otelcol/internal/scheduler"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/grafana/alloy/internal/util/zapadapter"
 	"github.com/prometheus/client_golang/prometheus"
 	otelcomponent "go.opentelemetry.io/collector/component"
@@ -74,11 +76,17 @@ type Connector struct {
 
 	sched     *scheduler.Scheduler
 	collector *lazycollector.Collector
+
+	liveDebuggingConsumer *livedebuggingconsumer.Consumer
+	debugDataPublisher    livedebugging.DebugDataPublisher
+
+	args Arguments
 }
 
 var (
 	_ component.Component       = (*Connector)(nil)
 	_ component.HealthComponent = (*Connector)(nil)
+	_ component.LiveDebugging   = (*Connector)(nil)
 )
 
 // New creates a new Alloy component which encapsulates an OpenTelemetry
@@ -88,6 +96,11 @@ var (
 // The registered component must be registered to export the
 // otelcol.ConsumerExports type, otherwise New will panic.
 func New(opts component.Options, f otelconnector.Factory, args Arguments) (*Connector, error) {
+	debugDataPublisher, err := opts.GetServiceData(livedebugging.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	consumer := lazyconsumer.New(ctx)
@@ -114,6 +127,9 @@ func New(opts component.Options, f otelconnector.Factory, args Arguments) (*Conn
 
 		sched:     scheduler.New(opts.Logger),
 		collector: collector,
+
+		liveDebuggingConsumer: livedebuggingconsumer.New(debugDataPublisher.(livedebugging.DebugDataPublisher), opts.ID),
+		debugDataPublisher:    debugDataPublisher.(livedebugging.DebugDataPublisher),
 	}
 	if err := p.Update(args); err != nil {
 		return nil, err
@@ -132,6 +148,7 @@ func (p *Connector) Run(ctx context.Context) error {
 // the underlying OpenTelemetry Collector connector.
 func (p *Connector) Update(args component.Arguments) error {
 	pargs := args.(Arguments)
+	p.args = pargs
 
 	host := scheduler.NewHost(
 		p.opts.Logger,
@@ -192,7 +209,11 @@ func (p *Connector) Update(args component.Arguments) error {
 		}
 
 		if len(next.Metrics) > 0 {
-			nextMetrics := fanoutconsumer.Metrics(next.Metrics)
+			metrics := next.Metrics
+			if p.debugDataPublisher.IsActive(livedebugging.ComponentID(p.opts.ID)) {
+				metrics = append(metrics, p.liveDebuggingConsumer)
+			}
+			nextMetrics := fanoutconsumer.Metrics(metrics)
 			tracesConnector, err = p.factory.CreateTracesToMetrics(p.ctx, settings, connectorConfig, nextMetrics)
 			if err != nil && !errors.Is(err, otelcomponent.ErrDataTypeIsNotSupported) {
 				return err
@@ -214,3 +235,7 @@ func (p *Connector) Update(args component.Arguments) error {
 func (p *Connector) CurrentHealth() component.Health {
 	return p.sched.CurrentHealth()
 }
+
+func (p *Connector) LiveDebugging(_ int) {
+	p.Update(p.args)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/alloy/internal/component/otelcol"
 	"github.com/grafana/alloy/internal/service/livedebugging"
 	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
@@ -42,8 +43,15 @@ func (c *Consumer) Capabilities() otelconsumer.Capabilities {
 // ConsumeTraces implements otelcol.ConsumeTraces.
 func (c *Consumer) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
 	if c.debugDataPublisher.IsActive(c.componentID) {
-		data, _ := c.tracesMarshaler.MarshalTraces(td)
-		c.debugDataPublisher.Publish(c.componentID, string(data))
+		// Publish an event per resource so that events can be filtered by
+		// resource attributes.
+		for i := 0; i < td.ResourceSpans().Len(); i++ {
+			rs := td.ResourceSpans().At(i)
+			single := ptrace.NewTraces()
+			rs.CopyTo(single.ResourceSpans().AppendEmpty())
+			data, _ := c.tracesMarshaler.MarshalTraces(single)
+			c.publish(livedebugging.DataTypeTraces, rs.Resource().Attributes(), data)
+		}
 	}
 	return nil
 }
@@ -51,8 +59,13 @@ func (c *Consumer) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
 // ConsumeMetrics implements otelcol.ConsumeMetrics.
 func (c *Consumer) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
 	if c.debugDataPublisher.IsActive(c.componentID) {
-		data, _ := c.metricsMarshaler.MarshalMetrics(md)
-		c.debugDataPublisher.Publish(c.componentID, string(data))
+		for i := 0; i < md.ResourceMetrics().Len(); i++ {
+			rm := md.ResourceMetrics().At(i)
+			single := pmetric.NewMetrics()
+			rm.CopyTo(single.ResourceMetrics().AppendEmpty())
+			data, _ := c.metricsMarshaler.MarshalMetrics(single)
+			c.publish(livedebugging.DataTypeMetrics, rm.Resource().Attributes(), data)
+		}
 	}
 	return nil
 }
@@ -60,8 +73,26 @@ func (c *Consumer) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error
 // ConsumeLogs implements otelcol.ConsumeLogs.
 func (c *Consumer) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
 	if c.debugDataPublisher.IsActive(c.componentID) {
-		data, _ := c.logsMarshaler.MarshalLogs(ld)
-		c.debugDataPublisher.Publish(c.componentID, string(data))
+		for i := 0; i < ld.ResourceLogs().Len(); i++ {
+			rl := ld.ResourceLogs().At(i)
+			single := plog.NewLogs()
+			rl.CopyTo(single.ResourceLogs().AppendEmpty())
+			data, _ := c.logsMarshaler.MarshalLogs(single)
+			c.publish(livedebugging.DataTypeLogs, rl.Resource().Attributes(), data)
+		}
 	}
 	return nil
 }
+
+func (c *Consumer) publish(dataType livedebugging.DataType, attrs pcommon.Map, data []byte) {
+	labels := make(map[string]string, attrs.Len())
+	attrs.Range(func(k string, v pcommon.Value) bool {
+		labels[k] = v.AsString()
+		return true
+	})
+	c.debugDataPublisher.Publish(c.componentID, livedebugging.Event{
+		Type:   dataType,
+		Labels: labels,
+		Body:   string(data),
+	})
+}
@@ -110,6 +110,7 @@ func (c *Component) Run(ctx context.Context) error {
 			if err != nil {
 				level.Error(c.opts.Logger).Log("msg", "failed to consume log entries", "err", err)
 			}
+			entry.Delivery.Done(err)
 		}
 	}
 }
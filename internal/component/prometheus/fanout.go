@@ -2,6 +2,8 @@ package prometheus
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -15,6 +17,7 @@ import (
 	"github.com/prometheus/prometheus/storage"
 
 	"github.com/grafana/alloy/internal/service/labelstore"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 )
 
 var _ storage.Appendable = (*Fanout)(nil)
@@ -29,6 +32,11 @@ type Fanout struct {
 	writeLatency   prometheus.Histogram
 	samplesCounter prometheus.Counter
 	ls             labelstore.LabelStore
+
+	// debugDataPublisher publishes the samples appended to the fanout to live
+	// debugging consumers. It's nil for components which publish their own
+	// debugging data.
+	debugDataPublisher livedebugging.DebugDataPublisher
 }
 
 // NewFanout creates a fanout appendable.
@@ -62,6 +70,14 @@ func (f *Fanout) UpdateChildren(children []storage.Appendable) {
 	f.children = children
 }
 
+// SetDebugDataPublisher publishes the samples appended to the fanout to the
+// live debugging consumers of the component.
+func (f *Fanout) SetDebugDataPublisher(publisher livedebugging.DebugDataPublisher) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	f.debugDataPublisher = publisher
+}
+
 // Appender satisfies the Appendable interface.
 func (f *Fanout) Appender(ctx context.Context) storage.Appender {
 	f.mut.RLock()
@@ -83,6 +99,11 @@ func (f *Fanout) Appender(ctx context.Context) storage.Appender {
 		ls:                f.ls,
 		stalenessTrackers: make([]labelstore.StalenessTracker, 0),
 	}
+	// Whether live debugging is active is only checked once per appender to
+	// keep it cheap to append samples.
+	if f.debugDataPublisher != nil && f.debugDataPublisher.IsActive(livedebugging.ComponentID(f.componentID)) {
+		app.debugDataPublisher = f.debugDataPublisher
+	}
 
 	for _, x := range f.children {
 		if x == nil {
@@ -101,6 +122,8 @@ type appender struct {
 	start             time.Time
 	ls                labelstore.LabelStore
 	stalenessTrackers []labelstore.StalenessTracker
+
+	debugDataPublisher livedebugging.DebugDataPublisher // Nil when live debugging isn't active.
 }
 
 var _ storage.Appender = (*appender)(nil)
@@ -131,9 +154,23 @@ func (a *appender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v flo
 	if updated {
 		a.samplesCounter.Inc()
 	}
+	a.publishDebugData(l, t, strconv.FormatFloat(v, 'g', -1, 64))
 	return ref, multiErr
 }
 
+// publishDebugData publishes a sample to the live debugging consumers.
+func (a *appender) publishDebugData(l labels.Labels, t int64, value string) {
+	if a.debugDataPublisher == nil {
+		return
+	}
+	a.debugDataPublisher.Publish(livedebugging.ComponentID(a.componentID), livedebugging.Event{
+		Type:      livedebugging.DataTypeMetrics,
+		Timestamp: time.UnixMilli(t),
+		Labels:    l.Map(),
+		Body:      fmt.Sprintf("%s %s", l.String(), value),
+	})
+}
+
 // Commit satisfies the Appender interface.
 func (a *appender) Commit() error {
 	defer a.recordLatency()
@@ -220,6 +257,11 @@ func (a *appender) AppendHistogram(ref storage.SeriesRef, l labels.Labels, t int
 			multiErr = multierror.Append(multiErr, err)
 		}
 	}
+	if h != nil {
+		a.publishDebugData(l, t, h.String())
+	} else if fh != nil {
+		a.publishDebugData(l, t, fh.String())
+	}
 	return ref, multiErr
 }
 
@@ -16,6 +16,8 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+var _ component.LiveDebugging = (*Component)(nil)
+
 type Component struct {
 	mut     sync.RWMutex
 	config  *operator.Arguments
@@ -189,3 +191,5 @@ func (c *Component) Handler() http.Handler {
 		}
 	})
 }
+
+func (c *Component) LiveDebugging(_ int) {}
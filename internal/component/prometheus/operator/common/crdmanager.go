@@ -34,6 +34,7 @@ import (
 	"github.com/grafana/alloy/internal/service/cluster"
 	"github.com/grafana/alloy/internal/service/http"
 	"github.com/grafana/alloy/internal/service/labelstore"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/grafana/alloy/internal/util"
 )
 
@@ -125,6 +126,7 @@ func (c *crdManager) Run(ctx context.Context) error {
 
 	// Start prometheus scrape manager.
 	alloyAppendable := prometheus.NewFanout(c.args.ForwardTo, c.opts.ID, c.opts.Registerer, c.ls)
+	alloyAppendable.SetDebugDataPublisher(livedebugging.GetPublisher(c.opts.GetServiceData))
 	opts := &scrape.Options{}
 	c.scrapeManager, err = scrape.NewManager(opts, c.logger, alloyAppendable, unregisterer)
 	if err != nil {
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/internal/service/labelstore"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/grafana/alloy/internal/util"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/storage"
@@ -44,6 +45,8 @@ func (args *Arguments) SetToDefault() {
 	}
 }
 
+var _ component.LiveDebugging = (*Component)(nil)
+
 type Component struct {
 	opts               component.Options
 	handler            http.Handler
@@ -62,6 +65,7 @@ func New(opts component.Options, args Arguments) (*Component, error) {
 	}
 	ls := service.(labelstore.LabelStore)
 	fanout := alloyprom.NewFanout(args.ForwardTo, opts.ID, opts.Registerer, ls)
+	fanout.SetDebugDataPublisher(livedebugging.GetPublisher(opts.GetServiceData))
 
 	uncheckedCollector := util.NewUncheckedCollector(nil)
 	opts.Registerer.MustRegister(uncheckedCollector)
@@ -153,3 +157,5 @@ func (c *Component) shutdownServer() {
 		c.server = nil
 	}
 }
+
+func (c *Component) LiveDebugging(_ int) {}
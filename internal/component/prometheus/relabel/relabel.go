@@ -275,7 +275,11 @@ func (c *Component) relabel(val float64, lbls labels.Labels) labels.Labels {
 
 	componentID := livedebugging.ComponentID(c.opts.ID)
 	if c.debugDataPublisher.IsActive(componentID) {
-		c.debugDataPublisher.Publish(componentID, fmt.Sprintf("%s => %s", lbls.String(), relabelled.String()))
+		c.debugDataPublisher.Publish(componentID, livedebugging.Event{
+			Type:   livedebugging.DataTypeMetrics,
+			Labels: lbls.Map(),
+			Body:   fmt.Sprintf("%s => %s", lbls.String(), relabelled.String()),
+		})
 	}
 
 	return relabelled
@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/component/discovery"
 	"github.com/grafana/alloy/internal/service/cluster"
+	"github.com/grafana/alloy/internal/service/cluster/crdt"
 	"github.com/grafana/alloy/internal/service/http"
 	"github.com/grafana/alloy/internal/service/labelstore"
 	"github.com/grafana/alloy/internal/util"
@@ -371,3 +372,15 @@ func (f *fakeCluster) Lookup(key shard.Key, _ int, _ shard.Op) ([]peer.Peer, err
 func (f *fakeCluster) Peers() []peer.Peer {
 	return f.peers
 }
+
+func (f *fakeCluster) Leader(name string) (peer.Peer, error) {
+	return cluster.LeaderOf(f, name)
+}
+
+func (f *fakeCluster) Changed() <-chan struct{} {
+	return nil
+}
+
+func (f *fakeCluster) State() *crdt.Store {
+	return nil
+}
@@ -77,6 +77,7 @@ func (s *ScrapeConfigBuilder) AppendLokiSourceFile(watchConfig *file.WatchConfig
 		Encoding:            s.cfg.Encoding,
 		DecompressionConfig: convertDecompressionConfig(s.cfg.DecompressionCfg),
 		FileWatch:           convertFileWatchConfig(watchConfig),
+		FingerprintConfig:   lokisourcefile.DefaultFingerprintConfig,
 		LegacyPositionsFile: positionsCfg.PositionsFile,
 	}
 	overrideHook := func(val interface{}) interface{} {
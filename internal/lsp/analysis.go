@@ -0,0 +1,432 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/grafana/alloy/internal/component"
+	alloy_runtime "github.com/grafana/alloy/internal/runtime"
+	"github.com/grafana/alloy/internal/runtime/logging"
+	"github.com/grafana/alloy/internal/runtime/tracing"
+	"github.com/grafana/alloy/syntax/ast"
+	"github.com/grafana/alloy/syntax/schema"
+)
+
+// Names of the blocks handled by the runtime rather than by components.
+const (
+	blockArgument = "argument"
+	blockDeclare  = "declare"
+	blockExport   = "export"
+	blockForeach  = "foreach"
+	blockFunction = "function"
+	blockLogging  = "logging"
+	blockTemplate = "template"
+	blockTracing  = "tracing"
+	importPrefix  = "import."
+)
+
+// configBlocks are the blocks which can be used in addition to components.
+var configBlocks = []string{
+	blockArgument, blockDeclare, blockExport, blockForeach, blockFunction,
+	"import.file", "import.git", "import.http", "import.string",
+	blockLogging, blockTracing,
+}
+
+// configBlockFields holds the fields of the config blocks which are decoded
+// into a Go type.
+var configBlockFields = map[string][]schema.Field{
+	blockLogging: schema.Fields(reflect.TypeOf(logging.Options{})),
+	blockTracing: schema.Fields(reflect.TypeOf(tracing.Options{})),
+}
+
+// componentFields holds the fields which every component accepts in addition
+// to its arguments.
+var componentFields = schema.Fields(reflect.TypeOf(struct {
+	Restart    alloy_runtime.RestartArguments    `alloy:"restart,block,optional"`
+	Clustering alloy_runtime.ClusteringArguments `alloy:"clustering,block,optional"`
+}{}))
+
+// argumentsFields returns the fields of the arguments of a component along
+// with componentFields. The fields of blocks defined by both are merged, such
+// as the clustering block of components which support clustering.
+func argumentsFields(args any) []schema.Field {
+	fields := schema.Fields(reflect.TypeOf(args))
+	for _, extra := range componentFields {
+		i := slices.IndexFunc(fields, func(f schema.Field) bool { return f.Name == extra.Name })
+		if i == -1 {
+			fields = append(fields, extra)
+			continue
+		}
+		if fields[i].Kind == schema.KindBlock && extra.Kind == schema.KindBlock {
+			fields[i].Fields = append(slices.Clone(fields[i].Fields), extra.Fields...)
+		}
+	}
+	return fields
+}
+
+// definition is a block defined in a document.
+type definition struct {
+	doc   *document
+	block *ast.BlockStmt
+}
+
+// location returns the location of the name and label of the block.
+func (def definition) location() Location {
+	end := ast.StartPos(def.block).Offset() + len(blockName(def.block))
+	if def.block.LabelPos.Valid() {
+		end = def.block.LabelPos.Offset() + len(def.block.Label) + 2 // Include the quotes of the label.
+	}
+	return Location{
+		URI:   def.doc.uri,
+		Range: def.doc.rangeOf(ast.StartPos(def.block).Offset(), end),
+	}
+}
+
+// scope holds the definitions visible from a body: the top level of a
+// configuration or the body of a declare block.
+type scope struct {
+	components map[string]definition // Labeled blocks by ID, such as "local.file.example".
+	declares   map[string]definition // Declare blocks by label.
+	imports    map[string]definition // Import blocks by label.
+	arguments  map[string]definition // Argument blocks by label.
+	topLevel   bool
+}
+
+func newScope(parent *scope) *scope {
+	s := &scope{
+		components: make(map[string]definition),
+		declares:   make(map[string]definition),
+		imports:    make(map[string]definition),
+		arguments:  make(map[string]definition),
+		topLevel:   parent == nil,
+	}
+	if parent != nil {
+		// Modules defined in a parent scope can be used from a declare block, but
+		// its components and arguments can't be referenced.
+		for label, def := range parent.declares {
+			s.declares[label] = def
+		}
+		for label, def := range parent.imports {
+			s.imports[label] = def
+		}
+	}
+	return s
+}
+
+// add adds the blocks of body to the scope.
+func (s *scope) add(doc *document, body ast.Body) {
+	for _, stmt := range body {
+		block, ok := stmt.(*ast.BlockStmt)
+		if !ok || block.Label == "" {
+			continue
+		}
+		def := definition{doc: doc, block: block}
+
+		name := blockName(block)
+		switch {
+		case name == blockDeclare:
+			s.declares[block.Label] = def
+		case strings.HasPrefix(name, importPrefix):
+			s.imports[block.Label] = def
+		case name == blockArgument:
+			s.arguments[block.Label] = def
+		case name == blockExport || name == blockForeach || name == blockFunction:
+			// These blocks can't be referenced.
+		default:
+			s.components[name+"."+block.Label] = def
+		}
+	}
+}
+
+// resolve returns the component referenced by a traversal such as
+// "prometheus.remote_write.default.receiver", along with the remaining names
+// of the traversal.
+func (s *scope) resolve(names []string) (definition, []string, bool) {
+	for i := len(names); i > 0; i-- {
+		if def, ok := s.components[strings.Join(names[:i], ".")]; ok {
+			return def, names[i:], true
+		}
+	}
+	return definition{}, nil, false
+}
+
+// sortedIDs returns the IDs of the components of the scope in order.
+func (s *scope) sortedIDs() []string {
+	ids := make([]string, 0, len(s.components))
+	for id := range s.components {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// blockPath returns the blocks of the document which contain offset, from the
+// outermost to the innermost.
+func blockPath(body ast.Body, offset int) []*ast.BlockStmt {
+	for _, stmt := range body {
+		block, ok := stmt.(*ast.BlockStmt)
+		if !ok || offset < ast.StartPos(block).Offset() || offset > ast.EndPos(block).Offset() {
+			continue
+		}
+		return append([]*ast.BlockStmt{block}, blockPath(block.Body, offset)...)
+	}
+	return nil
+}
+
+// scopeAt returns the scope visible from offset in doc.
+func (s *Server) scopeAt(doc *document, offset int) *scope {
+	root := newScope(nil)
+	for _, d := range s.workspace(doc) {
+		if d.file != nil {
+			root.add(d, d.file.Body)
+		}
+	}
+	if doc.file == nil {
+		return root
+	}
+
+	sc := root
+	for _, block := range blockPath(doc.file.Body, offset) {
+		if blockName(block) == blockDeclare {
+			sc = newScope(sc)
+			sc.add(doc, block.Body)
+		}
+	}
+	return sc
+}
+
+// workspace returns doc and the documents of the configuration files in the
+// same directory, which are loaded together by Alloy.
+func (s *Server) workspace(doc *document) []*document {
+	docs := []*document{doc}
+	if doc.path == doc.uri {
+		// Not a file on disk.
+		return docs
+	}
+
+	paths, _ := filepath.Glob(filepath.Join(filepath.Dir(doc.path), "*.alloy"))
+	for _, path := range paths {
+		if path == doc.path {
+			continue
+		}
+		uri := pathToURI(path)
+		if open, ok := s.docs[uri]; ok {
+			docs = append(docs, open)
+			continue
+		}
+		bb, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		docs = append(docs, newDocument(uri, string(bb)))
+	}
+	return docs
+}
+
+// blockFields returns the fields of the block named name, if the block is
+// known to the server.
+func (s *Server) blockFields(name string, sc *scope) ([]schema.Field, bool) {
+	if reg, ok := component.Get(name); ok {
+		return argumentsFields(reg.Args), true
+	}
+	if fields, ok := configBlockFields[name]; ok {
+		return fields, true
+	}
+	if sc.topLevel {
+		if def, ok := s.services[name]; ok && def.ConfigType != nil {
+			return schema.Fields(reflect.TypeOf(def.ConfigType)), true
+		}
+	}
+	if def, ok := sc.declares[name]; ok {
+		return declareFields(def.block), true
+	}
+	return nil, false
+}
+
+// declareFields returns the arguments of a declare block as attributes.
+func declareFields(block *ast.BlockStmt) []schema.Field {
+	var fields []schema.Field
+	for _, stmt := range block.Body {
+		arg, ok := stmt.(*ast.BlockStmt)
+		if !ok || blockName(arg) != blockArgument {
+			continue
+		}
+		fields = append(fields, schema.Field{
+			Name:     arg.Label,
+			Kind:     schema.KindAttr,
+			Optional: attrIsTrue(arg.Body, "optional"),
+		})
+	}
+	return fields
+}
+
+// exportFields returns the exports of the component or custom component
+// named name.
+func (s *Server) exportFields(name string, sc *scope) []schema.Field {
+	if reg, ok := component.Get(name); ok {
+		if reg.Exports == nil {
+			return nil
+		}
+		return schema.Fields(reflect.TypeOf(reg.Exports))
+	}
+	if decl, ok := sc.declares[name]; ok {
+		var fields []schema.Field
+		for _, stmt := range decl.block.Body {
+			if export, ok := stmt.(*ast.BlockStmt); ok && blockName(export) == blockExport {
+				fields = append(fields, schema.Field{Name: export.Label, Kind: schema.KindAttr})
+			}
+		}
+		return fields
+	}
+	return nil
+}
+
+// checkBody reports errors for the blocks of body which don't match the
+// components or modules they're using.
+func (s *Server) checkBody(doc *document, body ast.Body, sc *scope) []Diagnostic {
+	var diags []Diagnostic
+	errorf := func(n ast.Node, format string, args ...any) {
+		diags = append(diags, Diagnostic{
+			Range:    doc.nodeRange(n),
+			Severity: SeverityError,
+			Source:   "alloy",
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	for _, stmt := range body {
+		block, ok := stmt.(*ast.BlockStmt)
+		if !ok {
+			continue
+		}
+		name := blockName(block)
+		nameNode := &ast.Ident{Name: name, NamePos: block.NamePos}
+
+		switch {
+		case name == blockDeclare:
+			inner := newScope(sc)
+			inner.add(doc, block.Body)
+			diags = append(diags, s.checkBody(doc, block.Body, inner)...)
+			continue
+
+		case name == blockForeach:
+			for _, stmt := range block.Body {
+				if template, ok := stmt.(*ast.BlockStmt); ok && blockName(template) == blockTemplate {
+					diags = append(diags, s.checkBody(doc, template.Body, sc)...)
+				}
+			}
+			continue
+
+		case sc.imports[block.Name[0]].block != nil:
+			// Components of imported modules aren't known.
+			continue
+		}
+
+		fields, known := s.blockFields(name, sc)
+		switch {
+		case known:
+			diags = append(diags, checkFields(doc, block, fields)...)
+		case isConfigBlock(name):
+			// Config blocks without a fixed schema.
+		default:
+			errorf(nameNode, "cannot find the definition of component name %q", name)
+		}
+	}
+	return diags
+}
+
+// checkFields reports attributes and blocks of block which don't match
+// fields, as well as missing required fields.
+func checkFields(doc *document, block *ast.BlockStmt, fields []schema.Field) []Diagnostic {
+	var diags []Diagnostic
+	errorf := func(n ast.Node, format string, args ...any) {
+		diags = append(diags, Diagnostic{
+			Range:    doc.nodeRange(n),
+			Severity: SeverityError,
+			Source:   "alloy",
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	set := make(map[string]struct{})
+	for _, stmt := range block.Body {
+		switch stmt := stmt.(type) {
+		case *ast.AttributeStmt:
+			f, ok := findField(fields, stmt.Name.Name)
+			if !ok || f.Kind != schema.KindAttr {
+				errorf(stmt.Name, "unrecognized attribute name %q", stmt.Name.Name)
+				continue
+			}
+			set[f.Name] = struct{}{}
+
+		case *ast.BlockStmt:
+			name := blockName(stmt)
+			f, ok := findField(fields, name)
+			if !ok || f.Kind == schema.KindAttr {
+				errorf(&ast.Ident{Name: name, NamePos: stmt.NamePos}, "unrecognized block name %q", name)
+				continue
+			}
+			set[f.Name] = struct{}{}
+			diags = append(diags, checkFields(doc, stmt, f.Fields)...)
+		}
+	}
+
+	for _, f := range fields {
+		if _, ok := set[f.Name]; ok || f.Optional {
+			continue
+		}
+		switch f.Kind {
+		case schema.KindAttr:
+			errorf(&ast.Ident{Name: blockName(block), NamePos: block.NamePos}, "missing required attribute %q", f.Name)
+		case schema.KindBlock:
+			errorf(&ast.Ident{Name: blockName(block), NamePos: block.NamePos}, "missing required block %q", f.Name)
+		}
+	}
+	return diags
+}
+
+// findField returns the field named name. Blocks of an enum are found by
+// their full name, such as "stage.drop"; the field of the enum is returned
+// with the fields of the block.
+func findField(fields []schema.Field, name string) (schema.Field, bool) {
+	for _, f := range fields {
+		if f.Name == name {
+			return f, true
+		}
+		if f.Kind == schema.KindEnum && strings.HasPrefix(name, f.Name+".") {
+			if inner, ok := findField(f.Fields, strings.TrimPrefix(name, f.Name+".")); ok {
+				return schema.Field{Name: f.Name, Kind: schema.KindEnum, Optional: f.Optional, Type: inner.Type, Fields: inner.Fields}, true
+			}
+		}
+	}
+	return schema.Field{}, false
+}
+
+func isConfigBlock(name string) bool {
+	for _, b := range configBlocks {
+		if b == name {
+			return true
+		}
+	}
+	return false
+}
+
+func blockName(b *ast.BlockStmt) string { return strings.Join(b.Name, ".") }
+
+// attrIsTrue returns whether body sets the attribute name to true.
+func attrIsTrue(body ast.Body, name string) bool {
+	for _, stmt := range body {
+		attr, ok := stmt.(*ast.AttributeStmt)
+		if !ok || attr.Name.Name != name {
+			continue
+		}
+		lit, ok := attr.Value.(*ast.LiteralExpr)
+		return ok && lit.Value == "true"
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package lsp
+
+import (
+	"errors"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/grafana/alloy/syntax/ast"
+	"github.com/grafana/alloy/syntax/diag"
+	"github.com/grafana/alloy/syntax/parser"
+	"github.com/grafana/alloy/syntax/token"
+)
+
+// document is a configuration file known to the server.
+type document struct {
+	uri  string
+	path string // Path of the document on disk, if the URI is a file URI.
+	text string
+
+	file       *ast.File        // Last successfully parsed content, nil if the document never parsed.
+	parseDiags diag.Diagnostics // Errors of the last parse.
+}
+
+// newDocument creates a document and parses its text.
+func newDocument(uri, text string) *document {
+	d := &document{uri: uri, path: uriToPath(uri)}
+	d.update(text)
+	return d
+}
+
+// update replaces the text of the document. The parsed content of the
+// document is kept if text can't be parsed, so that it can still be used to
+// resolve definitions while the document is being edited.
+func (d *document) update(text string) {
+	d.text = text
+
+	f, err := parser.ParseFile(d.path, []byte(text))
+	if err != nil {
+		d.parseDiags = nil
+		if !errors.As(err, &d.parseDiags) {
+			d.parseDiags = diag.Diagnostics{{Severity: diag.SeverityLevelError, Message: err.Error()}}
+		}
+		return
+	}
+	d.file = f
+	d.parseDiags = nil
+}
+
+// offset converts pos into a byte offset of the text. Positions past the end
+// of a line or of the text are clamped.
+func (d *document) offset(pos Position) int {
+	lineStart := 0
+	for line := 0; line < pos.Line; line++ {
+		next := strings.IndexByte(d.text[lineStart:], '\n')
+		if next < 0 {
+			return len(d.text)
+		}
+		lineStart += next + 1
+	}
+
+	off := lineStart
+	for units := 0; units < pos.Character && off < len(d.text) && d.text[off] != '\n'; {
+		r, size := utf8.DecodeRuneInString(d.text[off:])
+		units += utf16Len(r)
+		off += size
+	}
+	return off
+}
+
+// position converts a byte offset of the text into a Position.
+func (d *document) position(offset int) Position {
+	offset = min(max(offset, 0), len(d.text))
+
+	lineStart := strings.LastIndexByte(d.text[:offset], '\n') + 1
+	pos := Position{Line: strings.Count(d.text[:lineStart], "\n")}
+	for _, r := range d.text[lineStart:offset] {
+		pos.Character += utf16Len(r)
+	}
+	return pos
+}
+
+// rangeOf returns the range between two byte offsets of the text.
+func (d *document) rangeOf(start, end int) Range {
+	return Range{Start: d.position(start), End: d.position(end)}
+}
+
+// nodeRange returns the range covered by an AST node.
+func (d *document) nodeRange(n ast.Node) Range {
+	return d.rangeOf(ast.StartPos(n).Offset(), ast.EndPos(n).Offset()+1)
+}
+
+// diagRange returns the range covered by a diagnostic.
+func (d *document) diagRange(diag diag.Diagnostic) Range {
+	start, end := diag.StartPos.Offset, diag.EndPos.Offset+1
+	if diag.EndPos == (token.Position{}) || end <= start {
+		end = start + 1
+	}
+	return d.rangeOf(start, end)
+}
+
+// wordAt returns the byte offsets of the dotted identifier, such as
+// "prometheus.remote_write.default.receiver", which surrounds offset.
+func (d *document) wordAt(offset int) (start, end int) {
+	start, end = offset, offset
+	for start > 0 && isWordChar(d.text[start-1]) {
+		start--
+	}
+	for end < len(d.text) && isWordChar(d.text[end]) {
+		end++
+	}
+	return start, end
+}
+
+// utf16Len returns the number of UTF-16 code units needed to encode r.
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+func isWordChar(ch byte) bool {
+	return ch == '_' || ch == '.' || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9')
+}
+
+// uriToPath returns the path of a file URI, or the URI itself for other
+// schemes.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// pathToURI returns the file URI of path.
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,477 @@
+package lsp
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/syntax/ast"
+	"github.com/grafana/alloy/syntax/parser"
+	"github.com/grafana/alloy/syntax/printer"
+	"github.com/grafana/alloy/syntax/scanner"
+	"github.com/grafana/alloy/syntax/schema"
+	"github.com/grafana/alloy/syntax/token"
+)
+
+// diagnostics returns the errors of doc: syntax errors if the document
+// can't be parsed, or blocks which don't match the components and modules
+// they're using otherwise.
+func (s *Server) diagnostics(doc *document) []Diagnostic {
+	res := []Diagnostic{}
+	if len(doc.parseDiags) > 0 {
+		for _, d := range doc.parseDiags {
+			res = append(res, Diagnostic{
+				Range:    doc.diagRange(d),
+				Severity: SeverityError,
+				Source:   "alloy",
+				Message:  d.Message,
+			})
+		}
+		return res
+	}
+	return append(res, s.checkBody(doc, doc.file.Body, s.scopeAt(doc, -1))...)
+}
+
+// completionContext describes where a completion is requested.
+type completionContext struct {
+	blocks []string // Names of the blocks containing the position, outermost first.
+	expr   bool     // Whether the position is within an expression.
+}
+
+// contextAt returns the completion context at the end of text. text doesn't
+// need to be valid; only its tokens are used.
+func contextAt(text string) completionContext {
+	type frame struct {
+		block string
+		expr  bool
+	}
+
+	var (
+		frames   []frame
+		stmt     []scannedToken // Tokens of the current statement of a block body.
+		assigned bool           // Whether the current statement is an attribute.
+	)
+	reset := func() {
+		stmt = stmt[:0]
+		assigned = false
+	}
+
+	s := scanner.New(token.NewFile(""), []byte(text), nil, 0)
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		inExpr := assigned || (len(frames) > 0 && frames[len(frames)-1].expr)
+
+		switch tok {
+		case token.LCURLY:
+			if name, ok := headerName(stmt); ok && !inExpr {
+				frames = append(frames, frame{block: name})
+				reset()
+				continue
+			}
+			frames = append(frames, frame{expr: true})
+		case token.LBRACK, token.LPAREN:
+			frames = append(frames, frame{expr: true})
+		case token.RCURLY, token.RBRACK, token.RPAREN:
+			if len(frames) == 0 {
+				continue
+			}
+			closed := frames[len(frames)-1]
+			frames = frames[:len(frames)-1]
+			if !closed.expr {
+				reset()
+			}
+		case token.TERMINATOR:
+			if len(frames) == 0 || !frames[len(frames)-1].expr {
+				reset()
+			}
+		case token.ASSIGN:
+			if !inExpr {
+				assigned = true
+			}
+		default:
+			if !inExpr {
+				stmt = append(stmt, scannedToken{tok: tok, lit: lit})
+			}
+		}
+	}
+
+	var ctx completionContext
+	for _, f := range frames {
+		if !f.expr {
+			ctx.blocks = append(ctx.blocks, f.block)
+		}
+	}
+	ctx.expr = assigned || (len(frames) > 0 && frames[len(frames)-1].expr)
+	return ctx
+}
+
+type scannedToken struct {
+	tok token.Token
+	lit string
+}
+
+// headerName returns the name of the block whose header is made of the
+// tokens of stmt, such as `prometheus.scrape "default"`.
+func headerName(stmt []scannedToken) (string, bool) {
+	if len(stmt) > 0 && stmt[len(stmt)-1].tok == token.STRING {
+		stmt = stmt[:len(stmt)-1]
+	}
+	if len(stmt)%2 == 0 {
+		return "", false
+	}
+	names := make([]string, 0, len(stmt)/2+1)
+	for i, t := range stmt {
+		switch {
+		case i%2 == 0 && t.tok == token.IDENT:
+			names = append(names, t.lit)
+		case i%2 == 1 && t.tok == token.DOT:
+		default:
+			return "", false
+		}
+	}
+	return strings.Join(names, "."), true
+}
+
+// moduleBlocks removes the blocks which start a new module body from blocks,
+// such as declare blocks and the templates of foreach blocks. The remaining
+// blocks are relative to the innermost module body.
+func moduleBlocks(blocks []string) []string {
+	start := 0
+	for i, name := range blocks {
+		switch {
+		case name == blockDeclare:
+			start = i + 1
+		case name == blockTemplate && i > 0 && blocks[i-1] == blockForeach:
+			start = i + 1
+		}
+	}
+	return blocks[start:]
+}
+
+// fieldsOf returns the fields of the innermost block of blocks, which are
+// relative to a module body.
+func (s *Server) fieldsOf(blocks []string, sc *scope) ([]schema.Field, bool) {
+	fields, ok := s.blockFields(blocks[0], sc)
+	if !ok {
+		return nil, false
+	}
+	for _, name := range blocks[1:] {
+		f, ok := findField(fields, name)
+		if !ok {
+			return nil, false
+		}
+		fields = f.Fields
+	}
+	return fields, true
+}
+
+// complete returns the completion items at pos.
+func (s *Server) complete(doc *document, pos Position) []CompletionItem {
+	var (
+		offset    = doc.offset(pos)
+		start, _  = doc.wordAt(offset)
+		prefix    = doc.text[start:offset]
+		ctx       = contextAt(doc.text[:start])
+		sc        = s.scopeAt(doc, offset)
+		items     = []CompletionItem{}
+		editRange = doc.rangeOf(start, offset)
+		addItem   = func(label string, kind int, detail string) {
+			items = append(items, CompletionItem{
+				Label:    label,
+				Kind:     kind,
+				Detail:   detail,
+				TextEdit: &TextEdit{Range: editRange, NewText: label},
+			})
+		}
+	)
+
+	if ctx.expr {
+		dot := strings.LastIndexByte(prefix, '.')
+		if dot < 0 {
+			for _, id := range sc.sortedIDs() {
+				addItem(id, CompletionKindReference, blockName(sc.components[id].block))
+			}
+			if len(sc.arguments) > 0 {
+				addItem(blockArgument, CompletionKindVariable, "module arguments")
+			}
+			return items
+		}
+
+		// Complete the fields of the value before the last dot.
+		editRange = doc.rangeOf(start+dot+1, offset)
+		names := strings.Split(prefix[:dot], ".")
+		switch {
+		case len(names) == 1 && names[0] == blockArgument:
+			for _, label := range sortedKeys(sc.arguments) {
+				addItem(label, CompletionKindVariable, "argument")
+			}
+		case len(names) == 2 && names[0] == blockArgument:
+			addItem("value", CompletionKindField, "value of the argument")
+		default:
+			def, rest, ok := sc.resolve(names)
+			if !ok || len(rest) > 0 {
+				return items
+			}
+			for _, f := range s.exportFields(blockName(def.block), sc) {
+				addItem(f.Name, CompletionKindField, fieldType(f))
+			}
+		}
+		return items
+	}
+
+	blocks := moduleBlocks(ctx.blocks)
+	if len(blocks) == 0 {
+		// Start of a statement in a module body.
+		for _, name := range component.AllNames() {
+			reg, _ := component.Get(name)
+			stability, _ := strconv.Unquote(reg.Stability.String())
+			addItem(name, CompletionKindClass, stability)
+		}
+		for _, label := range sortedKeys(sc.declares) {
+			addItem(label, CompletionKindModule, "custom component")
+		}
+		for _, label := range sortedKeys(sc.imports) {
+			addItem(label, CompletionKindModule, "imported module")
+		}
+		for _, name := range configBlocks {
+			addItem(name, CompletionKindKeyword, "block")
+		}
+		if len(ctx.blocks) == 0 {
+			for _, name := range sortedKeys(s.services) {
+				if s.services[name].ConfigType != nil {
+					addItem(name, CompletionKindKeyword, "block")
+				}
+			}
+		}
+		return items
+	}
+
+	fields, _ := s.fieldsOf(blocks, sc)
+	for _, f := range fields {
+		switch f.Kind {
+		case schema.KindAttr:
+			addItem(f.Name, CompletionKindProperty, fieldType(f))
+		case schema.KindBlock:
+			addItem(f.Name, CompletionKindStruct, "block")
+		case schema.KindEnum:
+			for _, inner := range f.Fields {
+				addItem(f.Name+"."+inner.Name, CompletionKindStruct, "block")
+			}
+		}
+	}
+	return items
+}
+
+// hover returns the documentation of the identifier at pos, or nil if there
+// is nothing to document.
+func (s *Server) hover(doc *document, pos Position) *Hover {
+	if doc.file == nil {
+		return nil
+	}
+
+	offset := doc.offset(pos)
+	start, end := doc.wordAt(offset)
+	if start == end {
+		return nil
+	}
+	var (
+		word = doc.text[start:end]
+		sc   = s.scopeAt(doc, offset)
+		path = blockPath(doc.file.Body, offset)
+	)
+
+	var names []string
+	for _, b := range path {
+		names = append(names, blockName(b))
+	}
+
+	var contents string
+	switch {
+	case len(path) > 0 && path[len(path)-1].NamePos.Offset() == start:
+		// Name of a block.
+		blocks := moduleBlocks(names)
+		if len(blocks) == 1 {
+			contents = s.blockDoc(blocks[0], sc)
+		} else if len(blocks) > 1 {
+			if fields, ok := s.fieldsOf(blocks[:len(blocks)-1], sc); ok {
+				if f, ok := findField(fields, blocks[len(blocks)-1]); ok {
+					contents = fieldDoc(f)
+				}
+			}
+		}
+
+	case len(path) > 0 && attributeAt(path[len(path)-1].Body, start) != nil:
+		// Name of an attribute.
+		if fields, ok := s.fieldsOf(moduleBlocks(names), sc); ok {
+			if f, ok := findField(fields, word); ok {
+				contents = fieldDoc(f)
+			}
+		}
+
+	default:
+		// Reference to a component.
+		refNames := strings.Split(word, ".")
+		def, rest, ok := sc.resolve(refNames)
+		if !ok {
+			return nil
+		}
+		if len(rest) > 0 {
+			if f, ok := findField(s.exportFields(blockName(def.block), sc), rest[0]); ok {
+				contents = fieldDoc(f)
+			}
+		} else {
+			contents = s.blockDoc(blockName(def.block), sc)
+		}
+	}
+
+	if contents == "" {
+		return nil
+	}
+	r := doc.rangeOf(start, end)
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: contents},
+		Range:    &r,
+	}
+}
+
+// blockDoc documents the arguments and exports of the block named name.
+func (s *Server) blockDoc(name string, sc *scope) string {
+	fields, ok := s.blockFields(name, sc)
+	if !ok {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**%s**\n", name)
+	if reg, ok := component.Get(name); ok {
+		stability, _ := strconv.Unquote(reg.Stability.String())
+		fmt.Fprintf(&sb, "\nStability: %s\n", stability)
+	} else if _, ok := sc.declares[name]; ok {
+		sb.WriteString("\nCustom component\n")
+	}
+
+	writeFields(&sb, "Arguments", fields)
+	writeFields(&sb, "Exports", s.exportFields(name, sc))
+	return sb.String()
+}
+
+func writeFields(sb *strings.Builder, title string, fields []schema.Field) {
+	if len(fields) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\n%s:\n", title)
+	for _, f := range fields {
+		fmt.Fprintf(sb, "- %s\n", fieldSummary(f))
+	}
+}
+
+// fieldDoc documents a single field.
+func fieldDoc(f schema.Field) string {
+	var sb strings.Builder
+	sb.WriteString(fieldSummary(f))
+	sb.WriteString("\n")
+	writeFields(&sb, "Fields", f.Fields)
+	return sb.String()
+}
+
+// fieldSummary describes a field in a single line.
+func fieldSummary(f schema.Field) string {
+	required := "required"
+	if f.Optional {
+		required = "optional"
+	}
+	switch f.Kind {
+	case schema.KindBlock:
+		return fmt.Sprintf("`%s` block (%s)", f.Name, required)
+	case schema.KindEnum:
+		return fmt.Sprintf("`%s` blocks (%s)", f.Name, required)
+	default:
+		return fmt.Sprintf("`%s` `%s` (%s)", f.Name, fieldType(f), required)
+	}
+}
+
+func fieldType(f schema.Field) string {
+	if f.Type == nil {
+		return "any"
+	}
+	return schema.TypeName(f.Type)
+}
+
+// attributeAt returns the attribute of body whose name starts at offset.
+func attributeAt(body ast.Body, offset int) *ast.AttributeStmt {
+	for _, stmt := range body {
+		if attr, ok := stmt.(*ast.AttributeStmt); ok && attr.Name.NamePos.Offset() == offset {
+			return attr
+		}
+	}
+	return nil
+}
+
+// definition returns the location of the block referenced by the identifier
+// at pos: a component, a module argument, a declare block or an import
+// block.
+func (s *Server) definition(doc *document, pos Position) []Location {
+	offset := doc.offset(pos)
+	start, end := doc.wordAt(offset)
+	if start == end {
+		return nil
+	}
+	var (
+		names = strings.Split(doc.text[start:end], ".")
+		sc    = s.scopeAt(doc, offset)
+	)
+
+	if def, _, ok := sc.resolve(names); ok {
+		return []Location{def.location()}
+	}
+	if names[0] == blockArgument && len(names) > 1 {
+		if def, ok := sc.arguments[names[1]]; ok {
+			return []Location{def.location()}
+		}
+	}
+	if def, ok := sc.declares[strings.Join(names, ".")]; ok {
+		return []Location{def.location()}
+	}
+	if def, ok := sc.imports[names[0]]; ok {
+		return []Location{def.location()}
+	}
+	return nil
+}
+
+// format returns the edits which format doc.
+func (s *Server) format(doc *document) ([]TextEdit, error) {
+	f, err := parser.ParseFile(doc.path, []byte(doc.text))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, f); err != nil {
+		return nil, err
+	}
+	// Add a newline at the end of the file.
+	buf.WriteByte('\n')
+
+	if buf.String() == doc.text {
+		return []TextEdit{}, nil
+	}
+	return []TextEdit{{
+		Range:   doc.rangeOf(0, len(doc.text)),
+		NewText: buf.String(),
+	}}, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
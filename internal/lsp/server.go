@@ -0,0 +1,286 @@
+// Package lsp implements a language server for Alloy configuration files.
+//
+// The server communicates with editors using the Language Server Protocol
+// over a stream such as stdio. It reports diagnostics for syntax errors and
+// for blocks which don't match the component registry, completes component
+// names, arguments and exports, documents components on hover, resolves the
+// definition of component references, and formats documents.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grafana/alloy/internal/build"
+	"github.com/grafana/alloy/internal/service"
+)
+
+// Options configures a Server.
+type Options struct {
+	// Services which can be configured with a block at the top level of a
+	// configuration.
+	Services []service.Definition
+}
+
+// Server is a language server for Alloy configuration files.
+type Server struct {
+	services map[string]service.Definition
+	docs     map[string]*document // Open documents by URI.
+
+	conn     *conn
+	shutdown bool // Whether the client requested the server to shut down.
+}
+
+// NewServer creates a new Server.
+func NewServer(opts Options) *Server {
+	services := make(map[string]service.Definition, len(opts.Services))
+	for _, def := range opts.Services {
+		services[def.Name] = def
+	}
+	return &Server{
+		services: services,
+		docs:     make(map[string]*document),
+	}
+}
+
+// ErrExitWithoutShutdown is returned by Serve when the client sends the exit
+// notification without requesting the server to shut down first, in which
+// case the server should exit with an error code.
+var ErrExitWithoutShutdown = errors.New("exit notification received before shutdown request")
+
+// Serve handles the messages read from r, writing responses to w. Serve
+// returns when the client sends the exit notification or closes r.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+
+	for {
+		content, err := s.conn.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(content, &req); err != nil {
+			err := s.conn.Write(errorResponse{
+				JSONRPC: "2.0",
+				Error:   &responseError{Code: codeParseError, Message: err.Error()},
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+
+		if s.shutdown {
+			// Notifications are dropped and requests fail once the server is
+			// shut down.
+			if req.ID == nil {
+				continue
+			}
+			err := s.conn.Write(errorResponse{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Error:   &responseError{Code: codeInvalidRequest, Message: "server is shut down"},
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, err := s.handle(req)
+		if req.ID == nil {
+			// Notifications don't have responses, so their errors are logged to
+			// the client instead.
+			if err != nil {
+				if err := s.logError(req.Method, err); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err != nil {
+			var respErr *responseError
+			if !errors.As(err, &respErr) {
+				respErr = &responseError{Code: codeRequestFailed, Message: err.Error()}
+			}
+			err = s.conn.Write(errorResponse{JSONRPC: "2.0", ID: req.ID, Error: respErr})
+		} else {
+			err = s.conn.Write(response{JSONRPC: "2.0", ID: req.ID, Result: result})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handle handles a request or a notification and returns its result.
+func (s *Server) handle(req request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync: TextDocumentSyncOptions{
+					OpenClose: true,
+					Change:    SyncFull,
+					Save:      SaveOptions{IncludeText: true},
+				},
+				CompletionProvider:         CompletionOptions{TriggerCharacters: []string{"."}},
+				HoverProvider:              true,
+				DefinitionProvider:         true,
+				DocumentFormattingProvider: true,
+			},
+			ServerInfo: ServerInfo{Name: "alloy", Version: build.Version},
+		}, nil
+
+	case "initialized":
+		return nil, nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		params, err := decodeParams[DidOpenTextDocumentParams](req)
+		if err != nil {
+			return nil, err
+		}
+		doc := newDocument(params.TextDocument.URI, params.TextDocument.Text)
+		s.docs[doc.uri] = doc
+		return nil, s.publishDiagnostics(doc)
+
+	case "textDocument/didChange":
+		params, err := decodeParams[DidChangeTextDocumentParams](req)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			// Documents are synchronized in full, so only the last change matters.
+			doc.update(params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+
+	case "textDocument/didSave":
+		params, err := decodeParams[DidSaveTextDocumentParams](req)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		if params.Text != nil {
+			doc.update(*params.Text)
+		}
+		return nil, s.publishDiagnostics(doc)
+
+	case "textDocument/didClose":
+		params, err := decodeParams[DidCloseTextDocumentParams](req)
+		if err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.conn.Write(notification{
+			JSONRPC: "2.0",
+			Method:  "textDocument/publishDiagnostics",
+			Params:  PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}},
+		})
+
+	case "textDocument/completion":
+		params, err := decodeParams[TextDocumentPositionParams](req)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		return s.complete(doc, params.Position), nil
+
+	case "textDocument/hover":
+		params, err := decodeParams[TextDocumentPositionParams](req)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		return s.hover(doc, params.Position), nil
+
+	case "textDocument/definition":
+		params, err := decodeParams[TextDocumentPositionParams](req)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		return s.definition(doc, params.Position), nil
+
+	case "textDocument/formatting":
+		params, err := decodeParams[DocumentFormattingParams](req)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		return s.format(doc)
+
+	default:
+		return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not supported", req.Method)}
+	}
+}
+
+// document returns the open document identified by id.
+func (s *Server) document(id TextDocumentIdentifier) (*document, error) {
+	doc, ok := s.docs[id.URI]
+	if !ok {
+		return nil, &responseError{Code: codeInvalidParams, Message: fmt.Sprintf("document %q is not open", id.URI)}
+	}
+	return doc, nil
+}
+
+// logError sends the error of a notification to the client as a log message.
+func (s *Server) logError(method string, err error) error {
+	return s.conn.Write(notification{
+		JSONRPC: "2.0",
+		Method:  "window/logMessage",
+		Params:  LogMessageParams{Type: MessageTypeError, Message: fmt.Sprintf("%s: %s", method, err)},
+	})
+}
+
+// publishDiagnostics sends the diagnostics of doc to the client.
+func (s *Server) publishDiagnostics(doc *document) error {
+	return s.conn.Write(notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  PublishDiagnosticsParams{URI: doc.uri, Diagnostics: s.diagnostics(doc)},
+	})
+}
+
+func decodeParams[T any](req request) (T, error) {
+	var params T
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return params, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return params, nil
+}
@@ -0,0 +1,460 @@
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/grafana/alloy/internal/component/local/file"
+)
+
+const testConfig = `local.file "a" {
+  filename = "/tmp/a"
+}
+
+local.file "b" {
+  filename = local.file.a.content
+}
+`
+
+func TestDiagnostics(t *testing.T) {
+	tt := []struct {
+		name   string
+		config string
+		expect []string
+	}{
+		{
+			name:   "valid",
+			config: testConfig,
+		},
+		{
+			name:   "syntax error",
+			config: `local.file "a" {`,
+			expect: []string{`expected }, got EOF`},
+		},
+		{
+			name:   "unknown component",
+			config: `local.fil "a" {}`,
+			expect: []string{`cannot find the definition of component name "local.fil"`},
+		},
+		{
+			name: "unknown attribute",
+			config: `local.file "a" {
+  filename = "/tmp/a"
+  filenames = "/tmp/b"
+}`,
+			expect: []string{`unrecognized attribute name "filenames"`},
+		},
+		{
+			name:   "missing attribute",
+			config: `local.file "a" {}`,
+			expect: []string{`missing required attribute "filename"`},
+		},
+		{
+			name: "restart block",
+			config: `local.file "a" {
+  filename = "/tmp/a"
+
+  restart {
+    max_restarts = 5
+    max_backof   = "5m"
+  }
+}`,
+			expect: []string{`unrecognized attribute name "max_backof"`},
+		},
+		{
+			name: "singleton clustering block",
+			config: `local.file "a" {
+  filename = "/tmp/a"
+
+  clustering {
+    singleton = true
+    enabled   = true
+  }
+}`,
+			expect: []string{`unrecognized attribute name "enabled"`},
+		},
+		{
+			name: "declare",
+			config: `declare "mod" {
+  argument "path" {}
+  local.file "a" { filename = argument.path.value }
+}
+
+mod "a" {
+  path = "/tmp/a"
+  other = true
+}
+
+mod "b" {}`,
+			expect: []string{
+				`unrecognized attribute name "other"`,
+				`missing required attribute "path"`,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTestClient(t)
+			diags := c.open(c.uri("config.alloy"), tc.config)
+
+			var messages []string
+			for _, d := range diags.Diagnostics {
+				messages = append(messages, d.Message)
+			}
+			require.Equal(t, tc.expect, messages)
+		})
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	tt := []struct {
+		name   string
+		config string // Completion is requested at the | character.
+		expect []string
+		reject []string
+	}{
+		{
+			name:   "top level",
+			config: "local.f|",
+			expect: []string{"local.file", "logging", "declare"},
+		},
+		{
+			name:   "arguments",
+			config: "local.file \"a\" {\n  |\n}",
+			expect: []string{"filename", "poll_frequency"},
+			reject: []string{"local.file"},
+		},
+		{
+			name:   "references",
+			config: testConfig + "local.file \"c\" {\n  filename = l|\n}",
+			expect: []string{"local.file.a", "local.file.b"},
+		},
+		{
+			name:   "exports",
+			config: testConfig + "local.file \"c\" {\n  filename = local.file.a.|content\n}",
+			expect: []string{"content"},
+		},
+		{
+			name:   "declare arguments",
+			config: "declare \"mod\" {\n  argument \"path\" {}\n}\n\nmod \"a\" {\n  |\n}",
+			expect: []string{"path"},
+		},
+		{
+			name:   "within declare",
+			config: "declare \"mod\" {\n  argument \"path\" {}\n\n  local.file \"a\" {\n    filename = argument.|path.value\n  }\n}",
+			expect: []string{"path"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				c      = newTestClient(t)
+				uri    = c.uri("config.alloy")
+				cursor = strings.Index(tc.config, "|")
+				text   = strings.Replace(tc.config, "|", "", 1)
+			)
+			c.open(uri, text)
+
+			var items []CompletionItem
+			c.call("textDocument/completion", TextDocumentPositionParams{
+				TextDocument: TextDocumentIdentifier{URI: uri},
+				Position:     testPosition(text, cursor),
+			}, &items)
+
+			var labels []string
+			for _, item := range items {
+				labels = append(labels, item.Label)
+			}
+			for _, label := range tc.expect {
+				require.Contains(t, labels, label)
+			}
+			for _, label := range tc.reject {
+				require.NotContains(t, labels, label)
+			}
+		})
+	}
+}
+
+func TestHover(t *testing.T) {
+	c := newTestClient(t)
+	uri := c.uri("config.alloy")
+	c.open(uri, testConfig)
+
+	hover := func(offset int) string {
+		var res *Hover
+		c.call("textDocument/hover", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Position:     testPosition(testConfig, offset),
+		}, &res)
+		if res == nil {
+			return ""
+		}
+		return res.Contents.Value
+	}
+
+	component := hover(strings.Index(testConfig, "local.file"))
+	require.Contains(t, component, "**local.file**")
+	require.Contains(t, component, "Stability: generally-available")
+	require.Contains(t, component, "`filename` `string` (required)")
+	require.Contains(t, component, "Exports:\n- `content`")
+
+	attr := hover(strings.Index(testConfig, "filename"))
+	require.Equal(t, "`filename` `string` (required)\n", attr)
+
+	ref := hover(strings.Index(testConfig, "content"))
+	require.Contains(t, ref, "`content`")
+
+	require.Empty(t, hover(strings.Index(testConfig, `"/tmp/a"`)+2))
+}
+
+func TestDefinition(t *testing.T) {
+	var (
+		c       = newTestClient(t)
+		uri     = c.uri("config.alloy")
+		sibling = `local.file "sibling" {
+  filename = "/tmp/sibling"
+}
+`
+		config = `local.file "a" {
+  filename = local.file.sibling.content
+}
+
+declare "mod" {
+  argument "path" {}
+
+  local.file "a" {
+    filename = argument.path.value
+  }
+}
+
+mod "a" {
+  path = local.file.a.content
+}
+`
+	)
+	require.NoError(t, os.WriteFile(uriToPath(c.uri("sibling.alloy")), []byte(sibling), 0o644))
+	c.open(uri, config)
+
+	definition := func(offset int) []Location {
+		var res []Location
+		c.call("textDocument/definition", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Position:     testPosition(config, offset),
+		}, &res)
+		return res
+	}
+
+	require.Equal(t, []Location{{
+		URI:   c.uri("sibling.alloy"),
+		Range: Range{Start: Position{0, 0}, End: Position{0, 20}},
+	}}, definition(strings.Index(config, "local.file.sibling")))
+
+	require.Equal(t, []Location{{
+		URI:   uri,
+		Range: Range{Start: Position{5, 2}, End: Position{5, 17}},
+	}}, definition(strings.Index(config, "argument.path.value")))
+
+	require.Equal(t, []Location{{
+		URI:   uri,
+		Range: Range{Start: Position{4, 0}, End: Position{4, 13}},
+	}}, definition(strings.Index(config, `mod "a"`)))
+
+	// Components of the declare block aren't visible from outside.
+	require.Equal(t, []Location{{
+		URI:   uri,
+		Range: Range{Start: Position{0, 0}, End: Position{0, 14}},
+	}}, definition(strings.Index(config, "local.file.a.content")))
+}
+
+func TestFormatting(t *testing.T) {
+	c := newTestClient(t)
+	uri := c.uri("config.alloy")
+	c.open(uri, "local.file \"a\" {\nfilename=\"/tmp/a\"\n}")
+
+	var edits []TextEdit
+	c.call("textDocument/formatting", DocumentFormattingParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+	}, &edits)
+	require.Equal(t, []TextEdit{{
+		Range:   Range{Start: Position{0, 0}, End: Position{2, 1}},
+		NewText: "local.file \"a\" {\n\tfilename = \"/tmp/a\"\n}\n",
+	}}, edits)
+
+	// Documents with syntax errors can't be formatted.
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: uri},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "local.file \"a\" {"}},
+	})
+	err := c.callError("textDocument/formatting", DocumentFormattingParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+	})
+	require.Equal(t, codeRequestFailed, err.Code)
+}
+
+func TestNotificationError(t *testing.T) {
+	c := newTestClient(t)
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: c.uri("config.alloy")},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: testConfig}},
+	})
+
+	var msg struct {
+		Method string           `json:"method"`
+		Params LogMessageParams `json:"params"`
+	}
+	require.NoError(t, json.Unmarshal(c.read(), &msg))
+	require.Equal(t, "window/logMessage", msg.Method)
+	require.Equal(t, MessageTypeError, msg.Params.Type)
+	require.Contains(t, msg.Params.Message, "textDocument/didChange: document")
+}
+
+func TestShutdown(t *testing.T) {
+	c := newTestClient(t)
+	uri := c.uri("config.alloy")
+	c.open(uri, testConfig)
+
+	var res any
+	c.call("shutdown", nil, &res)
+	require.Nil(t, res)
+
+	// Requests fail after shutdown.
+	err := c.callError("textDocument/hover", TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+	})
+	require.Equal(t, codeInvalidRequest, err.Code)
+
+	require.NoError(t, c.exit())
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	c := newTestClient(t)
+	require.ErrorIs(t, c.exit(), ErrExitWithoutShutdown)
+}
+
+// testClient is a language client connected to a Server.
+type testClient struct {
+	t      *testing.T
+	dir    string
+	conn   *conn
+	nextID int
+
+	done   chan error // Receives the error returned by Serve.
+	exited bool
+}
+
+func newTestClient(t *testing.T) *testClient {
+	var (
+		clientR, serverW = io.Pipe()
+		serverR, clientW = io.Pipe()
+	)
+	c := &testClient{t: t, dir: t.TempDir(), conn: newConn(clientR, clientW), done: make(chan error, 1)}
+
+	go func() {
+		c.done <- NewServer(Options{}).Serve(serverR, serverW)
+		serverW.Close()
+	}()
+	t.Cleanup(func() {
+		clientW.Close()
+		if !c.exited {
+			require.NoError(t, <-c.done)
+		}
+	})
+
+	var res InitializeResult
+	c.call("initialize", struct{}{}, &res)
+	require.True(t, res.Capabilities.HoverProvider)
+	c.notify("initialized", struct{}{})
+	return c
+}
+
+// uri returns the URI of a file in the directory of the client.
+func (c *testClient) uri(name string) string {
+	return pathToURI(filepath.Join(c.dir, name))
+}
+
+// open opens a document and returns its diagnostics.
+func (c *testClient) open(uri, text string) PublishDiagnosticsParams {
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, Version: 1, Text: text},
+	})
+
+	var msg struct {
+		Method string                   `json:"method"`
+		Params PublishDiagnosticsParams `json:"params"`
+	}
+	require.NoError(c.t, json.Unmarshal(c.read(), &msg))
+	require.Equal(c.t, "textDocument/publishDiagnostics", msg.Method)
+	require.Equal(c.t, uri, msg.Params.URI)
+	return msg.Params
+}
+
+// exit sends the exit notification and returns the error returned by the
+// server.
+func (c *testClient) exit() error {
+	c.notify("exit", nil)
+	c.exited = true
+	return <-c.done
+}
+
+func (c *testClient) notify(method string, params any) {
+	require.NoError(c.t, c.conn.Write(map[string]any{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	}))
+}
+
+// call sends a request and decodes its result into result.
+func (c *testClient) call(method string, params any, result any) {
+	msg := c.request(method, params)
+	require.Nil(c.t, msg.Error)
+	require.NoError(c.t, json.Unmarshal(msg.Result, result))
+}
+
+// callError sends a request which is expected to fail.
+func (c *testClient) callError(method string, params any) *responseError {
+	msg := c.request(method, params)
+	require.NotNil(c.t, msg.Error)
+	return msg.Error
+}
+
+type testResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+func (c *testClient) request(method string, params any) testResponse {
+	c.nextID++
+	require.NoError(c.t, c.conn.Write(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      c.nextID,
+		"method":  method,
+		"params":  params,
+	}))
+
+	var msg testResponse
+	require.NoError(c.t, json.Unmarshal(c.read(), &msg))
+	require.Equal(c.t, c.nextID, msg.ID)
+	return msg
+}
+
+func (c *testClient) read() []byte {
+	content, err := c.conn.Read()
+	require.NoError(c.t, err)
+	return content
+}
+
+// testPosition returns the position of an offset of an ASCII text.
+func testPosition(text string, offset int) Position {
+	line := strings.Count(text[:offset], "\n")
+	return Position{Line: line, Character: offset - (strings.LastIndexByte(text[:offset], '\n') + 1)}
+}
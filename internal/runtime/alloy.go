@@ -54,6 +54,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/atomic"
 
+	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/internal/controller"
 	"github.com/grafana/alloy/internal/runtime/internal/worker"
@@ -110,8 +111,59 @@ type Options struct {
 
 	// EnableCommunityComps enables the use of community components.
 	EnableCommunityComps bool
+
+	// ComponentRegistry is where the controller and its modules look up
+	// components. If nil, components registered to
+	// github.com/grafana/alloy/internal/component are used, restricted by
+	// MinStability and EnableCommunityComps.
+	ComponentRegistry ComponentRegistry
+
+	// RestartPolicy is the policy used to restart components whose Run method
+	// exits with an error. Components can override it with a restart block.
+	// DefaultRestartPolicy is used if RestartPolicy is the zero value.
+	RestartPolicy RestartPolicy
+
+	// ValidateOnly makes the controller decode the arguments of components and
+	// services without building components or updating services. It is used
+	// to validate a config source without running it; a controller created
+	// with ValidateOnly must not be run.
+	ValidateOnly bool
+}
+
+// ComponentRegistry is a collection of components which can be used by a
+// controller.
+type ComponentRegistry = controller.ComponentRegistry
+
+// NewDefaultComponentRegistry creates a ComponentRegistry which gets
+// components registered to github.com/grafana/alloy/internal/component.
+func NewDefaultComponentRegistry(minStability featuregate.Stability, enableCommunityComps bool) ComponentRegistry {
+	return controller.NewDefaultComponentRegistry(minStability, enableCommunityComps)
 }
 
+// RestartPolicy configures how components are restarted when their Run
+// method exits with an error.
+type RestartPolicy = controller.RestartPolicy
+
+// DefaultRestartPolicy is the default restart policy, which never restarts
+// components.
+var DefaultRestartPolicy = controller.DefaultRestartPolicy
+
+// RestartArguments are the arguments of the reserved restart block of
+// components.
+type RestartArguments = controller.RestartArguments
+
+// RestartBlockName is the name of the reserved block of components which
+// overrides their restart policy.
+const RestartBlockName = controller.RestartBlockName
+
+// ClusteringArguments are the arguments of the clustering block of components
+// which are handled by the runtime rather than by components.
+type ClusteringArguments = controller.ClusteringArguments
+
+// ClusteringBlockName is the name of the block of components whose singleton
+// attribute makes them run only on the leader of the cluster.
+const ClusteringBlockName = controller.ClusteringBlockName
+
 // Runtime is the Alloy system.
 type Runtime struct {
 	log    *logging.Logger
@@ -132,10 +184,11 @@ type Runtime struct {
 // New creates a new, unstarted Alloy controller. Call Run to run the controller.
 func New(o Options) *Runtime {
 	return newController(controllerOptions{
-		Options:        o,
-		ModuleRegistry: newModuleRegistry(),
-		IsModule:       false, // We are creating a new root controller.
-		WorkerPool:     worker.NewDefaultWorkerPool(),
+		Options:           o,
+		ComponentRegistry: o.ComponentRegistry,
+		ModuleRegistry:    newModuleRegistry(),
+		IsModule:          false, // We are creating a new root controller.
+		WorkerPool:        worker.NewDefaultWorkerPool(),
 	})
 }
 
@@ -149,6 +202,8 @@ type controllerOptions struct {
 	IsModule          bool                         // Whether this controller is for a module.
 	// A worker pool to evaluate components asynchronously. A default one will be created if this is nil.
 	WorkerPool worker.Pool
+	// Returns the exports of components from their global ID when ValidateOnly is set.
+	ValidateExports func(globalID string) (component.Exports, bool)
 }
 
 // newController creates a new, unstarted Alloy controller with a specific
@@ -175,13 +230,17 @@ func newController(o controllerOptions) *Runtime {
 		workerPool = worker.NewDefaultWorkerPool()
 	}
 
+	if o.RestartPolicy == (RestartPolicy{}) {
+		o.RestartPolicy = DefaultRestartPolicy
+	}
+
 	f := &Runtime{
 		log:    log,
 		tracer: tracer,
 		opts:   o,
 
 		updateQueue: controller.NewQueue(),
-		sched:       controller.NewScheduler(log),
+		sched:       controller.NewScheduler(log, o.ControllerID),
 
 		modules: o.ModuleRegistry,
 
@@ -197,6 +256,9 @@ func newController(o controllerOptions) *Runtime {
 			DataPath:             o.DataPath,
 			MinStability:         o.MinStability,
 			EnableCommunityComps: o.EnableCommunityComps,
+			ValidateOnly:         o.ValidateOnly,
+			RestartPolicy:        o.RestartPolicy,
+			ValidateExports:      o.ValidateExports,
 			OnBlockNodeUpdate: func(cn controller.BlockNode) {
 				// Changed node should be queued for reevaluation.
 				f.updateQueue.Enqueue(&controller.QueuedNode{Node: cn, LastUpdatedTime: time.Now()})
@@ -214,6 +276,9 @@ func newController(o controllerOptions) *Runtime {
 					DataPath:             o.DataPath,
 					MinStability:         o.MinStability,
 					EnableCommunityComps: o.EnableCommunityComps,
+					ValidateOnly:         o.ValidateOnly,
+					RestartPolicy:        o.RestartPolicy,
+					ValidateExports:      o.ValidateExports,
 					ID:                   id,
 					ServiceMap:           serviceMap,
 					WorkerPool:           workerPool,
@@ -310,6 +375,7 @@ func (f *Runtime) loadSource(source *Source, args map[string]any, customComponen
 		ComponentBlocks:         source.components,
 		ConfigBlocks:            source.configBlocks,
 		DeclareBlocks:           source.declareBlocks,
+		FunctionBlocks:          source.functionBlocks,
 		CustomComponentRegistry: customComponentRegistry,
 	}
 
@@ -64,7 +64,10 @@ func (f *Runtime) ListComponents(moduleID string, opts component.InfoOptions) ([
 }
 
 func (f *Runtime) getComponentDetail(cn controller.ComponentNode, graph *dag.Graph, opts component.InfoOptions) *component.Info {
-	var references, referencedBy []string
+	var (
+		references, referencedBy []string
+		referenceAttributes      map[string][]string
+	)
 
 	// Skip over any edge which isn't between two component nodes. This is a
 	// temporary workaround needed until there's a concept of configuration
@@ -74,9 +77,16 @@ func (f *Runtime) getComponentDetail(cn controller.ComponentNode, graph *dag.Gra
 	// block is referenced in the graph.
 	//
 	// TODO(rfratto): add support for config block nodes in the API and UI.
+	attrs := controller.ReferenceAttributes(cn, graph)
 	for _, dep := range graph.Dependencies(cn) {
 		if _, ok := dep.(controller.ComponentNode); ok {
 			references = append(references, dep.NodeID())
+			if names, ok := attrs[dep.NodeID()]; ok {
+				if referenceAttributes == nil {
+					referenceAttributes = make(map[string][]string)
+				}
+				referenceAttributes[dep.NodeID()] = names
+			}
 		}
 	}
 	for _, dep := range graph.Dependants(cn) {
@@ -110,8 +120,9 @@ func (f *Runtime) getComponentDetail(cn controller.ComponentNode, graph *dag.Gra
 		Label: cn.Label(),
 		Type:  componentType(cn),
 
-		References:   references,
-		ReferencedBy: referencedBy,
+		References:          references,
+		ReferencedBy:        referencedBy,
+		ReferenceAttributes: referenceAttributes,
 
 		ComponentName: cn.ComponentName(),
 		Health:        health,
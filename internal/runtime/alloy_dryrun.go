@@ -0,0 +1,284 @@
+package runtime
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/runtime/logging"
+	"github.com/grafana/alloy/syntax/ast"
+	"github.com/grafana/alloy/syntax/parser"
+	"github.com/grafana/alloy/syntax/printer"
+	"github.com/grafana/alloy/syntax/token/builder"
+)
+
+// ChangeType describes how loading a config source changes a component.
+type ChangeType string
+
+// Supported change types.
+const (
+	ChangeAdded     ChangeType = "added"     // The component would be created.
+	ChangeRemoved   ChangeType = "removed"   // The component would be shut down.
+	ChangeUpdated   ChangeType = "updated"   // The arguments of the component would change.
+	ChangeUnchanged ChangeType = "unchanged" // The component would be left untouched.
+)
+
+// DryRunReport describes the effect of loading a config source into a
+// controller.
+type DryRunReport struct {
+	// Components holds a change for every component of the controller and of
+	// the config source, sorted by ID.
+	Components []ComponentChange `json:"components"`
+}
+
+// ComponentChange describes how loading a config source changes a component.
+type ComponentChange struct {
+	ID     string     `json:"id"` // Global ID of the component.
+	Change ChangeType `json:"change"`
+
+	// Attributes holds the attributes and blocks of the arguments of the
+	// component whose value changes. Values are formatted as Alloy syntax, with
+	// secrets redacted. Attributes is only set for updated components.
+	Attributes []AttributeChange `json:"attributes,omitempty"`
+}
+
+// AttributeChange is the change of the value of an attribute or of a block of
+// the arguments of a component. Old is empty if the attribute is added, and New
+// is empty if the attribute is removed.
+type AttributeChange struct {
+	Name string `json:"name"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// DryRun evaluates source and args as LoadSource would, without building or
+// running components, and reports how loading them would change the
+// components of the controller. The controller isn't modified.
+//
+// Components of source are evaluated with the exports of the components of the
+// controller which have the same ID. The exports of new components keep their
+// zero value, so arguments which depend on them are reported as if the
+// component had no exports yet.
+//
+// DryRun returns the same errors as LoadSource if source can't be loaded.
+func (f *Runtime) DryRun(source *Source, args map[string]any) (*DryRunReport, error) {
+	// Imported modules may write to the data path; use a temporary one so that
+	// the dry run doesn't alter the data of the controller.
+	dataPath, err := os.MkdirTemp("", "alloy-dry-run")
+	if err != nil {
+		return nil, fmt.Errorf("creating data path: %w", err)
+	}
+	defer os.RemoveAll(dataPath)
+
+	l, err := logging.New(io.Discard, logging.DefaultOptions)
+	if err != nil {
+		return nil, fmt.Errorf("building logger: %w", err)
+	}
+
+	o := f.opts
+	o.Logger = l
+	o.DataPath = dataPath
+	o.Reg = prometheus.NewRegistry()
+	o.ValidateOnly = true
+	o.ModuleRegistry = newModuleRegistry()
+	o.ValidateExports = f.componentExports
+	if o.OnExportsChange != nil {
+		o.OnExportsChange = func(map[string]any) {}
+	}
+
+	dry := newController(o)
+	if err := dry.LoadSource(source, args); err != nil {
+		return nil, err
+	}
+
+	opts := component.InfoOptions{GetArguments: true}
+	return newDryRunReport(
+		component.GetAllComponents(f, opts),
+		component.GetAllComponents(dry, opts),
+	), nil
+}
+
+// componentExports returns the current exports of the component with the
+// given global ID.
+func (f *Runtime) componentExports(globalID string) (component.Exports, bool) {
+	info, err := f.GetComponent(component.ParseID(globalID), component.InfoOptions{GetExports: true})
+	if err != nil {
+		return nil, false
+	}
+	return info.Exports, true
+}
+
+func newDryRunReport(current, next []*component.Info) *DryRunReport {
+	var (
+		report  = &DryRunReport{}
+		oldArgs = make(map[string]component.Arguments, len(current))
+	)
+	for _, info := range current {
+		oldArgs[info.ID.String()] = info.Arguments
+	}
+
+	for _, info := range next {
+		id := info.ID.String()
+		old, ok := oldArgs[id]
+		delete(oldArgs, id)
+
+		switch {
+		case !ok:
+			report.Components = append(report.Components, ComponentChange{ID: id, Change: ChangeAdded})
+		case reflect.DeepEqual(old, info.Arguments):
+			report.Components = append(report.Components, ComponentChange{ID: id, Change: ChangeUnchanged})
+		default:
+			report.Components = append(report.Components, ComponentChange{
+				ID:         id,
+				Change:     ChangeUpdated,
+				Attributes: diffArguments(old, info.Arguments),
+			})
+		}
+	}
+	for id := range oldArgs {
+		report.Components = append(report.Components, ComponentChange{ID: id, Change: ChangeRemoved})
+	}
+
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].ID < report.Components[j].ID
+	})
+	return report
+}
+
+// diffArguments returns the attributes and blocks whose formatted value
+// differs between prev and next. Changes which aren't visible once formatted,
+// such as a change of a secret, aren't reported.
+func diffArguments(prev, next component.Arguments) []AttributeChange {
+	var (
+		oldAttrs = formatArguments(prev)
+		newAttrs = formatArguments(next)
+		names    []string
+	)
+	for name := range oldAttrs {
+		names = append(names, name)
+	}
+	for name := range newAttrs {
+		if _, ok := oldAttrs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []AttributeChange
+	for _, name := range names {
+		if oldAttrs[name] == newAttrs[name] {
+			continue
+		}
+		changes = append(changes, AttributeChange{Name: name, Old: oldAttrs[name], New: newAttrs[name]})
+	}
+	return changes
+}
+
+// formatArguments formats the attributes and blocks of args as Alloy syntax,
+// keyed by name. Blocks which are set more than once are keyed by their name
+// and their index, such as rule[1].
+func formatArguments(args component.Arguments) map[string]string {
+	f := builder.NewFile()
+	switch args := args.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		// Arguments of custom components.
+		names := make([]string, 0, len(args))
+		for name := range args {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			f.Body().SetAttributeValue(name, args[name])
+		}
+	default:
+		f.Body().AppendFrom(args)
+	}
+
+	file, err := parser.ParseFile("", f.Bytes())
+	if err != nil {
+		// Values which can't be printed as valid Alloy syntax are compared as a
+		// whole.
+		return map[string]string{"": string(f.Bytes())}
+	}
+
+	var (
+		res    = make(map[string]string, len(file.Body))
+		counts = make(map[string]int)
+	)
+	for _, stmt := range file.Body {
+		var (
+			name string
+			node ast.Node = stmt
+		)
+		switch stmt := stmt.(type) {
+		case *ast.AttributeStmt:
+			name, node = stmt.Name.Name, stmt.Value
+		case *ast.BlockStmt:
+			name = strings.Join(stmt.Name, ".")
+			if stmt.Label != "" {
+				name += " " + strconv.Quote(stmt.Label)
+			}
+			n := counts[name]
+			counts[name]++
+			if n > 0 {
+				name += fmt.Sprintf("[%d]", n)
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, node); err != nil {
+			continue
+		}
+		res[name] = buf.String()
+	}
+	return res
+}
+
+// WriteTo writes a human-readable summary of r to w. Unchanged components are
+// only counted.
+func (r *DryRunReport) WriteTo(w io.Writer) (int64, error) {
+	var (
+		buf    bytes.Buffer
+		counts = make(map[ChangeType]int)
+	)
+	for _, c := range r.Components {
+		counts[c.Change]++
+
+		switch c.Change {
+		case ChangeAdded:
+			fmt.Fprintf(&buf, "+ %s\n", c.ID)
+		case ChangeRemoved:
+			fmt.Fprintf(&buf, "- %s\n", c.ID)
+		case ChangeUpdated:
+			fmt.Fprintf(&buf, "~ %s\n", c.ID)
+			for _, a := range c.Attributes {
+				fmt.Fprintf(&buf, "    %s:\n", a.Name)
+				writePrefixedLines(&buf, "      - ", a.Old)
+				writePrefixedLines(&buf, "      + ", a.New)
+			}
+		}
+	}
+	fmt.Fprintf(&buf, "%d added, %d removed, %d updated, %d unchanged\n",
+		counts[ChangeAdded], counts[ChangeRemoved], counts[ChangeUpdated], counts[ChangeUnchanged])
+
+	return buf.WriteTo(w)
+}
+
+func writePrefixedLines(buf *bytes.Buffer, prefix, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		buf.WriteString(prefix + line + "\n")
+	}
+}
@@ -0,0 +1,92 @@
+package runtime
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_DryRun(t *testing.T) {
+	defer verifyNoGoroutineLeaks(t)
+	ctrl := New(testOptions(t))
+	defer cleanUpController(ctrl)
+
+	current, err := ParseSource(t.Name(), []byte(`
+		testcomponents.passthrough "static" {
+			input = "hello, world!"
+		}
+
+		testcomponents.passthrough "forwarded" {
+			input = testcomponents.passthrough.static.output
+		}
+
+		testcomponents.passthrough "removed" {
+			input = "removed"
+		}
+	`))
+	require.NoError(t, err)
+	require.NoError(t, ctrl.LoadSource(current, nil))
+
+	next, err := ParseSource(t.Name(), []byte(`
+		testcomponents.passthrough "static" {
+			input = "hello, world!"
+		}
+
+		testcomponents.passthrough "forwarded" {
+			input = testcomponents.passthrough.static.output
+			lag   = "1ms"
+		}
+
+		testcomponents.passthrough "added" {
+			input = "added"
+		}
+	`))
+	require.NoError(t, err)
+
+	report, err := ctrl.DryRun(next, nil)
+	require.NoError(t, err)
+	require.Equal(t, []ComponentChange{
+		{ID: "testcomponents.passthrough.added", Change: ChangeAdded},
+		{
+			ID:     "testcomponents.passthrough.forwarded",
+			Change: ChangeUpdated,
+			// The input of the component is evaluated with the exports of the
+			// running static component, so it doesn't change.
+			Attributes: []AttributeChange{{Name: "lag", New: `"1ms"`}},
+		},
+		{ID: "testcomponents.passthrough.removed", Change: ChangeRemoved},
+		{ID: "testcomponents.passthrough.static", Change: ChangeUnchanged},
+	}, report.Components)
+
+	var buf bytes.Buffer
+	_, err = report.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, `+ testcomponents.passthrough.added
+~ testcomponents.passthrough.forwarded
+    lag:
+      + "1ms"
+- testcomponents.passthrough.removed
+1 added, 1 removed, 1 updated, 1 unchanged
+`, buf.String())
+
+	// The dry run must not change the controller.
+	require.Len(t, ctrl.loader.Components(), 3)
+	require.NotNil(t, ctrl.loader.Graph().GetByID("testcomponents.passthrough.removed"))
+}
+
+func TestController_DryRun_Error(t *testing.T) {
+	defer verifyNoGoroutineLeaks(t)
+	ctrl := New(testOptions(t))
+	defer cleanUpController(ctrl)
+
+	f, err := ParseSource(t.Name(), []byte(`
+		testcomponents.passthrough "static" {
+			lag = "1ms"
+		}
+	`))
+	require.NoError(t, err)
+
+	_, err = ctrl.DryRun(f, nil)
+	require.ErrorContains(t, err, `missing required attribute "input"`)
+}
@@ -3,6 +3,7 @@ package runtime
 import (
 	"context"
 
+	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/runtime/internal/controller"
 	"github.com/grafana/alloy/internal/runtime/internal/dag"
 	"github.com/grafana/alloy/internal/service"
@@ -79,6 +80,7 @@ func (f *Runtime) NewController(id string) service.Controller {
 				MinStability:    f.opts.MinStability,
 				Reg:             f.opts.Reg,
 				Services:        f.opts.Services,
+				RestartPolicy:   f.opts.RestartPolicy,
 				OnExportsChange: nil, // NOTE(@tpaschalis, @wildum) The isolated controller shouldn't be able to export any values.
 			},
 			IsModule:       true,
@@ -101,3 +103,6 @@ func (sc serviceController) LoadSource(b []byte, args map[string]any) error {
 	return sc.f.LoadSource(source, args)
 }
 func (sc serviceController) Ready() bool { return sc.f.Ready() }
+func (sc serviceController) ListComponents(moduleID string, opts component.InfoOptions) ([]*component.Info, error) {
+	return sc.f.ListComponents(moduleID, opts)
+}
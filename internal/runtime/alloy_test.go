@@ -2,8 +2,11 @@ package runtime
 
 import (
 	"context"
+	"errors"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/featuregate"
@@ -11,6 +14,7 @@ import (
 	"github.com/grafana/alloy/internal/runtime/internal/dag"
 	"github.com/grafana/alloy/internal/runtime/internal/testcomponents"
 	"github.com/grafana/alloy/internal/runtime/logging"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 )
@@ -54,6 +58,216 @@ func TestController_LoadSource_Evaluation(t *testing.T) {
 	require.Equal(t, "hello, world!", out.(testcomponents.PassthroughExports).Output)
 }
 
+func TestController_LoadSource_RestartBlock(t *testing.T) {
+	tt := []struct {
+		name        string
+		config      string
+		expect      RestartPolicy
+		expectError string
+	}{
+		{
+			name:   "no restart block",
+			config: `testcomponents.passthrough "a" { input = "a" }`,
+			expect: DefaultRestartPolicy,
+		},
+		{
+			name: "restart block",
+			config: `testcomponents.passthrough "a" {
+				input = "a"
+
+				restart {
+					max_restarts = 5
+					max_backoff  = "5m"
+				}
+			}`,
+			expect: RestartPolicy{MaxRestarts: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Minute},
+		},
+		{
+			name: "duplicate restart block",
+			config: `testcomponents.passthrough "a" {
+				input = "a"
+
+				restart { max_restarts = 1 }
+				restart { max_restarts = 2 }
+			}`,
+			expectError: "the restart block may only be set once",
+		},
+		{
+			name: "invalid restart block",
+			config: `testcomponents.passthrough "a" {
+				input = "a"
+
+				restart { initial_backoff = "10m" }
+			}`,
+			expectError: "invalid restart block: max_backoff must be greater than or equal to initial_backoff",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := New(testOptions(t))
+			defer cleanUpController(ctrl)
+
+			f, err := ParseSource(t.Name(), []byte(tc.config))
+			require.NoError(t, err)
+
+			err = ctrl.LoadSource(f, nil)
+			if tc.expectError != "" {
+				require.ErrorContains(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+
+			n := ctrl.loader.Graph().GetByID("testcomponents.passthrough.a").(*controller.BuiltinComponentNode)
+			require.Equal(t, tc.expect, n.RestartPolicy())
+			require.Equal(t, "a", n.Arguments().(testcomponents.PassthroughConfig).Input)
+		})
+	}
+}
+
+func TestController_RestartComponent(t *testing.T) {
+	defer verifyNoGoroutineLeaks(t)
+
+	var builds atomic.Int32
+	registry := controller.NewRegistryMap(
+		featuregate.StabilityGenerallyAvailable,
+		true,
+		map[string]component.Registration{
+			"failing": {
+				Name:      "failing",
+				Stability: featuregate.StabilityGenerallyAvailable,
+				Args:      struct{}{},
+				Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+					// Like most components, the component registers its metrics when
+					// it's built and can't be run twice.
+					counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "failing_runs_total"})
+					if err := opts.Registerer.Register(counter); err != nil {
+						return nil, err
+					}
+
+					build := builds.Add(1)
+					stopped := make(chan struct{})
+					return &testcomponents.Fake{
+						RunFunc: func(ctx context.Context) error {
+							defer close(stopped)
+							counter.Inc()
+
+							if build == 1 {
+								return errors.New("first instance failed")
+							}
+							<-ctx.Done()
+							return nil
+						},
+					}, nil
+				},
+			},
+		},
+	)
+
+	f, err := ParseSource(t.Name(), []byte(`
+		failing "a" {
+			restart {
+				max_restarts    = 1
+				initial_backoff = "10ms"
+				max_backoff     = "10ms"
+			}
+		}
+	`))
+	require.NoError(t, err)
+
+	ctrl := newController(controllerOptions{
+		Options:           testOptions(t),
+		ComponentRegistry: registry,
+		ModuleRegistry:    newModuleRegistry(),
+	})
+	require.NoError(t, ctrl.LoadSource(f, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ctrl.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	n := ctrl.loader.Graph().GetByID("failing.a").(*controller.BuiltinComponentNode)
+	require.Eventually(t, func() bool {
+		return builds.Load() == 2 && n.CurrentHealth().Health == component.HealthTypeHealthy
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestController_LoadSource_SingletonClustering(t *testing.T) {
+	tt := []struct {
+		name        string
+		config      string
+		expect      bool
+		expectError string
+	}{
+		{
+			name:   "no clustering block",
+			config: `testcomponents.passthrough "a" { input = "a" }`,
+			expect: false,
+		},
+		{
+			name: "singleton",
+			config: `testcomponents.passthrough "a" {
+				input = "a"
+
+				clustering {
+					singleton = true
+				}
+			}`,
+			expect: true,
+		},
+		{
+			name: "duplicate singleton",
+			config: `testcomponents.passthrough "a" {
+				input = "a"
+
+				clustering { singleton = true }
+				clustering { singleton = false }
+			}`,
+			expectError: "the singleton attribute of the clustering block may only be set once",
+		},
+		{
+			name: "clustering block not supported by the component",
+			config: `testcomponents.passthrough "a" {
+				input = "a"
+
+				clustering {
+					singleton = true
+					enabled   = true
+				}
+			}`,
+			expectError: `unrecognized block name "clustering"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := New(testOptions(t))
+			defer cleanUpController(ctrl)
+
+			f, err := ParseSource(t.Name(), []byte(tc.config))
+			require.NoError(t, err)
+
+			err = ctrl.LoadSource(f, nil)
+			if tc.expectError != "" {
+				require.ErrorContains(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+
+			n := ctrl.loader.Graph().GetByID("testcomponents.passthrough.a").(*controller.BuiltinComponentNode)
+			require.Equal(t, tc.expect, n.Singleton())
+			require.Equal(t, "a", n.Arguments().(testcomponents.PassthroughConfig).Input)
+		})
+	}
+}
+
 func getFields(t *testing.T, g *dag.Graph, nodeID string) (component.Arguments, component.Exports) {
 	t.Helper()
 
@@ -0,0 +1,224 @@
+// Package componentprofile attributes the CPU time and goroutines of an Alloy
+// process to its components.
+//
+// The scheduler of the component controller runs each component under pprof
+// labels identifying it. Profiles collected from the process are then
+// aggregated by these labels.
+//
+// Heap profiles don't record pprof labels, so memory can't be attributed to
+// components this way.
+package componentprofile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+// Labels of the goroutines of components.
+const (
+	LabelComponentPath = "component_path" // Path of the module running the component.
+	LabelComponentID   = "component_id"   // ID of the component within its module.
+)
+
+// Do calls f with the pprof labels of the component id of the module at path
+// set for the current goroutine and the goroutines it starts.
+func Do(ctx context.Context, path, id string, f func(context.Context)) {
+	pprof.Do(ctx, pprof.Labels(LabelComponentPath, path, LabelComponentID, id), f)
+}
+
+// Type is a type of profile which can be attributed to components.
+type Type string
+
+// Supported types of profiles.
+const (
+	TypeCPU       Type = "cpu"
+	TypeGoroutine Type = "goroutine"
+)
+
+// DefaultDuration is the default duration of CPU profiles.
+const DefaultDuration = 10 * time.Second
+
+// Report is the usage of a profiled resource by component.
+type Report struct {
+	Type       Type    `json:"type"`
+	Unit       string  `json:"unit"`
+	Total      int64   `json:"total"`
+	Components []Usage `json:"components"`
+}
+
+// Usage is the usage of a profiled resource by a component. Usage not
+// attributed to any component has empty Path and ID.
+type Usage struct {
+	Path    string  `json:"path"`
+	ID      string  `json:"id"`
+	Value   int64   `json:"value"`
+	Percent float64 `json:"percent"`
+}
+
+// Collect collects a profile of type typ and aggregates it by component. CPU
+// profiles are collected for duration d, or until ctx is canceled.
+func Collect(ctx context.Context, typ Type, d time.Duration) (*Report, error) {
+	var buf bytes.Buffer
+
+	switch typ {
+	case TypeCPU:
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(d):
+		}
+		pprof.StopCPUProfile()
+	case TypeGoroutine:
+		if err := pprof.Lookup("goroutine").WriteTo(&buf, 0); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported profile type %q", typ)
+	}
+
+	p, err := profile.Parse(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("parsing profile: %w", err)
+	}
+	return Aggregate(typ, p), nil
+}
+
+// Aggregate aggregates the samples of p by component. The last sample type of
+// p is used, like pprof does by default.
+func Aggregate(typ Type, p *profile.Profile) *Report {
+	report := &Report{Type: typ}
+	if len(p.SampleType) == 0 {
+		return report
+	}
+	index := len(p.SampleType) - 1
+	report.Unit = p.SampleType[index].Unit
+
+	type key struct{ path, id string }
+	usage := make(map[key]int64)
+	for _, s := range p.Sample {
+		k := key{
+			path: firstLabel(s, LabelComponentPath),
+			id:   firstLabel(s, LabelComponentID),
+		}
+		usage[k] += s.Value[index]
+		report.Total += s.Value[index]
+	}
+
+	for k, v := range usage {
+		u := Usage{Path: k.path, ID: k.id, Value: v}
+		if report.Total > 0 {
+			u.Percent = float64(v) / float64(report.Total) * 100
+		}
+		report.Components = append(report.Components, u)
+	}
+	sort.Slice(report.Components, func(i, j int) bool {
+		a, b := report.Components[i], report.Components[j]
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.ID < b.ID
+	})
+	return report
+}
+
+func firstLabel(s *profile.Sample, key string) string {
+	if values := s.Label[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// WriteTo writes r as a table, with usage not attributed to any component
+// reported as "(other)".
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "PATH\tCOMPONENT\t%s\tPERCENT\n", valueHeader(r.Unit))
+	for _, u := range r.Components {
+		path, id := u.Path, u.ID
+		if path == "" && id == "" {
+			id = "(other)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.2f%%\n", path, id, u.Value, u.Percent)
+	}
+	_ = tw.Flush()
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+func valueHeader(unit string) string {
+	if unit == "" {
+		return "VALUE"
+	}
+	return "VALUE (" + unit + ")"
+}
+
+// Handler returns an HTTP handler which collects a profile and reports it by
+// component.
+//
+// The profile type is set with the type query parameter, cpu by default. The
+// duration of CPU profiles is set in seconds with the seconds query
+// parameter. The report is written as a table, or as JSON if the format query
+// parameter is json.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		typ := Type(query.Get("type"))
+		if typ == "" {
+			typ = TypeCPU
+		}
+		if typ != TypeCPU && typ != TypeGoroutine {
+			http.Error(w, fmt.Sprintf("unsupported profile type %q", typ), http.StatusBadRequest)
+			return
+		}
+
+		format := query.Get("format")
+		if format != "" && format != "text" && format != "json" {
+			http.Error(w, fmt.Sprintf("unsupported format %q", format), http.StatusBadRequest)
+			return
+		}
+
+		d := DefaultDuration
+		if s := query.Get("seconds"); s != "" {
+			seconds, err := strconv.ParseFloat(s, 64)
+			if err != nil || seconds <= 0 {
+				http.Error(w, fmt.Sprintf("invalid seconds %q", s), http.StatusBadRequest)
+				return
+			}
+			d = time.Duration(seconds * float64(time.Second))
+		}
+
+		report, err := Collect(r.Context(), typ, d)
+		if err != nil {
+			// Collecting a CPU profile fails if one is already being collected.
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if format == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(report)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = report.WriteTo(w)
+	})
+}
@@ -0,0 +1,88 @@
+package componentprofile_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/runtime/componentprofile"
+)
+
+func TestAggregate(t *testing.T) {
+	sample := func(value int64, path, id string) *profile.Sample {
+		s := &profile.Sample{Value: []int64{value / 10, value}}
+		if path != "" || id != "" {
+			s.Label = map[string][]string{
+				componentprofile.LabelComponentPath: {path},
+				componentprofile.LabelComponentID:   {id},
+			}
+		}
+		return s
+	}
+
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "samples", Unit: "count"},
+			{Type: "cpu", Unit: "nanoseconds"},
+		},
+		Sample: []*profile.Sample{
+			sample(100, "/", "loki.process.a"),
+			sample(300, "/", "loki.process.b"),
+			sample(200, "/", "loki.process.a"),
+			sample(100, "/import.file.module", "loki.process.a"),
+			sample(300, "", ""),
+		},
+	}
+
+	report := componentprofile.Aggregate(componentprofile.TypeCPU, p)
+	require.Equal(t, &componentprofile.Report{
+		Type:  componentprofile.TypeCPU,
+		Unit:  "nanoseconds",
+		Total: 1000,
+		Components: []componentprofile.Usage{
+			{Path: "", ID: "", Value: 300, Percent: 30},
+			{Path: "/", ID: "loki.process.a", Value: 300, Percent: 30},
+			{Path: "/", ID: "loki.process.b", Value: 300, Percent: 30},
+			{Path: "/import.file.module", ID: "loki.process.a", Value: 100, Percent: 10},
+		},
+	}, report)
+
+	var buf bytes.Buffer
+	_, err := report.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, `PATH                 COMPONENT       VALUE (nanoseconds)  PERCENT
+                     (other)         300                  30.00%
+/                    loki.process.a  300                  30.00%
+/                    loki.process.b  300                  30.00%
+/import.file.module  loki.process.a  100                  10.00%
+`, buf.String())
+}
+
+func TestCollect_Goroutine(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		go componentprofile.Do(ctx, "/", "loki.process.a", func(ctx context.Context) {
+			started <- struct{}{}
+			<-ctx.Done()
+		})
+	}
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+
+	report, err := componentprofile.Collect(ctx, componentprofile.TypeGoroutine, time.Second)
+	require.NoError(t, err)
+	require.Contains(t, report.Components, componentprofile.Usage{
+		Path:    "/",
+		ID:      "loki.process.a",
+		Value:   3,
+		Percent: 3 / float64(report.Total) * 100,
+	})
+}
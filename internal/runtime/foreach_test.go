@@ -0,0 +1,252 @@
+package runtime_test
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/runtime"
+	"github.com/grafana/alloy/internal/runtime/internal/testcomponents"
+	"github.com/grafana/alloy/internal/runtime/logging"
+	"github.com/grafana/alloy/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForeach(t *testing.T) {
+	config := `
+	testcomponents.count "inc" {
+		frequency = "10ms"
+		max = 10
+	}
+
+	foreach "loop" {
+		collection = [1, 2, 3]
+		var = "num"
+
+		template {
+			testcomponents.summation "sum" {
+				input = num * testcomponents.count.inc.count
+			}
+		}
+	}
+	`
+
+	ctrl := runtime.New(testOptions(t))
+	f, err := runtime.ParseSource(t.Name(), []byte(config))
+	require.NoError(t, err)
+
+	require.NoError(t, ctrl.LoadSource(f, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ctrl.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	require.Eventually(t, func() bool {
+		return sameInts(lastAddedByForeachChild(t, ctrl, "foreach.loop"), []int{10, 20, 30})
+	}, 3*time.Second, 10*time.Millisecond)
+}
+
+func TestForeachUpdateCollection(t *testing.T) {
+	config := `
+	foreach "loop" {
+		collection = [1, 2]
+		var = "num"
+
+		template {
+			testcomponents.summation "sum" {
+				input = num
+			}
+		}
+	}
+	`
+	newConfig := `
+	foreach "loop" {
+		collection = [2, 5, 7]
+		var = "num"
+
+		template {
+			testcomponents.summation "sum" {
+				input = num
+			}
+		}
+	}
+	`
+
+	ctrl := runtime.New(testOptions(t))
+	f, err := runtime.ParseSource(t.Name(), []byte(config))
+	require.NoError(t, err)
+
+	require.NoError(t, ctrl.LoadSource(f, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ctrl.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	require.Eventually(t, func() bool {
+		return sameInts(lastAddedByForeachChild(t, ctrl, "foreach.loop"), []int{1, 2})
+	}, 3*time.Second, 10*time.Millisecond)
+
+	idsBefore := foreachChildren(t, ctrl, "foreach.loop")
+
+	f, err = runtime.ParseSource(t.Name(), []byte(newConfig))
+	require.NoError(t, err)
+	require.NoError(t, ctrl.LoadSource(f, nil))
+
+	require.Eventually(t, func() bool {
+		return sameInts(lastAddedByForeachChild(t, ctrl, "foreach.loop"), []int{2, 5, 7})
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// The child created for the element 2 must be kept across reloads.
+	idsAfter := foreachChildren(t, ctrl, "foreach.loop")
+	var kept int
+	for _, id := range idsAfter {
+		for _, prev := range idsBefore {
+			if id == prev {
+				kept++
+			}
+		}
+	}
+	require.Equal(t, 1, kept)
+}
+
+func TestForeachError(t *testing.T) {
+	tt := []errorTestCase{
+		{
+			name: "MissingTemplate",
+			config: `
+			foreach "loop" {
+				collection = [1, 2]
+				var = "num"
+			}
+			`,
+			expectedError: regexp.MustCompile(`missing required block "template"`),
+		},
+		{
+			name: "InvalidVar",
+			config: `
+			foreach "loop" {
+				collection = [1, 2]
+				var = "not valid"
+				template {}
+			}
+			`,
+			expectedError: regexp.MustCompile(`var "not valid" must be a valid identifier`),
+		},
+		{
+			name: "MissingLabel",
+			config: `
+			foreach {
+				collection = [1, 2]
+				var = "num"
+				template {}
+			}
+			`,
+			expectedError: regexp.MustCompile(`foreach blocks must have a label`),
+		},
+		{
+			name: "ForbiddenDeclareLabel",
+			config: `
+			declare "foreach" {}
+			`,
+			expectedError: regexp.MustCompile(`'foreach' is not a valid label for a declare block`),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			defer verifyNoGoroutineLeaks(t)
+			s, err := logging.New(os.Stderr, logging.DefaultOptions)
+			require.NoError(t, err)
+			ctrl := runtime.New(runtime.Options{
+				Logger:       s,
+				DataPath:     t.TempDir(),
+				MinStability: featuregate.StabilityPublicPreview,
+				Reg:          nil,
+				Services:     []service.Service{},
+			})
+			f, err := runtime.ParseSource(t.Name(), []byte(tc.config))
+			require.NoError(t, err)
+			require.NotNil(t, f)
+
+			err = ctrl.LoadSource(f, nil)
+			if err == nil {
+				t.Errorf("Expected error to match regex %q, but got: nil", tc.expectedError)
+			} else if !tc.expectedError.MatchString(err.Error()) {
+				t.Errorf("Expected error to match regex %q, but got: %v", tc.expectedError, err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				ctrl.Run(ctx)
+				close(done)
+			}()
+			cancel()
+			<-done
+		})
+	}
+}
+
+// foreachChildren returns the module IDs of the running children of a foreach
+// block.
+func foreachChildren(t *testing.T, ctrl *runtime.Runtime, nodeID string) []string {
+	t.Helper()
+	info, err := ctrl.GetComponent(component.ID{LocalID: nodeID}, component.InfoOptions{})
+	require.NoError(t, err)
+	return info.ModuleIDs
+}
+
+// lastAddedByForeachChild returns the last value added to the summation
+// component of every running child of a foreach block.
+func lastAddedByForeachChild(t *testing.T, ctrl *runtime.Runtime, nodeID string) []int {
+	t.Helper()
+	var res []int
+	for _, moduleID := range foreachChildren(t, ctrl, nodeID) {
+		info, err := ctrl.GetComponent(component.ID{
+			ModuleID: moduleID,
+			LocalID:  "testcomponents.summation.sum",
+		}, component.InfoOptions{GetExports: true})
+		if err != nil {
+			// The child may not be running yet.
+			continue
+		}
+		exports, ok := info.Exports.(testcomponents.SummationExports)
+		if !ok {
+			continue
+		}
+		res = append(res, exports.LastAdded)
+	}
+	return res
+}
+
+func sameInts(actual, expected []int) bool {
+	if len(actual) != len(expected) {
+		return false
+	}
+	sort.Ints(actual)
+	sort.Ints(expected)
+	for i := range actual {
+		if actual[i] != expected[i] {
+			return false
+		}
+	}
+	return true
+}
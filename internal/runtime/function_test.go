@@ -0,0 +1,241 @@
+package runtime_test
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/runtime"
+	"github.com/grafana/alloy/internal/runtime/internal/testcomponents"
+	"github.com/grafana/alloy/internal/runtime/logging"
+	"github.com/grafana/alloy/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunction(t *testing.T) {
+	tt := []struct {
+		name     string
+		config   string
+		expected int
+	}{
+		{
+			name: "Local",
+			config: `
+			function "scale" {
+				argument "value" {}
+				argument "factor" {
+					optional = true
+					default  = 2
+				}
+
+				result = argument.value.value * argument.factor.value
+			}
+
+			testcomponents.summation "sum" {
+				input = scale(5) + scale(1, 10)
+			}
+			`,
+			expected: 20,
+		},
+		{
+			name: "CallOtherFunction",
+			config: `
+			function "inc" {
+				argument "value" {}
+				result = argument.value.value + 1
+			}
+
+			function "inc_twice" {
+				argument "value" {}
+				result = inc(inc(argument.value.value))
+			}
+
+			testcomponents.summation "sum" {
+				input = inc_twice(3)
+			}
+			`,
+			expected: 5,
+		},
+		{
+			name: "CalledFromDeclare",
+			config: `
+			function "negate" {
+				argument "value" {}
+				result = -argument.value.value
+			}
+
+			declare "a" {
+				argument "input" {}
+
+				export "output" {
+					value = negate(argument.input.value)
+				}
+			}
+
+			a "cc" {
+				input = 7
+			}
+
+			testcomponents.summation "sum" {
+				input = a.cc.output
+			}
+			`,
+			expected: -7,
+		},
+		{
+			name: "ShadowsStdlib",
+			config: `
+			function "coalesce" {
+				result = 42
+			}
+
+			testcomponents.summation "sum" {
+				input = coalesce()
+			}
+			`,
+			expected: 42,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := runtime.New(testOptions(t))
+			f, err := runtime.ParseSource(t.Name(), []byte(tc.config))
+			require.NoError(t, err)
+			require.NoError(t, ctrl.LoadSource(f, nil))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				ctrl.Run(ctx)
+				close(done)
+			}()
+			defer func() {
+				cancel()
+				<-done
+			}()
+
+			require.Eventually(t, func() bool {
+				export := getExport[testcomponents.SummationExports](t, ctrl, "", "testcomponents.summation.sum")
+				return export.LastAdded == tc.expected
+			}, 3*time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestFunctionError(t *testing.T) {
+	tt := []errorTestCase{
+		{
+			name: "MissingResult",
+			config: `
+			function "f" {
+				argument "value" {}
+			}
+			`,
+			expectedError: regexp.MustCompile(`missing required attribute "result" in function "f"`),
+		},
+		{
+			name: "Cycle",
+			config: `
+			function "a" {
+				result = b()
+			}
+
+			function "b" {
+				result = a()
+			}
+			`,
+			expectedError: regexp.MustCompile(`cycle: function\.(a|b), function\.(a|b)`),
+		},
+		{
+			name: "TooManyArguments",
+			config: `
+			function "f" {
+				argument "value" {}
+				result = argument.value.value
+			}
+
+			testcomponents.summation "sum" {
+				input = f(1, 2)
+			}
+			`,
+			expectedError: regexp.MustCompile(`expected at most 1 args, got 2`),
+		},
+		{
+			name: "MissingArgument",
+			config: `
+			function "f" {
+				argument "value" {}
+				result = argument.value.value
+			}
+
+			testcomponents.summation "sum" {
+				input = f()
+			}
+			`,
+			expectedError: regexp.MustCompile(`missing required argument "value"`),
+		},
+		{
+			name: "OptionalBeforeRequired",
+			config: `
+			function "f" {
+				argument "a" {
+					optional = true
+				}
+				argument "b" {}
+				result = 1
+			}
+			`,
+			expectedError: regexp.MustCompile(`required argument "b" of function "f" must be declared before optional arguments`),
+		},
+		{
+			name: "Redefined",
+			config: `
+			function "f" {
+				result = 1
+			}
+
+			function "f" {
+				result = 2
+			}
+			`,
+			expectedError: regexp.MustCompile(`block function.f already declared`),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			defer verifyNoGoroutineLeaks(t)
+			s, err := logging.New(os.Stderr, logging.DefaultOptions)
+			require.NoError(t, err)
+			ctrl := runtime.New(runtime.Options{
+				Logger:       s,
+				DataPath:     t.TempDir(),
+				MinStability: featuregate.StabilityPublicPreview,
+				Reg:          nil,
+				Services:     []service.Service{},
+			})
+			f, err := runtime.ParseSource(t.Name(), []byte(tc.config))
+			require.NoError(t, err)
+			require.NotNil(t, f)
+
+			err = ctrl.LoadSource(f, nil)
+			if err == nil {
+				t.Errorf("Expected error to match regex %q, but got: nil", tc.expectedError)
+			} else if !tc.expectedError.MatchString(err.Error()) {
+				t.Errorf("Expected error to match regex %q, but got: %v", tc.expectedError, err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				ctrl.Run(ctx)
+				close(done)
+			}()
+			cancel()
+			<-done
+		})
+	}
+}
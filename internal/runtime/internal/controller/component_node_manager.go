@@ -27,8 +27,15 @@ func NewComponentNodeManager(globals ComponentGlobals, componentReg ComponentReg
 	}
 }
 
-// CreateComponentNode creates a new builtin component or a new custom component.
+// CreateComponentNode creates a new builtin component, a new custom component
+// or a new foreach node.
 func (m *ComponentNodeManager) createComponentNode(componentName string, block *ast.BlockStmt) (ComponentNode, error) {
+	if componentName == foreachType {
+		if block.Label == "" {
+			return nil, fmt.Errorf("%s blocks must have a label", foreachType)
+		}
+		return NewForeachNode(m.globals, block, m.getCustomComponentRegistry), nil
+	}
 	if isCustomComponent(m.customComponentReg, block.Name[0]) {
 		return NewCustomComponentNode(m.globals, block, m.getCustomComponentConfig), nil
 	}
@@ -100,6 +107,13 @@ func findImportedDeclare(reg *CustomComponentRegistry, namespace string, compone
 	return nil, nil
 }
 
+// getCustomComponentRegistry returns the current custom component registry.
+func (m *ComponentNodeManager) getCustomComponentRegistry() *CustomComponentRegistry {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+	return m.customComponentReg
+}
+
 func (m *ComponentNodeManager) setCustomComponentRegistry(reg *CustomComponentRegistry) {
 	m.mut.Lock()
 	defer m.mut.Unlock()
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/grafana/alloy/internal/runtime/internal/dag"
 	"github.com/grafana/alloy/syntax/ast"
@@ -26,41 +27,99 @@ type Reference struct {
 
 // ComponentReferences returns the list of references a component is making to
 // other components.
-func ComponentReferences(cn dag.Node, g *dag.Graph) ([]Reference, diag.Diagnostics) {
+//
+// References which can't be resolved in g are reported as errors unless they
+// refer to a variable of scope or to the stdlib. scope may be nil.
+func ComponentReferences(cn dag.Node, g *dag.Graph, scope *vm.Scope) ([]Reference, diag.Diagnostics) {
 	var (
 		traversals []Traversal
 
+		// Traversals which are allowed to not be resolved.
+		optionalTraversals []Traversal
+
 		diags diag.Diagnostics
 	)
 
 	switch cn := cn.(type) {
+	case *FunctionNode:
+		// The result of a function may only reference the arguments of the
+		// function and other functions, which the loader wires separately.
+	case *ForeachNode:
+		// References made from the template of a foreach block may point to
+		// components declared in the template or to the iteration variable,
+		// which only exist once the template is instantiated. Errors for these
+		// references are reported by the instantiated children instead.
+		if cn.Block() != nil {
+			body, template := splitForeachBody(cn.Block().Body)
+			traversals = expressionsFromBody(body)
+			if template != nil {
+				optionalTraversals = expressionsFromBody(template.Body)
+			}
+		}
 	case BlockNode:
 		if cn.Block() != nil {
 			traversals = expressionsFromBody(cn.Block().Body)
 		}
 	}
 
-	refs := make([]Reference, 0, len(traversals))
+	refs := make([]Reference, 0, len(traversals)+len(optionalTraversals))
 	for _, t := range traversals {
 		ref, resolveDiags := resolveTraversal(t, g)
 		if resolveDiags.HasErrors() {
-			// We use an empty scope to determine if a reference refers to something in
-			// the stdlib, since vm.Scope.Lookup will search the scope tree + the
-			// stdlib.
+			// vm.Scope.Lookup will search the scope tree + the stdlib.
 			//
-			// Any call to an stdlib function is ignored.
-			var emptyScope vm.Scope
-			if _, exist := emptyScope.Lookup(t[0].Name); !exist {
+			// Any reference to a variable of the scope or call to an stdlib
+			// function is ignored.
+			if _, exist := scope.Lookup(t[0].Name); !exist {
 				diags = append(diags, resolveDiags...)
 			}
 			continue
 		}
 		refs = append(refs, ref)
 	}
+	for _, t := range optionalTraversals {
+		if ref, resolveDiags := resolveTraversal(t, g); !resolveDiags.HasErrors() {
+			refs = append(refs, ref)
+		}
+	}
 
 	return refs, diags
 }
 
+// ReferenceAttributes returns the names of the attributes of the block of cn
+// which reference other nodes of g, keyed by the ID of the referenced node.
+// Attributes of nested blocks are named after their path in the block, for
+// example "rule.source_labels". References which can't be resolved in g are
+// ignored.
+func ReferenceAttributes(cn BlockNode, g *dag.Graph) map[string][]string {
+	attrs := make(map[string][]string)
+	if cn.Block() != nil {
+		collectReferenceAttributes(attrs, "", cn.Block().Body, g)
+	}
+	return attrs
+}
+
+func collectReferenceAttributes(attrs map[string][]string, prefix string, body ast.Body, g *dag.Graph) {
+	for _, stmt := range body {
+		switch stmt := stmt.(type) {
+		case *ast.AttributeStmt:
+			name := prefix + stmt.Name.Name
+			for _, t := range expressionsFromBody(ast.Body{stmt}) {
+				ref, diags := resolveTraversal(t, g)
+				if diags.HasErrors() {
+					continue
+				}
+				id := ref.Target.NodeID()
+				if !slices.Contains(attrs[id], name) {
+					attrs[id] = append(attrs[id], name)
+				}
+			}
+		case *ast.BlockStmt:
+			collectReferenceAttributes(attrs, prefix+stmt.GetBlockName()+".", stmt.Body, g)
+		}
+	}
+}
+
 // expressionsFromSyntaxBody recurses through body and finds all variable
 // references.
 func expressionsFromBody(body ast.Body) []Traversal {
@@ -5,26 +5,35 @@ import (
 	"sync"
 
 	"github.com/grafana/alloy/syntax/ast"
+	"github.com/grafana/alloy/syntax/vm"
 )
 
-// CustomComponentRegistry holds custom component definitions that are available in the context.
+// CustomComponentRegistry holds custom component and function definitions that are available in the context.
 // The definitions are either imported, declared locally, or declared in a parent registry.
 // Imported definitions are stored inside of the corresponding import registry.
 type CustomComponentRegistry struct {
 	parent *CustomComponentRegistry // nil if root config
+	scope  *vm.Scope                // Extra variables exposed to the loaded config; nil if none
 
-	mut      sync.RWMutex
-	imports  map[string]*CustomComponentRegistry // importNamespace: importScope
-	declares map[string]ast.Body                 // customComponentName: template
+	mut       sync.RWMutex
+	imports   map[string]*CustomComponentRegistry // importNamespace: importScope
+	declares  map[string]ast.Body                 // customComponentName: template
+	functions map[string]*ast.BlockStmt           // functionName: function block
 }
 
 // NewCustomComponentRegistry creates a new CustomComponentRegistry with a parent.
 // parent can be nil.
-func NewCustomComponentRegistry(parent *CustomComponentRegistry) *CustomComponentRegistry {
+//
+// scope holds variables which are made available to the config loaded with
+// the registry, in addition to its own components and arguments. scope can
+// be nil.
+func NewCustomComponentRegistry(parent *CustomComponentRegistry, scope *vm.Scope) *CustomComponentRegistry {
 	return &CustomComponentRegistry{
-		parent:   parent,
-		declares: make(map[string]ast.Body),
-		imports:  make(map[string]*CustomComponentRegistry),
+		parent:    parent,
+		scope:     scope,
+		declares:  make(map[string]ast.Body),
+		imports:   make(map[string]*CustomComponentRegistry),
+		functions: make(map[string]*ast.BlockStmt),
 	}
 }
 
@@ -35,6 +44,15 @@ func (s *CustomComponentRegistry) getDeclare(name string) (ast.Body, bool) {
 	return declare, ok
 }
 
+// Scope returns the variables exposed to the config loaded with the registry.
+// Scope is safe to call on a nil registry.
+func (s *CustomComponentRegistry) Scope() *vm.Scope {
+	if s == nil {
+		return nil
+	}
+	return s.scope
+}
+
 func (s *CustomComponentRegistry) getImport(name string) (*CustomComponentRegistry, bool) {
 	s.mut.RLock()
 	defer s.mut.RUnlock()
@@ -49,6 +67,45 @@ func (s *CustomComponentRegistry) registerDeclare(declare *ast.BlockStmt) {
 	s.declares[declare.Label] = declare.Body
 }
 
+// registerFunction stores a local function block.
+func (s *CustomComponentRegistry) registerFunction(function *ast.BlockStmt) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.functions[function.Label] = function
+}
+
+// FunctionScope returns a scope exposing the functions which can be called
+// from the config loaded with the registry: local functions, functions of the
+// parent registries and imported functions, which are exposed under the
+// namespace of their import. Local functions take precedence over functions
+// of the parent registries.
+//
+// FunctionScope is safe to call on a nil registry.
+func (s *CustomComponentRegistry) FunctionScope() *vm.Scope {
+	if s == nil {
+		return nil
+	}
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	namespaces := &vm.Scope{
+		Parent:    s.parent.FunctionScope(),
+		Variables: make(map[string]any, len(s.imports)),
+	}
+	for namespace, imported := range s.imports {
+		// The import registry is nil until the content of the import is loaded.
+		functions := make(map[string]any)
+		if scope := imported.FunctionScope(); scope != nil {
+			for name, value := range flattenScope(scope) {
+				functions[name] = value
+			}
+		}
+		namespaces.Variables[namespace] = functions
+	}
+	return newFunctionScope(namespaces, s.functions)
+}
+
 // registerImport stores the import namespace.
 // The content will be added later during evaluation.
 // It's important to register it before populating the component nodes
@@ -69,19 +126,37 @@ func (s *CustomComponentRegistry) updateImportContent(importNode *ImportConfigNo
 	if _, exist := s.imports[importNode.label]; !exist {
 		panic(fmt.Errorf("import %q was not registered", importNode.label))
 	}
-	importScope := NewCustomComponentRegistry(nil)
+	importScope := NewCustomComponentRegistry(nil, nil)
 	importScope.declares = importNode.ImportedDeclares()
+	importScope.functions = importNode.ImportedFunctions()
 	importScope.updateImportContentChildren(importNode)
 	s.imports[importNode.label] = importScope
 }
 
 // updateImportContentChildren recurse through the children of an import node
-// and update their scope with the imported declare blocks.
+// and update their scope with the imported declare and function blocks.
 func (s *CustomComponentRegistry) updateImportContentChildren(importNode *ImportConfigNode) {
 	for _, child := range importNode.ImportConfigNodesChildren() {
-		childScope := NewCustomComponentRegistry(nil)
+		childScope := NewCustomComponentRegistry(nil, nil)
 		childScope.declares = child.ImportedDeclares()
+		childScope.functions = child.ImportedFunctions()
 		childScope.updateImportContentChildren(child)
 		s.imports[child.label] = childScope
 	}
 }
+
+// flattenScope returns the variables of scope and its parents in a single map.
+// Variables of scope take precedence over the variables of its parents.
+func flattenScope(scope *vm.Scope) map[string]any {
+	if scope == nil {
+		return nil
+	}
+	variables := flattenScope(scope.Parent)
+	if variables == nil {
+		variables = make(map[string]any, len(scope.Variables))
+	}
+	for name, value := range scope.Variables {
+		variables[name] = value
+	}
+	return variables
+}
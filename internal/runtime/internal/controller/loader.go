@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/alloy/internal/service"
 	"github.com/grafana/alloy/syntax/ast"
 	"github.com/grafana/alloy/syntax/diag"
+	"github.com/grafana/alloy/syntax/scanner"
 	"github.com/grafana/dskit/backoff"
 	"github.com/hashicorp/go-multierror"
 	"go.opentelemetry.io/otel/attribute"
@@ -44,6 +45,7 @@ type Loader struct {
 	originalGraph        *dag.Graph
 	componentNodes       []ComponentNode
 	declareNodes         map[string]*DeclareNode
+	functionNodes        map[string]*FunctionNode
 	importConfigNodes    map[string]*ImportConfigNode
 	serviceNodes         []*ServiceNode
 	cache                *valueCache
@@ -121,6 +123,7 @@ type ApplyOptions struct {
 	ComponentBlocks []*ast.BlockStmt // pieces of config that can be used to instantiate builtin components and services
 	ConfigBlocks    []*ast.BlockStmt // pieces of config that can be used to instantiate config nodes
 	DeclareBlocks   []*ast.BlockStmt // pieces of config that can be used as templates to instantiate custom components
+	FunctionBlocks  []*ast.BlockStmt // pieces of config that define functions callable from expressions
 
 	// CustomComponentRegistry holds custom component templates.
 	// The definition of a custom component instantiated inside of the loaded config
@@ -154,8 +157,13 @@ func (l *Loader) Apply(options ApplyOptions) diag.Diagnostics {
 
 	// Create a new CustomComponentRegistry based on the provided one.
 	// The provided one should be nil for the root config.
-	l.componentNodeManager.setCustomComponentRegistry(NewCustomComponentRegistry(options.CustomComponentRegistry))
-	newGraph, diags := l.loadNewGraph(options.Args, options.ComponentBlocks, options.ConfigBlocks, options.DeclareBlocks)
+	l.componentNodeManager.setCustomComponentRegistry(NewCustomComponentRegistry(options.CustomComponentRegistry, nil))
+
+	// Expose the variables provided by the registry (e.g., the iteration
+	// variable of a foreach block) to the loaded config.
+	l.cache.SetScope(options.CustomComponentRegistry.Scope())
+
+	newGraph, diags := l.loadNewGraph(options.Args, options.ComponentBlocks, options.ConfigBlocks, options.DeclareBlocks, options.FunctionBlocks)
 	if diags.HasErrors() {
 		return diags
 	}
@@ -278,7 +286,7 @@ func (l *Loader) Cleanup(stopWorkerPool bool) {
 }
 
 // loadNewGraph creates a new graph from the provided blocks and validates it.
-func (l *Loader) loadNewGraph(args map[string]any, componentBlocks []*ast.BlockStmt, configBlocks []*ast.BlockStmt, declareBlocks []*ast.BlockStmt) (dag.Graph, diag.Diagnostics) {
+func (l *Loader) loadNewGraph(args map[string]any, componentBlocks []*ast.BlockStmt, configBlocks []*ast.BlockStmt, declareBlocks []*ast.BlockStmt, functionBlocks []*ast.BlockStmt) (dag.Graph, diag.Diagnostics) {
 	var g dag.Graph
 
 	// Split component blocks into blocks for components and services.
@@ -292,6 +300,10 @@ func (l *Loader) loadNewGraph(args map[string]any, componentBlocks []*ast.BlockS
 	declareDiags := l.populateDeclareNodes(&g, declareBlocks)
 	diags = append(diags, declareDiags...)
 
+	// Fill our graph with function blocks, must be added before componentNodes.
+	functionDiags := l.populateFunctionNodes(&g, functionBlocks)
+	diags = append(diags, functionDiags...)
+
 	// Fill our graph with config blocks.
 	configBlockDiags := l.populateConfigBlockNodes(args, &g, configBlocks)
 	diags = append(diags, configBlockDiags...)
@@ -300,6 +312,10 @@ func (l *Loader) loadNewGraph(args map[string]any, componentBlocks []*ast.BlockS
 	componentNodeDiags := l.populateComponentNodes(&g, componentBlocks)
 	diags = append(diags, componentNodeDiags...)
 
+	// Expose the functions which are now registered so that calls to them are
+	// recognized when wiring the edges.
+	l.cache.SetFunctions(l.componentNodeManager.customComponentReg.FunctionScope())
+
 	// Write up the edges of the graph
 	wireDiags := l.wireGraphEdges(&g)
 	diags = append(diags, wireDiags...)
@@ -350,10 +366,10 @@ func (l *Loader) populateDeclareNodes(g *dag.Graph, declareBlocks []*ast.BlockSt
 	for _, declareBlock := range declareBlocks {
 		id := BlockComponentID(declareBlock).String()
 
-		if declareBlock.Label == declareType {
+		if declareBlock.Label == declareType || declareBlock.Label == foreachType || declareBlock.Label == functionType {
 			diags.Add(diag.Diagnostic{
 				Severity: diag.SeverityLevelError,
-				Message:  "'declare' is not a valid label for a declare block",
+				Message:  fmt.Sprintf("'%s' is not a valid label for a declare block", declareBlock.Label),
 				StartPos: ast.StartPos(declareBlock).Position(),
 				EndPos:   ast.EndPos(declareBlock).Position(),
 			})
@@ -378,6 +394,44 @@ func (l *Loader) populateDeclareNodes(g *dag.Graph, declareBlocks []*ast.BlockSt
 	return diags
 }
 
+func (l *Loader) populateFunctionNodes(g *dag.Graph, functionBlocks []*ast.BlockStmt) diag.Diagnostics {
+	var (
+		diags    diag.Diagnostics
+		node     *FunctionNode
+		blockMap = make(map[string]*ast.BlockStmt, len(functionBlocks))
+	)
+	l.functionNodes = map[string]*FunctionNode{}
+	for _, functionBlock := range functionBlocks {
+		id := BlockComponentID(functionBlock).String()
+
+		if !scanner.IsValidIdentifier(functionBlock.Label) {
+			diags.Add(diag.Diagnostic{
+				Severity: diag.SeverityLevelError,
+				Message:  fmt.Sprintf("'%s' is not a valid label for a function block", functionBlock.Label),
+				StartPos: ast.StartPos(functionBlock).Position(),
+				EndPos:   ast.EndPos(functionBlock).Position(),
+			})
+			continue
+		}
+
+		if diag, defined := blockAlreadyDefined(blockMap, id, functionBlock); defined {
+			diags = append(diags, diag)
+			continue
+		}
+
+		if exist := l.graph.GetByID(id); exist != nil {
+			node = exist.(*FunctionNode)
+			node.UpdateBlock(functionBlock)
+		} else {
+			node = NewFunctionNode(functionBlock)
+		}
+		l.componentNodeManager.customComponentReg.registerFunction(functionBlock)
+		l.functionNodes[node.label] = node
+		g.Add(node)
+	}
+	return diags
+}
+
 // blockAlreadyDefined returns (diag, true) if the given id is already in the provided blockMap.
 // else it adds the block to the map and returns (empty diag, false).
 func blockAlreadyDefined(blockMap map[string]*ast.BlockStmt, id string, block *ast.BlockStmt) (diag.Diagnostic, bool) {
@@ -421,7 +475,7 @@ func (l *Loader) populateServiceNodes(g *dag.Graph, serviceBlocks []*ast.BlockSt
 		if exist := l.graph.GetByID(id); exist != nil {
 			node = exist.(*ServiceNode)
 		} else {
-			node = NewServiceNode(l.host, svc)
+			node = NewServiceNode(l.globals, l.host, svc)
 		}
 
 		node.UpdateBlock(nil) // Reset configuration to nil.
@@ -613,10 +667,22 @@ func (l *Loader) wireGraphEdges(g *dag.Graph) diag.Diagnostics {
 			continue
 		case *CustomComponentNode:
 			l.wireCustomComponentNode(g, n)
+		case *ForeachNode:
+			// The template of a foreach block may instantiate custom components;
+			// wire the node to the import/declare nodes that they depend on.
+			if template := n.Template(); template != nil {
+				refs := l.findCustomComponentReferences(template)
+				for ref := range refs {
+					g.AddEdge(dag.Edge{From: n, To: ref})
+				}
+			}
 		}
 
+		// Wire the calls to user-defined functions.
+		l.wireFunctionCalls(g, n)
+
 		// Finally, wire component references.
-		refs, nodeDiags := ComponentReferences(n, g)
+		refs, nodeDiags := ComponentReferences(n, g, l.cache.ReferenceScope())
 		for _, ref := range refs {
 			g.AddEdge(dag.Edge{From: n, To: ref.Target})
 		}
@@ -642,6 +708,28 @@ func (l *Loader) wireCustomComponentNode(g *dag.Graph, cc *CustomComponentNode)
 	}
 }
 
+// wireFunctionCalls wires a node to the function and import nodes defining the
+// functions it calls.
+func (l *Loader) wireFunctionCalls(g *dag.Graph, n dag.Node) {
+	bn, ok := n.(BlockNode)
+	if !ok || bn.Block() == nil {
+		return
+	}
+	for _, t := range expressionsFromBody(bn.Block().Body) {
+		// Imported functions are checked first because they are exposed under
+		// the namespace of their import.
+		if len(t) > 1 {
+			if importNode, ok := l.importConfigNodes[t[0].Name]; ok {
+				g.AddEdge(dag.Edge{From: n, To: importNode})
+				continue
+			}
+		}
+		if functionNode, ok := l.functionNodes[t[0].Name]; ok {
+			g.AddEdge(dag.Edge{From: n, To: functionNode})
+		}
+	}
+}
+
 // Variables returns the Variables the Loader exposes for other components to
 // reference.
 func (l *Loader) Variables() map[string]interface{} {
@@ -715,6 +803,7 @@ func (l *Loader) EvaluateDependants(ctx context.Context, updatedNodes []*QueuedN
 		case *ImportConfigNode:
 			// Update the scope with the imported content.
 			l.componentNodeManager.customComponentReg.updateImportContent(parentNode)
+			l.cache.SetFunctions(l.componentNodeManager.customComponentReg.FunctionScope())
 		}
 		// We collect all nodes directly incoming to parent.
 		_ = dag.WalkIncomingNodes(l.graph, parent.Node, func(n dag.Node) error {
@@ -853,6 +942,7 @@ func (l *Loader) postEvaluate(logger log.Logger, bn BlockNode, err error) error
 		}
 	case *ImportConfigNode:
 		l.componentNodeManager.customComponentReg.updateImportContent(c)
+		l.cache.SetFunctions(l.componentNodeManager.customComponentReg.FunctionScope())
 	}
 
 	if err != nil {
@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging"
 	"github.com/grafana/alloy/internal/runtime/tracing"
+	"github.com/grafana/alloy/internal/util"
 	"github.com/grafana/alloy/syntax/ast"
 	"github.com/grafana/alloy/syntax/vm"
 )
@@ -74,6 +75,96 @@ type ComponentGlobals struct {
 	NewModuleController  func(id string) ModuleController       // Func to generate a module controller.
 	GetServiceData       func(name string) (interface{}, error) // Get data for a service.
 	EnableCommunityComps bool                                   // Enables the use of community components.
+	ValidateOnly         bool                                   // Only decode arguments, without building components or updating services.
+	RestartPolicy        RestartPolicy                          // Restart policy of components without a restart block.
+
+	// ValidateExports, if set, returns the exports of a component from its
+	// global ID when ValidateOnly is set. Components which aren't built keep
+	// the zero value of their exports otherwise.
+	ValidateExports func(globalID string) (component.Exports, bool)
+}
+
+// RestartBlockName is the name of the reserved block of builtin components
+// which overrides the restart policy of the component.
+const RestartBlockName = "restart"
+
+// RestartArguments are the arguments of the restart block. Unset arguments
+// default to the restart policy of the controller.
+type RestartArguments struct {
+	MaxRestarts    int           `alloy:"max_restarts,attr,optional"`
+	InitialBackoff time.Duration `alloy:"initial_backoff,attr,optional"`
+	MaxBackoff     time.Duration `alloy:"max_backoff,attr,optional"`
+}
+
+// ClusteringBlockName is the name of the block of builtin components whose
+// singleton attribute is handled by the controller. Other statements of the
+// block are decoded into the arguments of the component.
+const ClusteringBlockName = "clustering"
+
+// ClusteringArguments are the arguments of the clustering block handled by
+// the controller.
+type ClusteringArguments struct {
+	// Singleton makes the component run only on the leader of the cluster for
+	// the component.
+	Singleton bool `alloy:"singleton,attr,optional"`
+}
+
+// reservedStatements are the statements of the body of a builtin component
+// which are handled by the controller.
+type reservedStatements struct {
+	restart   []*ast.BlockStmt     // Restart blocks.
+	singleton []*ast.AttributeStmt // Singleton attributes of clustering blocks.
+}
+
+// splitReservedStatements separates the statements of the body of a builtin
+// component handled by the controller from the statements decoded into its
+// arguments.
+//
+// Clustering blocks are only decoded into the arguments if they have
+// statements other than the singleton attribute, so that any component can
+// be made a singleton.
+func splitReservedStatements(body ast.Body) (args ast.Body, reserved reservedStatements) {
+	args = make(ast.Body, 0, len(body))
+	for _, stmt := range body {
+		b, ok := stmt.(*ast.BlockStmt)
+		if !ok || len(b.Name) != 1 {
+			args = append(args, stmt)
+			continue
+		}
+
+		switch b.Name[0] {
+		case RestartBlockName:
+			reserved.restart = append(reserved.restart, b)
+			continue
+		case ClusteringBlockName:
+			clusteringBody, singleton := splitSingletonAttributes(b.Body)
+			if len(singleton) == 0 {
+				break
+			}
+			reserved.singleton = append(reserved.singleton, singleton...)
+			if len(clusteringBody) == 0 {
+				continue
+			}
+			clustering := *b
+			clustering.Body = clusteringBody
+			stmt = &clustering
+		}
+		args = append(args, stmt)
+	}
+	return args, reserved
+}
+
+// splitSingletonAttributes separates the singleton attributes from the body of
+// a clustering block.
+func splitSingletonAttributes(body ast.Body) (rest ast.Body, singleton []*ast.AttributeStmt) {
+	for _, stmt := range body {
+		if attr, ok := stmt.(*ast.AttributeStmt); ok && attr.Name.Name == "singleton" {
+			singleton = append(singleton, attr)
+			continue
+		}
+		rest = append(rest, stmt)
+	}
+	return rest, singleton
 }
 
 // BuiltinComponentNode is a controller node which manages a builtin component.
@@ -92,13 +183,25 @@ type BuiltinComponentNode struct {
 	registry          *prometheus.Registry
 	exportsType       reflect.Type
 	moduleController  ModuleController
-	OnBlockNodeUpdate func(cn BlockNode) // Informs controller that we need to reevaluate
-
-	mut     sync.RWMutex
-	block   *ast.BlockStmt // Current Alloy block to derive args from
-	eval    *vm.Evaluator
-	managed component.Component // Inner managed component
-	args    component.Arguments // Evaluated arguments for the managed component
+	validateOnly      bool                                            // Decode arguments without building the managed component
+	validateExports   func(globalID string) (component.Exports, bool) // Exports used when validating
+	OnBlockNodeUpdate func(cn BlockNode)                              // Informs controller that we need to reevaluate
+	defaultRestart    RestartPolicy                                   // Restart policy used when the block has no restart block
+	restarts          prometheus.Counter                              // Number of restarts of the managed component
+	getCluster        func() (leaderElector, error)                   // Returns the cluster used to elect the leader of singletons
+	singletonUpdate   chan struct{}                                   // Signals that the singleton setting changed
+
+	mut       sync.RWMutex
+	block     *ast.BlockStmt // Current Alloy block to derive args from
+	eval      *vm.Evaluator
+	reserved  reservedStatements  // Statements of the current Alloy block handled by the controller
+	restart   RestartPolicy       // Evaluated restart policy
+	singleton bool                // Evaluated singleton setting
+	managed   component.Component // Inner managed component
+	args      component.Arguments // Evaluated arguments for the managed component
+
+	managedReg util.Unregisterer // Registerer of the metrics of the managed component
+	managedRan bool              // Whether the managed component was already run
 
 	// NOTE(rfratto): health and exports have their own mutex because they may be
 	// set asynchronously while mut is still being held (i.e., when calling Evaluate
@@ -112,7 +215,10 @@ type BuiltinComponentNode struct {
 	exports    component.Exports // Evaluated exports for the managed component
 }
 
-var _ ComponentNode = (*BuiltinComponentNode)(nil)
+var (
+	_ ComponentNode   = (*BuiltinComponentNode)(nil)
+	_ RestartableNode = (*BuiltinComponentNode)(nil)
+)
 
 // NewBuiltinComponentNode creates a new BuiltinComponentNode from an initial ast.BlockStmt.
 // The underlying managed component isn't created until Evaluate is called.
@@ -138,6 +244,8 @@ func NewBuiltinComponentNode(globals ComponentGlobals, reg component.Registratio
 		globalID = path.Join(globals.ControllerID, nodeID)
 	}
 
+	argsBody, reserved := splitReservedStatements(b.Body)
+
 	cn := &BuiltinComponentNode{
 		id:                id,
 		globalID:          globalID,
@@ -147,10 +255,17 @@ func NewBuiltinComponentNode(globals ComponentGlobals, reg component.Registratio
 		reg:               reg,
 		exportsType:       getExportsType(reg),
 		moduleController:  globals.NewModuleController(globalID),
+		validateOnly:      globals.ValidateOnly,
+		validateExports:   globals.ValidateExports,
 		OnBlockNodeUpdate: globals.OnBlockNodeUpdate,
+		defaultRestart:    globals.RestartPolicy,
+		getCluster:        clusterFromServiceData(globals.GetServiceData),
+		singletonUpdate:   make(chan struct{}, 1),
 
-		block: b,
-		eval:  vm.New(b.Body),
+		block:    b,
+		eval:     vm.New(argsBody),
+		reserved: reserved,
+		restart:  globals.RestartPolicy,
 
 		// Prepopulate arguments and exports with their zero values.
 		args:    reg.Args,
@@ -161,6 +276,12 @@ func NewBuiltinComponentNode(globals ComponentGlobals, reg component.Registratio
 	}
 	cn.managedOpts = getManagedOptions(globals, cn)
 
+	cn.restarts = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "alloy_component_restarts_total",
+		Help: "Number of times the component was restarted after exiting with an error.",
+	})
+	cn.managedOpts.Registerer.MustRegister(cn.restarts)
+
 	return cn
 }
 
@@ -232,10 +353,13 @@ func (cn *BuiltinComponentNode) UpdateBlock(b *ast.BlockStmt) {
 		panic("UpdateBlock called with an block with a different component ID")
 	}
 
+	argsBody, reserved := splitReservedStatements(b.Body)
+
 	cn.mut.Lock()
 	defer cn.mut.Unlock()
 	cn.block = b
-	cn.eval = vm.New(b.Body)
+	cn.eval = vm.New(argsBody)
+	cn.reserved = reserved
 }
 
 // Evaluate implements BlockNode and updates the arguments for the managed component
@@ -261,22 +385,51 @@ func (cn *BuiltinComponentNode) evaluate(scope *vm.Scope) error {
 	cn.mut.Lock()
 	defer cn.mut.Unlock()
 
+	restart, err := cn.evaluateRestart(scope)
+	if err != nil {
+		return err
+	}
+	singleton, err := cn.evaluateSingleton(scope)
+	if err != nil {
+		return err
+	}
+
 	argsPointer := cn.reg.CloneArguments()
 	if err := cn.eval.Evaluate(scope, argsPointer); err != nil {
 		return fmt.Errorf("decoding configuration: %w", err)
 	}
+	cn.restart = restart
+	if cn.singleton != singleton {
+		cn.singleton = singleton
+		select {
+		case cn.singletonUpdate <- struct{}{}:
+		default: // Update already pending.
+		}
+	}
 
 	// args is always a pointer to the args type, so we want to deference it since
 	// components expect a non-pointer.
 	argsCopyValue := reflect.ValueOf(argsPointer).Elem().Interface()
 
+	if cn.validateOnly {
+		// The component is never built when validating: its exports keep their
+		// zero value unless they're provided by validateExports.
+		cn.args = argsCopyValue
+		if cn.validateExports != nil {
+			if e, ok := cn.validateExports(cn.globalID); ok && reflect.TypeOf(e) == cn.exportsType {
+				cn.exportsMut.Lock()
+				cn.exports = e
+				cn.exportsMut.Unlock()
+			}
+		}
+		return nil
+	}
+
 	if cn.managed == nil {
 		// We haven't built the managed component successfully yet.
-		managed, err := cn.reg.Build(cn.managedOpts, argsCopyValue)
-		if err != nil {
+		if err := cn.buildManaged(argsCopyValue); err != nil {
 			return fmt.Errorf("building component: %w", err)
 		}
-		cn.managed = managed
 		cn.args = argsCopyValue
 
 		return nil
@@ -298,12 +451,97 @@ func (cn *BuiltinComponentNode) evaluate(scope *vm.Scope) error {
 	return nil
 }
 
+// buildManaged builds a new managed component from args, replacing the
+// previous one. The metrics of the previous managed component are
+// unregistered first so that the new one can register them again. cn.mut
+// must be held when calling buildManaged.
+func (cn *BuiltinComponentNode) buildManaged(args component.Arguments) error {
+	if cn.managedReg != nil {
+		cn.managedReg.UnregisterAll()
+	}
+
+	opts := cn.managedOpts
+	reg := util.WrapWithUnregisterer(opts.Registerer)
+	opts.Registerer = reg
+
+	managed, err := cn.reg.Build(opts, args)
+	if err != nil {
+		reg.UnregisterAll()
+		return err
+	}
+	cn.managed, cn.managedReg, cn.managedRan = managed, reg, false
+	return nil
+}
+
+// runnableManaged returns the managed component to run. Components aren't
+// meant to be run again once their Run method returned, so a new managed
+// component is built from the current arguments if it was already run.
+func (cn *BuiltinComponentNode) runnableManaged() (component.Component, error) {
+	cn.mut.Lock()
+	defer cn.mut.Unlock()
+
+	if cn.managed == nil {
+		return nil, ErrUnevaluated
+	}
+	if cn.managedRan {
+		if err := cn.buildManaged(cn.args); err != nil {
+			return nil, fmt.Errorf("rebuilding component: %w", err)
+		}
+	}
+	cn.managedRan = true
+	return cn.managed, nil
+}
+
+// evaluateRestart evaluates the restart policy of the component from its
+// restart block. cn.mut must be held when calling evaluateRestart.
+func (cn *BuiltinComponentNode) evaluateRestart(scope *vm.Scope) (RestartPolicy, error) {
+	switch len(cn.reserved.restart) {
+	case 0:
+		return cn.defaultRestart, nil
+	case 1:
+	default:
+		return RestartPolicy{}, fmt.Errorf("the %s block may only be set once", RestartBlockName)
+	}
+
+	args := RestartArguments(cn.defaultRestart)
+	if err := vm.New(cn.reserved.restart[0]).Evaluate(scope, &args); err != nil {
+		return RestartPolicy{}, fmt.Errorf("decoding %s block: %w", RestartBlockName, err)
+	}
+
+	policy := RestartPolicy(args)
+	if err := policy.Validate(); err != nil {
+		return RestartPolicy{}, fmt.Errorf("invalid %s block: %w", RestartBlockName, err)
+	}
+	return policy, nil
+}
+
+// evaluateSingleton evaluates the singleton attribute of the clustering block
+// of the component. cn.mut must be held when calling evaluateSingleton.
+func (cn *BuiltinComponentNode) evaluateSingleton(scope *vm.Scope) (bool, error) {
+	switch len(cn.reserved.singleton) {
+	case 0:
+		return false, nil
+	case 1:
+	default:
+		return false, fmt.Errorf("the singleton attribute of the %s block may only be set once", ClusteringBlockName)
+	}
+
+	var args ClusteringArguments
+	if err := vm.New(ast.Body{cn.reserved.singleton[0]}).Evaluate(scope, &args); err != nil {
+		return false, fmt.Errorf("decoding %s block: %w", ClusteringBlockName, err)
+	}
+	return args.Singleton, nil
+}
+
 // Run runs the managed component in the calling goroutine until ctx is
 // canceled. Evaluate must have been called at least once without returning an
 // error before calling Run.
 //
 // Run will immediately return ErrUnevaluated if Evaluate has never been called
 // successfully. Otherwise, Run will return nil.
+//
+// A new managed component is built when Run is called again after the
+// previous call returned, for example to restart the component.
 func (cn *BuiltinComponentNode) Run(ctx context.Context) error {
 	cn.mut.RLock()
 	managed := cn.managed
@@ -314,7 +552,7 @@ func (cn *BuiltinComponentNode) Run(ctx context.Context) error {
 	}
 
 	cn.setRunHealth(component.HealthTypeHealthy, "started component")
-	err := cn.managed.Run(ctx)
+	err := cn.runManaged(ctx)
 
 	// Note: logging of this error is handled by the scheduler.
 	if err != nil {
@@ -325,6 +563,26 @@ func (cn *BuiltinComponentNode) Run(ctx context.Context) error {
 	return err
 }
 
+// RestartPolicy implements RestartableNode and returns the evaluated restart
+// policy of the component.
+func (cn *BuiltinComponentNode) RestartPolicy() RestartPolicy {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+	return cn.restart
+}
+
+// OnRestart implements RestartableNode. It reports the component as crash
+// looping until it's run again.
+func (cn *BuiltinComponentNode) OnRestart(err error, restarts int, backoff time.Duration) {
+	cn.restarts.Inc()
+
+	limit := "unlimited"
+	if maxRestarts := cn.RestartPolicy().MaxRestarts; maxRestarts > 0 {
+		limit = fmt.Sprint(maxRestarts)
+	}
+	cn.setRunHealth(component.HealthTypeCrashLoop, fmt.Sprintf("component shut down with error, restarting in %s (restart %d of %s): %s", backoff, restarts, limit, err))
+}
+
 // ErrUnevaluated is returned if BuiltinComponentNode.Run is called before a managed
 // component is built.
 var ErrUnevaluated = errors.New("managed component not built")
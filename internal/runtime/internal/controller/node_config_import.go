@@ -26,8 +26,8 @@ import (
 	"github.com/grafana/alloy/syntax/vm"
 )
 
-// ImportConfigNode imports declare and import blocks via a managed import source.
-// The imported declare are stored in importedDeclares.
+// ImportConfigNode imports declare, function and import blocks via a managed import source.
+// The imported declare are stored in importedDeclares and the imported functions in importedFunctions.
 // For every imported import block, the ImportConfigNode will create ImportConfigNode children.
 // The children are evaluated and ran by the parent.
 // When an ImportConfigNode receives new content from its source, it updates its importedDeclares and recreates its children.
@@ -52,6 +52,7 @@ type ImportConfigNode struct {
 	importConfigNodesChildren map[string]*ImportConfigNode
 	importChildrenRunning     bool
 	importedDeclares          map[string]ast.Body
+	importedFunctions         map[string]*ast.BlockStmt
 
 	healthMut     sync.RWMutex
 	evalHealth    component.Health // Health of the last source evaluation
@@ -206,6 +207,7 @@ func (cn *ImportConfigNode) onContentUpdate(importedContent map[string]string) {
 		cn.importedContent[k] = v
 	}
 	cn.importedDeclares = make(map[string]ast.Body)
+	cn.importedFunctions = make(map[string]*ast.BlockStmt)
 	cn.importConfigNodesChildren = make(map[string]*ImportConfigNode)
 
 	for f, ic := range importedContent {
@@ -225,6 +227,12 @@ func (cn *ImportConfigNode) onContentUpdate(importedContent map[string]string) {
 		}
 	}
 
+	if err := validateFunctions(cn.importedFunctions); err != nil {
+		level.Error(cn.logger).Log("msg", "failed to process imported functions", "err", err)
+		cn.setContentHealth(component.HealthTypeUnhealthy, fmt.Sprintf("imported function is invalid: %s", err))
+		return
+	}
+
 	// evaluate the importConfigNodesChildren that have been created
 	err := cn.evaluateChildren()
 	if err != nil {
@@ -245,25 +253,27 @@ func (cn *ImportConfigNode) onContentUpdate(importedContent map[string]string) {
 	cn.OnBlockNodeUpdate(cn)
 }
 
-// processImportedContent processes declare and import blocks of the provided ast content.
+// processImportedContent processes declare, function and import blocks of the provided ast content.
 func (cn *ImportConfigNode) processImportedContent(content *ast.File) error {
 	for _, stmt := range content.Body {
 		blockStmt, ok := stmt.(*ast.BlockStmt)
 		if !ok {
-			return fmt.Errorf("only declare and import blocks are allowed in a module")
+			return fmt.Errorf("only declare, function and import blocks are allowed in a module")
 		}
 
 		componentName := strings.Join(blockStmt.Name, ".")
 		switch componentName {
 		case declareType:
 			cn.processDeclareBlock(blockStmt)
+		case functionType:
+			cn.processFunctionBlock(blockStmt)
 		case importsource.BlockImportFile, importsource.BlockImportString, importsource.BlockImportHTTP, importsource.BlockImportGit:
 			err := cn.processImportBlock(blockStmt, componentName)
 			if err != nil {
 				return err
 			}
 		default:
-			return fmt.Errorf("only declare and import blocks are allowed in a module, got %s", componentName)
+			return fmt.Errorf("only declare, function and import blocks are allowed in a module, got %s", componentName)
 		}
 	}
 	return nil
@@ -278,6 +288,15 @@ func (cn *ImportConfigNode) processDeclareBlock(stmt *ast.BlockStmt) {
 	cn.importedDeclares[stmt.Label] = stmt.Body
 }
 
+// processFunctionBlock stores the function definition in the importedFunctions.
+func (cn *ImportConfigNode) processFunctionBlock(stmt *ast.BlockStmt) {
+	if _, ok := cn.importedFunctions[stmt.Label]; ok {
+		level.Error(cn.logger).Log("msg", "function block redefined", "name", stmt.Label)
+		return
+	}
+	cn.importedFunctions[stmt.Label] = stmt
+}
+
 // processDeclareBlock creates an ImportConfigNode child from the provided import block.
 func (cn *ImportConfigNode) processImportBlock(stmt *ast.BlockStmt, fullName string) error {
 	sourceType := importsource.GetSourceType(fullName)
@@ -424,6 +443,13 @@ func (cn *ImportConfigNode) ImportedDeclares() map[string]ast.Body {
 	return cn.importedDeclares
 }
 
+// ImportedFunctions returns all function blocks that it imported.
+func (cn *ImportConfigNode) ImportedFunctions() map[string]*ast.BlockStmt {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+	return cn.importedFunctions
+}
+
 // ImportConfigNodesChildren returns the ImportConfigNodesChildren of this ImportConfigNode.
 func (cn *ImportConfigNode) ImportConfigNodesChildren() map[string]*ImportConfigNode {
 	cn.mut.Lock()
@@ -0,0 +1,405 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/syntax/ast"
+	"github.com/grafana/alloy/syntax/scanner"
+	"github.com/grafana/alloy/syntax/vm"
+)
+
+const (
+	foreachType         = "foreach"
+	foreachTemplateType = "template"
+)
+
+// getCustomComponentRegistry is used by the foreach node to retrieve the
+// customComponentRegistry of the loader which manages it.
+type getCustomComponentRegistry func() *CustomComponentRegistry
+
+// ForeachNode is a controller node which instantiates its template once per
+// element of a collection.
+//
+// Each instance of the template runs as a child custom component with a stable
+// ID derived from the element it was created for. Children are added, updated
+// and removed as the collection changes between evaluations.
+type ForeachNode struct {
+	id               ComponentID
+	globalID         string
+	label            string
+	componentName    string
+	nodeID           string // Cached from id.String() to avoid allocating new strings every time NodeID is called.
+	moduleController ModuleController
+	logger           log.Logger
+
+	getRegistry getCustomComponentRegistry // Retrieve the registry of the loader.
+
+	mut      sync.RWMutex
+	block    *ast.BlockStmt // Current Alloy block to derive args from
+	template *ast.BlockStmt // Template block of the current Alloy block
+	eval     *vm.Evaluator
+	args     foreachArguments
+	children map[string]CustomComponent // Instantiated templates by child ID
+
+	childrenChanged chan struct{} // Notifies Run that children must be synced.
+
+	healthMut  sync.RWMutex
+	evalHealth component.Health // Health of the last evaluate
+	runHealth  component.Health // Health of running the component
+}
+
+var _ ComponentNode = (*ForeachNode)(nil)
+
+// foreachArguments holds the attributes of a foreach block.
+type foreachArguments struct {
+	Collection []any  `alloy:"collection,attr"`
+	Var        string `alloy:"var,attr"`
+}
+
+// Validate implements syntax.Validator.
+func (args *foreachArguments) Validate() error {
+	if !scanner.IsValidIdentifier(args.Var) {
+		return fmt.Errorf("var %q must be a valid identifier", args.Var)
+	}
+	return nil
+}
+
+// NewForeachNode creates a new ForeachNode from an initial ast.BlockStmt. The
+// children aren't created until Evaluate is called.
+func NewForeachNode(globals ComponentGlobals, b *ast.BlockStmt, getRegistry getCustomComponentRegistry) *ForeachNode {
+	var (
+		id     = BlockComponentID(b)
+		nodeID = id.String()
+	)
+
+	initHealth := component.Health{
+		Health:     component.HealthTypeUnknown,
+		Message:    "foreach created",
+		UpdateTime: time.Now(),
+	}
+
+	globalID := nodeID
+	if globals.ControllerID != "" {
+		globalID = path.Join(globals.ControllerID, nodeID)
+	}
+
+	parent, node := splitPath(globalID)
+	body, template := splitForeachBody(b.Body)
+
+	return &ForeachNode{
+		id:               id,
+		globalID:         globalID,
+		label:            b.Label,
+		componentName:    b.GetBlockName(),
+		nodeID:           nodeID,
+		moduleController: globals.NewModuleController(globalID),
+		logger:           log.With(globals.Logger, "component_path", parent, "component_id", node),
+		getRegistry:      getRegistry,
+
+		block:    b,
+		template: template,
+		eval:     vm.New(body),
+		children: make(map[string]CustomComponent),
+
+		childrenChanged: make(chan struct{}, 1),
+
+		evalHealth: initHealth,
+		runHealth:  initHealth,
+	}
+}
+
+// splitForeachBody separates the template block of a foreach block from the
+// rest of its body, which can be evaluated into foreachArguments.
+func splitForeachBody(body ast.Body) (ast.Body, *ast.BlockStmt) {
+	var (
+		rest     = make(ast.Body, 0, len(body))
+		template *ast.BlockStmt
+	)
+	for _, stmt := range body {
+		if block, ok := stmt.(*ast.BlockStmt); ok && block.GetBlockName() == foreachTemplateType && template == nil {
+			template = block
+			continue
+		}
+		rest = append(rest, stmt)
+	}
+	return rest, template
+}
+
+// ID returns the component ID of the foreach block.
+func (fn *ForeachNode) ID() ComponentID { return fn.id }
+
+// Label returns the label for the block.
+func (fn *ForeachNode) Label() string { return fn.label }
+
+// NodeID implements dag.Node and returns the unique ID for this node.
+func (fn *ForeachNode) NodeID() string { return fn.nodeID }
+
+// ComponentName returns the name of the block.
+func (fn *ForeachNode) ComponentName() string { return fn.componentName }
+
+// UpdateBlock updates the Alloy block used to construct the children. The new
+// block isn't used until the next time Evaluate is invoked.
+//
+// UpdateBlock will panic if the block does not match the component ID of the
+// ForeachNode.
+func (fn *ForeachNode) UpdateBlock(b *ast.BlockStmt) {
+	if !BlockComponentID(b).Equals(fn.id) {
+		panic("UpdateBlock called with an Alloy block with a different component ID")
+	}
+
+	body, template := splitForeachBody(b.Body)
+
+	fn.mut.Lock()
+	defer fn.mut.Unlock()
+	fn.block = b
+	fn.template = template
+	fn.eval = vm.New(body)
+}
+
+// Block implements BlockNode and returns the current block of the foreach.
+func (fn *ForeachNode) Block() *ast.BlockStmt {
+	fn.mut.RLock()
+	defer fn.mut.RUnlock()
+	return fn.block
+}
+
+// Template returns the current template block of the foreach, or nil if the
+// block has no template.
+func (fn *ForeachNode) Template() *ast.BlockStmt {
+	fn.mut.RLock()
+	defer fn.mut.RUnlock()
+	return fn.template
+}
+
+// Evaluate implements BlockNode and re-evaluates the collection of the foreach
+// block with the provided scope. A child is created for every new element of
+// the collection, existing children are reloaded and children whose element
+// was removed from the collection are stopped.
+//
+// Each child can reference the values of evalScope in addition to its
+// element, which is exposed through the variable named by the var attribute.
+func (fn *ForeachNode) Evaluate(evalScope *vm.Scope) error {
+	err := fn.evaluate(evalScope)
+
+	switch err {
+	case nil:
+		fn.setEvalHealth(component.HealthTypeHealthy, "foreach evaluated")
+	default:
+		msg := fmt.Sprintf("foreach evaluation failed: %s", err)
+		fn.setEvalHealth(component.HealthTypeUnhealthy, msg)
+	}
+	return err
+}
+
+func (fn *ForeachNode) evaluate(evalScope *vm.Scope) error {
+	fn.mut.Lock()
+	defer fn.mut.Unlock()
+
+	if fn.template == nil {
+		return fmt.Errorf("missing required block %q", foreachTemplateType)
+	}
+
+	var args foreachArguments
+	if err := fn.eval.Evaluate(evalScope, &args); err != nil {
+		return fmt.Errorf("decoding configuration: %w", err)
+	}
+	fn.args = args
+
+	var (
+		errs     []error
+		registry = fn.getRegistry()
+		children = make(map[string]CustomComponent, len(args.Collection))
+	)
+
+	for i, childID := range foreachChildIDs(args.Collection) {
+		child, ok := fn.children[childID]
+		if !ok {
+			var err error
+			child, err = fn.moduleController.NewCustomComponent(childID, nil)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("creating foreach child %q: %w", childID, err))
+				continue
+			}
+		}
+		children[childID] = child
+
+		scope := &vm.Scope{
+			Parent:    evalScope,
+			Variables: map[string]any{args.Var: args.Collection[i]},
+		}
+		if err := child.LoadBody(fn.template.Body, nil, NewCustomComponentRegistry(registry, scope)); err != nil {
+			errs = append(errs, fmt.Errorf("updating foreach child %q: %w", childID, err))
+		}
+	}
+
+	fn.children = children
+
+	select {
+	case fn.childrenChanged <- struct{}{}:
+	default:
+		// A sync of the children is already scheduled.
+	}
+	return errors.Join(errs...)
+}
+
+// foreachChildIDs returns a stable ID for each element of collection. IDs are
+// derived from a hash of the element so that children keep their ID when
+// elements are added to or removed from the collection. Elements which are
+// equal are disambiguated by their number of occurrences.
+func foreachChildIDs(collection []any) []string {
+	var (
+		ids         = make([]string, 0, len(collection))
+		occurrences = make(map[uint64]int, len(collection))
+	)
+	for _, item := range collection {
+		h := fnv.New64a()
+		_, _ = fmt.Fprintf(h, "%v", item)
+		sum := h.Sum64()
+
+		occurrences[sum]++
+		ids = append(ids, fmt.Sprintf("foreach_%016x_%d", sum, occurrences[sum]))
+	}
+	return ids
+}
+
+// Run runs the children of the foreach node until ctx is canceled. Children
+// are started and stopped as they are added and removed by Evaluate.
+func (fn *ForeachNode) Run(ctx context.Context) error {
+	fn.setRunHealth(component.HealthTypeHealthy, "started foreach")
+
+	running := make(map[string]*runningForeachChild)
+	defer func() {
+		for _, child := range running {
+			child.stop()
+		}
+	}()
+
+	for {
+		fn.syncChildren(ctx, running)
+
+		select {
+		case <-ctx.Done():
+			fn.setRunHealth(component.HealthTypeExited, "foreach shut down cleanly")
+			return nil
+		case <-fn.childrenChanged:
+		}
+	}
+}
+
+// syncChildren starts the children which aren't running yet and stops the
+// running children which have been removed.
+func (fn *ForeachNode) syncChildren(ctx context.Context, running map[string]*runningForeachChild) {
+	fn.mut.RLock()
+	children := make(map[string]CustomComponent, len(fn.children))
+	for id, child := range fn.children {
+		children[id] = child
+	}
+	fn.mut.RUnlock()
+
+	for id, r := range running {
+		if child, ok := children[id]; ok && child == r.child {
+			continue
+		}
+		// Wait for the child to exit so that its ID is released before a new
+		// child with the same ID is started.
+		r.stop()
+		delete(running, id)
+	}
+
+	for id, child := range children {
+		if _, ok := running[id]; ok {
+			continue
+		}
+
+		childCtx, cancel := context.WithCancel(ctx)
+		r := &runningForeachChild{
+			child:  child,
+			cancel: cancel,
+			exited: make(chan struct{}),
+		}
+		running[id] = r
+
+		go func(id string) {
+			defer close(r.exited)
+			if err := r.child.Run(childCtx); err != nil {
+				level.Error(fn.logger).Log("msg", "foreach child exited with error", "child", id, "err", err)
+			}
+		}(id)
+	}
+}
+
+// runningForeachChild is a child of a foreach node which has been started.
+type runningForeachChild struct {
+	child  CustomComponent
+	cancel context.CancelFunc
+	exited chan struct{}
+}
+
+// stop cancels the child and waits for it to exit.
+func (r *runningForeachChild) stop() {
+	r.cancel()
+	<-r.exited
+}
+
+// Arguments returns the current arguments of the foreach block.
+func (fn *ForeachNode) Arguments() component.Arguments {
+	fn.mut.RLock()
+	defer fn.mut.RUnlock()
+	return fn.args
+}
+
+// Exports returns nil; foreach blocks don't have exports.
+func (fn *ForeachNode) Exports() component.Exports { return nil }
+
+// CurrentHealth returns the current health of the ForeachNode.
+//
+// The health of a ForeachNode is determined by combining:
+//
+//  1. Health from the call to Run().
+//  2. Health from the last call to Evaluate().
+func (fn *ForeachNode) CurrentHealth() component.Health {
+	fn.healthMut.RLock()
+	defer fn.healthMut.RUnlock()
+	return component.LeastHealthy(fn.runHealth, fn.evalHealth)
+}
+
+// setEvalHealth sets the internal health from a call to Evaluate. See Health
+// for information on how overall health is calculated.
+func (fn *ForeachNode) setEvalHealth(t component.HealthType, msg string) {
+	fn.healthMut.Lock()
+	defer fn.healthMut.Unlock()
+
+	fn.evalHealth = component.Health{
+		Health:     t,
+		Message:    msg,
+		UpdateTime: time.Now(),
+	}
+}
+
+// setRunHealth sets the internal health from a call to Run. See Health for
+// information on how overall health is calculated.
+func (fn *ForeachNode) setRunHealth(t component.HealthType, msg string) {
+	fn.healthMut.Lock()
+	defer fn.healthMut.Unlock()
+
+	fn.runHealth = component.Health{
+		Health:     t,
+		Message:    msg,
+		UpdateTime: time.Now(),
+	}
+}
+
+// ModuleIDs returns the IDs of the running children of the foreach block.
+func (fn *ForeachNode) ModuleIDs() []string {
+	return fn.moduleController.ModuleIDs()
+}
@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/grafana/alloy/syntax/ast"
+	"github.com/grafana/alloy/syntax/scanner"
+	"github.com/grafana/alloy/syntax/vm"
+)
+
+const (
+	functionType         = "function"
+	functionArgumentType = "argument"
+	functionResultAttr   = "result"
+)
+
+// FunctionNode represents a function block in the DAG.
+//
+// The function itself is exposed to expressions through the
+// CustomComponentRegistry; the node is used to report errors in the
+// definition of the function and to detect cyclic calls between functions.
+type FunctionNode struct {
+	label         string
+	nodeID        string
+	componentName string
+	mut           sync.RWMutex
+	block         *ast.BlockStmt
+}
+
+var _ BlockNode = (*FunctionNode)(nil)
+
+// NewFunctionNode creates a new function node from a function block.
+func NewFunctionNode(block *ast.BlockStmt) *FunctionNode {
+	return &FunctionNode{
+		label:         block.Label,
+		nodeID:        BlockComponentID(block).String(),
+		componentName: block.GetBlockName(),
+		block:         block,
+	}
+}
+
+// Evaluate validates the definition of the function. The function is only
+// evaluated when it's called.
+func (fn *FunctionNode) Evaluate(scope *vm.Scope) error {
+	_, err := parseFunction(fn.Block())
+	return err
+}
+
+// Label returns the label of the block.
+func (fn *FunctionNode) Label() string { return fn.label }
+
+// Block implements BlockNode and returns the current block of the function.
+func (fn *FunctionNode) Block() *ast.BlockStmt {
+	fn.mut.RLock()
+	defer fn.mut.RUnlock()
+	return fn.block
+}
+
+// NodeID implements dag.Node and returns the unique ID for the function node.
+func (fn *FunctionNode) NodeID() string { return fn.nodeID }
+
+// UpdateBlock updates the managed Alloy block.
+//
+// UpdateBlock will panic if the block does not match the component ID of the
+// FunctionNode.
+func (fn *FunctionNode) UpdateBlock(b *ast.BlockStmt) {
+	if !BlockComponentID(b).Equals(strings.Split(fn.nodeID, ".")) {
+		panic("UpdateBlock called with an Alloy block with a different ID")
+	}
+
+	fn.mut.Lock()
+	defer fn.mut.Unlock()
+	fn.block = b
+}
+
+// function is the parsed definition of a function block.
+type function struct {
+	name      string
+	arguments []functionArgument
+	result    ast.Expr
+}
+
+// functionArgument is a positional argument of a function.
+type functionArgument struct {
+	name string
+	argumentBlock
+}
+
+// parseFunction parses the definition of a function block. The result
+// expression isn't evaluated.
+func parseFunction(block *ast.BlockStmt) (*function, error) {
+	if !scanner.IsValidIdentifier(block.Label) {
+		return nil, fmt.Errorf("function label %q must be a valid identifier", block.Label)
+	}
+
+	f := &function{name: block.Label}
+	seen := make(map[string]struct{})
+
+	for _, stmt := range block.Body {
+		switch stmt := stmt.(type) {
+		case *ast.AttributeStmt:
+			if stmt.Name.Name != functionResultAttr {
+				return nil, fmt.Errorf("unrecognized attribute %q in function %q", stmt.Name.Name, f.name)
+			}
+			if f.result != nil {
+				return nil, fmt.Errorf("attribute %q may only be set once in function %q", functionResultAttr, f.name)
+			}
+			f.result = stmt.Value
+
+		case *ast.BlockStmt:
+			if stmt.GetBlockName() != functionArgumentType {
+				return nil, fmt.Errorf("unrecognized block %q in function %q", stmt.GetBlockName(), f.name)
+			}
+			if !scanner.IsValidIdentifier(stmt.Label) {
+				return nil, fmt.Errorf("argument label %q of function %q must be a valid identifier", stmt.Label, f.name)
+			}
+			if _, redefined := seen[stmt.Label]; redefined {
+				return nil, fmt.Errorf("argument %q redefined in function %q", stmt.Label, f.name)
+			}
+			seen[stmt.Label] = struct{}{}
+
+			arg := functionArgument{name: stmt.Label}
+			if err := vm.New(stmt.Body).Evaluate(nil, &arg.argumentBlock); err != nil {
+				return nil, fmt.Errorf("decoding argument %q of function %q: %w", stmt.Label, f.name, err)
+			}
+			if !arg.Optional && len(f.arguments) > 0 && f.arguments[len(f.arguments)-1].Optional {
+				return nil, fmt.Errorf("required argument %q of function %q must be declared before optional arguments", stmt.Label, f.name)
+			}
+			f.arguments = append(f.arguments, arg)
+		}
+	}
+
+	if f.result == nil {
+		return nil, fmt.Errorf("missing required attribute %q in function %q", functionResultAttr, f.name)
+	}
+	return f, nil
+}
+
+// call evaluates the result of the function with the provided arguments.
+// Functions and namespaces of scope can be called from the result.
+func (f *function) call(scope *vm.Scope, args []any) (any, error) {
+	if len(args) > len(f.arguments) {
+		return nil, fmt.Errorf("expected at most %d args, got %d", len(f.arguments), len(args))
+	}
+
+	arguments := make(map[string]any, len(f.arguments))
+	for i, arg := range f.arguments {
+		var value any
+		switch {
+		case i < len(args):
+			value = args[i]
+		case arg.Optional:
+			value = arg.Default
+		default:
+			return nil, fmt.Errorf("missing required argument %q", arg.name)
+		}
+		arguments[arg.name] = map[string]any{"value": value}
+	}
+
+	var result any
+	err := vm.New(f.result).Evaluate(&vm.Scope{
+		Parent:    scope,
+		Variables: map[string]any{functionArgumentType: arguments},
+	}, &result)
+	return result, err
+}
+
+// newFunctionScope returns a scope exposing a value for every function block
+// of blocks, which can be called from Alloy expressions. The functions can
+// call each other and any function exposed by parent. parent may be nil.
+//
+// Invalid functions are still exposed; calling them returns the reason why
+// they are invalid.
+func newFunctionScope(parent *vm.Scope, blocks map[string]*ast.BlockStmt) *vm.Scope {
+	scope := &vm.Scope{
+		Parent:    parent,
+		Variables: make(map[string]any, len(blocks)),
+	}
+
+	functions := make(map[string]*function, len(blocks))
+	for name, block := range blocks {
+		f, err := parseFunction(block)
+		if err != nil {
+			scope.Variables[name] = func(args ...any) (any, error) { return nil, err }
+			continue
+		}
+		functions[name] = f
+	}
+
+	// Calls between functions are checked for cycles to prevent unbounded
+	// recursion; cycles between local functions are also detected by the
+	// loader, but imported functions are only checked here.
+	cycleErr := checkFunctionCycles(functions)
+	for name, f := range functions {
+		scope.Variables[name] = func(args ...any) (any, error) {
+			if cycleErr != nil {
+				return nil, cycleErr
+			}
+			return f.call(scope, args)
+		}
+	}
+	return scope
+}
+
+// validateFunctions parses every function block of blocks and checks that
+// functions don't call each other in a cycle.
+func validateFunctions(blocks map[string]*ast.BlockStmt) error {
+	functions := make(map[string]*function, len(blocks))
+	for name, block := range blocks {
+		f, err := parseFunction(block)
+		if err != nil {
+			return err
+		}
+		functions[name] = f
+	}
+	return checkFunctionCycles(functions)
+}
+
+// checkFunctionCycles returns an error if some functions call each other in a
+// cycle.
+func checkFunctionCycles(functions map[string]*function) error {
+	calls := make(map[string][]string, len(functions))
+	for name, f := range functions {
+		var w traversalWalker
+		ast.Walk(&w, f.result)
+		w.flush()
+		for _, t := range w.traversals {
+			if _, ok := functions[t[0].Name]; ok {
+				calls[name] = append(calls[name], t[0].Name)
+			}
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(functions))
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("function %q calls itself through a cycle", name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, callee := range calls[name] {
+			if err := visit(callee); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+	for name := range functions {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -18,6 +18,8 @@ type ServiceNode struct {
 	svc  service.Service
 	def  service.Definition
 
+	validateOnly bool // Decode arguments without updating the service
+
 	mut   sync.RWMutex
 	block *ast.BlockStmt // Current Alloy block to derive args from
 	eval  *vm.Evaluator
@@ -28,11 +30,13 @@ var _ RunnableNode = (*ServiceNode)(nil)
 
 // NewServiceNode creates a new instance of a ServiceNode from an instance of a
 // Service. The provided host is used when running the service.
-func NewServiceNode(host service.Host, svc service.Service) *ServiceNode {
+func NewServiceNode(globals ComponentGlobals, host service.Host, svc service.Service) *ServiceNode {
 	return &ServiceNode{
 		host: host,
 		svc:  svc,
 		def:  svc.Definition(),
+
+		validateOnly: globals.ValidateOnly,
 	}
 }
 
@@ -104,6 +108,12 @@ func (sn *ServiceNode) Evaluate(scope *vm.Scope) error {
 	// since services expect a non-pointer.
 	argsCopyValue := reflect.ValueOf(argsPointer).Elem().Interface()
 
+	if sn.validateOnly {
+		// Services aren't updated when validating.
+		sn.args = argsCopyValue
+		return nil
+	}
+
 	if reflect.DeepEqual(sn.args, argsCopyValue) {
 		// Ignore arguments which haven't changed. This reduces the cost of calling
 		// evaluate for services where evaluation is expensive (e.g., if
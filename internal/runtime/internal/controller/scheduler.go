@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/go-kit/log"
 
+	"github.com/grafana/alloy/internal/runtime/componentprofile"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 )
 
@@ -16,27 +18,81 @@ type RunnableNode interface {
 	Run(ctx context.Context) error
 }
 
+// RestartableNode is a RunnableNode which the Scheduler restarts when Run
+// exits with an error, following the policy returned by RestartPolicy.
+type RestartableNode interface {
+	RunnableNode
+
+	// RestartPolicy returns the policy used to restart the node.
+	RestartPolicy() RestartPolicy
+
+	// OnRestart is called when Run exited with err and the node will be run
+	// again after backoff. restarts is the number of consecutive restarts of
+	// the node, including this one.
+	OnRestart(err error, restarts int, backoff time.Duration)
+}
+
+// RestartPolicy configures how a RestartableNode is restarted when Run exits
+// with an error.
+//
+// The time to wait before a restart starts at InitialBackoff, and doubles
+// after every consecutive restart up to MaxBackoff. Restarts stop being
+// consecutive once a node runs for longer than MaxBackoff before exiting.
+type RestartPolicy struct {
+	// MaxRestarts is the maximum number of consecutive restarts. Nodes aren't
+	// restarted if MaxRestarts is 0, and are restarted indefinitely if
+	// MaxRestarts is negative.
+	MaxRestarts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRestartPolicy is the default restart policy, which never restarts
+// nodes.
+var DefaultRestartPolicy = RestartPolicy{
+	MaxRestarts:    0,
+	InitialBackoff: time.Second,
+	MaxBackoff:     time.Minute,
+}
+
+// Validate returns an error if p is invalid.
+func (p RestartPolicy) Validate() error {
+	if p.InitialBackoff <= 0 {
+		return fmt.Errorf("initial_backoff must be greater than 0")
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		return fmt.Errorf("max_backoff must be greater than or equal to initial_backoff")
+	}
+	return nil
+}
+
 // Scheduler runs components.
 type Scheduler struct {
 	ctx     context.Context
 	cancel  context.CancelFunc
 	running sync.WaitGroup
 	logger  log.Logger
+	path    string // Path of the module whose components are run.
 
 	tasksMut sync.Mutex
 	tasks    map[string]*task
 }
 
-// NewScheduler creates a new Scheduler. Call Synchronize to manage the set of
-// components which are running.
+// NewScheduler creates a new Scheduler for the components of the controller
+// with the given ID. Call Synchronize to manage the set of components which
+// are running.
+//
+// Components are run under pprof labels identifying them, so that profiles
+// can be attributed to components.
 //
 // Call Close to stop the Scheduler and all running components.
-func NewScheduler(logger log.Logger) *Scheduler {
+func NewScheduler(logger log.Logger, controllerID string) *Scheduler {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Scheduler{
 		ctx:    ctx,
 		cancel: cancel,
 		logger: logger,
+		path:   "/" + controllerID,
 
 		tasks: make(map[string]*task),
 	}
@@ -90,7 +146,11 @@ func (s *Scheduler) Synchronize(rr []RunnableNode) error {
 
 		opts := taskOptions{
 			Context:  s.ctx,
+			Path:     s.path,
 			Runnable: newRunnable,
+			OnRestart: func(err error, restarts int, backoff time.Duration) {
+				level.Warn(s.logger).Log("msg", "node exited with error, restarting", "node", nodeID, "restarts", restarts, "backoff", backoff, "err", err)
+			},
 			OnDone: func(err error) {
 				defer s.running.Done()
 
@@ -131,9 +191,11 @@ type task struct {
 }
 
 type taskOptions struct {
-	Context  context.Context
-	Runnable RunnableNode
-	OnDone   func(error)
+	Context   context.Context
+	Path      string // Path of the module of Runnable, used to label its goroutines.
+	Runnable  RunnableNode
+	OnRestart func(err error, restarts int, backoff time.Duration)
+	OnDone    func(error)
 }
 
 // newTask creates and starts a new task.
@@ -147,13 +209,61 @@ func newTask(opts taskOptions) *task {
 	}
 
 	go func() {
-		err := opts.Runnable.Run(t.ctx)
+		var err error
+		componentprofile.Do(ctx, opts.Path, opts.Runnable.NodeID(), func(ctx context.Context) {
+			err = t.run(ctx, opts)
+		})
 		close(t.exited)
 		opts.OnDone(err)
 	}()
 	return t
 }
 
+// run runs the runnable of the task, restarting it according to its restart
+// policy if it's a RestartableNode. It returns the error of the last run.
+func (t *task) run(ctx context.Context, opts taskOptions) error {
+	var (
+		restarts int
+		backoff  time.Duration
+	)
+
+	for {
+		start := time.Now()
+		err := opts.Runnable.Run(ctx)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		rn, ok := opts.Runnable.(RestartableNode)
+		if !ok {
+			return err
+		}
+		policy := rn.RestartPolicy()
+
+		if time.Since(start) > policy.MaxBackoff {
+			// The node ran for long enough that this exit isn't part of a crash
+			// loop.
+			restarts, backoff = 0, 0
+		}
+		if policy.MaxRestarts >= 0 && restarts >= policy.MaxRestarts {
+			return err
+		}
+
+		restarts++
+		backoff = min(max(backoff*2, policy.InitialBackoff), policy.MaxBackoff)
+		rn.OnRestart(err, restarts, backoff)
+		if opts.OnRestart != nil {
+			opts.OnRestart(err, restarts, backoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
+}
+
 func (t *task) Stop() {
 	t.cancel()
 	<-t.exited
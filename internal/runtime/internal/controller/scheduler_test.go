@@ -2,9 +2,12 @@ package controller_test
 
 import (
 	"context"
+	"errors"
 	"os"
+	"runtime/pprof"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/stretchr/testify/require"
@@ -30,7 +33,7 @@ func TestScheduler_Synchronize(t *testing.T) {
 			return nil
 		}
 
-		sched := controller.NewScheduler(logger)
+		sched := controller.NewScheduler(logger, "")
 		sched.Synchronize([]controller.RunnableNode{
 			fakeRunnable{ID: "component-a", Component: mockComponent{RunFunc: runFunc}},
 			fakeRunnable{ID: "component-b", Component: mockComponent{RunFunc: runFunc}},
@@ -52,7 +55,7 @@ func TestScheduler_Synchronize(t *testing.T) {
 			return nil
 		}
 
-		sched := controller.NewScheduler(logger)
+		sched := controller.NewScheduler(logger, "")
 
 		for i := 0; i < 10; i++ {
 			// If a new runnable is created, runFunc will panic since the WaitGroup
@@ -78,7 +81,7 @@ func TestScheduler_Synchronize(t *testing.T) {
 			return nil
 		}
 
-		sched := controller.NewScheduler(logger)
+		sched := controller.NewScheduler(logger, "")
 
 		sched.Synchronize([]controller.RunnableNode{
 			fakeRunnable{ID: "component-a", Component: mockComponent{RunFunc: runFunc}},
@@ -92,6 +95,106 @@ func TestScheduler_Synchronize(t *testing.T) {
 	})
 }
 
+func TestScheduler_Restart(t *testing.T) {
+	logger := log.NewLogfmtLogger(os.Stdout)
+	errRun := errors.New("run failed")
+
+	t.Run("Restarts failing jobs with backoff", func(t *testing.T) {
+		var runs int
+		finished := make(chan struct{})
+
+		r := &fakeRestartable{
+			fakeRunnable: fakeRunnable{ID: "component-a", Component: mockComponent{RunFunc: func(ctx context.Context) error {
+				runs++
+				if runs <= 3 {
+					return errRun
+				}
+				close(finished)
+				<-ctx.Done()
+				return nil
+			}}},
+			Policy: controller.RestartPolicy{MaxRestarts: 5, InitialBackoff: time.Millisecond, MaxBackoff: 3 * time.Millisecond},
+		}
+
+		sched := controller.NewScheduler(logger, "")
+		sched.Synchronize([]controller.RunnableNode{r})
+		<-finished
+		require.NoError(t, sched.Close())
+
+		require.Equal(t, []int{1, 2, 3}, r.Restarts)
+		require.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}, r.Backoffs)
+	})
+
+	t.Run("Stops restarting after max restarts", func(t *testing.T) {
+		var (
+			mut  sync.Mutex
+			runs int
+		)
+		r := &fakeRestartable{
+			fakeRunnable: fakeRunnable{ID: "component-a", Component: mockComponent{RunFunc: func(ctx context.Context) error {
+				mut.Lock()
+				defer mut.Unlock()
+				runs++
+				return errRun
+			}}},
+			Policy: controller.RestartPolicy{MaxRestarts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		}
+		getRuns := func() int {
+			mut.Lock()
+			defer mut.Unlock()
+			return runs
+		}
+
+		sched := controller.NewScheduler(logger, "")
+		sched.Synchronize([]controller.RunnableNode{r})
+		require.Eventually(t, func() bool { return getRuns() == 3 }, time.Second, time.Millisecond)
+		require.Never(t, func() bool { return getRuns() > 3 }, 50*time.Millisecond, time.Millisecond)
+		require.NoError(t, sched.Close())
+	})
+
+	t.Run("Does not restart jobs which are not restartable", func(t *testing.T) {
+		var runs int
+		finished := make(chan struct{})
+
+		sched := controller.NewScheduler(logger, "")
+		sched.Synchronize([]controller.RunnableNode{
+			fakeRunnable{ID: "component-a", Component: mockComponent{RunFunc: func(ctx context.Context) error {
+				runs++
+				close(finished)
+				return errRun
+			}}},
+		})
+		<-finished
+		require.NoError(t, sched.Close())
+		require.Equal(t, 1, runs)
+	})
+}
+
+func TestScheduler_ProfileLabels(t *testing.T) {
+	logger := log.NewLogfmtLogger(os.Stdout)
+
+	labels := make(chan map[string]string, 1)
+	sched := controller.NewScheduler(logger, "import.file.module")
+	sched.Synchronize([]controller.RunnableNode{
+		fakeRunnable{ID: "component-a", Component: mockComponent{RunFunc: func(ctx context.Context) error {
+			set := make(map[string]string)
+			pprof.ForLabels(ctx, func(key, value string) bool {
+				set[key] = value
+				return true
+			})
+			labels <- set
+			<-ctx.Done()
+			return nil
+		}}},
+	})
+
+	require.Equal(t, map[string]string{
+		"component_path": "/import.file.module",
+		"component_id":   "component-a",
+	}, <-labels)
+	require.NoError(t, sched.Close())
+}
+
 type fakeRunnable struct {
 	ID        string
 	Component component.Component
@@ -105,6 +208,23 @@ func (fr fakeRunnable) Block() *ast.BlockStmt          { return nil }
 func (fr fakeRunnable) Evaluate(scope *vm.Scope) error { return nil }
 func (fr fakeRunnable) UpdateBlock(b *ast.BlockStmt)   {}
 
+type fakeRestartable struct {
+	fakeRunnable
+	Policy controller.RestartPolicy
+
+	Restarts []int
+	Backoffs []time.Duration
+}
+
+var _ controller.RestartableNode = (*fakeRestartable)(nil)
+
+func (fr *fakeRestartable) RestartPolicy() controller.RestartPolicy { return fr.Policy }
+
+func (fr *fakeRestartable) OnRestart(_ error, restarts int, backoff time.Duration) {
+	fr.Restarts = append(fr.Restarts, restarts)
+	fr.Backoffs = append(fr.Backoffs, backoff)
+}
+
 type mockComponent struct {
 	RunFunc    func(ctx context.Context) error
 	UpdateFunc func(newConfig component.Arguments) error
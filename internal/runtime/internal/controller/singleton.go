@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/ckit/peer"
+
+	"github.com/grafana/alloy/internal/component"
+)
+
+// clusterServiceName is the name of the service whose data elects the leader
+// of singleton components. The cluster service can't be imported by the
+// controller since it depends on it.
+const clusterServiceName = "cluster"
+
+// leaderElector is the subset of the cluster service data used to run
+// singleton components.
+type leaderElector interface {
+	// Leader returns the peer elected as the leader of the election called
+	// name.
+	Leader(name string) (peer.Peer, error)
+
+	// Changed returns a channel which is closed the next time the set of
+	// peers changes.
+	Changed() <-chan struct{}
+}
+
+// clusterFromServiceData returns a function which retrieves the leaderElector
+// from the data of the cluster service.
+func clusterFromServiceData(getServiceData func(name string) (interface{}, error)) func() (leaderElector, error) {
+	return func() (leaderElector, error) {
+		if getServiceData == nil {
+			return nil, fmt.Errorf("service %q does not exist", clusterServiceName)
+		}
+		data, err := getServiceData(clusterServiceName)
+		if err != nil {
+			return nil, err
+		}
+		elector, ok := data.(leaderElector)
+		if !ok {
+			return nil, fmt.Errorf("service %q does not support leader election", clusterServiceName)
+		}
+		return elector, nil
+	}
+}
+
+// Singleton returns whether the component only runs on the leader of the
+// cluster.
+func (cn *BuiltinComponentNode) Singleton() bool {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+	return cn.singleton
+}
+
+// runManaged runs the managed component until ctx is canceled or the
+// component exits.
+//
+// Singleton components are only run while the local node is the leader of
+// the election named after the global ID of the component. They're stopped
+// when the leadership moves to another node, and a new managed component is
+// started if it comes back.
+func (cn *BuiltinComponentNode) runManaged(ctx context.Context) error {
+	var running *managedRun
+
+	for {
+		run, changed := cn.shouldRun()
+
+		switch {
+		case run && running == nil:
+			managed, err := cn.runnableManaged()
+			if err != nil {
+				return err
+			}
+			running = startManagedRun(ctx, managed)
+			if cn.Singleton() {
+				cn.setRunHealth(component.HealthTypeHealthy, "started component on the leader of the cluster")
+			}
+		case !run && running != nil:
+			_ = running.Stop()
+			running = nil
+		}
+
+		var exited <-chan error
+		if running != nil {
+			exited = running.exited
+		}
+
+		select {
+		case <-ctx.Done():
+			if running != nil {
+				return running.Stop()
+			}
+			return nil
+		case err := <-exited:
+			running.cancel()
+			return err
+		case <-changed:
+		case <-cn.singletonUpdate:
+		}
+	}
+}
+
+// managedRun is a call to the Run method of a managed component.
+type managedRun struct {
+	cancel context.CancelFunc
+	exited chan error
+}
+
+// startManagedRun calls managed.Run in a new goroutine.
+func startManagedRun(ctx context.Context, managed component.Component) *managedRun {
+	ctx, cancel := context.WithCancel(ctx)
+	r := &managedRun{cancel: cancel, exited: make(chan error, 1)}
+	go func() { r.exited <- managed.Run(ctx) }()
+	return r
+}
+
+// Stop cancels the run and returns the error returned by Run.
+func (r *managedRun) Stop() error {
+	r.cancel()
+	return <-r.exited
+}
+
+// shouldRun returns whether the managed component should be running on the
+// local node, and a channel which is closed when the answer may change.
+func (cn *BuiltinComponentNode) shouldRun() (run bool, changed <-chan struct{}) {
+	if !cn.Singleton() {
+		return true, nil
+	}
+
+	cluster, err := cn.getCluster()
+	if err != nil {
+		// Without a cluster, the local node is the only one which can run the
+		// component.
+		return true, nil
+	}
+
+	changed = cluster.Changed()
+	leader, err := cluster.Leader(cn.globalID)
+	switch {
+	case err != nil:
+		cn.setRunHealth(component.HealthTypeUnhealthy, fmt.Sprintf("singleton component is not running: %s", err))
+		return false, changed
+	case !leader.Self:
+		cn.setRunHealth(component.HealthTypeHealthy, fmt.Sprintf("singleton component is running on the leader %s", leader.Name))
+		return false, changed
+	}
+	return true, changed
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/ckit/peer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component"
+)
+
+func TestBuiltinComponentNode_RunSingleton(t *testing.T) {
+	elector := newFakeElector(false)
+	cn, builder := newRunCountingNode(t, elector, true)
+	managed := builder.Last()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	exited := make(chan error, 1)
+	go func() { exited <- cn.runManaged(ctx) }()
+
+	// The component must not run while another node is the leader.
+	time.Sleep(50 * time.Millisecond)
+	require.False(t, managed.Running())
+	cn.healthMut.RLock()
+	require.Equal(t, component.HealthTypeHealthy, cn.runHealth.Health)
+	require.Contains(t, cn.runHealth.Message, "running on the leader other")
+	cn.healthMut.RUnlock()
+
+	elector.SetLeader(true)
+	require.Eventually(t, managed.Running, time.Second, 10*time.Millisecond)
+
+	elector.SetLeader(false)
+	require.Eventually(t, func() bool { return !managed.Running() }, time.Second, 10*time.Millisecond)
+
+	// A new component is built when the leadership comes back, since the
+	// stopped one can't be run again.
+	elector.SetLeader(true)
+	require.Eventually(t, func() bool { return builder.Builds() == 2 && builder.Last().Running() }, time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, managed.Runs())
+	require.Equal(t, 1, builder.Last().Runs())
+
+	cancel()
+	require.NoError(t, <-exited)
+	require.False(t, builder.Last().Running())
+}
+
+func TestBuiltinComponentNode_RunSingletonUpdate(t *testing.T) {
+	elector := newFakeElector(false)
+	cn, builder := newRunCountingNode(t, elector, false)
+	managed := builder.Last()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = cn.runManaged(ctx) }()
+
+	// Components which aren't singletons always run.
+	require.Eventually(t, managed.Running, time.Second, 10*time.Millisecond)
+
+	cn.mut.Lock()
+	cn.singleton = true
+	cn.mut.Unlock()
+	cn.singletonUpdate <- struct{}{}
+	require.Eventually(t, func() bool { return !managed.Running() }, time.Second, 10*time.Millisecond)
+}
+
+// newRunCountingNode returns a node whose managed components are built by the
+// returned builder. The first managed component is already built.
+func newRunCountingNode(t *testing.T, elector leaderElector, singleton bool) (*BuiltinComponentNode, *runCountingBuilder) {
+	builder := &runCountingBuilder{}
+	cn := &BuiltinComponentNode{
+		globalID:        "singleton.test",
+		reg:             component.Registration{Build: builder.Build},
+		managedOpts:     component.Options{Registerer: prometheus.NewRegistry()},
+		singleton:       singleton,
+		singletonUpdate: make(chan struct{}, 1),
+		getCluster:      func() (leaderElector, error) { return elector, nil },
+	}
+
+	cn.mut.Lock()
+	defer cn.mut.Unlock()
+	require.NoError(t, cn.buildManaged(struct{}{}))
+	return cn, builder
+}
+
+type fakeElector struct {
+	mut     sync.Mutex
+	leader  bool
+	changed chan struct{}
+}
+
+func newFakeElector(leader bool) *fakeElector {
+	return &fakeElector{leader: leader, changed: make(chan struct{})}
+}
+
+func (e *fakeElector) Leader(string) (peer.Peer, error) {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+	if e.leader {
+		return peer.Peer{Name: "self", Self: true, State: peer.StateParticipant}, nil
+	}
+	return peer.Peer{Name: "other", State: peer.StateParticipant}, nil
+}
+
+func (e *fakeElector) Changed() <-chan struct{} {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+	return e.changed
+}
+
+func (e *fakeElector) SetLeader(leader bool) {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+	e.leader = leader
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+// runCountingBuilder builds runCountingComponents.
+type runCountingBuilder struct {
+	mut   sync.Mutex
+	built []*runCountingComponent
+}
+
+func (b *runCountingBuilder) Build(component.Options, component.Arguments) (component.Component, error) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	c := &runCountingComponent{}
+	b.built = append(b.built, c)
+	return c, nil
+}
+
+func (b *runCountingBuilder) Builds() int {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	return len(b.built)
+}
+
+// Last returns the last built component.
+func (b *runCountingBuilder) Last() *runCountingComponent {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	return b.built[len(b.built)-1]
+}
+
+// runCountingComponent is a component which tracks whether it's running and
+// how many times it was run.
+type runCountingComponent struct {
+	mut     sync.Mutex
+	running bool
+	runs    int
+}
+
+var _ component.Component = (*runCountingComponent)(nil)
+
+func (c *runCountingComponent) Run(ctx context.Context) error {
+	c.mut.Lock()
+	c.running = true
+	c.runs++
+	c.mut.Unlock()
+
+	<-ctx.Done()
+
+	c.mut.Lock()
+	c.running = false
+	c.mut.Unlock()
+	return nil
+}
+
+func (c *runCountingComponent) Update(component.Arguments) error { return nil }
+
+func (c *runCountingComponent) Running() bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.running
+}
+
+func (c *runCountingComponent) Runs() int {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.runs
+}
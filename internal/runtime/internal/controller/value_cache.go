@@ -21,6 +21,8 @@ type valueCache struct {
 	moduleArguments    map[string]any         // key -> module arguments value
 	moduleExports      map[string]any         // name -> value for the value of module exports
 	moduleChangedIndex int                    // Everytime a change occurs this is incremented
+	scope              *vm.Scope              // Optional parent scope for the built context
+	functions          map[string]any         // name -> user-defined function or namespace of imported functions
 }
 
 // newValueCache creates a new ValueCache.
@@ -123,6 +125,43 @@ func (vc *valueCache) ExportChangeIndex() int {
 	return vc.moduleChangedIndex
 }
 
+// SetScope sets the parent scope of the contexts built by BuildContext. The
+// values of the parent scope are available to expressions unless they are
+// overridden by the cached values. scope may be nil.
+func (vc *valueCache) SetScope(scope *vm.Scope) {
+	vc.mut.Lock()
+	defer vc.mut.Unlock()
+	vc.scope = scope
+}
+
+// SetFunctions sets the user-defined functions exposed by the contexts built
+// by BuildContext. Functions take precedence over the values of the parent
+// scope set by SetScope. scope may be nil.
+func (vc *valueCache) SetFunctions(scope *vm.Scope) {
+	vc.mut.Lock()
+	defer vc.mut.Unlock()
+	vc.functions = flattenScope(scope)
+}
+
+// ReferenceScope returns the scope which is visible to expressions in
+// addition to the cached component values: the parent scope set by SetScope
+// and the functions set by SetFunctions.
+func (vc *valueCache) ReferenceScope() *vm.Scope {
+	vc.mut.RLock()
+	defer vc.mut.RUnlock()
+	return vc.referenceScope()
+}
+
+func (vc *valueCache) referenceScope() *vm.Scope {
+	if len(vc.functions) == 0 {
+		return vc.scope
+	}
+	return &vm.Scope{
+		Parent:    vc.scope,
+		Variables: vc.functions,
+	}
+}
+
 // SyncIDs will remove any cached values for any Component ID which is not in
 // ids. SyncIDs should be called with the current set of components after the
 // graph is updated.
@@ -164,8 +203,9 @@ func (vc *valueCache) BuildContext() *vm.Scope {
 	vc.mut.RLock()
 	defer vc.mut.RUnlock()
 
+	parent := vc.referenceScope()
 	scope := &vm.Scope{
-		Parent:    nil,
+		Parent:    parent,
 		Variables: make(map[string]interface{}),
 	}
 
@@ -176,9 +216,15 @@ func (vc *valueCache) BuildContext() *vm.Scope {
 		componentsByBlockName[blockName] = append(componentsByBlockName[blockName], id)
 	}
 
-	// Then, convert each partition into a single value.
+	// Then, convert each partition into a single value. Values sharing a block
+	// name with a value from the parent scope are merged with it so that local
+	// components don't hide unrelated components of the parent scope.
 	for blockName, ids := range componentsByBlockName {
-		scope.Variables[blockName] = vc.buildValue(ids, 1)
+		value := vc.buildValue(ids, 1)
+		if parentValue, found := lookupVariable(parent, blockName); found {
+			value = mergeValues(parentValue, value)
+		}
+		scope.Variables[blockName] = value
 	}
 
 	// Add module arguments to the scope.
@@ -230,3 +276,40 @@ func (vc *valueCache) buildValue(from []ComponentID, offset int) interface{} {
 	}
 	return attrs
 }
+
+// lookupVariable looks up a variable by name in scope and its parents,
+// ignoring the stdlib.
+func lookupVariable(scope *vm.Scope, name string) (interface{}, bool) {
+	for ; scope != nil; scope = scope.Parent {
+		if val, ok := scope.Variables[name]; ok {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+// mergeValues merges two values built by buildValue. Objects are merged
+// recursively, with fields from local taking precedence over fields from
+// parent. Any other kind of value in local replaces the value from parent.
+func mergeValues(parent, local interface{}) interface{} {
+	parentAttrs, ok := parent.(map[string]interface{})
+	if !ok {
+		return local
+	}
+	localAttrs, ok := local.(map[string]interface{})
+	if !ok {
+		return local
+	}
+
+	merged := make(map[string]interface{}, len(parentAttrs)+len(localAttrs))
+	for key, value := range parentAttrs {
+		merged[key] = value
+	}
+	for key, value := range localAttrs {
+		if parentValue, found := parentAttrs[key]; found {
+			value = mergeValues(parentValue, value)
+		}
+		merged[key] = value
+	}
+	return merged
+}
@@ -0,0 +1,309 @@
+// Package lastgood keeps track of the last known good config of an Alloy
+// controller.
+//
+// A config is good once all of its components are healthy within a grace
+// period after being loaded. The last known good config is persisted, so a
+// controller can be rolled back to it when a newer config doesn't become
+// healthy, even across restarts.
+package lastgood
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/alloy/internal/component"
+	alloy_runtime "github.com/grafana/alloy/internal/runtime"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// FileName is the name of the file where the last known good config is
+// persisted, relative to the storage path.
+const FileName = "last_known_good.alloy"
+
+// DefaultGracePeriod is the default time the components of a config have to
+// become healthy.
+const DefaultGracePeriod = time.Minute
+
+// Options configure a Tracker.
+type Options struct {
+	Logger      log.Logger
+	StoragePath string        // Directory where the last known good config is persisted.
+	GracePeriod time.Duration // Time the components of a config have to become healthy.
+	Rollback    bool          // Roll back to the last known good config when a config doesn't become healthy.
+	Metrics     prometheus.Registerer
+
+	// Components returns the components whose health is checked.
+	Components component.Provider
+
+	// Load loads a config source into the controller.
+	Load func(source *alloy_runtime.Source) error
+}
+
+// Tracker loads config sources into a controller and keeps track of the last
+// one whose components all became healthy.
+type Tracker struct {
+	opts    Options
+	log     log.Logger
+	metrics *metrics
+
+	mut     sync.Mutex
+	cancel  context.CancelFunc // Stops watching the current config.
+	watches sync.WaitGroup
+}
+
+type metrics struct {
+	lastKnownGood  prometheus.Gauge
+	unhealthyLoads prometheus.Counter
+	rollbacks      *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		lastKnownGood: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "alloy_config_last_known_good_timestamp_seconds",
+			Help: "Timestamp of the last time a config was persisted as the last known good config.",
+		}),
+		unhealthyLoads: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "alloy_config_unhealthy_loads_total",
+			Help: "Number of loaded configs whose components didn't all become healthy within the grace period.",
+		}),
+		rollbacks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "alloy_config_rollbacks_total",
+			Help: "Number of rollbacks to the last known good config by result.",
+		}, []string{"result"}),
+	}
+	if reg != nil {
+		reg.MustRegister(m.lastKnownGood, m.unhealthyLoads, m.rollbacks)
+	}
+	return m
+}
+
+// New creates a new Tracker.
+func New(opts Options) (*Tracker, error) {
+	if opts.GracePeriod <= 0 {
+		return nil, fmt.Errorf("grace period must be greater than 0")
+	}
+	if opts.Components == nil || opts.Load == nil {
+		return nil, fmt.Errorf("components and load must be set")
+	}
+
+	l := opts.Logger
+	if l == nil {
+		l = log.NewNopLogger()
+	}
+	return &Tracker{
+		opts:    opts,
+		log:     l,
+		metrics: newMetrics(opts.Metrics),
+	}, nil
+}
+
+// path returns the path of the persisted config.
+func (t *Tracker) path() string {
+	return filepath.Join(t.opts.StoragePath, FileName)
+}
+
+// LoadSource loads source into the controller, and watches its components
+// until they're all healthy or the grace period expires. source is persisted
+// as the last known good config once its components are all healthy. If they
+// aren't healthy once the grace period expires and rollback is enabled, the
+// controller is rolled back to the last known good config.
+//
+// Loading a new source stops watching the previous one.
+func (t *Tracker) LoadSource(source *alloy_runtime.Source) error {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	t.stopWatch()
+	if err := t.opts.Load(source); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.watches.Add(1)
+	go func() {
+		defer t.watches.Done()
+		t.watch(ctx, source)
+	}()
+	return nil
+}
+
+// Rollback loads the last known good config into the controller. The
+// components of the last known good config aren't watched, so they're never
+// rolled back.
+func (t *Tracker) Rollback() error {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	t.stopWatch()
+	return t.rollback()
+}
+
+// Stop stops watching the current config.
+func (t *Tracker) Stop() {
+	t.mut.Lock()
+	t.stopWatch()
+	t.mut.Unlock()
+
+	t.watches.Wait()
+}
+
+// stopWatch stops watching the current config. t.mut must be held.
+func (t *Tracker) stopWatch() {
+	if t.cancel != nil {
+		t.cancel()
+		t.cancel = nil
+	}
+}
+
+// rollback loads the last known good config. t.mut must be held.
+func (t *Tracker) rollback() error {
+	source, err := t.LastKnownGood()
+	if err == nil && source == nil {
+		err = errors.New("no last known good config")
+	}
+	if err == nil {
+		err = t.opts.Load(source)
+	}
+
+	if err != nil {
+		t.metrics.rollbacks.WithLabelValues("failure").Inc()
+		level.Error(t.log).Log("msg", "failed to roll back to the last known good config", "path", t.path(), "err", err)
+		return err
+	}
+	t.metrics.rollbacks.WithLabelValues("success").Inc()
+	level.Warn(t.log).Log("msg", "rolled back to the last known good config", "path", t.path())
+	return nil
+}
+
+// LastKnownGood returns the persisted last known good config, or nil if there
+// isn't one.
+func (t *Tracker) LastKnownGood() (*alloy_runtime.Source, error) {
+	bb, err := os.ReadFile(t.path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return alloy_runtime.ParseSource(t.path(), bb)
+}
+
+// watch waits for the components of source to all become healthy.
+func (t *Tracker) watch(ctx context.Context, source *alloy_runtime.Source) {
+	ticker := time.NewTicker(max(min(time.Second, t.opts.GracePeriod/10), time.Millisecond))
+	defer ticker.Stop()
+	timeout := time.NewTimer(t.opts.GracePeriod)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if len(t.unhealthyComponents()) > 0 {
+				continue
+			}
+			if err := t.persist(source); err != nil {
+				level.Error(t.log).Log("msg", "failed to persist the last known good config", "path", t.path(), "err", err)
+			}
+			return
+		case <-timeout.C:
+			t.onUnhealthy(ctx, source)
+			return
+		}
+	}
+}
+
+// onUnhealthy is called when the components of source, the current config,
+// didn't all become healthy within the grace period.
+func (t *Tracker) onUnhealthy(ctx context.Context, source *alloy_runtime.Source) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	if ctx.Err() != nil {
+		// A new config was loaded in the meantime.
+		return
+	}
+	t.stopWatch()
+
+	t.metrics.unhealthyLoads.Inc()
+	level.Warn(t.log).Log("msg", "components didn't become healthy within the grace period", "grace_period", t.opts.GracePeriod, "components", fmt.Sprint(t.unhealthyComponents()))
+
+	if !t.opts.Rollback {
+		return
+	}
+	if prev, err := os.ReadFile(t.path()); err == nil && bytes.Equal(prev, combine(source)) {
+		level.Warn(t.log).Log("msg", "not rolling back, the current config is the last known good config", "path", t.path())
+		return
+	}
+	_ = t.rollback()
+}
+
+// unhealthyComponents returns the IDs of the components which aren't
+// healthy, sorted.
+func (t *Tracker) unhealthyComponents() []string {
+	var ids []string
+	for _, info := range component.GetAllComponents(t.opts.Components, component.InfoOptions{GetHealth: true}) {
+		if info.Health.Health != component.HealthTypeHealthy {
+			ids = append(ids, info.ID.String())
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// combine combines the files of source into a single file, since they form a
+// single unit.
+func combine(source *alloy_runtime.Source) []byte {
+	var (
+		files = source.RawConfigs()
+		names = make([]string, 0, len(files))
+		buf   bytes.Buffer
+	)
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		buf.Write(files[name])
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// persist writes source as the last known good config.
+func (t *Tracker) persist(source *alloy_runtime.Source) error {
+	bb := combine(source)
+	if prev, err := os.ReadFile(t.path()); err == nil && bytes.Equal(prev, bb) {
+		t.metrics.lastKnownGood.SetToCurrentTime()
+		return nil
+	}
+
+	if err := os.MkdirAll(t.opts.StoragePath, 0o750); err != nil {
+		return err
+	}
+	// Write to a temporary file first so that the persisted config is never
+	// partially written.
+	tmp := t.path() + ".tmp"
+	if err := os.WriteFile(tmp, bb, 0o640); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, t.path()); err != nil {
+		return err
+	}
+
+	t.metrics.lastKnownGood.SetToCurrentTime()
+	level.Info(t.log).Log("msg", "persisted the last known good config", "path", t.path())
+	return nil
+}
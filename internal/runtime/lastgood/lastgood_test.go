@@ -0,0 +1,155 @@
+package lastgood_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component"
+	alloy_runtime "github.com/grafana/alloy/internal/runtime"
+	"github.com/grafana/alloy/internal/runtime/lastgood"
+)
+
+const (
+	goodConfig = `local.file "good" { filename = "/tmp/good" }`
+	badConfig  = `local.file "bad" { filename = "/tmp/bad" }`
+)
+
+func TestTracker_PersistsHealthyConfig(t *testing.T) {
+	env := newTestEnvironment(t, false)
+	env.setHealth(component.HealthTypeHealthy)
+
+	require.NoError(t, env.tracker.LoadSource(parseSource(t, goodConfig)))
+	require.Eventually(t, func() bool {
+		bb, err := os.ReadFile(filepath.Join(env.dir, lastgood.FileName))
+		return err == nil && string(bb) == goodConfig+"\n"
+	}, time.Second, 10*time.Millisecond)
+
+	source, err := env.tracker.LastKnownGood()
+	require.NoError(t, err)
+	require.Equal(t, goodConfig+"\n", string(source.RawConfigs()[filepath.Join(env.dir, lastgood.FileName)]))
+}
+
+func TestTracker_RollsBackUnhealthyConfig(t *testing.T) {
+	env := newTestEnvironment(t, true)
+	require.NoError(t, os.WriteFile(filepath.Join(env.dir, lastgood.FileName), []byte(goodConfig+"\n"), 0o644))
+
+	env.setHealth(component.HealthTypeUnhealthy)
+	require.NoError(t, env.tracker.LoadSource(parseSource(t, badConfig)))
+
+	require.Eventually(t, func() bool { return len(env.getLoaded()) == 2 }, time.Second, 10*time.Millisecond)
+	env.tracker.Stop()
+
+	loaded := env.getLoaded()
+	require.Equal(t, badConfig, loaded[0])
+	require.Equal(t, goodConfig+"\n", loaded[1])
+	require.Equal(t, 1.0, env.counter("alloy_config_unhealthy_loads_total"))
+}
+
+func TestTracker_NoRollbackWhenDisabled(t *testing.T) {
+	env := newTestEnvironment(t, false)
+	require.NoError(t, os.WriteFile(filepath.Join(env.dir, lastgood.FileName), []byte(goodConfig+"\n"), 0o644))
+
+	env.setHealth(component.HealthTypeUnhealthy)
+	require.NoError(t, env.tracker.LoadSource(parseSource(t, badConfig)))
+
+	require.Eventually(t, func() bool {
+		return env.counter("alloy_config_unhealthy_loads_total") == 1
+	}, time.Second, 10*time.Millisecond)
+	env.tracker.Stop()
+
+	require.Equal(t, []string{badConfig}, env.getLoaded())
+}
+
+func TestTracker_Rollback(t *testing.T) {
+	env := newTestEnvironment(t, true)
+	require.EqualError(t, env.tracker.Rollback(), "no last known good config")
+
+	require.NoError(t, os.WriteFile(filepath.Join(env.dir, lastgood.FileName), []byte(goodConfig+"\n"), 0o644))
+	require.NoError(t, env.tracker.Rollback())
+	require.Equal(t, []string{goodConfig + "\n"}, env.getLoaded())
+}
+
+type testEnvironment struct {
+	dir     string
+	reg     *prometheus.Registry
+	tracker *lastgood.Tracker
+
+	mut    sync.Mutex
+	health component.HealthType
+	loaded []string
+}
+
+func newTestEnvironment(t *testing.T, rollback bool) *testEnvironment {
+	env := &testEnvironment{
+		dir: t.TempDir(),
+		reg: prometheus.NewRegistry(),
+	}
+
+	var err error
+	env.tracker, err = lastgood.New(lastgood.Options{
+		StoragePath: env.dir,
+		GracePeriod: 200 * time.Millisecond,
+		Rollback:    rollback,
+		Metrics:     env.reg,
+		Components:  env,
+		Load: func(source *alloy_runtime.Source) error {
+			env.mut.Lock()
+			defer env.mut.Unlock()
+			for _, bb := range source.RawConfigs() {
+				env.loaded = append(env.loaded, string(bb))
+			}
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(env.tracker.Stop)
+	return env
+}
+
+func (env *testEnvironment) setHealth(h component.HealthType) {
+	env.mut.Lock()
+	defer env.mut.Unlock()
+	env.health = h
+}
+
+func (env *testEnvironment) getLoaded() []string {
+	env.mut.Lock()
+	defer env.mut.Unlock()
+	return append([]string(nil), env.loaded...)
+}
+
+// counter returns the value of the counter with the given name.
+func (env *testEnvironment) counter(name string) float64 {
+	mfs, _ := env.reg.Gather()
+	for _, mf := range mfs {
+		if mf.GetName() == name {
+			return mf.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func (env *testEnvironment) GetComponent(id component.ID, opts component.InfoOptions) (*component.Info, error) {
+	return nil, component.ErrComponentNotFound
+}
+
+func (env *testEnvironment) ListComponents(moduleID string, opts component.InfoOptions) ([]*component.Info, error) {
+	env.mut.Lock()
+	defer env.mut.Unlock()
+	return []*component.Info{{
+		ID:     component.ID{LocalID: "local.file.test"},
+		Health: component.Health{Health: env.health},
+	}}, nil
+}
+
+func parseSource(t *testing.T, config string) *alloy_runtime.Source {
+	source, err := alloy_runtime.ParseSource(t.Name(), []byte(config))
+	require.NoError(t, err)
+	return source
+}
@@ -42,21 +42,10 @@ func (m *moduleController) NewModule(id string, export component.ExportFunc) (co
 	if id != "" && !scanner.IsValidIdentifier(id) {
 		return nil, fmt.Errorf("module ID %q is not a valid identifier", id)
 	}
-
-	m.mut.Lock()
-	defer m.mut.Unlock()
-	fullPath := m.o.ID
-	if id != "" {
-		fullPath = path.Join(fullPath, id)
+	mod, err := m.newModule(id, export)
+	if err != nil {
+		return nil, err
 	}
-
-	mod := newModule(&moduleOptions{
-		ID:                      fullPath,
-		export:                  export,
-		moduleControllerOptions: m.o,
-		parent:                  m,
-	})
-
 	return mod, nil
 }
 
@@ -65,7 +54,15 @@ func (m *moduleController) NewCustomComponent(id string, export component.Export
 	if id != "" && !scanner.IsValidIdentifier(id) {
 		return nil, fmt.Errorf("customComponent ID %q is not a valid identifier", id)
 	}
+	mod, err := m.newModule(id, export)
+	if err != nil {
+		return nil, err
+	}
+	return mod, nil
+}
 
+// newModule creates a new, unstarted module with the given ID relative to m.
+func (m *moduleController) newModule(id string, export component.ExportFunc) (*module, error) {
 	m.mut.Lock()
 	defer m.mut.Unlock()
 	fullPath := m.o.ID
@@ -80,6 +77,13 @@ func (m *moduleController) NewCustomComponent(id string, export component.Export
 		parent:                  m,
 	})
 
+	if m.o.ValidateOnly {
+		// Modules of a controller which only validates its config are never run,
+		// so they're registered as soon as they're created to be listed.
+		if err := m.registerModule(mod); err != nil {
+			return nil, err
+		}
+	}
 	return mod, nil
 }
 
@@ -94,6 +98,11 @@ func (m *moduleController) removeModule(mod *module) {
 func (m *moduleController) addModule(mod *module) error {
 	m.mut.Lock()
 	defer m.mut.Unlock()
+	return m.registerModule(mod)
+}
+
+// registerModule registers mod. m.mut must be held.
+func (m *moduleController) registerModule(mod *module) error {
 	if err := m.o.ModuleRegistry.Register(mod.o.ID, mod); err != nil {
 		level.Error(m.o.Logger).Log("msg", "error registering module", "id", mod.o.ID, "err", err)
 		return err
@@ -135,6 +144,7 @@ func newModule(o *moduleOptions) *module {
 			ModuleRegistry:    o.ModuleRegistry,
 			ComponentRegistry: o.ComponentRegistry,
 			WorkerPool:        o.WorkerPool,
+			ValidateExports:   o.ValidateExports,
 			Options: Options{
 				ControllerID:         o.ID,
 				Tracer:               o.Tracer,
@@ -143,6 +153,8 @@ func newModule(o *moduleOptions) *module {
 				DataPath:             o.DataPath,
 				MinStability:         o.MinStability,
 				EnableCommunityComps: o.EnableCommunityComps,
+				ValidateOnly:         o.ValidateOnly,
+				RestartPolicy:        o.RestartPolicy,
 				OnExportsChange: func(exports map[string]any) {
 					if o.export != nil {
 						o.export(exports)
@@ -230,4 +242,15 @@ type moduleControllerOptions struct {
 
 	// EnableCommunityComps enables the use of community components.
 	EnableCommunityComps bool
+
+	// ValidateOnly makes the module decode the arguments of its components
+	// without building them.
+	ValidateOnly bool
+
+	// RestartPolicy is the restart policy of the components of the module.
+	RestartPolicy RestartPolicy
+
+	// ValidateExports returns the exports of components from their global ID
+	// when ValidateOnly is set.
+	ValidateExports func(globalID string) (component.Exports, bool)
 }
@@ -0,0 +1,472 @@
+package pipelinetest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/storage"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	alloy_runtime "github.com/grafana/alloy/internal/runtime"
+)
+
+var (
+	logsReceiverType = reflect.TypeOf((*loki.LogsReceiver)(nil)).Elem()
+	appendableType   = reflect.TypeOf((*storage.Appendable)(nil)).Elem()
+	consumerType     = reflect.TypeOf((*otelcol.Consumer)(nil)).Elem()
+	consumerArgsType = reflect.TypeOf(otelcol.ConsumerArguments{})
+)
+
+// registry wraps a component registry to replace the components used as
+// inputs and outputs of a test. Components are replaced by ID rather than by
+// name, so other components of the same name are left unchanged.
+type registry struct {
+	inner   alloy_runtime.ComponentRegistry
+	sources map[string]*fixtureSource // Fixtures by component ID.
+	sinks   map[string]*capture       // Captures by component ID.
+
+	mut   sync.Mutex
+	built map[string]struct{}
+}
+
+var _ alloy_runtime.ComponentRegistry = (*registry)(nil)
+
+// Get implements alloy_runtime.ComponentRegistry.
+func (r *registry) Get(name string) (component.Registration, error) {
+	reg, err := r.inner.Get(name)
+	if err != nil {
+		return reg, err
+	}
+
+	build := reg.Build
+	exports := reg.Exports
+	reg.Build = func(opts component.Options, args component.Arguments) (component.Component, error) {
+		if src, ok := r.sources[opts.ID]; ok {
+			r.markBuilt(opts.ID)
+			return newSourceComponent(opts, src, exports, args)
+		}
+		if c, ok := r.sinks[opts.ID]; ok {
+			r.markBuilt(opts.ID)
+			return newSinkComponent(opts, c, exports)
+		}
+		return build(opts, args)
+	}
+	return reg, nil
+}
+
+func (r *registry) markBuilt(id string) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	if r.built == nil {
+		r.built = make(map[string]struct{})
+	}
+	r.built[id] = struct{}{}
+}
+
+// checkBuilt returns an error if an input or output component isn't part of
+// the loaded pipeline.
+func (r *registry) checkBuilt() error {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	var missing []string
+	for id := range r.sources {
+		if _, ok := r.built[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	for id := range r.sinks {
+		if _, ok := r.built[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("component %q not found in the pipeline", missing[0])
+	}
+	return nil
+}
+
+// fixtureSource is the data sent by a source component.
+type fixtureSource struct {
+	format Format
+	data   *data
+}
+
+// destinations are the receivers in the arguments of a source component.
+type destinations struct {
+	logs       []loki.LogsReceiver
+	appenders  []storage.Appendable
+	otelLogs   []otelcol.Consumer
+	otelMetric []otelcol.Consumer
+	otelTraces []otelcol.Consumer
+}
+
+// findDestinations walks v to find the receivers it holds.
+func findDestinations(v reflect.Value, dests *destinations) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Interface && v.CanInterface() {
+			switch recv := v.Interface().(type) {
+			case loki.LogsReceiver:
+				dests.logs = append(dests.logs, recv)
+				return
+			case storage.Appendable:
+				dests.appenders = append(dests.appenders, recv)
+				return
+			}
+		}
+		findDestinations(v.Elem(), dests)
+
+	case reflect.Struct:
+		if v.Type() == consumerArgsType && v.CanInterface() {
+			args := v.Interface().(otelcol.ConsumerArguments)
+			dests.otelLogs = append(dests.otelLogs, args.Logs...)
+			dests.otelMetric = append(dests.otelMetric, args.Metrics...)
+			dests.otelTraces = append(dests.otelTraces, args.Traces...)
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				findDestinations(v.Field(i), dests)
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			findDestinations(v.Index(i), dests)
+		}
+	}
+}
+
+// sourceComponent replaces a source component of the pipeline. It keeps the
+// arguments of the replaced component, and sends the data of its fixture to
+// the receivers found in them.
+type sourceComponent struct {
+	src *fixtureSource
+
+	mut  sync.Mutex
+	args component.Arguments
+}
+
+func newSourceComponent(opts component.Options, src *fixtureSource, exports component.Exports, args component.Arguments) (*sourceComponent, error) {
+	c := &sourceComponent{src: src}
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	// Other components may reference the exports of the replaced component,
+	// so it exports their zero value.
+	if exports != nil {
+		opts.OnStateChange(reflect.New(reflect.TypeOf(exports)).Elem().Interface())
+	}
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *sourceComponent) Run(ctx context.Context) error {
+	c.mut.Lock()
+	dests := c.destinations()
+	c.mut.Unlock()
+
+	switch c.src.format {
+	case FormatLoki:
+		for _, entry := range c.src.data.entries {
+			for _, recv := range dests.logs {
+				select {
+				case recv.Chan() <- entry.Clone():
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+
+	case FormatPrometheus:
+		for _, appendable := range dests.appenders {
+			app := appendable.Appender(ctx)
+			for _, s := range c.src.data.samples {
+				if _, err := app.Append(0, s.labels, s.t, s.v); err != nil {
+					_ = app.Rollback()
+					return err
+				}
+			}
+			if err := app.Commit(); err != nil {
+				return err
+			}
+		}
+
+	case FormatOTLPLogs:
+		for _, consumer := range dests.otelLogs {
+			logs := plog.NewLogs()
+			c.src.data.logs.CopyTo(logs)
+			if err := consumer.ConsumeLogs(ctx, logs); err != nil {
+				return err
+			}
+		}
+
+	case FormatOTLPMetrics:
+		for _, consumer := range dests.otelMetric {
+			metrics := pmetric.NewMetrics()
+			c.src.data.metrics.CopyTo(metrics)
+			if err := consumer.ConsumeMetrics(ctx, metrics); err != nil {
+				return err
+			}
+		}
+
+	case FormatOTLPTraces:
+		for _, consumer := range dests.otelTraces {
+			traces := ptrace.NewTraces()
+			c.src.data.traces.CopyTo(traces)
+			if err := consumer.ConsumeTraces(ctx, traces); err != nil {
+				return err
+			}
+		}
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+// Update implements component.Component.
+func (c *sourceComponent) Update(args component.Arguments) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.args = args
+
+	dests := c.destinations()
+	var found bool
+	switch c.src.format {
+	case FormatLoki:
+		found = len(dests.logs) > 0
+	case FormatPrometheus:
+		found = len(dests.appenders) > 0
+	case FormatOTLPLogs:
+		found = len(dests.otelLogs) > 0
+	case FormatOTLPMetrics:
+		found = len(dests.otelMetric) > 0
+	case FormatOTLPTraces:
+		found = len(dests.otelTraces) > 0
+	}
+	if !found {
+		return fmt.Errorf("the arguments of the component have no receivers for input format %q", c.src.format)
+	}
+	return nil
+}
+
+func (c *sourceComponent) destinations() destinations {
+	var dests destinations
+	findDestinations(reflect.ValueOf(c.args), &dests)
+	return dests
+}
+
+// sinkComponent replaces a sink component of the pipeline. It exports
+// receivers which record the data sent to them in a capture.
+type sinkComponent struct {
+	c    *capture
+	logs loki.LogsReceiver
+}
+
+func newSinkComponent(opts component.Options, c *capture, exports component.Exports) (*sinkComponent, error) {
+	if exports == nil {
+		return nil, fmt.Errorf("the component has no exports to receive output format %q", c.format)
+	}
+
+	s := &sinkComponent{c: c, logs: loki.NewLogsReceiver()}
+
+	var found bool
+	rv := reflect.New(reflect.TypeOf(exports)).Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Field(i)
+		if !rv.Type().Field(i).IsExported() {
+			continue
+		}
+		switch field.Type() {
+		case logsReceiverType:
+			field.Set(reflect.ValueOf(s.logs))
+			found = found || c.format == FormatLoki
+		case appendableType:
+			field.Set(reflect.ValueOf(storage.Appendable(c)))
+			found = found || c.format == FormatPrometheus
+		case consumerType:
+			field.Set(reflect.ValueOf(otelcol.Consumer(c)))
+			found = found || c.format == FormatOTLPLogs || c.format == FormatOTLPMetrics || c.format == FormatOTLPTraces
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("the exports of the component have no receiver for output format %q", c.format)
+	}
+
+	opts.OnStateChange(rv.Interface())
+	return s, nil
+}
+
+// Run implements component.Component.
+func (s *sinkComponent) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case entry := <-s.logs.Chan():
+			s.c.appendEntry(entry)
+		}
+	}
+}
+
+// Update implements component.Component.
+func (s *sinkComponent) Update(component.Arguments) error { return nil }
+
+// capture records the data received by a sink component.
+type capture struct {
+	format Format
+
+	mut  sync.Mutex
+	data data
+}
+
+var (
+	_ storage.Appendable = (*capture)(nil)
+	_ otelcol.Consumer   = (*capture)(nil)
+)
+
+func newCapture(format Format) *capture {
+	return &capture{
+		format: format,
+		data: data{
+			logs:    plog.NewLogs(),
+			metrics: pmetric.NewMetrics(),
+			traces:  ptrace.NewTraces(),
+		},
+	}
+}
+
+func (c *capture) appendEntry(entry loki.Entry) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.data.entries = append(c.data.entries, entry)
+}
+
+// count returns the number of captured entries, samples, log records, data
+// points or spans.
+func (c *capture) count() int {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	switch c.format {
+	case FormatLoki:
+		return len(c.data.entries)
+	case FormatPrometheus:
+		return len(c.data.samples)
+	case FormatOTLPLogs:
+		return c.data.logs.LogRecordCount()
+	case FormatOTLPMetrics:
+		return c.data.metrics.DataPointCount()
+	case FormatOTLPTraces:
+		return c.data.traces.SpanCount()
+	}
+	return 0
+}
+
+// encode encodes the captured data in the format of the capture.
+func (c *capture) encode() ([]byte, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return encode(c.format, &c.data)
+}
+
+// Appender implements storage.Appendable.
+func (c *capture) Appender(context.Context) storage.Appender {
+	return &captureAppender{c: c}
+}
+
+// Capabilities implements otelconsumer.baseConsumer.
+func (c *capture) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{MutatesData: false}
+}
+
+// ConsumeLogs implements otelconsumer.Logs.
+func (c *capture) ConsumeLogs(_ context.Context, ld plog.Logs) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		ld.ResourceLogs().At(i).CopyTo(c.data.logs.ResourceLogs().AppendEmpty())
+	}
+	return nil
+}
+
+// ConsumeMetrics implements otelconsumer.Metrics.
+func (c *capture) ConsumeMetrics(_ context.Context, md pmetric.Metrics) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		md.ResourceMetrics().At(i).CopyTo(c.data.metrics.ResourceMetrics().AppendEmpty())
+	}
+	return nil
+}
+
+// ConsumeTraces implements otelconsumer.Traces.
+func (c *capture) ConsumeTraces(_ context.Context, td ptrace.Traces) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	for i := 0; i < td.ResourceSpans().Len(); i++ {
+		td.ResourceSpans().At(i).CopyTo(c.data.traces.ResourceSpans().AppendEmpty())
+	}
+	return nil
+}
+
+// captureAppender records samples in a capture when committed.
+type captureAppender struct {
+	c       *capture
+	samples []sample
+}
+
+var _ storage.Appender = (*captureAppender)(nil)
+
+func (a *captureAppender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	a.samples = append(a.samples, sample{labels: l, t: t, v: v})
+	return ref, nil
+}
+
+func (a *captureAppender) Commit() error {
+	a.c.mut.Lock()
+	defer a.c.mut.Unlock()
+	a.c.data.samples = append(a.c.data.samples, a.samples...)
+	a.samples = nil
+	return nil
+}
+
+func (a *captureAppender) Rollback() error {
+	a.samples = nil
+	return nil
+}
+
+func (a *captureAppender) AppendExemplar(ref storage.SeriesRef, _ labels.Labels, _ exemplar.Exemplar) (storage.SeriesRef, error) {
+	return ref, nil
+}
+
+func (a *captureAppender) AppendHistogram(ref storage.SeriesRef, _ labels.Labels, _ int64, _ *histogram.Histogram, _ *histogram.FloatHistogram) (storage.SeriesRef, error) {
+	return ref, nil
+}
+
+func (a *captureAppender) UpdateMetadata(ref storage.SeriesRef, _ labels.Labels, _ metadata.Metadata) (storage.SeriesRef, error) {
+	return ref, nil
+}
+
+func (a *captureAppender) AppendCTZeroSample(ref storage.SeriesRef, _ labels.Labels, _, _ int64) (storage.SeriesRef, error) {
+	return ref, nil
+}
@@ -0,0 +1,303 @@
+package pipelinetest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/textparse"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+)
+
+// Format is the format of a fixture file.
+type Format string
+
+// Supported formats.
+const (
+	// FormatLoki is a log entry per line, encoded as a JSON object with the
+	// timestamp, labels, line and structured_metadata fields. Entries
+	// without a timestamp have the Unix epoch as their timestamp.
+	FormatLoki Format = "loki"
+
+	// FormatPrometheus is a sample per line in the Prometheus text exposition
+	// format. Samples without a timestamp have a timestamp of 0. Samples are
+	// written sorted, since pipelines don't keep the order of samples.
+	FormatPrometheus Format = "prometheus"
+
+	// FormatOTLPLogs, FormatOTLPMetrics and FormatOTLPTraces are the JSON
+	// encoding of OTLP requests.
+	FormatOTLPLogs    Format = "otlp_logs"
+	FormatOTLPMetrics Format = "otlp_metrics"
+	FormatOTLPTraces  Format = "otlp_traces"
+)
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (f *Format) UnmarshalText(text []byte) error {
+	switch v := Format(text); v {
+	case FormatLoki, FormatPrometheus, FormatOTLPLogs, FormatOTLPMetrics, FormatOTLPTraces:
+		*f = v
+		return nil
+	}
+	return fmt.Errorf("unrecognized format %q, expected one of %q, %q, %q, %q or %q",
+		string(text), FormatLoki, FormatPrometheus, FormatOTLPLogs, FormatOTLPMetrics, FormatOTLPTraces)
+}
+
+// data holds the data of a fixture. Only the field of its format is set.
+type data struct {
+	entries []loki.Entry
+	samples []sample
+	logs    plog.Logs
+	metrics pmetric.Metrics
+	traces  ptrace.Traces
+}
+
+type sample struct {
+	labels labels.Labels
+	t      int64
+	v      float64
+}
+
+// lokiEntry is the JSON encoding of a log entry.
+type lokiEntry struct {
+	Timestamp          time.Time         `json:"timestamp"`
+	Labels             map[string]string `json:"labels"`
+	Line               string            `json:"line"`
+	StructuredMetadata map[string]string `json:"structured_metadata,omitempty"`
+}
+
+// decode decodes fixture data of format f.
+func decode(f Format, bb []byte) (*data, error) {
+	var (
+		d   = &data{}
+		err error
+	)
+	switch f {
+	case FormatLoki:
+		d.entries, err = decodeLoki(bb)
+	case FormatPrometheus:
+		d.samples, err = decodePrometheus(bb)
+	case FormatOTLPLogs:
+		d.logs, err = (&plog.JSONUnmarshaler{}).UnmarshalLogs(bb)
+	case FormatOTLPMetrics:
+		d.metrics, err = (&pmetric.JSONUnmarshaler{}).UnmarshalMetrics(bb)
+	case FormatOTLPTraces:
+		d.traces, err = (&ptrace.JSONUnmarshaler{}).UnmarshalTraces(bb)
+	default:
+		err = fmt.Errorf("unsupported format %q", f)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func decodeLoki(bb []byte) ([]loki.Entry, error) {
+	var entries []loki.Entry
+
+	scanner := bufio.NewScanner(bytes.NewReader(bb))
+	scanner.Buffer(nil, math.MaxInt32)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var e lokiEntry
+		dec := json.NewDecoder(strings.NewReader(text))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if e.Timestamp.IsZero() {
+			e.Timestamp = time.Unix(0, 0)
+		}
+
+		entry := loki.Entry{
+			Labels: make(model.LabelSet, len(e.Labels)),
+			Entry: logproto.Entry{
+				Timestamp: e.Timestamp,
+				Line:      e.Line,
+			},
+		}
+		for name, value := range e.Labels {
+			entry.Labels[model.LabelName(name)] = model.LabelValue(value)
+		}
+		for _, name := range sortedKeys(e.StructuredMetadata) {
+			entry.StructuredMetadata = append(entry.StructuredMetadata, logproto.LabelAdapter{
+				Name:  name,
+				Value: e.StructuredMetadata[name],
+			})
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func decodePrometheus(bb []byte) ([]sample, error) {
+	var samples []sample
+
+	p := textparse.NewPromParser(bb, labels.NewSymbolTable())
+	for {
+		entry, err := p.Next()
+		if errors.Is(err, io.EOF) {
+			return samples, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch entry {
+		case textparse.EntrySeries:
+			var s sample
+			_, ts, v := p.Series()
+			if ts != nil {
+				s.t = *ts
+			}
+			s.v = v
+			p.Metric(&s.labels)
+			samples = append(samples, s)
+		case textparse.EntryHistogram:
+			return nil, fmt.Errorf("native histograms are not supported")
+		}
+	}
+}
+
+// encode encodes fixture data in format f. Data decoded from equivalent files
+// are encoded the same way, so encoded data can be compared.
+func encode(f Format, d *data) ([]byte, error) {
+	switch f {
+	case FormatLoki:
+		return encodeLoki(d.entries)
+	case FormatPrometheus:
+		return encodePrometheus(d.samples), nil
+	case FormatOTLPLogs:
+		return indentJSON((&plog.JSONMarshaler{}).MarshalLogs(d.logs))
+	case FormatOTLPMetrics:
+		return indentJSON((&pmetric.JSONMarshaler{}).MarshalMetrics(d.metrics))
+	case FormatOTLPTraces:
+		return indentJSON((&ptrace.JSONMarshaler{}).MarshalTraces(d.traces))
+	}
+	return nil, fmt.Errorf("unsupported format %q", f)
+}
+
+func encodeLoki(entries []loki.Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	for _, entry := range entries {
+		e := lokiEntry{
+			Timestamp: entry.Timestamp.UTC(),
+			Labels:    make(map[string]string, len(entry.Labels)),
+			Line:      entry.Line,
+		}
+		for name, value := range entry.Labels {
+			e.Labels[string(name)] = string(value)
+		}
+		if len(entry.StructuredMetadata) > 0 {
+			e.StructuredMetadata = make(map[string]string, len(entry.StructuredMetadata))
+			for _, l := range entry.StructuredMetadata {
+				e.StructuredMetadata[l.Name] = l.Value
+			}
+		}
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func encodePrometheus(samples []sample) []byte {
+	lines := make([]string, 0, len(samples))
+	for _, s := range samples {
+		var sb strings.Builder
+		sb.WriteString(s.labels.Get(labels.MetricName))
+
+		first := true
+		s.labels.Range(func(l labels.Label) {
+			if l.Name == labels.MetricName {
+				return
+			}
+			if first {
+				sb.WriteString("{")
+				first = false
+			} else {
+				sb.WriteString(",")
+			}
+			sb.WriteString(l.Name + `="` + escapeLabelValue(l.Value) + `"`)
+		})
+		if !first {
+			sb.WriteString("}")
+		}
+
+		sb.WriteString(" " + strconv.FormatFloat(s.v, 'g', -1, 64))
+		sb.WriteString(" " + strconv.FormatInt(s.t, 10))
+		lines = append(lines, sb.String())
+	}
+	sort.Strings(lines)
+
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line + "\n")
+	}
+	return buf.Bytes()
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// escapeLabelValue escapes a label value for the text exposition format.
+func escapeLabelValue(v string) string {
+	return labelValueReplacer.Replace(v)
+}
+
+func indentJSON(bb []byte, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bb, "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// diffOutputs returns a diff of the expected and actual encoded outputs, or
+// an empty string if they're equal.
+func diffOutputs(expected, actual []byte) string {
+	if bytes.Equal(expected, actual) {
+		return ""
+	}
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expected)),
+		B:        difflib.SplitLines(string(actual)),
+		FromFile: "Expected",
+		ToFile:   "Actual",
+		Context:  1,
+	})
+	return diff
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
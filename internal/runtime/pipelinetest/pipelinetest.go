@@ -0,0 +1,385 @@
+// Package pipelinetest runs tests against Alloy pipelines.
+//
+// A test replaces source components of a pipeline with components which send
+// the data of fixture files, and captures the data which reaches sink
+// components to compare it against expected files. Replaced components keep
+// their arguments, so the rest of the pipeline is loaded and run unchanged.
+package pipelinetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/alloy/internal/featuregate"
+	alloy_runtime "github.com/grafana/alloy/internal/runtime"
+	"github.com/grafana/alloy/internal/runtime/logging"
+	"github.com/grafana/alloy/internal/service"
+	"github.com/grafana/alloy/syntax"
+)
+
+// TestsDir is the name of the directory of a pipeline which holds its tests.
+const TestsDir = "tests"
+
+// DefaultTimeout is the default time a test waits for the outputs of the
+// pipeline to match the expected files.
+const DefaultTimeout = 5 * time.Second
+
+// settleTime is how long outputs must remain unchanged before they're
+// written in update mode.
+const settleTime = time.Second
+
+// Suite is a pipeline and its tests.
+type Suite struct {
+	Sources map[string][]byte // Config files of the pipeline.
+	Tests   []*Test
+}
+
+// Test replaces the inputs of a pipeline with fixture files and compares the
+// outputs of the pipeline with expected files.
+type Test struct {
+	Name    string
+	Timeout time.Duration
+	Inputs  []Fixture
+	Outputs []Fixture
+}
+
+// Fixture associates a component of the pipeline with a file.
+type Fixture struct {
+	Component string // ID of the component, such as loki.source.file.default.
+	Format    Format // Format of the file.
+	Path      string // Path of the file.
+}
+
+// testFile is the body of a test file.
+type testFile struct {
+	Timeout time.Duration  `alloy:"timeout,attr,optional"`
+	Inputs  []fixtureBlock `alloy:"input,block"`
+	Outputs []fixtureBlock `alloy:"output,block"`
+}
+
+type fixtureBlock struct {
+	Component string `alloy:"component,attr"`
+	Format    Format `alloy:"format,attr"`
+	Path      string `alloy:"path,attr"`
+}
+
+var _ syntax.Defaulter = (*testFile)(nil)
+var _ syntax.Validator = (*testFile)(nil)
+
+// SetToDefault implements syntax.Defaulter.
+func (f *testFile) SetToDefault() {
+	*f = testFile{Timeout: DefaultTimeout}
+}
+
+// Validate implements syntax.Validator.
+func (f *testFile) Validate() error {
+	if f.Timeout <= 0 {
+		return fmt.Errorf("timeout must be greater than 0")
+	}
+	if len(f.Inputs) == 0 {
+		return fmt.Errorf("at least one input block must be set")
+	}
+	if len(f.Outputs) == 0 {
+		return fmt.Errorf("at least one output block must be set")
+	}
+
+	seen := make(map[string]struct{})
+	for _, b := range append(f.Inputs, f.Outputs...) {
+		if _, ok := seen[b.Component]; ok {
+			return fmt.Errorf("component %q is used by more than one input or output block", b.Component)
+		}
+		seen[b.Component] = struct{}{}
+	}
+	return nil
+}
+
+// Load loads the pipeline in dir and its tests. The config files of the
+// pipeline are the files of dir with a .alloy extension. Every file with a
+// .alloy extension in the tests subdirectory of dir is a test, named after
+// the file. The paths of the fixtures of a test are relative to the tests
+// directory.
+func Load(dir string) (*Suite, error) {
+	sources, err := readAlloyFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no config files found in %s", dir)
+	}
+
+	testsDir := filepath.Join(dir, TestsDir)
+	files, err := readAlloyFiles(testsDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no tests found in %s", testsDir)
+	}
+
+	s := &Suite{Sources: sources}
+	for path, bb := range files {
+		var f testFile
+		if err := syntax.Unmarshal(bb, &f); err != nil {
+			return nil, fmt.Errorf("loading test %s: %w", path, err)
+		}
+		s.Tests = append(s.Tests, &Test{
+			Name:    strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			Timeout: f.Timeout,
+			Inputs:  fixtures(testsDir, f.Inputs),
+			Outputs: fixtures(testsDir, f.Outputs),
+		})
+	}
+	sort.Slice(s.Tests, func(i, j int) bool { return s.Tests[i].Name < s.Tests[j].Name })
+	return s, nil
+}
+
+// readAlloyFiles reads the files with a .alloy extension in dir, keyed by
+// path.
+func readAlloyFiles(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".alloy" {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		bb, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = bb
+	}
+	return files, nil
+}
+
+func fixtures(dir string, blocks []fixtureBlock) []Fixture {
+	res := make([]Fixture, 0, len(blocks))
+	for _, b := range blocks {
+		path := b.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		res = append(res, Fixture{Component: b.Component, Format: b.Format, Path: path})
+	}
+	return res
+}
+
+// Options configure how tests are run.
+type Options struct {
+	// Logger for the controller running the pipeline. Logs are discarded if
+	// Logger is nil.
+	Logger *logging.Logger
+
+	// MinStability is the minimum stability level of the components of the
+	// pipeline.
+	MinStability featuregate.Stability
+
+	// EnableCommunityComps enables the use of community components.
+	EnableCommunityComps bool
+
+	// Services returns the services of the controller running the pipeline.
+	// It's called for every test, since services can't be shared between
+	// controllers. dataPath is the data path of the controller.
+	Services func(dataPath string) ([]service.Service, error)
+
+	// Update makes tests write the outputs of the pipeline to the expected
+	// files instead of comparing them. Outputs are written once they stop
+	// changing, or once the test times out.
+	Update bool
+}
+
+// Run runs a test of the suite. An error is returned if the pipeline can't be
+// run or if its outputs don't match the expected files.
+func (s *Suite) Run(ctx context.Context, t *Test, opts Options) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	reg := &registry{
+		inner:   alloy_runtime.NewDefaultComponentRegistry(opts.MinStability, opts.EnableCommunityComps),
+		sources: make(map[string]*fixtureSource),
+		sinks:   make(map[string]*capture),
+	}
+	for _, in := range t.Inputs {
+		bb, err := os.ReadFile(in.Path)
+		if err != nil {
+			return fmt.Errorf("input %s: %w", in.Component, err)
+		}
+		d, err := decode(in.Format, bb)
+		if err != nil {
+			return fmt.Errorf("input %s: decoding %s: %w", in.Component, in.Path, err)
+		}
+		reg.sources[in.Component] = &fixtureSource{format: in.Format, data: d}
+	}
+
+	expected := make(map[string][]byte, len(t.Outputs))
+	for _, out := range t.Outputs {
+		reg.sinks[out.Component] = newCapture(out.Format)
+		if opts.Update {
+			continue
+		}
+
+		bb, err := os.ReadFile(out.Path)
+		if err != nil {
+			return fmt.Errorf("output %s: %w", out.Component, err)
+		}
+		d, err := decode(out.Format, bb)
+		if err != nil {
+			return fmt.Errorf("output %s: decoding %s: %w", out.Component, out.Path, err)
+		}
+		expected[out.Component], err = encode(out.Format, d)
+		if err != nil {
+			return fmt.Errorf("output %s: %w", out.Component, err)
+		}
+	}
+
+	source, err := alloy_runtime.ParseSources(s.Sources)
+	if err != nil {
+		return err
+	}
+
+	dataPath, err := os.MkdirTemp("", "alloy-test")
+	if err != nil {
+		return fmt.Errorf("creating data path: %w", err)
+	}
+	defer os.RemoveAll(dataPath)
+
+	l := opts.Logger
+	if l == nil {
+		l, err = logging.New(io.Discard, logging.DefaultOptions)
+		if err != nil {
+			return fmt.Errorf("building logger: %w", err)
+		}
+	}
+
+	var services []service.Service
+	if opts.Services != nil {
+		services, err = opts.Services(dataPath)
+		if err != nil {
+			return fmt.Errorf("building services: %w", err)
+		}
+	}
+
+	f := alloy_runtime.New(alloy_runtime.Options{
+		Logger:               l,
+		DataPath:             dataPath,
+		Reg:                  prometheus.NewRegistry(),
+		MinStability:         opts.MinStability,
+		EnableCommunityComps: opts.EnableCommunityComps,
+		Services:             services,
+		ComponentRegistry:    reg,
+	})
+	if err := f.LoadSource(source, nil); err != nil {
+		return err
+	}
+	if err := reg.checkBuilt(); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		f.Run(ctx)
+	}()
+
+	if opts.Update {
+		return reg.update(ctx, t)
+	}
+	return reg.compare(ctx, t, expected)
+}
+
+// compare waits for the outputs of t to match expected, keyed by component
+// ID. The mismatch is returned if they don't match once t times out.
+func (r *registry) compare(ctx context.Context, t *Test, expected map[string][]byte) error {
+	check := func() error {
+		var errs []error
+		for _, out := range t.Outputs {
+			actual, err := r.sinks[out.Component].encode()
+			if err != nil {
+				return fmt.Errorf("output %s: %w", out.Component, err)
+			}
+			if diff := diffOutputs(expected[out.Component], actual); diff != "" {
+				errs = append(errs, fmt.Errorf("output %s does not match %s:\n\n%s", out.Component, out.Path, diff))
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(t.Timeout)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout:
+			return check()
+		case <-ticker.C:
+			if check() == nil {
+				return nil
+			}
+		}
+	}
+}
+
+// update waits for the outputs of t to settle and writes them to the
+// expected files.
+func (r *registry) update(ctx context.Context, t *Test) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(t.Timeout)
+
+	var (
+		lastCount   = -1
+		lastChanged = time.Now()
+	)
+
+Wait:
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout:
+			break Wait
+		case <-ticker.C:
+		}
+
+		var count int
+		for _, c := range r.sinks {
+			count += c.count()
+		}
+		if count != lastCount {
+			lastCount, lastChanged = count, time.Now()
+		} else if count > 0 && time.Since(lastChanged) >= settleTime {
+			break Wait
+		}
+	}
+
+	for _, out := range t.Outputs {
+		bb, err := r.sinks[out.Component].encode()
+		if err != nil {
+			return fmt.Errorf("output %s: %w", out.Component, err)
+		}
+		if err := os.WriteFile(out.Path, bb, 0o644); err != nil {
+			return fmt.Errorf("output %s: %w", out.Component, err)
+		}
+	}
+	return nil
+}
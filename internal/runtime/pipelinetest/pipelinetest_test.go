@@ -0,0 +1,105 @@
+package pipelinetest_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/grafana/alloy/internal/component/all"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/runtime/pipelinetest"
+	"github.com/grafana/alloy/internal/service"
+	"github.com/grafana/alloy/internal/service/labelstore"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+)
+
+func TestRun(t *testing.T) {
+	for _, dir := range []string{"logs", "metrics", "traces"} {
+		t.Run(dir, func(t *testing.T) {
+			s, err := pipelinetest.Load(filepath.Join("testdata", dir))
+			require.NoError(t, err)
+			require.Len(t, s.Tests, 1)
+
+			require.NoError(t, s.Run(context.Background(), s.Tests[0], testOptions()))
+		})
+	}
+}
+
+func TestRun_Mismatch(t *testing.T) {
+	s, err := pipelinetest.Load(filepath.Join("testdata", "metrics"))
+	require.NoError(t, err)
+
+	test := s.Tests[0]
+	test.Timeout = 500 * time.Millisecond
+	test.Outputs[0].Path = filepath.Join(t.TempDir(), "expected.prom")
+	require.NoError(t, os.WriteFile(test.Outputs[0].Path, []byte(`up{host="localhost",instance="localhost:12345",job="alloy"} 0 1714557600000`), 0o644))
+
+	err = s.Run(context.Background(), test, testOptions())
+	require.ErrorContains(t, err, "output prometheus.remote_write.default does not match")
+	require.ErrorContains(t, err, `-up{host="localhost",instance="localhost:12345",job="alloy"} 0 1714557600000`)
+	require.ErrorContains(t, err, `+up{host="localhost",instance="localhost:12345",job="alloy"} 1 1714557600000`)
+}
+
+func TestRun_Update(t *testing.T) {
+	s, err := pipelinetest.Load(filepath.Join("testdata", "logs"))
+	require.NoError(t, err)
+
+	test := s.Tests[0]
+	expected, err := os.ReadFile(test.Outputs[0].Path)
+	require.NoError(t, err)
+	test.Outputs[0].Path = filepath.Join(t.TempDir(), "expected.jsonl")
+
+	opts := testOptions()
+	opts.Update = true
+	require.NoError(t, s.Run(context.Background(), test, opts))
+
+	actual, err := os.ReadFile(test.Outputs[0].Path)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(actual))
+}
+
+func TestRun_UnknownComponent(t *testing.T) {
+	s, err := pipelinetest.Load(filepath.Join("testdata", "logs"))
+	require.NoError(t, err)
+
+	test := s.Tests[0]
+	test.Inputs[0].Component = "loki.source.file.other"
+
+	err = s.Run(context.Background(), test, testOptions())
+	require.EqualError(t, err, `component "loki.source.file.other" not found in the pipeline`)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.alloy"), []byte(`logging {}`), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, pipelinetest.TestsDir), 0o755))
+
+	testFile := filepath.Join(dir, pipelinetest.TestsDir, "invalid.alloy")
+	require.NoError(t, os.WriteFile(testFile, []byte(`
+		input {
+			component = "loki.source.file.app"
+			format    = "syslog"
+			path      = "input.log"
+		}
+	`), 0o644))
+
+	_, err := pipelinetest.Load(dir)
+	require.ErrorContains(t, err, `unrecognized format "syslog"`)
+}
+
+func testOptions() pipelinetest.Options {
+	return pipelinetest.Options{
+		MinStability: featuregate.StabilityGenerallyAvailable,
+		Services: func(string) ([]service.Service, error) {
+			return []service.Service{
+				labelstore.New(nil, prometheus.NewRegistry()),
+				livedebugging.New(),
+			}, nil
+		},
+	}
+}
@@ -19,9 +19,10 @@ type Source struct {
 
 	// Components holds the list of raw Alloy AST blocks describing components.
 	// The Alloy controller can interpret them.
-	components    []*ast.BlockStmt
-	configBlocks  []*ast.BlockStmt
-	declareBlocks []*ast.BlockStmt
+	components     []*ast.BlockStmt
+	configBlocks   []*ast.BlockStmt
+	declareBlocks  []*ast.BlockStmt
+	functionBlocks []*ast.BlockStmt
 }
 
 // ParseSource parses the Alloy file specified by bb into a File. name should be
@@ -58,6 +59,7 @@ func sourceFromBody(body ast.Body) (*Source, error) {
 		components []*ast.BlockStmt
 		configs    []*ast.BlockStmt
 		declares   []*ast.BlockStmt
+		functions  []*ast.BlockStmt
 	)
 
 	for _, stmt := range body {
@@ -75,6 +77,8 @@ func sourceFromBody(body ast.Body) (*Source, error) {
 			switch fullName {
 			case "declare":
 				declares = append(declares, stmt)
+			case "function":
+				functions = append(functions, stmt)
 			case "logging", "tracing", "argument", "export", "import.file", "import.string", "import.http", "import.git":
 				configs = append(configs, stmt)
 			default:
@@ -92,9 +96,10 @@ func sourceFromBody(body ast.Body) (*Source, error) {
 	}
 
 	return &Source{
-		components:    components,
-		configBlocks:  configs,
-		declareBlocks: declares,
+		components:     components,
+		configBlocks:   configs,
+		declareBlocks:  declares,
+		functionBlocks: functions,
 	}, nil
 }
 
@@ -135,6 +140,7 @@ func ParseSources(sources map[string][]byte) (*Source, error) {
 		mergedSource.components = append(mergedSource.components, sourceFragment.components...)
 		mergedSource.configBlocks = append(mergedSource.configBlocks, sourceFragment.configBlocks...)
 		mergedSource.declareBlocks = append(mergedSource.declareBlocks, sourceFragment.declareBlocks...)
+		mergedSource.functionBlocks = append(mergedSource.functionBlocks, sourceFragment.functionBlocks...)
 	}
 
 	mergedSource.hash = [32]byte(hash.Sum(nil))
@@ -3,8 +3,10 @@
 package cluster
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net"
@@ -29,6 +31,7 @@ import (
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/internal/service"
+	"github.com/grafana/alloy/internal/service/cluster/crdt"
 	"github.com/grafana/alloy/internal/service/cluster/discovery"
 	http_service "github.com/grafana/alloy/internal/service/http"
 	"github.com/grafana/alloy/internal/util"
@@ -60,6 +63,26 @@ const (
 	// This allows to rate limit the number of updates when the cluster is frequently changing (e.g. during rollout).
 	// This is only used when Options.EnableStateUpdatesLimiter is set to true.
 	stateUpdateMinInterval = time.Second
+
+	// leaderKeyPrefix is prepended to the name of an election to build the key
+	// whose owner in the hash ring is the leader of the election.
+	leaderKeyPrefix = "alloy/leader/"
+
+	// stateGossipInterval is how frequently the shared state is exchanged with
+	// other peers, and stateGossipFanout is the number of peers it's exchanged
+	// with every time. State changes reach all peers of a cluster of N nodes
+	// after roughly log(N) intervals.
+	stateGossipInterval = time.Second
+	stateGossipFanout   = 3
+
+	// statePruneInterval is how frequently the expired entries of the shared
+	// state are removed.
+	statePruneInterval = 10 * time.Second
+
+	// stateEndpoint is the HTTP endpoint where peers exchange their shared
+	// state, and maxStateSize is the maximum size of the state they accept.
+	stateEndpoint = "/api/v1/cluster/state"
+	maxStateSize  = 32 << 20
 )
 
 // Options are used to configure the cluster service. Options are constant for
@@ -95,11 +118,16 @@ type Service struct {
 	sharder shard.Sharder
 	node    *ckit.Node
 	randGen *rand.Rand
+	changes *changeNotifier
+
+	httpClient *http.Client
+	state      *crdt.Store
 }
 
 var (
-	_ service.Service             = (*Service)(nil)
-	_ http_service.ServiceHandler = (*Service)(nil)
+	_ service.Service                   = (*Service)(nil)
+	_ http_service.ServiceHandler       = (*Service)(nil)
+	_ http_service.ServiceRoutesHandler = (*Service)(nil)
 )
 
 // New returns a new, unstarted instance of the cluster service.
@@ -160,6 +188,10 @@ func New(opts Options) (*Service, error) {
 		sharder: ckitConfig.Sharder,
 		node:    node,
 		randGen: rand.New(rand.NewSource(time.Now().UnixNano())),
+		changes: newChangeNotifier(),
+
+		httpClient: httpClient,
+		state:      crdt.NewStore(opts.NodeName),
 	}, nil
 }
 
@@ -189,14 +221,29 @@ func (s *Service) ServiceHandler(host service.Host) (base string, handler http.H
 	base, handler = s.node.Handler()
 
 	if !s.opts.EnableClustering {
-		handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-			http.Error(w, "clustering is disabled", http.StatusNotFound)
-		})
+		handler = disabledHandler
 	}
 
 	return base, handler
 }
 
+// ServiceRoutes returns the handler of the endpoint where peers exchange
+// their shared state. The handler always returns 404 when clustering is
+// disabled.
+func (s *Service) ServiceRoutes(host service.Host) map[string]http.Handler {
+	var handler http.Handler = http.HandlerFunc(s.handleState)
+	if !s.opts.EnableClustering {
+		handler = disabledHandler
+	}
+	return map[string]http.Handler{stateEndpoint: handler}
+}
+
+// disabledHandler handles requests to the clustering endpoints when
+// clustering is disabled.
+var disabledHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	http.Error(w, "clustering is disabled", http.StatusNotFound)
+})
+
 // ChangeState changes the state of the service. If clustering is enabled,
 // ChangeState will block until the state change has been propagated to another
 // node; cancel the current context to stop waiting. ChangeState fails if the
@@ -248,6 +295,10 @@ func (s *Service) Run(ctx context.Context, host service.Host) error {
 		s.logPeers("peers changed", toStringSlice(peers))
 		span.SetAttributes(attribute.Int("peers_count", len(peers)))
 
+		// Wake up anything waiting on a leader election before notifying
+		// components, so singletons are moved as soon as possible.
+		s.changes.notify()
+
 		// Notify all components about the clustering change.
 		components := component.GetAllComponents(host, component.InfoOptions{})
 		for _, component := range components {
@@ -302,6 +353,21 @@ func (s *Service) Run(ctx context.Context, host service.Host) error {
 		}
 	}
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.pruneState(ctx)
+	}()
+
+	if s.opts.EnableClustering {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			s.gossipState(ctx)
+		}()
+	}
+
 	if s.opts.EnableClustering && s.opts.RejoinInterval > 0 {
 		wg.Add(1)
 
@@ -384,6 +450,110 @@ func (s *Service) stop() {
 	}
 }
 
+// pruneState periodically removes the expired entries of the shared state
+// until ctx is canceled.
+func (s *Service) pruneState(ctx context.Context) {
+	t := time.NewTicker(statePruneInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			s.state.Prune()
+		}
+	}
+}
+
+// gossipState periodically exchanges the shared state with random peers until
+// ctx is canceled.
+func (s *Service) gossipState(ctx context.Context) {
+	t := time.NewTicker(stateGossipInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		var peers []peer.Peer
+		for _, p := range s.node.Peers() {
+			if !p.Self {
+				peers = append(peers, p)
+			}
+		}
+		rand.Shuffle(len(peers), func(i, j int) {
+			peers[i], peers[j] = peers[j], peers[i]
+		})
+		if len(peers) > stateGossipFanout {
+			peers = peers[:stateGossipFanout]
+		}
+
+		for _, p := range peers {
+			if err := s.exchangeState(ctx, p); err != nil {
+				level.Debug(s.log).Log("msg", "failed to exchange shared state with peer", "peer", p.Name, "err", err)
+			}
+		}
+	}
+}
+
+// exchangeState sends the shared state to p and merges the state of p in the
+// response.
+func (s *Service) exchangeState(ctx context.Context, p peer.Peer) error {
+	bb, err := json.Marshal(s.state.Snapshot())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, stateGossipInterval)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+p.Addr+stateEndpoint, bytes.NewReader(bb))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var snap crdt.Snapshot
+	if err := json.NewDecoder(http.MaxBytesReader(nil, resp.Body, maxStateSize)).Decode(&snap); err != nil {
+		return fmt.Errorf("failed to decode shared state: %w", err)
+	}
+	s.state.Merge(snap)
+	return nil
+}
+
+// handleState merges the shared state sent by a peer and responds with the
+// local shared state.
+func (s *Service) handleState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var snap crdt.Snapshot
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxStateSize)).Decode(&snap); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode shared state: %s", err), http.StatusBadRequest)
+		return
+	}
+	s.state.Merge(snap)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.state.Snapshot())
+}
+
 // Update implements [service.Service]. It returns an error since the cluster
 // service does not support runtime configuration.
 func (s *Service) Update(newConfig any) error {
@@ -392,7 +562,11 @@ func (s *Service) Update(newConfig any) error {
 
 // Data returns an instance of [Cluster].
 func (s *Service) Data() any {
-	return &sharderCluster{sharder: s.sharder}
+	sc := &sharderCluster{sharder: s.sharder, changes: s.changes}
+	if s.opts.EnableClustering {
+		sc.state = s.state
+	}
+	return sc
 }
 
 func (s *Service) logPeers(msg string, peers []string) {
@@ -419,6 +593,10 @@ type Component interface {
 // ComponentBlock holds common arguments for clustering settings within a
 // component. ComponentBlock is intended to be exposed as a block called
 // "clustering".
+//
+// The clustering block of every component additionally accepts a singleton
+// attribute, which is handled by the component controller rather than by
+// ComponentBlock: singleton components only run on the leader of the cluster.
 type ComponentBlock struct {
 	Enabled bool `alloy:"enabled,attr"`
 }
@@ -436,11 +614,52 @@ type Cluster interface {
 
 	// Peers returns the current set of peers for a Node.
 	Peers() []peer.Peer
+
+	// Leader returns the peer elected as the leader of the election called
+	// name. The leader is the owner of a well-known key derived from name in
+	// the hash ring, so different elections are spread across the cluster.
+	//
+	// Only peers in the participant state can be elected. A leader leaving the
+	// cluster moves to the terminating state first, which hands leadership
+	// over to another peer before it stops. Peers joining the cluster only
+	// take over an election if they become the owner of its key.
+	//
+	// Leader returns an error if no peer can be elected.
+	Leader(name string) (peer.Peer, error)
+
+	// Changed returns a channel which is closed the next time the set of
+	// peers changes. Callers should call Leader again once the channel is
+	// closed to find out whether the leader of an election changed.
+	Changed() <-chan struct{}
+
+	// State returns the state shared by the peers of the cluster. Changes to
+	// the state are gossiped to other peers in the background, and are
+	// eventually visible to all of them. State returns nil when clustering is
+	// disabled.
+	State() *crdt.Store
+}
+
+// LeaderOf returns the leader of the election called name from the owners of
+// its key in the hash ring. It can be used to implement [Cluster.Leader] on
+// top of [Cluster.Lookup].
+func LeaderOf(c Cluster, name string) (peer.Peer, error) {
+	peers, err := c.Lookup(shard.StringKey(leaderKeyPrefix+name), 1, shard.OpReadWrite)
+	if err != nil {
+		return peer.Peer{}, fmt.Errorf("failed to elect leader for %s: %w", name, err)
+	}
+	if len(peers) != 1 {
+		return peer.Peer{}, fmt.Errorf("failed to elect leader for %s: no peer can be elected", name)
+	}
+	return peers[0], nil
 }
 
 // sharderCluster shims an implementation of [shard.Sharder] to [Cluster] which
 // removes the ability to change peers.
-type sharderCluster struct{ sharder shard.Sharder }
+type sharderCluster struct {
+	sharder shard.Sharder
+	changes *changeNotifier
+	state   *crdt.Store
+}
 
 var _ Cluster = (*sharderCluster)(nil)
 
@@ -452,6 +671,44 @@ func (sc *sharderCluster) Peers() []peer.Peer {
 	return sc.sharder.Peers()
 }
 
+func (sc *sharderCluster) Leader(name string) (peer.Peer, error) {
+	return LeaderOf(sc, name)
+}
+
+func (sc *sharderCluster) Changed() <-chan struct{} {
+	return sc.changes.wait()
+}
+
+func (sc *sharderCluster) State() *crdt.Store {
+	return sc.state
+}
+
+// changeNotifier broadcasts changes of the peers of the cluster by closing a
+// channel, which is replaced after every change.
+type changeNotifier struct {
+	mut sync.Mutex
+	ch  chan struct{}
+}
+
+func newChangeNotifier() *changeNotifier {
+	return &changeNotifier{ch: make(chan struct{})}
+}
+
+// wait returns a channel which is closed on the next call to notify.
+func (n *changeNotifier) wait() <-chan struct{} {
+	n.mut.Lock()
+	defer n.mut.Unlock()
+	return n.ch
+}
+
+// notify closes the channel returned by previous calls to wait.
+func (n *changeNotifier) notify() {
+	n.mut.Lock()
+	defer n.mut.Unlock()
+	close(n.ch)
+	n.ch = make(chan struct{})
+}
+
 func toStringSlice[T any](slice []T) []string {
 	s := make([]string, 0, len(slice))
 	for _, p := range slice {
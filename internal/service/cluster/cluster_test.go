@@ -1,12 +1,21 @@
 package cluster
 
 import (
+	"bytes"
+	"encoding/json"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
+	"github.com/grafana/ckit/peer"
+	"github.com/grafana/ckit/shard"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/service/cluster/crdt"
 )
 
 func mockDiscoverPeers(peers []string, err error) func() ([]string, error) {
@@ -59,3 +68,100 @@ func TestGetPeers(t *testing.T) {
 		})
 	}
 }
+
+func TestLeader(t *testing.T) {
+	sharder := shard.Ring(tokensPerNode)
+	sc := &sharderCluster{sharder: sharder, changes: newChangeNotifier()}
+
+	_, err := sc.Leader("test")
+	require.ErrorContains(t, err, "failed to elect leader for test")
+
+	peers := []peer.Peer{
+		{Name: "a", State: peer.StateParticipant, Self: true},
+		{Name: "b", State: peer.StateParticipant},
+		{Name: "c", State: peer.StateParticipant},
+	}
+	sharder.SetPeers(peers)
+
+	leader, err := sc.Leader("test")
+	require.NoError(t, err)
+
+	// The leader is stable for the same set of peers.
+	for i := 0; i < 10; i++ {
+		again, err := sc.Leader("test")
+		require.NoError(t, err)
+		require.Equal(t, leader.Name, again.Name)
+	}
+
+	// A terminating leader hands leadership over to another peer.
+	for i := range peers {
+		if peers[i].Name == leader.Name {
+			peers[i].State = peer.StateTerminating
+		}
+	}
+	sharder.SetPeers(peers)
+
+	next, err := sc.Leader("test")
+	require.NoError(t, err)
+	require.NotEqual(t, leader.Name, next.Name)
+}
+
+func TestChangeNotifier(t *testing.T) {
+	n := newChangeNotifier()
+
+	changed := n.wait()
+	select {
+	case <-changed:
+		require.FailNow(t, "channel closed before notify")
+	default:
+	}
+
+	n.notify()
+	<-changed
+
+	select {
+	case <-n.wait():
+		require.FailNow(t, "new channel closed before notify")
+	default:
+	}
+}
+
+func TestHandleState(t *testing.T) {
+	s := &Service{state: crdt.NewStore("local")}
+	s.state.Counter("c").Add(1, time.Minute)
+
+	remote := crdt.NewStore("remote")
+	remote.Counter("c").Add(2, time.Minute)
+	remote.Map("m").Set("k", "v", time.Minute)
+
+	bb, err := json.Marshal(remote.Snapshot())
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	s.handleState(rec, httptest.NewRequest(http.MethodPost, stateEndpoint, bytes.NewReader(bb)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// The remote state is merged into the local state.
+	require.Equal(t, uint64(3), s.state.Counter("c").Value())
+	v, ok := s.state.Map("m").Get("k")
+	require.True(t, ok)
+	require.Equal(t, "v", v)
+
+	// The response holds the local state for the remote to merge.
+	var snap crdt.Snapshot
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&snap))
+	remote.Merge(snap)
+	require.Equal(t, uint64(3), remote.Counter("c").Value())
+
+	rec = httptest.NewRecorder()
+	s.handleState(rec, httptest.NewRequest(http.MethodGet, stateEndpoint, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestState_Disabled(t *testing.T) {
+	s := &Service{state: crdt.NewStore("local")}
+	require.Nil(t, s.Data().(Cluster).State())
+
+	s.opts.EnableClustering = true
+	require.Equal(t, s.state, s.Data().(Cluster).State())
+}
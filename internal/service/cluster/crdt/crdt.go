@@ -0,0 +1,334 @@
+// Package crdt implements conflict-free replicated data types which the
+// cluster service replicates between the peers of a cluster.
+//
+// Replicas converge by merging the snapshots of each other in any order. The
+// state is eventually consistent: a change made on a peer is only visible to
+// other peers once a snapshot including it has been gossiped to them.
+package crdt
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// tombstoneTTL is how long deleted map entries are kept so that deletions are
+// propagated to other peers.
+const tombstoneTTL = time.Minute
+
+// Store holds named counters and maps replicated between the peers of a
+// cluster. Names are shared by the whole cluster: components should prefix
+// them with their ID to avoid conflicts.
+type Store struct {
+	node string           // Name of the local peer.
+	now  func() time.Time // Clock used for timestamps and TTLs.
+
+	mut      sync.RWMutex
+	counters map[string]map[string]CounterSlot // Counter slots by counter name and peer.
+	maps     map[string]map[string]MapEntry    // Map entries by map name and key.
+}
+
+// NewStore returns an empty Store for the peer called node.
+func NewStore(node string) *Store {
+	return &Store{
+		node:     node,
+		now:      time.Now,
+		counters: make(map[string]map[string]CounterSlot),
+		maps:     make(map[string]map[string]MapEntry),
+	}
+}
+
+// CounterSlot is the part of a counter incremented by a single peer.
+//
+// A slot restarts from zero once it has expired. Start identifies the
+// generation of the slot, so that a restarted slot replaces the expired one
+// when merged rather than being added to it.
+type CounterSlot struct {
+	Start   int64  `json:"start"`   // Time the slot was started at, in Unix nanoseconds.
+	Count   uint64 `json:"count"`   // Sum of the increments of the slot.
+	Expires int64  `json:"expires"` // Time the slot expires at, in Unix nanoseconds.
+}
+
+// merge returns the slot which wins between s and other.
+func (s CounterSlot) merge(other CounterSlot) CounterSlot {
+	switch {
+	case other.Start > s.Start:
+		return other
+	case other.Start < s.Start:
+		return s
+	}
+	return CounterSlot{
+		Start:   s.Start,
+		Count:   max(s.Count, other.Count),
+		Expires: max(s.Expires, other.Expires),
+	}
+}
+
+// MapEntry is a value of a last-writer-wins map.
+type MapEntry struct {
+	Value     string `json:"value"`
+	Deleted   bool   `json:"deleted,omitempty"` // Whether the entry is a tombstone.
+	Timestamp int64  `json:"timestamp"`         // Time the entry was written at, in Unix nanoseconds.
+	Node      string `json:"node"`              // Peer which wrote the entry, used to break ties.
+	Expires   int64  `json:"expires"`           // Time the entry expires at, in Unix nanoseconds.
+}
+
+// newerThan returns whether e was written after other.
+func (e MapEntry) newerThan(other MapEntry) bool {
+	if e.Timestamp != other.Timestamp {
+		return e.Timestamp > other.Timestamp
+	}
+	return e.Node > other.Node
+}
+
+// Snapshot is the serializable state of a Store.
+type Snapshot struct {
+	Counters map[string]map[string]CounterSlot `json:"counters,omitempty"`
+	Maps     map[string]map[string]MapEntry    `json:"maps,omitempty"`
+}
+
+// Snapshot returns a copy of the entries of s which haven't expired.
+func (s *Store) Snapshot() Snapshot {
+	now := s.now().UnixNano()
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	snap := Snapshot{
+		Counters: make(map[string]map[string]CounterSlot, len(s.counters)),
+		Maps:     make(map[string]map[string]MapEntry, len(s.maps)),
+	}
+	for name, slots := range s.counters {
+		for node, slot := range slots {
+			if slot.Expires <= now {
+				continue
+			}
+			if snap.Counters[name] == nil {
+				snap.Counters[name] = make(map[string]CounterSlot, len(slots))
+			}
+			snap.Counters[name][node] = slot
+		}
+	}
+	for name, entries := range s.maps {
+		for key, entry := range entries {
+			if entry.Expires <= now {
+				continue
+			}
+			if snap.Maps[name] == nil {
+				snap.Maps[name] = make(map[string]MapEntry, len(entries))
+			}
+			snap.Maps[name][key] = entry
+		}
+	}
+	return snap
+}
+
+// Merge merges the state of another replica into s.
+func (s *Store) Merge(snap Snapshot) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for name, slots := range snap.Counters {
+		local := s.counters[name]
+		if local == nil {
+			local = make(map[string]CounterSlot, len(slots))
+			s.counters[name] = local
+		}
+		for node, slot := range slots {
+			if existing, ok := local[node]; ok {
+				slot = existing.merge(slot)
+			}
+			local[node] = slot
+		}
+	}
+	for name, entries := range snap.Maps {
+		local := s.maps[name]
+		if local == nil {
+			local = make(map[string]MapEntry, len(entries))
+			s.maps[name] = local
+		}
+		for key, entry := range entries {
+			if existing, ok := local[key]; ok && !entry.newerThan(existing) {
+				continue
+			}
+			local[key] = entry
+		}
+	}
+}
+
+// Prune removes the expired entries of s.
+func (s *Store) Prune() {
+	now := s.now().UnixNano()
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for name, slots := range s.counters {
+		for node, slot := range slots {
+			if slot.Expires <= now {
+				delete(slots, node)
+			}
+		}
+		if len(slots) == 0 {
+			delete(s.counters, name)
+		}
+	}
+	for name, entries := range s.maps {
+		for key, entry := range entries {
+			if entry.Expires <= now {
+				delete(entries, key)
+			}
+		}
+		if len(entries) == 0 {
+			delete(s.maps, name)
+		}
+	}
+}
+
+// Len returns the number of counters and maps of s, including the ones which
+// expired but weren't pruned yet.
+func (s *Store) Len() int {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	return len(s.counters) + len(s.maps)
+}
+
+// Counter returns the counter called name. Counters which were never
+// incremented have a value of 0.
+func (s *Store) Counter(name string) Counter {
+	return Counter{store: s, name: name}
+}
+
+// Counter is a grow-only counter. Every peer increments its own slot of the
+// counter, and the value of the counter is the sum of the slots which haven't
+// expired.
+type Counter struct {
+	store *Store
+	name  string
+}
+
+// Add increments the counter by delta. The slot of the local peer expires
+// after ttl, and restarts from zero if it's incremented again afterwards.
+func (c Counter) Add(delta uint64, ttl time.Duration) {
+	s := c.store
+	now := s.now()
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	slots := s.counters[c.name]
+	if slots == nil {
+		slots = make(map[string]CounterSlot)
+		s.counters[c.name] = slots
+	}
+
+	slot, ok := slots[s.node]
+	if !ok || slot.Expires <= now.UnixNano() {
+		slot = CounterSlot{Start: now.UnixNano()}
+	}
+	slot.Count += delta
+	slot.Expires = max(slot.Expires, now.Add(ttl).UnixNano())
+	slots[s.node] = slot
+}
+
+// Value returns the sum of the slots of the counter which haven't expired.
+func (c Counter) Value() uint64 {
+	s := c.store
+	now := s.now().UnixNano()
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	var sum uint64
+	for _, slot := range s.counters[c.name] {
+		if slot.Expires > now {
+			sum += slot.Count
+		}
+	}
+	return sum
+}
+
+// Map returns the last-writer-wins map called name.
+func (s *Store) Map(name string) Map {
+	return Map{store: s, name: name}
+}
+
+// Map is a last-writer-wins map of strings. Concurrent writes to the same key
+// are resolved by keeping the write with the latest timestamp.
+type Map struct {
+	store *Store
+	name  string
+}
+
+// Set sets key to value. The entry expires after ttl.
+func (m Map) Set(key, value string, ttl time.Duration) {
+	now := m.store.now()
+	m.write(key, MapEntry{
+		Value:     value,
+		Timestamp: now.UnixNano(),
+		Node:      m.store.node,
+		Expires:   now.Add(ttl).UnixNano(),
+	})
+}
+
+// Delete deletes key from the map.
+func (m Map) Delete(key string) {
+	now := m.store.now()
+	m.write(key, MapEntry{
+		Deleted:   true,
+		Timestamp: now.UnixNano(),
+		Node:      m.store.node,
+		Expires:   now.Add(tombstoneTTL).UnixNano(),
+	})
+}
+
+func (m Map) write(key string, entry MapEntry) {
+	s := m.store
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	entries := s.maps[m.name]
+	if entries == nil {
+		entries = make(map[string]MapEntry)
+		s.maps[m.name] = entries
+	}
+	if existing, ok := entries[key]; ok && existing.newerThan(entry) {
+		// Another peer wrote the key with a timestamp ahead of the local clock.
+		entry.Timestamp = existing.Timestamp + 1
+	}
+	entries[key] = entry
+}
+
+// Get returns the value of key, if it's set and hasn't expired.
+func (m Map) Get(key string) (string, bool) {
+	s := m.store
+	now := s.now().UnixNano()
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	entry, ok := s.maps[m.name][key]
+	if !ok || entry.Deleted || entry.Expires <= now {
+		return "", false
+	}
+	return entry.Value, true
+}
+
+// Keys returns the sorted keys of the map which are set and haven't expired.
+func (m Map) Keys() []string {
+	s := m.store
+	now := s.now().UnixNano()
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	var keys []string
+	for key, entry := range s.maps[m.name] {
+		if !entry.Deleted && entry.Expires > now {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,127 @@
+package crdt
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStores(nodes ...string) ([]*Store, *time.Time) {
+	now := time.Unix(1000, 0)
+	stores := make([]*Store, 0, len(nodes))
+	for _, node := range nodes {
+		s := NewStore(node)
+		s.now = func() time.Time { return now }
+		stores = append(stores, s)
+	}
+	return stores, &now
+}
+
+// syncStores merges the snapshots of all stores into each other.
+func syncStores(stores ...*Store) {
+	for _, from := range stores {
+		for _, to := range stores {
+			if from != to {
+				to.Merge(from.Snapshot())
+			}
+		}
+	}
+}
+
+func TestCounter(t *testing.T) {
+	stores, now := newTestStores("a", "b")
+	a, b := stores[0], stores[1]
+
+	a.Counter("c").Add(2, time.Minute)
+	b.Counter("c").Add(3, time.Minute)
+	require.Equal(t, uint64(2), a.Counter("c").Value())
+
+	syncStores(a, b)
+	require.Equal(t, uint64(5), a.Counter("c").Value())
+	require.Equal(t, uint64(5), b.Counter("c").Value())
+
+	// Merging again is idempotent.
+	syncStores(a, b)
+	require.Equal(t, uint64(5), a.Counter("c").Value())
+
+	// Expired slots restart from zero rather than adding to the old slot.
+	*now = now.Add(2 * time.Minute)
+	require.Equal(t, uint64(0), a.Counter("c").Value())
+	a.Counter("c").Add(1, time.Minute)
+	b.Merge(a.Snapshot())
+	require.Equal(t, uint64(1), b.Counter("c").Value())
+}
+
+func TestMap(t *testing.T) {
+	stores, now := newTestStores("a", "b")
+	a, b := stores[0], stores[1]
+
+	a.Map("m").Set("k", "from a", time.Minute)
+	*now = now.Add(time.Second)
+	b.Map("m").Set("k", "from b", time.Minute)
+	b.Map("m").Set("other", "value", time.Minute)
+
+	syncStores(a, b)
+	for _, s := range stores {
+		v, ok := s.Map("m").Get("k")
+		require.True(t, ok)
+		require.Equal(t, "from b", v)
+		require.Equal(t, []string{"k", "other"}, s.Map("m").Keys())
+	}
+
+	a.Map("m").Delete("other")
+	syncStores(a, b)
+	_, ok := b.Map("m").Get("other")
+	require.False(t, ok)
+
+	*now = now.Add(2 * time.Minute)
+	_, ok = a.Map("m").Get("k")
+	require.False(t, ok)
+}
+
+func TestMap_TimestampTie(t *testing.T) {
+	stores, _ := newTestStores("a", "b")
+	a, b := stores[0], stores[1]
+
+	a.Map("m").Set("k", "from a", time.Minute)
+	b.Map("m").Set("k", "from b", time.Minute)
+	syncStores(a, b)
+
+	va, _ := a.Map("m").Get("k")
+	vb, _ := b.Map("m").Get("k")
+	require.Equal(t, va, vb)
+}
+
+func TestPrune(t *testing.T) {
+	stores, now := newTestStores("a")
+	a := stores[0]
+
+	a.Counter("c").Add(1, time.Second)
+	a.Map("m").Set("k", "v", time.Second)
+
+	*now = now.Add(2 * time.Second)
+	a.Prune()
+	require.Empty(t, a.counters)
+	require.Empty(t, a.maps)
+}
+
+func TestSnapshot_JSON(t *testing.T) {
+	stores, _ := newTestStores("a", "b")
+	a, b := stores[0], stores[1]
+
+	a.Counter("c").Add(4, time.Minute)
+	a.Map("m").Set("k", "v", time.Minute)
+
+	bb, err := json.Marshal(a.Snapshot())
+	require.NoError(t, err)
+
+	var snap Snapshot
+	require.NoError(t, json.Unmarshal(bb, &snap))
+	b.Merge(snap)
+
+	require.Equal(t, uint64(4), b.Counter("c").Value())
+	v, _ := b.Map("m").Get("k")
+	require.Equal(t, "v", v)
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/grafana/ckit"
 	"github.com/grafana/ckit/peer"
 	"github.com/grafana/ckit/shard"
+
+	"github.com/grafana/alloy/internal/service/cluster/crdt"
 )
 
 // Mock returns a mock implementation of the Cluster interface.
@@ -31,6 +33,27 @@ func (mockCluster) Peers() []peer.Peer {
 	}}
 }
 
+func (mockCluster) Leader(string) (peer.Peer, error) {
+	return peer.Peer{
+		Name:  "self",
+		Addr:  "127.0.0.1",
+		Self:  true,
+		State: peer.StateParticipant,
+	}, nil
+}
+
+// Changed returns a nil channel, since the peers of the mock never change.
+func (mockCluster) Changed() <-chan struct{} {
+	return nil
+}
+
+// mockState is the state of mock clusters, which is local to the process.
+var mockState = crdt.NewStore("self")
+
+func (mockCluster) State() *crdt.Store {
+	return mockState
+}
+
 func (mockCluster) Observe(ckit.Observer) {
 	// no-op
 }
@@ -2,9 +2,12 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	_ "net/http/pprof" // Register pprof handlers
@@ -19,6 +22,7 @@ import (
 	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/featuregate"
 	alloy_runtime "github.com/grafana/alloy/internal/runtime"
+	"github.com/grafana/alloy/internal/runtime/componentprofile"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/internal/service"
 	"github.com/grafana/alloy/internal/static/server"
@@ -46,6 +50,11 @@ type Options struct {
 	ReadyFunc  func() bool
 	ReloadFunc func() (*alloy_runtime.Source, error)
 
+	// DryRunFunc reports how loading source would change the running
+	// components without applying it. If source is nil, the config source
+	// which ReloadFunc would load is used.
+	DryRunFunc func(source *alloy_runtime.Source) (*alloy_runtime.DryRunReport, error)
+
 	HTTPListenAddr   string // Address to listen for HTTP traffic on.
 	MemoryListenAddr string // Address to accept in-memory traffic on.
 	EnablePProf      bool   // Whether pprof endpoints should be exposed.
@@ -171,6 +180,7 @@ func (s *Service) Run(ctx context.Context, host service.Host) error {
 		promhttp.HandlerFor(s.gatherer, promhttp.HandlerOpts{}),
 	)
 	if s.opts.EnablePProf {
+		r.Handle("/debug/pprof/components", componentprofile.Handler())
 		r.PathPrefix("/debug/pprof").Handler(http.DefaultServeMux)
 	}
 
@@ -204,6 +214,10 @@ func (s *Service) Run(ctx context.Context, host service.Host) error {
 		}).Methods(http.MethodGet, http.MethodPost)
 	}
 
+	if s.opts.DryRunFunc != nil {
+		r.HandleFunc("/-/reload/dry-run", s.handleDryRun).Methods(http.MethodGet, http.MethodPost)
+	}
+
 	// Wire custom service handlers for services which depend on the http
 	// service.
 	//
@@ -259,12 +273,63 @@ func (s *Service) getServiceRoutes(host service.Host) []serviceRoute {
 			Base:    base,
 			Handler: handler,
 		})
+
+		if rh, ok := sh.(ServiceRoutesHandler); ok {
+			for base, handler := range rh.ServiceRoutes(host) {
+				routes = append(routes, serviceRoute{
+					Base:    base,
+					Handler: handler,
+				})
+			}
+		}
 	}
 
 	sort.Sort(routes)
 	return routes
 }
 
+// handleDryRun reports how reloading the config would change the running
+// components. The config sent in the request body is used instead of the
+// config which would be reloaded if the body isn't empty. The report is
+// written as text, or as JSON if the format query parameter is set to json.
+func (s *Service) handleDryRun(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "text" && format != "json" {
+		http.Error(w, fmt.Sprintf("unsupported format %q", format), http.StatusBadRequest)
+		return
+	}
+
+	bb, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var source *alloy_runtime.Source
+	if len(bytes.TrimSpace(bb)) > 0 {
+		source, err = alloy_runtime.ParseSource("request", bb)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := s.opts.DryRunFunc(source)
+	if err != nil {
+		level.Warn(s.log).Log("msg", "config dry run failed", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(report)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = report.WriteTo(w)
+}
+
 func (s *Service) componentHandler(host service.Host) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Trim the path prefix to get our full path.
@@ -414,6 +479,17 @@ type ServiceHandler interface {
 	ServiceHandler(host service.Host) (base string, handler http.Handler)
 }
 
+// ServiceRoutesHandler is a ServiceHandler which exposes HTTP handlers under
+// other base routes than the one returned by ServiceHandler.
+type ServiceRoutesHandler interface {
+	ServiceHandler
+
+	// ServiceRoutes returns the additional HTTP handlers to register for the
+	// provided service by base route. Base routes are prioritized the same
+	// way as those returned by ServiceHandler.
+	ServiceRoutes(host service.Host) map[string]http.Handler
+}
+
 // lazyListener is a [net.Listener] which lazily initializes the underlying
 // listener.
 type lazyListener struct {
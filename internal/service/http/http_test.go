@@ -3,7 +3,9 @@ package http
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/grafana/alloy/internal/component"
@@ -45,6 +47,45 @@ func TestHTTP(t *testing.T) {
 	})
 }
 
+func TestDryRun(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+
+	env, err := newTestEnvironment(t)
+	require.NoError(t, err)
+	require.NoError(t, env.ApplyConfig(`/* empty */`))
+
+	go func() {
+		require.NoError(t, env.Run(ctx))
+	}()
+
+	dryRun := func(t require.TestingT, method, query, body string) (int, string) {
+		req, err := http.NewRequest(method, fmt.Sprintf("http://%s/-/reload/dry-run%s", env.ListenAddr(), query), strings.NewReader(body))
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		bb, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(bb)
+	}
+
+	util.Eventually(t, func(t require.TestingT) {
+		status, body := dryRun(t, http.MethodGet, "", "")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "~ local.file.a\n0 added, 0 removed, 1 updated, 0 unchanged\n", body)
+	})
+
+	status, body := dryRun(t, http.MethodPost, "?format=json", `local.file "b" { filename = "/tmp/b" }`)
+	require.Equal(t, http.StatusOK, status)
+	require.JSONEq(t, `{"components": [{"id": "local.file.b", "change": "added"}]}`, body)
+
+	status, body = dryRun(t, http.MethodPost, "", `local.file "b" {`)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Contains(t, body, "expected }, got EOF")
+}
+
 func TestTLS(t *testing.T) {
 	ctx := componenttest.TestContext(t)
 
@@ -173,6 +214,18 @@ func newTestEnvironment(t *testing.T) (*testEnvironment, error) {
 
 		ReadyFunc:  func() bool { return true },
 		ReloadFunc: func() (*runtime.Source, error) { return nil, nil },
+		DryRunFunc: func(source *runtime.Source) (*runtime.DryRunReport, error) {
+			// Report the components of the source of the request as added,
+			// and the component of the current config as updated.
+			if source == nil {
+				return &runtime.DryRunReport{Components: []runtime.ComponentChange{
+					{ID: "local.file.a", Change: runtime.ChangeUpdated},
+				}}, nil
+			}
+			return &runtime.DryRunReport{Components: []runtime.ComponentChange{
+				{ID: "local.file.b", Change: runtime.ChangeAdded},
+			}}, nil
+		},
 
 		HTTPListenAddr:   fmt.Sprintf("127.0.0.1:%d", port),
 		MemoryListenAddr: "alloy.internal:12345",
@@ -0,0 +1,36 @@
+package livedebugging
+
+import (
+	"time"
+)
+
+// DataType is the type of signal of the data carried by an Event.
+type DataType string
+
+const (
+	DataTypeLogs    DataType = "logs"
+	DataTypeMetrics DataType = "metrics"
+	DataTypeTraces  DataType = "traces"
+)
+
+// Event is debugging data published by a component.
+type Event struct {
+	// ComponentID is the ID of the component which published the event. It's
+	// set by the live debugging service when the event is published.
+	ComponentID ComponentID `json:"component_id"`
+
+	Type      DataType  `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+
+	// Labels holds the labels of the data, or the attributes of the resource
+	// of OpenTelemetry data. Filters match against them.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Body is a human-readable representation of the data.
+	Body string `json:"body"`
+}
+
+// String returns the body of the event.
+func (e Event) String() string {
+	return e.Body
+}
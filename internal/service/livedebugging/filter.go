@@ -0,0 +1,80 @@
+package livedebugging
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"golang.org/x/time/rate"
+)
+
+// Filter selects the events which are sent to a live debugging consumer.
+type Filter struct {
+	// Matchers which the labels of events must all match. A label missing
+	// from an event matches like an empty label.
+	Matchers []*labels.Matcher
+
+	// SampleProb is the probability for an event to be kept, from 0 to 1.
+	SampleProb float64
+
+	// MaxEventsPerSecond limits the rate of events. Events above the rate are
+	// dropped. 0 disables the limit.
+	MaxEventsPerSecond float64
+}
+
+// DefaultFilter keeps every event.
+var DefaultFilter = Filter{SampleProb: 1}
+
+// ParseMatchers parses a label selector such as {job="a", level=~"warn|error"}.
+func ParseMatchers(selector string) ([]*labels.Matcher, error) {
+	matchers, err := parser.ParseMetricSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector %q: %w", selector, err)
+	}
+	return matchers, nil
+}
+
+// Validate returns an error if f is invalid.
+func (f Filter) Validate() error {
+	if f.SampleProb < 0 || f.SampleProb > 1 {
+		return fmt.Errorf("sample probability must be between 0 and 1, got %v", f.SampleProb)
+	}
+	if f.MaxEventsPerSecond < 0 {
+		return fmt.Errorf("maximum events per second must not be negative, got %v", f.MaxEventsPerSecond)
+	}
+	return nil
+}
+
+// Matches returns whether the labels of e match the matchers of f.
+func (f Filter) Matches(e Event) bool {
+	for _, m := range f.Matchers {
+		if !m.Matches(e.Labels[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Wrap returns a callback which calls callback with the events selected by
+// f. The returned callback must not be shared between consumers, since the
+// rate limit applies to all the events it receives.
+func (f Filter) Wrap(callback func(Event)) func(Event) {
+	var limiter *rate.Limiter
+	if f.MaxEventsPerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(f.MaxEventsPerSecond), max(1, int(f.MaxEventsPerSecond)))
+	}
+
+	return func(e Event) {
+		if !f.Matches(e) {
+			return
+		}
+		if f.SampleProb < 1 && rand.Float64() >= f.SampleProb {
+			return
+		}
+		if limiter != nil && !limiter.Allow() {
+			return
+		}
+		callback(e)
+	}
+}
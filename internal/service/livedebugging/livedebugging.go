@@ -3,6 +3,7 @@ package livedebugging
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/service"
@@ -15,22 +16,25 @@ type CallbackID string
 type CallbackManager interface {
 	// AddCallback sets a callback for a given componentID.
 	// The callback is used to send debugging data to live debugging consumers.
-	AddCallback(callbackID CallbackID, componentID ComponentID, callback func(string)) error
+	// Use [Filter.Wrap] to only receive some of the events.
+	AddCallback(callbackID CallbackID, componentID ComponentID, callback func(Event)) error
 	// DeleteCallback deletes a callback for a given componentID.
 	DeleteCallback(callbackID CallbackID, componentID ComponentID)
 }
 
 // DebugDataPublisher is used by components to push information to live debugging consumers.
 type DebugDataPublisher interface {
-	// Publish sends debugging data for a given componentID.
-	Publish(componentID ComponentID, data string)
+	// Publish sends debugging data for a given componentID. The ComponentID
+	// of the event is set to componentID, and its Timestamp defaults to the
+	// current time.
+	Publish(componentID ComponentID, event Event)
 	// IsActive returns true when at least one consumer is listening for debugging data for the given componentID.
 	IsActive(componentID ComponentID) bool
 }
 
 type liveDebugging struct {
 	loadMut   sync.RWMutex
-	callbacks map[ComponentID]map[CallbackID]func(string)
+	callbacks map[ComponentID]map[CallbackID]func(Event)
 	host      service.Host
 	enabled   bool
 }
@@ -41,16 +45,20 @@ var _ DebugDataPublisher = &liveDebugging{}
 // NewLiveDebugging creates a new instance of liveDebugging.
 func NewLiveDebugging() *liveDebugging {
 	return &liveDebugging{
-		callbacks: make(map[ComponentID]map[CallbackID]func(string)),
+		callbacks: make(map[ComponentID]map[CallbackID]func(Event)),
 	}
 }
 
-func (s *liveDebugging) Publish(componentID ComponentID, data string) {
+func (s *liveDebugging) Publish(componentID ComponentID, event Event) {
 	s.loadMut.RLock()
 	defer s.loadMut.RUnlock()
 	if s.enabled {
+		event.ComponentID = componentID
+		if event.Timestamp.IsZero() {
+			event.Timestamp = time.Now()
+		}
 		for _, callback := range s.callbacks[componentID] {
-			callback(data)
+			callback(event)
 		}
 	}
 }
@@ -62,7 +70,7 @@ func (s *liveDebugging) IsActive(componentID ComponentID) bool {
 	return exist && len(callbacks) > 0
 }
 
-func (s *liveDebugging) AddCallback(callbackID CallbackID, componentID ComponentID, callback func(string)) error {
+func (s *liveDebugging) AddCallback(callbackID CallbackID, componentID ComponentID, callback func(Event)) error {
 	err := s.addCallback(callbackID, componentID, callback)
 	if err != nil {
 		return err
@@ -78,7 +86,7 @@ func (s *liveDebugging) DeleteCallback(callbackID CallbackID, componentID Compon
 	delete(s.callbacks[componentID], callbackID)
 }
 
-func (s *liveDebugging) addCallback(callbackID CallbackID, componentID ComponentID, callback func(string)) error {
+func (s *liveDebugging) addCallback(callbackID CallbackID, componentID ComponentID, callback func(Event)) error {
 	s.loadMut.Lock()
 	defer s.loadMut.Unlock()
 
@@ -100,7 +108,7 @@ func (s *liveDebugging) addCallback(callbackID CallbackID, componentID Component
 	}
 
 	if _, ok := s.callbacks[componentID]; !ok {
-		s.callbacks[componentID] = make(map[CallbackID]func(string))
+		s.callbacks[componentID] = make(map[CallbackID]func(Event))
 	}
 	s.callbacks[componentID][callbackID] = callback
 	return nil
@@ -131,3 +139,27 @@ func (s *liveDebugging) SetEnabled(enabled bool) {
 	defer s.loadMut.Unlock()
 	s.enabled = enabled
 }
+
+// GetPublisher returns the DebugDataPublisher of the live debugging service
+// from the getServiceData function of the options of a component. When the
+// service isn't available, for example in tests which don't provide services,
+// it returns a publisher which is never active.
+func GetPublisher(getServiceData func(name string) (interface{}, error)) DebugDataPublisher {
+	if getServiceData == nil {
+		return noopPublisher{}
+	}
+	data, err := getServiceData(ServiceName)
+	if err != nil {
+		return noopPublisher{}
+	}
+	publisher, ok := data.(DebugDataPublisher)
+	if !ok {
+		return noopPublisher{}
+	}
+	return publisher
+}
+
+type noopPublisher struct{}
+
+func (noopPublisher) Publish(ComponentID, Event) {}
+func (noopPublisher) IsActive(ComponentID) bool  { return false }
@@ -12,7 +12,7 @@ import (
 func TestAddCallback(t *testing.T) {
 	livedebugging := NewLiveDebugging()
 	callbackID := CallbackID("callback1")
-	callback := func(data string) {}
+	callback := func(Event) {}
 
 	err := livedebugging.AddCallback(callbackID, "fake.liveDebugging", callback)
 	require.ErrorContains(t, err, "the live debugging service is disabled. Check the documentation to find out how to enable it")
@@ -47,28 +47,31 @@ func TestStream(t *testing.T) {
 	componentID := ComponentID("fake.liveDebugging")
 	callbackID := CallbackID("callback1")
 
-	var receivedData string
-	callback := func(data string) {
-		receivedData = data
+	var receivedData Event
+	callback := func(event Event) {
+		receivedData = event
 	}
 	require.False(t, livedebugging.IsActive(componentID))
 	livedebugging.AddCallback(callbackID, componentID, callback)
 	require.True(t, livedebugging.IsActive(componentID))
 	require.Len(t, livedebugging.callbacks[componentID], 1)
 
-	livedebugging.Publish(componentID, "test data")
-	require.Equal(t, "test data", receivedData)
+	livedebugging.Publish(componentID, Event{Type: DataTypeLogs, Body: "test data"})
+	require.Equal(t, "test data", receivedData.Body)
+	require.Equal(t, componentID, receivedData.ComponentID)
+	require.Equal(t, DataTypeLogs, receivedData.Type)
+	require.False(t, receivedData.Timestamp.IsZero())
 
 	livedebugging.SetEnabled(false)
-	livedebugging.Publish(componentID, "new test data")
-	require.Equal(t, "test data", receivedData) // not updated because the feature is disabled
+	livedebugging.Publish(componentID, Event{Body: "new test data"})
+	require.Equal(t, "test data", receivedData.Body) // not updated because the feature is disabled
 }
 
 func TestStreamEmpty(t *testing.T) {
 	livedebugging := NewLiveDebugging()
 	setupServiceHost(livedebugging)
 	componentID := ComponentID("fake.liveDebugging")
-	require.NotPanics(t, func() { livedebugging.Publish(componentID, "test data") })
+	require.NotPanics(t, func() { livedebugging.Publish(componentID, Event{Body: "test data"}) })
 }
 
 func TestMultipleStreams(t *testing.T) {
@@ -79,20 +82,20 @@ func TestMultipleStreams(t *testing.T) {
 	callbackID2 := CallbackID("callback2")
 
 	var receivedData1 string
-	callback1 := func(data string) {
-		receivedData1 = data
+	callback1 := func(event Event) {
+		receivedData1 = event.Body
 	}
 
 	var receivedData2 string
-	callback2 := func(data string) {
-		receivedData2 = data
+	callback2 := func(event Event) {
+		receivedData2 = event.Body
 	}
 
 	require.NoError(t, livedebugging.AddCallback(callbackID1, componentID, callback1))
 	require.NoError(t, livedebugging.AddCallback(callbackID2, componentID, callback2))
 	require.Len(t, livedebugging.callbacks[componentID], 2)
 
-	livedebugging.Publish(componentID, "test data")
+	livedebugging.Publish(componentID, Event{Body: "test data"})
 	require.Equal(t, "test data", receivedData1)
 	require.Equal(t, "test data", receivedData2)
 }
@@ -104,8 +107,8 @@ func TestDeleteCallback(t *testing.T) {
 	callbackID1 := CallbackID("callback1")
 	callbackID2 := CallbackID("callback2")
 
-	callback1 := func(data string) {}
-	callback2 := func(data string) {}
+	callback1 := func(Event) {}
+	callback2 := func(Event) {}
 
 	component, _ := livedebugging.host.GetComponent(component.ParseID("fake.liveDebugging"), component.InfoOptions{})
 
@@ -130,6 +133,45 @@ func TestDeleteCallback(t *testing.T) {
 	require.False(t, livedebugging.IsActive(ComponentID("fake.liveDebugging")))
 }
 
+func TestFilter(t *testing.T) {
+	matchers, err := ParseMatchers(`{job="a", level=~"warn|error"}`)
+	require.NoError(t, err)
+
+	var received []string
+	callback := Filter{Matchers: matchers, SampleProb: 1}.Wrap(func(e Event) {
+		received = append(received, e.Body)
+	})
+	callback(Event{Body: "match", Labels: map[string]string{"job": "a", "level": "warn"}})
+	callback(Event{Body: "other job", Labels: map[string]string{"job": "b", "level": "warn"}})
+	callback(Event{Body: "missing label", Labels: map[string]string{"job": "a"}})
+	callback(Event{Body: "no labels"})
+	require.Equal(t, []string{"match"}, received)
+
+	// Events above the rate are dropped.
+	received = nil
+	callback = Filter{SampleProb: 1, MaxEventsPerSecond: 2}.Wrap(func(e Event) {
+		received = append(received, e.Body)
+	})
+	for range 10 {
+		callback(Event{Body: "event"})
+	}
+	require.Len(t, received, 2)
+
+	// A sample probability of 0 drops every event.
+	received = nil
+	callback = Filter{SampleProb: 0}.Wrap(func(e Event) {
+		received = append(received, e.Body)
+	})
+	callback(Event{Body: "event"})
+	require.Empty(t, received)
+
+	_, err = ParseMatchers(`{job=}`)
+	require.ErrorContains(t, err, "invalid label selector")
+	require.Error(t, Filter{SampleProb: 2}.Validate())
+	require.Error(t, Filter{MaxEventsPerSecond: -1}.Validate())
+	require.NoError(t, DefaultFilter.Validate())
+}
+
 func setupServiceHost(liveDebugging *liveDebugging) {
 	host := &fakeServiceHost{
 		componentsInfo: map[component.ID]fakeInfo{
@@ -24,3 +24,8 @@ func (c noopClient) RegisterCollector(context.Context, *connect.Request[collecto
 func (c noopClient) UnregisterCollector(context.Context, *connect.Request[collectorv1.UnregisterCollectorRequest]) (*connect.Response[collectorv1.UnregisterCollectorResponse], error) {
 	return nil, errors.New("noop client")
 }
+
+// ReportStatus reports the status of the remote configurations to the API.
+func (c noopClient) ReportStatus(context.Context, *connect.Request[statusReport]) (*connect.Response[statusReportResponse], error) {
+	return nil, errors.New("noop client")
+}
@@ -1,6 +1,7 @@
 package remotecfg
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"hash/fnv"
@@ -10,6 +11,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -49,16 +51,37 @@ type Service struct {
 	opts Options
 	args Arguments
 
-	ctrl service.Controller
+	mut             sync.RWMutex
+	ctx             context.Context // Context of Run, nil until the service runs.
+	asClient        collectorv1connect.CollectorServiceClient
+	statusClient    statusClient
+	ticker          *jitter.Ticker
+	dataPath        string
+	configs         map[string]*remoteConfig // Remote configurations by name.
+	startController func(id string) (service.Controller, context.CancelFunc)
+	systemAttrs     map[string]string
+	attrs           map[string]string
+	metrics         *metrics
+}
+
+// remoteConfig is a configuration fetched from the API and loaded in its own
+// controller. The default configuration has an empty name.
+type remoteConfig struct {
+	name   string
+	attrs  map[string]string  // Attributes sent when fetching the configuration.
+	ctrl   service.Controller // Nil until the service runs.
+	cancel context.CancelFunc // Stops ctrl.
+
+	hash    string // Hash of the loaded configuration.
+	loadErr error  // Error of the last attempt to load the configuration.
+}
 
-	mut               sync.RWMutex
-	asClient          collectorv1connect.CollectorServiceClient
-	ticker            *jitter.Ticker
-	dataPath          string
-	currentConfigHash string
-	systemAttrs       map[string]string
-	attrs             map[string]string
-	metrics           *metrics
+// controllerID returns the ID of the controller which loads rc.
+func (rc *remoteConfig) controllerID() string {
+	if rc.name == "" {
+		return ServiceName
+	}
+	return ServiceName + "/" + rc.name
 }
 
 type metrics struct {
@@ -76,6 +99,10 @@ const ServiceName = "remotecfg"
 const reservedAttributeNamespace = "collector"
 const namespaceDelimiter = "."
 
+// configNameAttribute is the system attribute which holds the name of a named
+// remote configuration when fetching it.
+const configNameAttribute = reservedAttributeNamespace + namespaceDelimiter + "config"
+
 // Options are used to configure the remotecfg service. Options are
 // constant for the lifetime of the remotecfg service.
 type Options struct {
@@ -91,9 +118,18 @@ type Arguments struct {
 	Name             string                   `alloy:"name,attr,optional"`
 	Attributes       map[string]string        `alloy:"attributes,attr,optional"`
 	PollFrequency    time.Duration            `alloy:"poll_frequency,attr,optional"`
+	ReportStatus     bool                     `alloy:"report_status,attr,optional"`
+	Configs          []ConfigArguments        `alloy:"config,block,optional"`
 	HTTPClientConfig *config.HTTPClientConfig `alloy:",squash"`
 }
 
+// ConfigArguments holds the settings of a named remote configuration, which
+// is fetched and loaded in its own module next to the default one.
+type ConfigArguments struct {
+	Name       string            `alloy:",label"`
+	Attributes map[string]string `alloy:"attributes,attr,optional"`
+}
+
 // GetDefaultArguments populates the default values for the Arguments struct.
 func GetDefaultArguments() Arguments {
 	return Arguments{
@@ -115,9 +151,19 @@ func (a *Arguments) Validate() error {
 		return fmt.Errorf("poll_frequency must be at least \"10s\", got %q", a.PollFrequency)
 	}
 
-	for k := range a.Attributes {
-		if strings.HasPrefix(k, reservedAttributeNamespace+namespaceDelimiter) {
-			return fmt.Errorf("%q is a reserved namespace for remotecfg attribute keys", reservedAttributeNamespace)
+	if err := validateAttributes(a.Attributes); err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{}, len(a.Configs))
+	for _, c := range a.Configs {
+		if _, ok := names[c.Name]; ok {
+			return fmt.Errorf("config block label %q is used more than once", c.Name)
+		}
+		names[c.Name] = struct{}{}
+
+		if err := validateAttributes(c.Attributes); err != nil {
+			return err
 		}
 	}
 
@@ -130,6 +176,15 @@ func (a *Arguments) Validate() error {
 	return nil
 }
 
+func validateAttributes(attrs map[string]string) error {
+	for k := range attrs {
+		if strings.HasPrefix(k, reservedAttributeNamespace+namespaceDelimiter) {
+			return fmt.Errorf("%q is a reserved namespace for remotecfg attribute keys", reservedAttributeNamespace)
+		}
+	}
+	return nil
+}
+
 // Hash marshals the Arguments and returns a hash representation.
 func (a *Arguments) Hash() (string, error) {
 	b, err := syntax.Marshal(a)
@@ -150,6 +205,7 @@ func New(opts Options) (*Service, error) {
 	return &Service{
 		opts:        opts,
 		systemAttrs: getSystemAttributes(),
+		configs:     map[string]*remoteConfig{"": {}},
 		ticker:      jitter.NewTicker(math.MaxInt64-baseJitter, baseJitter), // first argument is set as-is to avoid overflowing
 	}, nil
 }
@@ -225,23 +281,31 @@ var _ service.Service = (*Service)(nil)
 // Run implements [service.Service] and starts the remotecfg service. It will
 // run until the provided context is canceled or there is a fatal error.
 func (s *Service) Run(ctx context.Context, host service.Host) error {
-	s.ctrl = host.NewController(ServiceName)
+	s.mut.Lock()
+	s.ctx = ctx
+	// Each remote configuration runs in the service's own controller.
+	s.startController = func(id string) (service.Controller, context.CancelFunc) {
+		ctrl := host.NewController(id)
+		ctx, cancel := context.WithCancel(ctx)
+		go ctrl.Run(ctx)
+		return ctrl, cancel
+	}
+	s.syncConfigs()
+	s.mut.Unlock()
 
 	s.fetch()
 	s.registerCollector()
 
-	// Run the service's own controller.
-	go func() {
-		s.ctrl.Run(ctx)
-	}()
-
 	for {
 		select {
 		case <-s.ticker.C:
-			err := s.fetchRemote()
-			if err != nil {
-				level.Error(s.opts.Logger).Log("msg", "failed to fetch remote configuration from the API", "err", err)
+			for _, rc := range s.getConfigs() {
+				err := s.fetchRemote(rc)
+				if err != nil {
+					level.Error(s.opts.Logger).Log("msg", "failed to fetch remote configuration from the API", "config", rc.name, "err", err)
+				}
 			}
+			s.reportStatus()
 		case <-ctx.Done():
 			s.ticker.Stop()
 			return nil
@@ -259,10 +323,12 @@ func (s *Service) Update(newConfig any) error {
 		s.mut.Lock()
 		s.ticker.Reset(math.MaxInt64 - baseJitter) // avoid overflowing
 		s.asClient = noopClient{}
+		s.statusClient = noopClient{}
 		s.args.HTTPClientConfig = config.CloneDefaultHTTPClientConfig()
+		defaultConfig := s.configs[""]
 		s.mut.Unlock()
 
-		s.setCfgHash("")
+		s.setCfgHash(defaultConfig, "")
 		return nil
 	}
 
@@ -274,7 +340,7 @@ func (s *Service) Update(newConfig any) error {
 	s.dataPath = filepath.Join(s.opts.StoragePath, ServiceName, hash)
 	s.ticker.Reset(newArgs.PollFrequency)
 	// Update the HTTP client last since it might fail.
-	if !reflect.DeepEqual(s.args.HTTPClientConfig, newArgs.HTTPClientConfig) {
+	if s.args.URL != newArgs.URL || !reflect.DeepEqual(s.args.HTTPClientConfig, newArgs.HTTPClientConfig) {
 		httpClient, err := commonconfig.NewClientFromConfig(*newArgs.HTTPClientConfig.Convert(), "remoteconfig")
 		if err != nil {
 			return err
@@ -283,6 +349,7 @@ func (s *Service) Update(newConfig any) error {
 			httpClient,
 			newArgs.URL,
 		)
+		s.statusClient = newStatusClient(httpClient, newArgs.URL)
 	}
 	// Combine the new attributes on top of the system attributes
 	s.attrs = maps.Clone(s.systemAttrs)
@@ -290,25 +357,80 @@ func (s *Service) Update(newConfig any) error {
 
 	// Update the args as the last step to avoid polluting any comparisons
 	s.args = newArgs
+	s.syncConfigs()
 	s.registerCollector()
+	running := s.startController != nil
 	s.mut.Unlock()
 
 	// If we've already called Run, then immediately trigger an API call with
 	// the updated Arguments, and/or fall back to the updated cache location.
-	if s.ctrl != nil && s.ctrl.Ready() {
+	if running {
 		s.fetch()
 	}
 
 	return nil
 }
 
-// fetch attempts to read configuration from the API and the local cache
-// and then parse/load their contents in order of preference.
+// syncConfigs updates the set of remote configurations to match the
+// arguments, starting the controllers of new configurations if the service
+// is running and stopping the ones of removed configurations. s.mut must be
+// held when calling syncConfigs.
+func (s *Service) syncConfigs() {
+	wanted := map[string]map[string]string{"": s.attrs}
+	for _, c := range s.args.Configs {
+		attrs := maps.Clone(s.attrs)
+		maps.Copy(attrs, c.Attributes)
+		attrs[configNameAttribute] = c.Name
+		wanted[c.Name] = attrs
+	}
+
+	for name, rc := range s.configs {
+		if _, ok := wanted[name]; !ok {
+			if rc.cancel != nil {
+				rc.cancel()
+			}
+			delete(s.configs, name)
+		}
+	}
+	for name, attrs := range wanted {
+		rc, ok := s.configs[name]
+		if !ok {
+			rc = &remoteConfig{name: name}
+			s.configs[name] = rc
+		}
+		rc.attrs = attrs
+		if rc.ctrl == nil && s.startController != nil {
+			rc.ctrl, rc.cancel = s.startController(rc.controllerID())
+		}
+	}
+}
+
+// getConfigs returns the remote configurations sorted by name.
+func (s *Service) getConfigs() []*remoteConfig {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	configs := make([]*remoteConfig, 0, len(s.configs))
+	for _, rc := range s.configs {
+		configs = append(configs, rc)
+	}
+	slices.SortFunc(configs, func(a, b *remoteConfig) int {
+		return cmp.Compare(a.name, b.name)
+	})
+	return configs
+}
+
+// fetch attempts to read every remote configuration from the API and the
+// local cache and then parse/load their contents in order of preference. The
+// status of the configurations is then reported to the API.
 func (s *Service) fetch() {
-	if err := s.fetchRemote(); err != nil {
-		level.Error(s.opts.Logger).Log("msg", "failed to fetch remote config", "err", err)
-		s.fetchLocal()
+	for _, rc := range s.getConfigs() {
+		if err := s.fetchRemote(rc); err != nil {
+			level.Error(s.opts.Logger).Log("msg", "failed to fetch remote config", "config", rc.name, "err", err)
+			s.fetchLocal(rc)
+		}
 	}
+	s.reportStatus()
 }
 
 func (s *Service) registerCollector() error {
@@ -326,12 +448,12 @@ func (s *Service) registerCollector() error {
 	return nil
 }
 
-func (s *Service) fetchRemote() error {
+func (s *Service) fetchRemote(rc *remoteConfig) error {
 	if !s.isEnabled() {
 		return nil
 	}
 
-	b, err := s.getAPIConfig()
+	b, err := s.getAPIConfig(rc)
 	s.metrics.totalAttempts.Add(1)
 	if err != nil {
 		s.metrics.totalFailures.Add(1)
@@ -343,40 +465,40 @@ func (s *Service) fetchRemote() error {
 
 	// API return the same configuration, no need to reload.
 	newConfigHash := getHash(b)
-	if s.getCfgHash() == newConfigHash {
-		level.Debug(s.opts.Logger).Log("msg", "skipping over API response since it contained the same hash")
+	if s.getConfigHash(rc) == newConfigHash {
+		level.Debug(s.opts.Logger).Log("msg", "skipping over API response since it contained the same hash", "config", rc.name)
 		return nil
 	}
 
-	err = s.parseAndLoad(b)
+	err = s.parseAndLoad(rc, b)
 	if err != nil {
 		return err
 	}
 
 	// If successful, flush to disk and keep a copy.
-	s.setCachedConfig(b)
-	s.setCfgHash(newConfigHash)
+	s.setCachedConfig(rc, b)
+	s.setCfgHash(rc, newConfigHash)
 	return nil
 }
 
-func (s *Service) fetchLocal() {
-	b, err := s.getCachedConfig()
+func (s *Service) fetchLocal(rc *remoteConfig) {
+	b, err := s.getCachedConfig(rc)
 	if err != nil {
-		level.Error(s.opts.Logger).Log("msg", "failed to read from cache", "err", err)
+		level.Error(s.opts.Logger).Log("msg", "failed to read from cache", "config", rc.name, "err", err)
 		return
 	}
 
-	err = s.parseAndLoad(b)
+	err = s.parseAndLoad(rc, b)
 	if err != nil {
-		level.Error(s.opts.Logger).Log("msg", "failed to load from cache", "err", err)
+		level.Error(s.opts.Logger).Log("msg", "failed to load from cache", "config", rc.name, "err", err)
 	}
 }
 
-func (s *Service) getAPIConfig() ([]byte, error) {
+func (s *Service) getAPIConfig(rc *remoteConfig) ([]byte, error) {
 	s.mut.RLock()
 	req := connect.NewRequest(&collectorv1.GetConfigRequest{
 		Id:         s.args.ID,
-		Attributes: s.attrs,
+		Attributes: rc.attrs,
 	})
 	client := s.asClient
 	s.mut.RUnlock()
@@ -390,17 +512,27 @@ func (s *Service) getAPIConfig() ([]byte, error) {
 	return []byte(gcr.Msg.GetContent()), nil
 }
 
-func (s *Service) getCachedConfig() ([]byte, error) {
+// cachePath returns the path of the on-disk cache of rc. The name of named
+// configurations is hashed so that it can't escape the storage path. s.mut
+// must be held when calling cachePath.
+func (s *Service) cachePath(rc *remoteConfig) string {
+	if rc.name == "" {
+		return s.dataPath
+	}
+	return s.dataPath + "-" + getHash([]byte(rc.name))
+}
+
+func (s *Service) getCachedConfig(rc *remoteConfig) ([]byte, error) {
 	s.mut.RLock()
-	p := s.dataPath
+	p := s.cachePath(rc)
 	s.mut.RUnlock()
 
 	return os.ReadFile(p)
 }
 
-func (s *Service) setCachedConfig(b []byte) {
+func (s *Service) setCachedConfig(rc *remoteConfig, b []byte) {
 	s.mut.RLock()
-	p := s.dataPath
+	p := s.cachePath(rc)
 	s.mut.RUnlock()
 
 	err := os.WriteFile(p, b, 0750)
@@ -409,39 +541,55 @@ func (s *Service) setCachedConfig(b []byte) {
 	}
 }
 
-func (s *Service) parseAndLoad(b []byte) error {
+func (s *Service) parseAndLoad(rc *remoteConfig, b []byte) error {
 	s.mut.RLock()
-	ctrl := s.ctrl
+	ctrl := rc.ctrl
 	s.mut.RUnlock()
 
 	if len(b) == 0 {
 		return nil
 	}
+	if ctrl == nil {
+		return fmt.Errorf("the controller of remote config %q is not running", rc.name)
+	}
 
 	err := ctrl.LoadSource(b, nil)
+	s.mut.Lock()
+	rc.loadErr = err
+	s.mut.Unlock()
 	if err != nil {
 		return err
 	}
 
-	s.setCfgHash(getHash(b))
+	s.setCfgHash(rc, getHash(b))
 	return nil
 }
 
+// getCfgHash returns the hash of the loaded default remote configuration.
 func (s *Service) getCfgHash() string {
+	s.mut.RLock()
+	rc := s.configs[""]
+	s.mut.RUnlock()
+
+	return s.getConfigHash(rc)
+}
+
+func (s *Service) getConfigHash(rc *remoteConfig) string {
 	s.mut.RLock()
 	defer s.mut.RUnlock()
 
-	return s.currentConfigHash
+	return rc.hash
 }
 
-func (s *Service) setCfgHash(h string) {
+func (s *Service) setCfgHash(rc *remoteConfig, h string) {
 	s.mut.Lock()
 	defer s.mut.Unlock()
-	if s.metrics != nil {
+	// The metric only reports the hash of the default configuration.
+	if s.metrics != nil && rc.name == "" {
 		s.metrics.configHash.Reset()
 		s.metrics.configHash.WithLabelValues(h).Set(1)
 	}
-	s.currentConfigHash = h
+	rc.hash = h
 }
 
 func (s *Service) isEnabled() bool {
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -12,6 +15,8 @@ import (
 
 	"connectrpc.com/connect"
 	collectorv1 "github.com/grafana/alloy-remote-config/api/gen/proto/go/collector/v1"
+	"github.com/grafana/alloy-remote-config/api/gen/proto/go/collector/v1/collectorv1connect"
+	"github.com/grafana/alloy/internal/build"
 	"github.com/grafana/alloy/internal/component"
 	_ "github.com/grafana/alloy/internal/component/loki/process"
 	"github.com/grafana/alloy/internal/featuregate"
@@ -43,6 +48,7 @@ func TestOnDiskCache(t *testing.T) {
 
 	client := &collectorClient{}
 	env.svc.asClient = client
+	env.svc.statusClient = client
 
 	var registerCalled atomic.Bool
 	client.registerCollectorFunc = buildRegisterCollectorFunc(&registerCalled)
@@ -80,6 +86,7 @@ func TestAPIResponse(t *testing.T) {
 
 	client := &collectorClient{}
 	env.svc.asClient = client
+	env.svc.statusClient = client
 
 	// Mock client to return a valid response.
 	var registerCalled atomic.Bool
@@ -114,6 +121,229 @@ func TestAPIResponse(t *testing.T) {
 	cancel()
 }
 
+func TestStatusReport(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Each named config is served based on the attribute holding its name.
+	srv := newStandInServer(t, map[string]string{
+		"":       `loki.process "default" { forward_to = [] }`,
+		"extra":  `loki.process "extra" { forward_to = [] }`,
+		"broken": `loki.process "broken" {`,
+	})
+
+	env := newTestEnvironment(t)
+	require.NoError(t, env.ApplyConfig(fmt.Sprintf(`
+		url            = "%s"
+		id             = "test-collector"
+		poll_frequency = "10s"
+		report_status  = true
+
+		config "extra" {
+			attributes = {"pipeline" = "extra"}
+		}
+
+		config "broken" { }
+	`, srv.URL)))
+
+	go func() {
+		require.NoError(t, env.Run(ctx))
+	}()
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		report := srv.LastReport()
+		if !assert.NotNil(c, report) || !assert.Len(c, report.Configs, 3) {
+			return
+		}
+		assert.Equal(c, "test-collector", report.ID)
+		assert.Equal(c, build.Version, report.Version)
+
+		// Configs are reported sorted by name.
+		def, broken, extra := report.Configs[0], report.Configs[1], report.Configs[2]
+
+		assert.Equal(c, "", def.Name)
+		assert.Equal(c, getHash([]byte(srv.configs[""])), def.Hash)
+		assert.Empty(c, def.LoadError)
+		assert.Equal(c, []string{"remotecfg"}, def.ModuleIDs)
+		if assert.Len(c, def.Components, 1) {
+			assert.Equal(c, "remotecfg/loki.process.default", def.Components[0].ID)
+			assert.Equal(c, component.HealthTypeHealthy.String(), def.Components[0].Health)
+		}
+
+		assert.Equal(c, "broken", broken.Name)
+		assert.Empty(c, broken.Hash)
+		assert.NotEmpty(c, broken.LoadError)
+
+		assert.Equal(c, "extra", extra.Name)
+		assert.Equal(c, getHash([]byte(srv.configs["extra"])), extra.Hash)
+		assert.Equal(c, []string{"remotecfg/extra"}, extra.ModuleIDs)
+		if assert.Len(c, extra.Components, 1) {
+			assert.Equal(c, "remotecfg/extra/loki.process.extra", extra.Components[0].ID)
+		}
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Named configs are fetched with their own attributes.
+	attrs := srv.Attributes("extra")
+	require.Equal(t, "extra", attrs["pipeline"])
+	require.Equal(t, build.Version, attrs["collector.version"])
+}
+
+func TestStatusReport_Disabled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := newStandInServer(t, map[string]string{
+		"": `loki.process "default" { forward_to = [] }`,
+	})
+
+	env := newTestEnvironment(t)
+	require.NoError(t, env.ApplyConfig(fmt.Sprintf(`
+		url = "%s"
+	`, srv.URL)))
+
+	go func() {
+		require.NoError(t, env.Run(ctx))
+	}()
+
+	// Reports are only sent once the configuration is loaded.
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, getHash([]byte(srv.configs[""])), env.svc.getCfgHash())
+	}, 5*time.Second, 50*time.Millisecond)
+	env.svc.reportStatus()
+	require.Nil(t, srv.LastReport())
+}
+
+func TestCachePath(t *testing.T) {
+	svc := &Service{dataPath: filepath.Join(t.TempDir(), "remotecfg", "hash")}
+
+	def := svc.cachePath(&remoteConfig{})
+	require.Equal(t, svc.dataPath, def)
+
+	// Names of named configs can't escape the storage path.
+	named := svc.cachePath(&remoteConfig{name: "../../etc"})
+	require.Equal(t, filepath.Dir(def), filepath.Dir(named))
+	require.NotEqual(t, def, named)
+	require.NotEqual(t, named, svc.cachePath(&remoteConfig{name: "other"}))
+}
+
+func TestArguments_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name: "valid named configs",
+			config: `
+				config "a" { }
+				config "b" {
+					attributes = {"key" = "value"}
+				}`,
+		},
+		{
+			name: "duplicate name",
+			config: `
+				config "a" { }
+				config "a" { }`,
+			err: `config block label "a" is used more than once`,
+		},
+		{
+			// The default remote configuration has an empty name.
+			name:   "empty name",
+			config: `config "" { }`,
+			err:    `1:1: block "config" requires non-empty label`,
+		},
+		{
+			name: "reserved attribute",
+			config: `
+				config "a" {
+					attributes = {"collector.config" = "b"}
+				}`,
+			err: `"collector" is a reserved namespace for remotecfg attribute keys`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var args Arguments
+			err := syntax.Unmarshal([]byte(tc.config), &args)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+// standInServer is a local stand-in for the remote configuration API.
+type standInServer struct {
+	*httptest.Server
+	configs map[string]string // Configs by name.
+
+	mut        sync.Mutex
+	attributes map[string]map[string]string // Attributes of the last GetConfig request by config name.
+	lastReport *statusReport
+}
+
+func newStandInServer(t *testing.T, configs map[string]string) *standInServer {
+	srv := &standInServer{
+		configs:    configs,
+		attributes: make(map[string]map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(collectorv1connect.NewCollectorServiceHandler(srv))
+	mux.Handle(reportStatusProcedure, connect.NewUnaryHandler(
+		reportStatusProcedure,
+		srv.ReportStatus,
+		connect.WithCodec(jsonCodec{}),
+	))
+	srv.Server = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (srv *standInServer) GetConfig(_ context.Context, req *connect.Request[collectorv1.GetConfigRequest]) (*connect.Response[collectorv1.GetConfigResponse], error) {
+	name := req.Msg.Attributes[configNameAttribute]
+
+	srv.mut.Lock()
+	srv.attributes[name] = req.Msg.Attributes
+	srv.mut.Unlock()
+
+	content, ok := srv.configs[name]
+	if !ok {
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("no config named %q", name))
+	}
+	return connect.NewResponse(&collectorv1.GetConfigResponse{Content: content}), nil
+}
+
+func (srv *standInServer) RegisterCollector(context.Context, *connect.Request[collectorv1.RegisterCollectorRequest]) (*connect.Response[collectorv1.RegisterCollectorResponse], error) {
+	return connect.NewResponse(&collectorv1.RegisterCollectorResponse{}), nil
+}
+
+func (srv *standInServer) UnregisterCollector(context.Context, *connect.Request[collectorv1.UnregisterCollectorRequest]) (*connect.Response[collectorv1.UnregisterCollectorResponse], error) {
+	return connect.NewResponse(&collectorv1.UnregisterCollectorResponse{}), nil
+}
+
+func (srv *standInServer) ReportStatus(_ context.Context, req *connect.Request[statusReport]) (*connect.Response[statusReportResponse], error) {
+	srv.mut.Lock()
+	defer srv.mut.Unlock()
+	srv.lastReport = req.Msg
+	return connect.NewResponse(&statusReportResponse{}), nil
+}
+
+func (srv *standInServer) LastReport() *statusReport {
+	srv.mut.Lock()
+	defer srv.mut.Unlock()
+	return srv.lastReport
+}
+
+func (srv *standInServer) Attributes(name string) map[string]string {
+	srv.mut.Lock()
+	defer srv.mut.Unlock()
+	return srv.attributes[name]
+}
+
 func buildGetConfigHandler(in string) func(context.Context, *connect.Request[collectorv1.GetConfigRequest]) (*connect.Response[collectorv1.GetConfigResponse], error) {
 	return func(context.Context, *connect.Request[collectorv1.GetConfigRequest]) (*connect.Response[collectorv1.GetConfigResponse], error) {
 		rsp := &connect.Response[collectorv1.GetConfigResponse]{
@@ -190,7 +420,7 @@ func (fakeHost) GetService(_ string) (service.Service, bool)     { return nil, f
 func (f fakeHost) NewController(id string) service.Controller {
 	logger, _ := logging.New(io.Discard, logging.DefaultOptions)
 	ctrl := alloy_runtime.New(alloy_runtime.Options{
-		ControllerID:    ServiceName,
+		ControllerID:    id,
 		Logger:          logger,
 		Tracer:          nil,
 		DataPath:        "",
@@ -209,6 +439,10 @@ type collectorClient struct {
 	registerCollectorFunc func(ctx context.Context, req *connect.Request[collectorv1.RegisterCollectorRequest]) (*connect.Response[collectorv1.RegisterCollectorResponse], error)
 }
 
+func (ag *collectorClient) ReportStatus(context.Context, *connect.Request[statusReport]) (*connect.Response[statusReportResponse], error) {
+	return connect.NewResponse(&statusReportResponse{}), nil
+}
+
 func (ag *collectorClient) GetConfig(ctx context.Context, req *connect.Request[collectorv1.GetConfigRequest]) (*connect.Response[collectorv1.GetConfigResponse], error) {
 	ag.mut.RLock()
 	defer ag.mut.RUnlock()
@@ -248,3 +482,6 @@ func (sc serviceController) LoadSource(b []byte, args map[string]any) error {
 	return sc.f.LoadSource(source, args)
 }
 func (sc serviceController) Ready() bool { return sc.f.Ready() }
+func (sc serviceController) ListComponents(moduleID string, opts component.InfoOptions) ([]*component.Info, error) {
+	return sc.f.ListComponents(moduleID, opts)
+}
@@ -0,0 +1,184 @@
+package remotecfg
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/grafana/alloy/internal/build"
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service"
+)
+
+// reportStatusProcedure is the procedure of the API which receives status
+// reports. It isn't part of the collector API definition, so reports are only
+// sent to APIs which are known to implement it through the report_status
+// argument. APIs which don't implement it respond with CodeUnimplemented.
+const reportStatusProcedure = "/collector.v1.CollectorService/ReportStatus"
+
+// statusReportTimeout is the maximum time to wait for the API to receive a
+// status report.
+const statusReportTimeout = 10 * time.Second
+
+// statusReport is the status of the remote configurations of the collector
+// which is pushed to the API after they're fetched.
+type statusReport struct {
+	ID      string         `json:"id"`
+	Version string         `json:"version"`
+	Configs []configStatus `json:"configs"`
+}
+
+// configStatus is the status of a single remote configuration. The default
+// configuration has an empty name.
+type configStatus struct {
+	Name       string            `json:"name,omitempty"`
+	Hash       string            `json:"hash"`
+	LoadError  string            `json:"load_error,omitempty"`
+	ModuleIDs  []string          `json:"module_ids"`
+	Components []componentStatus `json:"components"`
+}
+
+// componentStatus is the health of a component of a remote configuration.
+type componentStatus struct {
+	ID          string    `json:"id"`
+	Health      string    `json:"health"`
+	Message     string    `json:"message,omitempty"`
+	UpdatedTime time.Time `json:"updated_time"`
+}
+
+type statusReportResponse struct{}
+
+// statusClient sends status reports to the API.
+type statusClient interface {
+	ReportStatus(context.Context, *connect.Request[statusReport]) (*connect.Response[statusReportResponse], error)
+}
+
+type connectStatusClient struct {
+	client *connect.Client[statusReport, statusReportResponse]
+}
+
+// newStatusClient returns a statusClient which sends status reports as JSON
+// to the API at baseURL.
+func newStatusClient(httpClient connect.HTTPClient, baseURL string) statusClient {
+	return connectStatusClient{
+		client: connect.NewClient[statusReport, statusReportResponse](
+			httpClient,
+			baseURL+reportStatusProcedure,
+			connect.WithCodec(jsonCodec{}),
+		),
+	}
+}
+
+func (c connectStatusClient) ReportStatus(ctx context.Context, req *connect.Request[statusReport]) (*connect.Response[statusReportResponse], error) {
+	return c.client.CallUnary(ctx, req)
+}
+
+// jsonCodec is a connect codec for messages which aren't protobuf messages.
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                    { return "json" }
+func (jsonCodec) Marshal(msg any) ([]byte, error) { return json.Marshal(msg) }
+func (jsonCodec) Unmarshal(b []byte, msg any) error {
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, msg)
+}
+
+// reportStatus pushes the status of the remote configurations to the API if
+// the report_status argument is set.
+func (s *Service) reportStatus() {
+	if !s.isEnabled() {
+		return
+	}
+
+	s.mut.RLock()
+	var (
+		enabled = s.args.ReportStatus
+		client  = s.statusClient
+		ctx     = s.ctx
+	)
+	s.mut.RUnlock()
+	if !enabled || ctx == nil {
+		return
+	}
+
+	report := s.buildStatusReport()
+
+	ctx, cancel := context.WithTimeout(ctx, statusReportTimeout)
+	defer cancel()
+	_, err := client.ReportStatus(ctx, connect.NewRequest(report))
+	switch {
+	case connect.CodeOf(err) == connect.CodeUnimplemented:
+		level.Debug(s.opts.Logger).Log("msg", "the API does not support status reports", "err", err)
+	case err != nil:
+		level.Error(s.opts.Logger).Log("msg", "failed to report status to the API", "err", err)
+	}
+}
+
+// buildStatusReport returns the current status of the remote configurations.
+func (s *Service) buildStatusReport() *statusReport {
+	s.mut.RLock()
+	id := s.args.ID
+	s.mut.RUnlock()
+
+	report := &statusReport{
+		ID:      id,
+		Version: build.Version,
+		Configs: []configStatus{},
+	}
+	for _, rc := range s.getConfigs() {
+		s.mut.RLock()
+		status := configStatus{
+			Name:       rc.name,
+			Hash:       rc.hash,
+			ModuleIDs:  []string{},
+			Components: []componentStatus{},
+		}
+		if rc.loadErr != nil {
+			status.LoadError = rc.loadErr.Error()
+		}
+		ctrl := rc.ctrl
+		s.mut.RUnlock()
+
+		if ctrl != nil {
+			status.ModuleIDs = append(status.ModuleIDs, rc.controllerID())
+			s.addComponentStatus(&status, ctrl, "")
+		}
+		report.Configs = append(report.Configs, status)
+	}
+	return report
+}
+
+// addComponentStatus adds the health of the components of a module of ctrl,
+// and of the modules they run, to status.
+func (s *Service) addComponentStatus(status *configStatus, ctrl service.Controller, moduleID string) {
+	infos, err := ctrl.ListComponents(moduleID, component.InfoOptions{GetHealth: true})
+	if err != nil {
+		if !errors.Is(err, component.ErrModuleNotFound) {
+			level.Warn(s.opts.Logger).Log("msg", "failed to list components for status report", "module", moduleID, "err", err)
+		}
+		return
+	}
+	slices.SortFunc(infos, func(a, b *component.Info) int {
+		return cmp.Compare(a.ID.String(), b.ID.String())
+	})
+
+	for _, info := range infos {
+		status.Components = append(status.Components, componentStatus{
+			ID:          info.ID.String(),
+			Health:      info.Health.Health.String(),
+			Message:     info.Health.Message,
+			UpdatedTime: info.Health.UpdateTime,
+		})
+		for _, mod := range info.ModuleIDs {
+			status.ModuleIDs = append(status.ModuleIDs, mod)
+			s.addComponentStatus(status, ctrl, mod)
+		}
+	}
+}
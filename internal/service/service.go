@@ -77,6 +77,10 @@ type Controller interface {
 	Run(ctx context.Context)
 	LoadSource(source []byte, args map[string]any) error
 	Ready() bool
+
+	// ListComponents lists all components within a given module of the
+	// controller. Passing an empty moduleID lists the root components.
+	ListComponents(moduleID string, opts component.InfoOptions) ([]*component.Info, error)
 }
 
 type Consumer struct {
@@ -6,11 +6,11 @@ package api
 
 import (
 	"encoding/json"
-	"math/rand"
+	"fmt"
 	"net/http"
+	"net/url"
 	"path"
 	"strconv"
-	"strings"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -120,30 +120,50 @@ func (a *AlloyAPI) liveDebugging() http.HandlerFunc {
 		vars := mux.Vars(r)
 		componentID := livedebugging.ComponentID(vars["id"])
 
+		filter, err := parseLiveDebuggingFilter(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// The UI reads chunks of text delimited by |;|, while the json format
+		// writes an event per line.
+		var write func(livedebugging.Event) error
+		switch format := r.URL.Query().Get("format"); format {
+		case "", "text":
+			write = func(event livedebugging.Event) error {
+				// |;| delimiter is added at the end of every chunk
+				_, err := w.Write([]byte(event.String() + "|;|"))
+				return err
+			}
+		case "json":
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			enc := json.NewEncoder(w)
+			write = func(event livedebugging.Event) error { return enc.Encode(event) }
+		default:
+			http.Error(w, fmt.Sprintf("unsupported format %q", format), http.StatusBadRequest)
+			return
+		}
+
 		// Buffer of 1000 entries to handle load spikes and prevent this functionality from eating up too much memory.
 		// TODO: in the future we may want to make this value configurable to handle heavy load
-		dataCh := make(chan string, 1000)
+		dataCh := make(chan livedebugging.Event, 1000)
 		ctx := r.Context()
 
-		sampleProb := setSampleProb(w, r.URL.Query().Get("sampleProb"))
-
 		id := livedebugging.CallbackID(uuid.New().String())
 
-		err := a.CallbackManager.AddCallback(id, componentID, func(data string) {
+		err = a.CallbackManager.AddCallback(id, componentID, filter.Wrap(func(event livedebugging.Event) {
 			select {
 			case <-ctx.Done():
 				return
 			default:
-				if sampleProb < 1 && rand.Float64() > sampleProb {
-					return
-				}
 				// Avoid blocking the channel when the channel is full
 				select {
-				case dataCh <- data:
+				case dataCh <- event:
 				default:
 				}
 			}
-		})
+		}))
 
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -155,15 +175,13 @@ func (a *AlloyAPI) liveDebugging() http.HandlerFunc {
 			a.CallbackManager.DeleteCallback(id, componentID)
 		}()
 
+		// Send the headers right away so that clients know the stream started.
+		w.(http.Flusher).Flush()
+
 		for {
 			select {
-			case data := <-dataCh:
-				var builder strings.Builder
-				builder.WriteString(string(data))
-				// |;| delimiter is added at the end of every chunk
-				builder.WriteString("|;|")
-				_, writeErr := w.Write([]byte(builder.String()))
-				if writeErr != nil {
+			case event := <-dataCh:
+				if writeErr := write(event); writeErr != nil {
 					return
 				}
 				// TODO: flushing at a regular interval might be better performance wise
@@ -175,15 +193,31 @@ func (a *AlloyAPI) liveDebugging() http.HandlerFunc {
 	}
 }
 
-func setSampleProb(w http.ResponseWriter, sampleProbParam string) (sampleProb float64) {
-	sampleProb = 1.0
-	if sampleProbParam != "" {
+// parseLiveDebuggingFilter returns the filter set by the match, sampleProb
+// and maxEventsPerSecond query parameters.
+func parseLiveDebuggingFilter(query url.Values) (livedebugging.Filter, error) {
+	filter := livedebugging.DefaultFilter
+
+	if match := query.Get("match"); match != "" {
+		matchers, err := livedebugging.ParseMatchers(match)
+		if err != nil {
+			return filter, err
+		}
+		filter.Matchers = matchers
+	}
+	if sampleProb := query.Get("sampleProb"); sampleProb != "" {
+		var err error
+		filter.SampleProb, err = strconv.ParseFloat(sampleProb, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid sample probability: %w", err)
+		}
+	}
+	if maxRate := query.Get("maxEventsPerSecond"); maxRate != "" {
 		var err error
-		sampleProb, err = strconv.ParseFloat(sampleProbParam, 64)
-		if err != nil || sampleProb < 0 || sampleProb > 1 {
-			http.Error(w, "Invalid sample probability", http.StatusBadRequest)
-			return 1.0
+		filter.MaxEventsPerSecond, err = strconv.ParseFloat(maxRate, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid maximum events per second: %w", err)
 		}
 	}
-	return sampleProb
+	return filter, filter.Validate()
 }
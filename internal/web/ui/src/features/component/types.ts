@@ -76,6 +76,7 @@ export enum ComponentHealthState {
   UNHEALTHY = 'unhealthy',
   UNKNOWN = 'unknown',
   EXITED = 'exited',
+  CRASHLOOP = 'crashloop',
 }
 
 /*
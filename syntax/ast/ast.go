@@ -154,6 +154,13 @@ type ParenExpr struct {
 	LParenPos, RParenPos token.Pos
 }
 
+// ConditionalExpr evaluates to one of two values depending on a boolean
+// condition (i.e., `cond ? a : b`).
+type ConditionalExpr struct {
+	Condition, Then, Else Expr
+	QuestionPos, ColonPos token.Pos
+}
+
 // Type assertions
 
 var (
@@ -172,6 +179,7 @@ var (
 	_ Node = (*UnaryExpr)(nil)
 	_ Node = (*BinaryExpr)(nil)
 	_ Node = (*ParenExpr)(nil)
+	_ Node = (*ConditionalExpr)(nil)
 
 	_ Stmt = (*AttributeStmt)(nil)
 	_ Stmt = (*BlockStmt)(nil)
@@ -186,39 +194,42 @@ var (
 	_ Expr = (*UnaryExpr)(nil)
 	_ Expr = (*BinaryExpr)(nil)
 	_ Expr = (*ParenExpr)(nil)
+	_ Expr = (*ConditionalExpr)(nil)
 )
 
-func (n *File) astNode()           {}
-func (n Body) astNode()            {}
-func (n CommentGroup) astNode()    {}
-func (n *Comment) astNode()        {}
-func (n *AttributeStmt) astNode()  {}
-func (n *BlockStmt) astNode()      {}
-func (n *Ident) astNode()          {}
-func (n *IdentifierExpr) astNode() {}
-func (n *LiteralExpr) astNode()    {}
-func (n *ArrayExpr) astNode()      {}
-func (n *ObjectExpr) astNode()     {}
-func (n *AccessExpr) astNode()     {}
-func (n *IndexExpr) astNode()      {}
-func (n *CallExpr) astNode()       {}
-func (n *UnaryExpr) astNode()      {}
-func (n *BinaryExpr) astNode()     {}
-func (n *ParenExpr) astNode()      {}
+func (n *File) astNode()            {}
+func (n Body) astNode()             {}
+func (n CommentGroup) astNode()     {}
+func (n *Comment) astNode()         {}
+func (n *AttributeStmt) astNode()   {}
+func (n *BlockStmt) astNode()       {}
+func (n *Ident) astNode()           {}
+func (n *IdentifierExpr) astNode()  {}
+func (n *LiteralExpr) astNode()     {}
+func (n *ArrayExpr) astNode()       {}
+func (n *ObjectExpr) astNode()      {}
+func (n *AccessExpr) astNode()      {}
+func (n *IndexExpr) astNode()       {}
+func (n *CallExpr) astNode()        {}
+func (n *UnaryExpr) astNode()       {}
+func (n *BinaryExpr) astNode()      {}
+func (n *ParenExpr) astNode()       {}
+func (n *ConditionalExpr) astNode() {}
 
 func (n *AttributeStmt) astStmt() {}
 func (n *BlockStmt) astStmt()     {}
 
-func (n *IdentifierExpr) astExpr() {}
-func (n *LiteralExpr) astExpr()    {}
-func (n *ArrayExpr) astExpr()      {}
-func (n *ObjectExpr) astExpr()     {}
-func (n *AccessExpr) astExpr()     {}
-func (n *IndexExpr) astExpr()      {}
-func (n *CallExpr) astExpr()       {}
-func (n *UnaryExpr) astExpr()      {}
-func (n *BinaryExpr) astExpr()     {}
-func (n *ParenExpr) astExpr()      {}
+func (n *IdentifierExpr) astExpr()  {}
+func (n *LiteralExpr) astExpr()     {}
+func (n *ArrayExpr) astExpr()       {}
+func (n *ObjectExpr) astExpr()      {}
+func (n *AccessExpr) astExpr()      {}
+func (n *IndexExpr) astExpr()       {}
+func (n *CallExpr) astExpr()        {}
+func (n *UnaryExpr) astExpr()       {}
+func (n *BinaryExpr) astExpr()      {}
+func (n *ParenExpr) astExpr()       {}
+func (n *ConditionalExpr) astExpr() {}
 
 // StartPos returns the position of the first character belonging to a Node.
 func StartPos(n Node) token.Pos {
@@ -266,6 +277,8 @@ func StartPos(n Node) token.Pos {
 		return StartPos(n.Left)
 	case *ParenExpr:
 		return n.LParenPos
+	case *ConditionalExpr:
+		return StartPos(n.Condition)
 	default:
 		panic(fmt.Sprintf("Unhandled Node type %T", n))
 	}
@@ -317,6 +330,8 @@ func EndPos(n Node) token.Pos {
 		return EndPos(n.Right)
 	case *ParenExpr:
 		return n.RParenPos
+	case *ConditionalExpr:
+		return EndPos(n.Else)
 	default:
 		panic(fmt.Sprintf("Unhandled Node type %T", n))
 	}
@@ -65,6 +65,10 @@ func Walk(v Visitor, node Node) {
 		Walk(v, n.Right)
 	case *ParenExpr:
 		Walk(v, n.Inner)
+	case *ConditionalExpr:
+		Walk(v, n.Condition)
+		Walk(v, n.Then)
+		Walk(v, n.Else)
 	default:
 		panic(fmt.Sprintf("syntax/ast: unexpected node type %T", n))
 	}
@@ -1,4 +1,5 @@
-// Package alloyjson encodes Alloy configuration syntax as JSON.
+// Package alloyjson encodes Alloy configuration syntax as JSON and decodes it
+// back into Alloy syntax.
 package alloyjson
 
 import (
@@ -0,0 +1,510 @@
+package alloyjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/grafana/alloy/syntax/ast"
+	"github.com/grafana/alloy/syntax/diag"
+	"github.com/grafana/alloy/syntax/parser"
+	"github.com/grafana/alloy/syntax/scanner"
+	"github.com/grafana/alloy/syntax/token"
+)
+
+// UnmarshalBody decodes the JSON representation of an Alloy body, as
+// produced by MarshalBody, into an AST. The returned AST can be printed with
+// syntax/printer or evaluated with syntax/vm.
+//
+// In addition to the value types produced by MarshalBody, values may be of
+// type "expr", where the value is a string holding an Alloy expression such
+// as "prometheus.remote_write.default.receiver". Capsule values are decoded
+// the same way, which allows values produced from capsules which print as
+// expressions to be decoded. Function values can't be decoded.
+//
+// The positions of the nodes of the AST and of the returned diagnostics refer
+// to data.
+func UnmarshalBody(data []byte) (ast.Body, error) {
+	d, root, err := newDecoder(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decodeBody(root)
+}
+
+// UnmarshalValue decodes the JSON representation of an Alloy value, as
+// produced by MarshalValue, into an expression. See UnmarshalBody for the
+// supported value types.
+func UnmarshalValue(data []byte) (ast.Expr, error) {
+	d, root, err := newDecoder(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decodeValue(root)
+}
+
+// decoder decodes JSON values into an AST whose positions refer to data.
+type decoder struct {
+	file *token.File
+	data []byte
+}
+
+// newDecoder returns a decoder for data and the JSON value it holds.
+func newDecoder(data []byte) (*decoder, *jsonNode, error) {
+	d := &decoder{file: token.NewFile(""), data: data}
+	for i, b := range data {
+		if b == '\n' {
+			d.file.AddLine(i + 1)
+		}
+	}
+
+	if err := json.Unmarshal(data, new(any)); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, nil, d.errorf(max(int(syntaxErr.Offset)-1, 0), "%s", err)
+		}
+		return nil, nil, err
+	}
+
+	r := &jsonReader{data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+	r.dec.UseNumber()
+	root, err := r.read()
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, root, nil
+}
+
+// errorf returns a diagnostic for the JSON value at the offset off of data.
+func (d *decoder) errorf(off int, format string, args ...any) error {
+	return diag.Diagnostic{
+		Severity: diag.SeverityLevelError,
+		StartPos: d.file.PositionFor(d.file.Pos(off)),
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func (d *decoder) decodeBody(n *jsonNode) (ast.Body, error) {
+	elems, ok := n.value.([]*jsonNode)
+	if !ok {
+		return nil, d.errorf(n.start, "expected body to be an array")
+	}
+
+	body := make(ast.Body, 0, len(elems))
+	for _, elem := range elems {
+		stmt, err := d.decodeStmt(elem)
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, stmt)
+	}
+	return body, nil
+}
+
+func (d *decoder) decodeStmt(n *jsonNode) (ast.Stmt, error) {
+	if _, ok := n.value.([]jsonMember); !ok {
+		return nil, d.errorf(n.start, "expected statement to be an object")
+	}
+
+	typ, err := d.stringMember(n, "type")
+	if err != nil {
+		return nil, err
+	}
+	name, err := d.stringMember(n, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	// The statement starts at its object, so that the positions of its name
+	// come before the positions of its value or body whatever the order of
+	// the keys.
+	switch typ {
+	case "attr":
+		if !scanner.IsValidIdentifier(name) {
+			return nil, d.errorf(d.memberStart(n, "name"), "expected attribute name to be a valid identifier, but got %q", name)
+		}
+		value := n.member("value")
+		if value == nil {
+			return nil, d.errorf(n.start, "missing value of attribute %q", name)
+		}
+		expr, err := d.decodeValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.AttributeStmt{
+			Name:  &ast.Ident{Name: name, NamePos: d.file.Pos(n.start)},
+			Value: expr,
+		}, nil
+
+	case "block":
+		for _, part := range strings.Split(name, ".") {
+			if !scanner.IsValidIdentifier(part) {
+				return nil, d.errorf(d.memberStart(n, "name"), "expected block name to be a valid identifier, but got %q", name)
+			}
+		}
+		block := &ast.BlockStmt{
+			Name:      strings.Split(name, "."),
+			NamePos:   d.file.Pos(n.start),
+			LCurlyPos: d.file.Pos(n.start),
+			RCurlyPos: d.file.Pos(n.end),
+		}
+
+		label, err := d.stringMember(n, "label")
+		if err != nil {
+			return nil, err
+		}
+		if label != "" {
+			if !scanner.IsValidIdentifier(label) {
+				return nil, d.errorf(d.memberStart(n, "label"), "expected block label to be a valid identifier, but got %q", label)
+			}
+			block.Label, block.LabelPos = label, d.file.Pos(d.memberStart(n, "label"))
+		}
+
+		if body := n.member("body"); body != nil && body.value != nil {
+			block.LCurlyPos, block.RCurlyPos = d.file.Pos(body.start), d.file.Pos(body.end)
+			if block.Body, err = d.decodeBody(body); err != nil {
+				return nil, err
+			}
+		}
+		return block, nil
+
+	default:
+		return nil, d.errorf(d.memberStart(n, "type"), "unrecognized statement type %q", typ)
+	}
+}
+
+func (d *decoder) decodeValue(n *jsonNode) (ast.Expr, error) {
+	if _, ok := n.value.([]jsonMember); !ok {
+		return nil, d.errorf(n.start, "expected value to be an object")
+	}
+	typ, err := d.stringMember(n, "type")
+	if err != nil {
+		return nil, err
+	}
+
+	// value is the JSON value of the Alloy value. Values of type null may
+	// omit it, in which case the Alloy value is at its object.
+	value := n.member("value")
+	if value == nil {
+		if typ != "null" {
+			return nil, d.errorf(n.start, "missing value of type %q", typ)
+		}
+		value = n
+	}
+
+	switch typ {
+	case "null":
+		return &ast.LiteralExpr{Kind: token.NULL, ValuePos: d.file.Pos(value.start), Value: "null"}, nil
+
+	case "number":
+		num, ok := value.value.(json.Number)
+		if !ok {
+			return nil, d.errorf(value.start, "expected number")
+		}
+		// Numbers are parsed by the Alloy parser, which decides between integers
+		// and floats and parses the sign of negative numbers.
+		return d.parseExpr(num.String(), value.start, nil)
+
+	case "string":
+		s, ok := value.value.(string)
+		if !ok {
+			return nil, d.errorf(value.start, "expected string")
+		}
+		return &ast.LiteralExpr{Kind: token.STRING, ValuePos: d.file.Pos(value.start), Value: strconv.Quote(s)}, nil
+
+	case "bool":
+		b, ok := value.value.(bool)
+		if !ok {
+			return nil, d.errorf(value.start, "expected bool")
+		}
+		return &ast.LiteralExpr{Kind: token.BOOL, ValuePos: d.file.Pos(value.start), Value: strconv.FormatBool(b)}, nil
+
+	case "array":
+		elems, ok := value.value.([]*jsonNode)
+		if !ok {
+			return nil, d.errorf(value.start, "expected array")
+		}
+		arr := &ast.ArrayExpr{LBrackPos: d.file.Pos(value.start), RBrackPos: d.file.Pos(value.end)}
+		for _, elem := range elems {
+			expr, err := d.decodeValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			arr.Elements = append(arr.Elements, expr)
+		}
+		return arr, nil
+
+	case "object":
+		fields, ok := value.value.([]*jsonNode)
+		if !ok {
+			return nil, d.errorf(value.start, "expected array of object fields")
+		}
+		obj := &ast.ObjectExpr{LCurlyPos: d.file.Pos(value.start), RCurlyPos: d.file.Pos(value.end)}
+		for _, field := range fields {
+			if _, ok := field.value.([]jsonMember); !ok {
+				return nil, d.errorf(field.start, "expected object field to be an object")
+			}
+			key, err := d.stringMember(field, "key")
+			if err != nil {
+				return nil, err
+			}
+			fieldValue := field.member("value")
+			if fieldValue == nil {
+				return nil, d.errorf(field.start, "missing value of object field %q", key)
+			}
+			expr, err := d.decodeValue(fieldValue)
+			if err != nil {
+				return nil, err
+			}
+			obj.Fields = append(obj.Fields, &ast.ObjectField{
+				Name:   &ast.Ident{Name: key, NamePos: d.file.Pos(field.start)},
+				Quoted: !scanner.IsValidIdentifier(key),
+				Value:  expr,
+			})
+		}
+		return obj, nil
+
+	case "expr", "capsule":
+		s, ok := value.value.(string)
+		if !ok {
+			return nil, d.errorf(value.start, "expected string")
+		}
+		// The expression starts after the opening quote of the string.
+		expr, err := d.parseExpr(s, value.start+1, stringOffsets(d.data[value.start+1:value.end]))
+		var diagErr diag.Diagnostic
+		if errors.As(err, &diagErr) {
+			diagErr.Message = fmt.Sprintf("parsing %s %q: %s", typ, s, diagErr.Message)
+			return nil, diagErr
+		}
+		return expr, err
+
+	default:
+		return nil, d.errorf(d.memberStart(n, "type"), "unsupported value type %q", typ)
+	}
+}
+
+// parseExpr parses the Alloy expression expr, found at the offset off of
+// data. offsets maps the offsets of expr to the offsets of its source from
+// off, when they differ because of escape sequences.
+func (d *decoder) parseExpr(expr string, off int, offsets []int) (ast.Expr, error) {
+	pos := func(exprOff int) int {
+		if offsets == nil {
+			return off + exprOff
+		}
+		return off + offsets[min(exprOff, len(offsets)-1)]
+	}
+
+	e, err := parser.ParseExpression(expr)
+	if err != nil {
+		var diags diag.Diagnostics
+		if errors.As(err, &diags) && len(diags) > 0 {
+			return nil, d.errorf(pos(diags[0].StartPos.Offset), "%s", diags[0].Message)
+		}
+		return nil, err
+	}
+
+	ast.Walk(&rebaser{pos: func(p token.Pos) token.Pos {
+		if !p.Valid() {
+			return p
+		}
+		return d.file.Pos(pos(p.Offset()))
+	}}, e)
+	return e, nil
+}
+
+// stringMember returns the string value of the member key of the object n,
+// or an empty string if n has no such member.
+func (d *decoder) stringMember(n *jsonNode, key string) (string, error) {
+	value := n.member(key)
+	if value == nil || value.value == nil {
+		return "", nil
+	}
+	s, ok := value.value.(string)
+	if !ok {
+		return "", d.errorf(value.start, "expected %s to be a string", key)
+	}
+	return s, nil
+}
+
+// memberStart returns the offset of the value of the member key of the object
+// n, or the offset of n if it has no such member.
+func (d *decoder) memberStart(n *jsonNode, key string) int {
+	if value := n.member(key); value != nil {
+		return value.start
+	}
+	return n.start
+}
+
+// rebaser moves the positions of the nodes of an expression parsed from a
+// JSON string to the positions of the string.
+type rebaser struct {
+	pos func(token.Pos) token.Pos
+}
+
+func (r *rebaser) Visit(node ast.Node) ast.Visitor {
+	switch n := node.(type) {
+	case *ast.Ident:
+		n.NamePos = r.pos(n.NamePos)
+	case *ast.LiteralExpr:
+		n.ValuePos = r.pos(n.ValuePos)
+	case *ast.ArrayExpr:
+		n.LBrackPos, n.RBrackPos = r.pos(n.LBrackPos), r.pos(n.RBrackPos)
+	case *ast.ObjectExpr:
+		n.LCurlyPos, n.RCurlyPos = r.pos(n.LCurlyPos), r.pos(n.RCurlyPos)
+	case *ast.IndexExpr:
+		n.LBrackPos, n.RBrackPos = r.pos(n.LBrackPos), r.pos(n.RBrackPos)
+	case *ast.CallExpr:
+		n.LParenPos, n.RParenPos = r.pos(n.LParenPos), r.pos(n.RParenPos)
+	case *ast.UnaryExpr:
+		n.KindPos = r.pos(n.KindPos)
+	case *ast.BinaryExpr:
+		n.KindPos = r.pos(n.KindPos)
+	case *ast.ParenExpr:
+		n.LParenPos, n.RParenPos = r.pos(n.LParenPos), r.pos(n.RParenPos)
+	case *ast.ConditionalExpr:
+		n.QuestionPos, n.ColonPos = r.pos(n.QuestionPos), r.pos(n.ColonPos)
+	}
+	return r
+}
+
+// stringOffsets returns the offsets in raw, the content of a JSON string
+// without its quotes, of each byte of the decoded string, followed by the
+// length of raw.
+func stringOffsets(raw []byte) []int {
+	offsets := make([]int, 0, len(raw)+1)
+	for i := 0; i < len(raw); {
+		if raw[i] != '\\' || i+1 >= len(raw) {
+			offsets = append(offsets, i)
+			i++
+			continue
+		}
+		if raw[i+1] != 'u' || i+6 > len(raw) {
+			offsets = append(offsets, i)
+			i += 2
+			continue
+		}
+
+		// A \u escape sequence, which may be followed by the low half of a
+		// surrogate pair.
+		start := i
+		r := decodeHex(raw[i+2 : i+6])
+		i += 6
+		if utf16.IsSurrogate(r) && i+6 <= len(raw) && raw[i] == '\\' && raw[i+1] == 'u' {
+			if pair := utf16.DecodeRune(r, decodeHex(raw[i+2:i+6])); pair != utf8.RuneError {
+				r = pair
+				i += 6
+			}
+		}
+		if !utf8.ValidRune(r) {
+			r = utf8.RuneError
+		}
+		for n := utf8.RuneLen(r); n > 0; n-- {
+			offsets = append(offsets, start)
+		}
+	}
+	return append(offsets, len(raw))
+}
+
+// decodeHex decodes the four hexadecimal digits of a \u escape sequence.
+func decodeHex(b []byte) rune {
+	n, err := strconv.ParseUint(string(b), 16, 16)
+	if err != nil {
+		return utf8.RuneError
+	}
+	return rune(n)
+}
+
+// jsonNode is a JSON value with the offsets of its first and last bytes in
+// the decoded data. value holds nil, a bool, a json.Number, a string, a
+// []*jsonNode for arrays or a []jsonMember for objects.
+type jsonNode struct {
+	start, end int
+	value      any
+}
+
+// jsonMember is a member of a JSON object.
+type jsonMember struct {
+	key   string
+	value *jsonNode
+}
+
+// member returns the value of the last member key of the object n, or nil if
+// n isn't an object or has no such member.
+func (n *jsonNode) member(key string) *jsonNode {
+	members, _ := n.value.([]jsonMember)
+	var value *jsonNode
+	for _, m := range members {
+		if m.key == key {
+			value = m.value
+		}
+	}
+	return value
+}
+
+// jsonReader reads the valid JSON data into jsonNodes.
+type jsonReader struct {
+	data []byte
+	dec  *json.Decoder
+}
+
+// token returns the next token and the offsets of its first and last bytes.
+func (r *jsonReader) token() (tok json.Token, start, end int, err error) {
+	// The offset of the decoder is at the end of the previous token, before
+	// the whitespace and separators which precede the next one.
+	start = int(r.dec.InputOffset())
+	for start < len(r.data) && strings.IndexByte(" \t\r\n,:", r.data[start]) >= 0 {
+		start++
+	}
+	tok, err = r.dec.Token()
+	return tok, start, int(r.dec.InputOffset()) - 1, err
+}
+
+func (r *jsonReader) read() (*jsonNode, error) {
+	tok, start, end, err := r.token()
+	if err != nil {
+		return nil, err
+	}
+	n := &jsonNode{start: start, end: end, value: tok}
+
+	switch tok {
+	case json.Delim('['):
+		elems := []*jsonNode{}
+		for r.dec.More() {
+			elem, err := r.read()
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, elem)
+		}
+		n.value = elems
+
+	case json.Delim('{'):
+		members := []jsonMember{}
+		for r.dec.More() {
+			key, _, _, err := r.token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := r.read()
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, jsonMember{key: key.(string), value: value})
+		}
+		n.value = members
+
+	default:
+		return n, nil
+	}
+
+	// Read the closing delimiter.
+	if _, _, n.end, err = r.token(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
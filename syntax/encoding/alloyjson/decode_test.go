@@ -0,0 +1,225 @@
+package alloyjson_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/grafana/alloy/syntax"
+	"github.com/grafana/alloy/syntax/ast"
+	"github.com/grafana/alloy/syntax/encoding/alloyjson"
+	"github.com/grafana/alloy/syntax/printer"
+	"github.com/grafana/alloy/syntax/vm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalBody(t *testing.T) {
+	input := `[
+		{
+			"name": "logging",
+			"type": "block",
+			"body": [
+				{ "name": "level", "type": "attr", "value": { "type": "string", "value": "debug \"quoted\"" } }
+			]
+		},
+		{
+			"name": "prometheus.scrape",
+			"type": "block",
+			"label": "default",
+			"body": [
+				{
+					"name": "targets",
+					"type": "attr",
+					"value": {
+						"type": "array",
+						"value": [{
+							"type": "object",
+							"value": [
+								{ "key": "__address__", "value": { "type": "string", "value": "localhost:12345" } },
+								{ "key": "service.name", "value": { "type": "null" } }
+							]
+						}]
+					}
+				},
+				{
+					"name": "forward_to",
+					"type": "attr",
+					"value": {
+						"type": "array",
+						"value": [{ "type": "expr", "value": "prometheus.remote_write.default.receiver" }]
+					}
+				},
+				{ "name": "enabled", "type": "attr", "value": { "type": "bool", "value": true } },
+				{ "name": "offset", "type": "attr", "value": { "type": "number", "value": -15 } },
+				{ "name": "ratio", "type": "attr", "value": { "type": "number", "value": 0.5 } },
+				{ "name": "password", "type": "attr", "value": { "type": "capsule", "value": "sys.env(\"PASSWORD\")" } },
+				{ "name": "basic_auth", "type": "block", "body": [] }
+			]
+		}
+	]`
+
+	expect := `logging {
+	level = "debug \"quoted\""
+}
+
+prometheus.scrape "default" {
+	targets = [
+		{
+			__address__    = "localhost:12345",
+			"service.name" = null,
+		},
+	]
+
+	forward_to = [prometheus.remote_write.default.receiver]
+
+	enabled  = true
+	offset   = -15
+	ratio    = 0.5
+	password = sys.env("PASSWORD")
+
+	basic_auth { }
+}`
+
+	body, err := alloyjson.UnmarshalBody([]byte(input))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, printer.Fprint(&buf, &ast.File{Body: body}))
+	require.Equal(t, expect, buf.String())
+}
+
+func TestUnmarshalBody_RoundTrip(t *testing.T) {
+	val := testBlock{
+		Number:  -5,
+		String:  "hello\nworld",
+		Boolean: true,
+		Array:   []any{1, "two", 3.5},
+		Object:  map[string]any{"key": "value", "other key": 2},
+		Labeled: []labeledBlock{
+			{TestBlock: testBlock{Boolean: true}, Label: "label_a"},
+		},
+		Blocks: []testBlock{
+			{String: "hello"},
+			{Blocks: []testBlock{{Number: 1}}},
+		},
+	}
+
+	bb, err := alloyjson.MarshalBody(val)
+	require.NoError(t, err)
+	body, err := alloyjson.UnmarshalBody(bb)
+	require.NoError(t, err)
+
+	// Marshaling the AST again must give the same JSON.
+	var buf bytes.Buffer
+	require.NoError(t, printer.Fprint(&buf, &ast.File{Body: body}))
+
+	var actual testBlock
+	require.NoError(t, syntax.Unmarshal(buf.Bytes(), &actual))
+	require.Equal(t, val, actual)
+
+	again, err := alloyjson.MarshalBody(actual)
+	require.NoError(t, err)
+	require.JSONEq(t, string(bb), string(again))
+}
+
+func TestUnmarshalBody_Positions(t *testing.T) {
+	input := `[
+		{ "name": "local.file", "type": "block", "label": "a", "body": [
+			{ "name": "filename", "type": "attr", "value": { "type": "expr", "value": "\"a\\u00e9\" + env" } },
+			{ "name": "is_secret", "type": "attr", "value": { "type": "bool", "value": true } }
+		] }
+	]`
+
+	body, err := alloyjson.UnmarshalBody([]byte(input))
+	require.NoError(t, err)
+
+	offset := func(n ast.Node) int { return ast.StartPos(n).Offset() }
+
+	block := body[0].(*ast.BlockStmt)
+	require.Equal(t, strings.Index(input, `{ "name": "local.file"`), offset(block))
+	require.Equal(t, strings.Index(input, `"a", "body"`), block.LabelPos.Offset())
+
+	// The positions of expressions account for the escape sequences of the
+	// strings they're parsed from.
+	filename := block.Body[0].(*ast.AttributeStmt)
+	require.Equal(t, strings.Index(input, `{ "name": "filename"`), offset(filename))
+	expr := filename.Value.(*ast.BinaryExpr)
+	require.Equal(t, strings.Index(input, `\"a`), offset(expr.Left))
+	require.Equal(t, strings.Index(input, `env"`), offset(expr.Right))
+
+	isSecret := block.Body[1].(*ast.AttributeStmt)
+	require.Equal(t, strings.Index(input, `true`), offset(isSecret.Value))
+	require.Equal(t, 4, ast.StartPos(isSecret.Value).Position().Line)
+}
+
+func TestUnmarshalBody_Errors(t *testing.T) {
+	tt := []struct {
+		name   string
+		input  string
+		expect string
+	}{
+		{
+			name:   "invalid JSON",
+			input:  `[{]`,
+			expect: "1:3: invalid character ']' looking for beginning of object key string",
+		},
+		{
+			name:   "not an array",
+			input:  `{}`,
+			expect: "1:1: expected body to be an array",
+		},
+		{
+			name:   "unknown statement type",
+			input:  `[{ "name": "foo", "type": "bar" }]`,
+			expect: `1:27: unrecognized statement type "bar"`,
+		},
+		{
+			name:   "invalid attribute name",
+			input:  `[{ "name": "foo-bar", "type": "attr", "value": { "type": "null" } }]`,
+			expect: `1:12: expected attribute name to be a valid identifier, but got "foo-bar"`,
+		},
+		{
+			name:   "invalid block name",
+			input:  `[{ "name": "foo..bar", "type": "block", "body": [] }]`,
+			expect: `1:12: expected block name to be a valid identifier, but got "foo..bar"`,
+		},
+		{
+			name:   "invalid label",
+			input:  `[{ "name": "foo", "label": "a b", "type": "block", "body": [] }]`,
+			expect: `1:28: expected block label to be a valid identifier, but got "a b"`,
+		},
+		{
+			name: "nested value",
+			input: `[{ "name": "foo", "type": "block", "body": [
+				{ "name": "bar", "type": "attr", "value": { "type": "array", "value": [{ "type": "function", "value": "function" }] } }
+			] }]`,
+			expect: `2:86: unsupported value type "function"`,
+		},
+		{
+			name:   "mismatched value",
+			input:  `[{ "name": "foo", "type": "attr", "value": { "type": "number", "value": "5" } }]`,
+			expect: `1:73: expected number`,
+		},
+		{
+			name:   "invalid expression",
+			input:  `[{ "name": "foo", "type": "attr", "value": { "type": "expr", "value": "1 +" } }]`,
+			expect: `1:75: parsing expr "1 +": expected expression, got EOF`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := alloyjson.UnmarshalBody([]byte(tc.input))
+			require.EqualError(t, err, tc.expect)
+		})
+	}
+}
+
+func TestUnmarshalValue(t *testing.T) {
+	expr, err := alloyjson.UnmarshalValue([]byte(`{ "type": "number", "value": 1e3 }`))
+	require.NoError(t, err)
+
+	var actual float64
+	require.NoError(t, vm.New(expr).Evaluate(nil, &actual))
+	require.Equal(t, 1000.0, actual)
+}
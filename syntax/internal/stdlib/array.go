@@ -0,0 +1,179 @@
+package stdlib
+
+import (
+	"fmt"
+
+	"github.com/grafana/alloy/syntax/internal/value"
+)
+
+// arrayFuncs holds the functions of the array namespace. They are implemented
+// as raw functions to avoid converting arrays such as lists of targets into
+// []interface{}.
+var arrayFuncs = map[string]interface{}{
+	// filter returns the elements of an array which match a value. Objects
+	// match if they contain every field of the value with an equal value, so
+	// that targets can be filtered by a subset of their labels. Other elements
+	// match if they're equal to the value.
+	"filter": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if err := checkArgs(funcValue, args, 2); err != nil {
+			return value.Null, err
+		}
+		if err := checkArgType(funcValue, args, 0, value.TypeArray); err != nil {
+			return value.Null, err
+		}
+
+		arr, match := args[0], args[1]
+		raw := make([]value.Value, 0, arr.Len())
+		for i := 0; i < arr.Len(); i++ {
+			if elem := arr.Index(i); matches(elem, match) {
+				raw = append(raw, elem)
+			}
+		}
+		return value.Array(raw...), nil
+	}),
+
+	// distinct returns the elements of an array without duplicates. The first
+	// occurrence of each element is kept.
+	"distinct": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if err := checkArgs(funcValue, args, 1); err != nil {
+			return value.Null, err
+		}
+		if err := checkArgType(funcValue, args, 0, value.TypeArray); err != nil {
+			return value.Null, err
+		}
+
+		var (
+			arr = args[0]
+			raw = make([]value.Value, 0, arr.Len())
+
+			// Strings are deduplicated with a set since they're the most common
+			// elements; other elements are compared one by one.
+			seenStrings = make(map[string]struct{})
+		)
+		for i := 0; i < arr.Len(); i++ {
+			elem := arr.Index(i)
+			if elem.Type() == value.TypeString {
+				if _, seen := seenStrings[elem.Text()]; seen {
+					continue
+				}
+				seenStrings[elem.Text()] = struct{}{}
+			} else if containsValue(raw, elem) {
+				continue
+			}
+			raw = append(raw, elem)
+		}
+		return value.Array(raw...), nil
+	}),
+
+	// flatten returns the elements of an array and of its nested arrays, in a
+	// single array.
+	"flatten": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if err := checkArgs(funcValue, args, 1); err != nil {
+			return value.Null, err
+		}
+		if err := checkArgType(funcValue, args, 0, value.TypeArray); err != nil {
+			return value.Null, err
+		}
+		return value.Array(flatten(nil, args[0])...), nil
+	}),
+
+	// contains returns whether an array contains a value.
+	"contains": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if err := checkArgs(funcValue, args, 2); err != nil {
+			return value.Null, err
+		}
+		if err := checkArgType(funcValue, args, 0, value.TypeArray); err != nil {
+			return value.Null, err
+		}
+
+		arr := args[0]
+		for i := 0; i < arr.Len(); i++ {
+			if value.Equal(arr.Index(i), args[1]) {
+				return value.Bool(true), nil
+			}
+		}
+		return value.Bool(false), nil
+	}),
+
+	// slice returns the elements of an array from index start (inclusive) to
+	// index end (exclusive).
+	"slice": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if err := checkArgs(funcValue, args, 3); err != nil {
+			return value.Null, err
+		}
+		for i, expected := range []value.Type{value.TypeArray, value.TypeNumber, value.TypeNumber} {
+			if err := checkArgType(funcValue, args, i, expected); err != nil {
+				return value.Null, err
+			}
+		}
+
+		var (
+			arr        = args[0]
+			start, end = args[1].Int(), args[2].Int()
+		)
+		if start < 0 || end < start || end > int64(arr.Len()) {
+			return value.Null, value.Error{
+				Value: funcValue,
+				Inner: fmt.Errorf("invalid slice [%d:%d] of array with %d elements", start, end, arr.Len()),
+			}
+		}
+
+		raw := make([]value.Value, 0, end-start)
+		for i := start; i < end; i++ {
+			raw = append(raw, arr.Index(int(i)))
+		}
+		return value.Array(raw...), nil
+	}),
+
+	// length returns the number of elements of an array.
+	"length": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if err := checkArgs(funcValue, args, 1); err != nil {
+			return value.Null, err
+		}
+		if err := checkArgType(funcValue, args, 0, value.TypeArray); err != nil {
+			return value.Null, err
+		}
+		return value.Int(int64(args[0].Len())), nil
+	}),
+}
+
+// matches reports whether elem matches the value used to filter an array.
+func matches(elem, match value.Value) bool {
+	if elem.Type() != value.TypeObject || match.Type() != value.TypeObject {
+		return value.Equal(elem, match)
+	}
+	for _, key := range match.Keys() {
+		elemField, ok := elem.Key(key)
+		if !ok {
+			return false
+		}
+		matchField, _ := match.Key(key)
+		if !value.Equal(elemField, matchField) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsValue reports whether vv contains a value equal to v.
+func containsValue(vv []value.Value, v value.Value) bool {
+	for _, elem := range vv {
+		if value.Equal(elem, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// flatten appends the elements of arr and of its nested arrays to raw.
+func flatten(raw []value.Value, arr value.Value) []value.Value {
+	for i := 0; i < arr.Len(); i++ {
+		elem := arr.Index(i)
+		if elem.Type() == value.TypeArray {
+			raw = flatten(raw, elem)
+			continue
+		}
+		raw = append(raw, elem)
+	}
+	return raw
+}
@@ -0,0 +1,46 @@
+package stdlib
+
+import (
+	"encoding/base64"
+	"net/url"
+
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+// encodingFuncs holds the functions of the encoding namespace. The functions
+// accept both strings and secrets; the result of encoding or decoding a
+// secret is a secret.
+var encodingFuncs = map[string]interface{}{
+	"from_base64": func(in alloytypes.OptionalSecret) (interface{}, error) {
+		decoded, err := base64.StdEncoding.DecodeString(in.Value)
+		if err != nil {
+			return nil, err
+		}
+		return keepSecrecy(in, string(decoded)), nil
+	},
+
+	"to_base64": func(in alloytypes.OptionalSecret) interface{} {
+		return keepSecrecy(in, base64.StdEncoding.EncodeToString([]byte(in.Value)))
+	},
+
+	"url_encode": func(in alloytypes.OptionalSecret) interface{} {
+		return keepSecrecy(in, url.QueryEscape(in.Value))
+	},
+
+	"url_decode": func(in alloytypes.OptionalSecret) (interface{}, error) {
+		decoded, err := url.QueryUnescape(in.Value)
+		if err != nil {
+			return nil, err
+		}
+		return keepSecrecy(in, decoded), nil
+	},
+}
+
+// keepSecrecy returns out as a secret if in is a secret, and as a string
+// otherwise.
+func keepSecrecy(in alloytypes.OptionalSecret, out string) interface{} {
+	if in.IsSecret {
+		return alloytypes.Secret(out)
+	}
+	return out
+}
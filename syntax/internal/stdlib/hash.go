@@ -0,0 +1,36 @@
+package stdlib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"math"
+	"strconv"
+
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+// hashFuncs holds the functions of the hash namespace. The functions accept
+// both strings and secrets; the hash of a secret is a secret.
+var hashFuncs = map[string]interface{}{
+	// sha256 returns the hex-encoded SHA-256 hash of a string.
+	"sha256": func(in alloytypes.OptionalSecret) interface{} {
+		sum := sha256.Sum256([]byte(in.Value))
+		return keepSecrecy(in, hex.EncodeToString(sum[:]))
+	},
+
+	// fnv64 returns the 64-bit FNV-1a hash of a string as a number, which can
+	// be used for sharding. The highest bit of the hash is cleared so that it
+	// stays positive when combined with signed numbers, such as in
+	// hash.fnv64(x) % n. The hash of a secret is a secret holding the decimal
+	// form of the number.
+	"fnv64": func(in alloytypes.OptionalSecret) interface{} {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(in.Value))
+		sum := int64(h.Sum64() & math.MaxInt64)
+		if in.IsSecret {
+			return alloytypes.Secret(strconv.FormatInt(sum, 10))
+		}
+		return sum
+	},
+}
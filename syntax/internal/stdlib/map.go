@@ -0,0 +1,145 @@
+package stdlib
+
+import (
+	"sort"
+
+	"github.com/grafana/alloy/syntax/internal/value"
+)
+
+// mapFuncs holds the functions of the map namespace. They are implemented as
+// raw functions to avoid converting objects such as targets and labels into
+// map[string]interface{}.
+var mapFuncs = map[string]interface{}{
+	// keys returns the keys of an object in lexicographical order.
+	"keys": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if err := checkArgs(funcValue, args, 1); err != nil {
+			return value.Null, err
+		}
+		if err := checkArgType(funcValue, args, 0, value.TypeObject); err != nil {
+			return value.Null, err
+		}
+
+		keys := sortedKeys(args[0])
+		raw := make([]value.Value, 0, len(keys))
+		for _, key := range keys {
+			raw = append(raw, value.String(key))
+		}
+		return value.Array(raw...), nil
+	}),
+
+	// values returns the values of an object, ordered by their keys.
+	"values": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if err := checkArgs(funcValue, args, 1); err != nil {
+			return value.Null, err
+		}
+		if err := checkArgType(funcValue, args, 0, value.TypeObject); err != nil {
+			return value.Null, err
+		}
+
+		keys := sortedKeys(args[0])
+		raw := make([]value.Value, 0, len(keys))
+		for _, key := range keys {
+			v, _ := args[0].Key(key)
+			raw = append(raw, v)
+		}
+		return value.Array(raw...), nil
+	}),
+
+	// merge merges objects together. When a key is present in several objects,
+	// the value from the last object wins.
+	"merge": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		var finalSize int
+		for i := range args {
+			if err := checkArgType(funcValue, args, i, value.TypeObject); err != nil {
+				return value.Null, err
+			}
+			finalSize += args[i].Len()
+		}
+
+		// Optimization: if there's only one object, we can just return it
+		// directly.
+		if len(args) == 1 {
+			return args[0], nil
+		}
+
+		merged := make(map[string]value.Value, finalSize)
+		for _, arg := range args {
+			for _, key := range arg.Keys() {
+				merged[key], _ = arg.Key(key)
+			}
+		}
+		return value.Object(merged), nil
+	}),
+
+	// pick returns an object with only the given keys of an object. Keys which
+	// don't exist in the object are ignored.
+	"pick": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		obj, keys, err := objectAndKeys(funcValue, args)
+		if err != nil {
+			return value.Null, err
+		}
+
+		picked := make(map[string]value.Value, len(keys))
+		for key := range keys {
+			if v, ok := obj.Key(key); ok {
+				picked[key] = v
+			}
+		}
+		return value.Object(picked), nil
+	}),
+
+	// omit returns an object without the given keys of an object.
+	"omit": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		obj, keys, err := objectAndKeys(funcValue, args)
+		if err != nil {
+			return value.Null, err
+		}
+
+		kept := make(map[string]value.Value, obj.Len())
+		for _, key := range obj.Keys() {
+			if _, omitted := keys[key]; !omitted {
+				kept[key], _ = obj.Key(key)
+			}
+		}
+		return value.Object(kept), nil
+	}),
+}
+
+// sortedKeys returns the keys of obj in lexicographical order.
+func sortedKeys(obj value.Value) []string {
+	keys := obj.Keys()
+	sort.Strings(keys)
+	return keys
+}
+
+// objectAndKeys checks that args holds an object followed by an array of
+// strings, and returns them.
+func objectAndKeys(funcValue value.Value, args []value.Value) (value.Value, map[string]struct{}, error) {
+	if err := checkArgs(funcValue, args, 2); err != nil {
+		return value.Null, nil, err
+	}
+	if err := checkArgType(funcValue, args, 0, value.TypeObject); err != nil {
+		return value.Null, nil, err
+	}
+	if err := checkArgType(funcValue, args, 1, value.TypeArray); err != nil {
+		return value.Null, nil, err
+	}
+
+	keys := make(map[string]struct{}, args[1].Len())
+	for i := 0; i < args[1].Len(); i++ {
+		key := args[1].Index(i)
+		if key.Type() != value.TypeString {
+			return value.Null, nil, value.ArgError{
+				Function: funcValue,
+				Argument: args[1],
+				Index:    1,
+				Inner: value.TypeError{
+					Value:    key,
+					Expected: value.TypeString,
+				},
+			}
+		}
+		keys[key.Text()] = struct{}{}
+	}
+	return args[0], keys, nil
+}
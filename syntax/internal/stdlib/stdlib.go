@@ -25,6 +25,14 @@ var Identifiers = map[string]interface{}{
 	// See constants.go for the definition.
 	"constants": constants,
 
+	// Namespaces of functions; see the file of each namespace for the
+	// definitions.
+	"map":      mapFuncs,
+	"array":    arrayFuncs,
+	"encoding": encodingFuncs,
+	"hash":     hashFuncs,
+	"string":   stringFuncs,
+
 	"env": os.Getenv,
 
 	"nonsensitive": func(secret alloytypes.Secret) string {
@@ -149,3 +157,32 @@ var Identifiers = map[string]interface{}{
 	"trim_suffix": strings.TrimSuffix,
 	"trim_space":  strings.TrimSpace,
 }
+
+// checkArgs returns an error if the number of args passed to the raw function
+// funcValue isn't n.
+func checkArgs(funcValue value.Value, args []value.Value, n int) error {
+	if len(args) != n {
+		return value.Error{
+			Value: funcValue,
+			Inner: fmt.Errorf("expected %d args, got %d", n, len(args)),
+		}
+	}
+	return nil
+}
+
+// checkArgType returns an error if the argument at index i of the raw function
+// funcValue isn't of type expected.
+func checkArgType(funcValue value.Value, args []value.Value, i int, expected value.Type) error {
+	if args[i].Type() != expected {
+		return value.ArgError{
+			Function: funcValue,
+			Argument: args[i],
+			Index:    i,
+			Inner: value.TypeError{
+				Value:    args[i],
+				Expected: expected,
+			},
+		}
+	}
+	return nil
+}
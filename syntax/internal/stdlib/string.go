@@ -0,0 +1,42 @@
+package stdlib
+
+import (
+	"regexp"
+)
+
+// stringFuncs holds the functions of the string namespace.
+var stringFuncs = map[string]interface{}{
+	// regex_match returns whether a string contains a match of a regular
+	// expression.
+	"regex_match": func(in string, pattern string) (bool, error) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(in), nil
+	},
+
+	// regex_replace replaces the matches of a regular expression in a string.
+	// The replacement can reference capture groups with $1 or ${name}.
+	"regex_replace": func(in string, pattern string, replacement string) (string, error) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", err
+		}
+		return re.ReplaceAllString(in, replacement), nil
+	},
+
+	// regex_find_all returns all the matches of a regular expression in a
+	// string.
+	"regex_find_all": func(in string, pattern string) ([]string, error) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		matches := re.FindAllString(in, -1)
+		if matches == nil {
+			matches = []string{}
+		}
+		return matches, nil
+	},
+}
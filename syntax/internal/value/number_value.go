@@ -23,6 +23,22 @@ const (
 	NumberKindFloat
 )
 
+// FitNumberKinds returns the NumberKind which can represent numbers of both
+// kinds a and b.
+func FitNumberKinds(a, b NumberKind) NumberKind {
+	aPrec, bPrec := numberKindPrec[a], numberKindPrec[b]
+	if aPrec > bPrec {
+		return a
+	}
+	return b
+}
+
+var numberKindPrec = map[NumberKind]int{
+	NumberKindUint:  0,
+	NumberKindInt:   1,
+	NumberKindFloat: 2,
+}
+
 // makeNumberKind converts a Go kind to an Alloy kind.
 func makeNumberKind(k reflect.Kind) NumberKind {
 	switch k {
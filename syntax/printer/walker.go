@@ -179,6 +179,9 @@ func (w *walker) walkExpr(e ast.Expr) {
 		w.p.Write(token.LPAREN)
 		w.walkExpr(e.Inner)
 		w.p.Write(token.RPAREN)
+
+	case *ast.ConditionalExpr:
+		w.walkConditionalExpr(e)
 	}
 }
 
@@ -332,6 +335,29 @@ func (w *walker) walkCallExpr(e *ast.CallExpr) {
 	w.p.Write(token.RPAREN)
 }
 
+func (w *walker) walkConditionalExpr(e *ast.ConditionalExpr) {
+	w.walkExpr(e.Condition)
+	w.p.Write(wsBlank, e.QuestionPos, token.QUESTION)
+
+	// Keep the branches on their own lines if they were on different lines
+	// than the operator preceding them in the source.
+	w.walkConditionalBranch(e.QuestionPos, e.Then)
+	w.p.Write(wsBlank, e.ColonPos, token.COLON)
+	w.walkConditionalBranch(e.ColonPos, e.Else)
+}
+
+func (w *walker) walkConditionalBranch(opPos token.Pos, branch ast.Expr) {
+	if differentLines(opPos, ast.StartPos(branch)) {
+		w.p.Write(wsIndent, wsFormfeed)
+		w.walkExpr(branch)
+		w.p.Write(wsUnindent)
+		return
+	}
+
+	w.p.Write(wsBlank)
+	w.walkExpr(branch)
+}
+
 // differentLines returns true if a and b are on different lines.
 func differentLines(a, b token.Pos) bool {
 	return a.Position().Line != b.Position().Line
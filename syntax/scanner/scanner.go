@@ -338,6 +338,10 @@ scanAgain:
 		case '.':
 			// NOTE: Fractions starting with '.' are handled by outer switch
 			tok = token.DOT
+		case '?':
+			tok = token.QUESTION
+		case ':':
+			tok = token.COLON
 
 		default:
 			// s.next() reports invalid BOMs so we don't need to repeat the error.
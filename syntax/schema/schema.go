@@ -0,0 +1,172 @@
+// Package schema describes the attributes and blocks which Go types are
+// decoded from, based on their alloy struct tags.
+//
+// The description follows the same rules as the decoding performed by the
+// syntax and vm packages, and can be used by tools which need to know the
+// shape of a configuration block without evaluating it, such as editors or
+// schema generators.
+package schema
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/grafana/alloy/syntax/alloytypes"
+	"github.com/grafana/alloy/syntax/internal/syntaxtags"
+	"github.com/grafana/alloy/syntax/internal/value"
+)
+
+// Kind is the kind of a Field.
+type Kind uint8
+
+// Supported Kind values.
+const (
+	KindAttr  Kind = iota // KindAttr is an attribute.
+	KindBlock             // KindBlock is a block.
+	KindEnum              // KindEnum is a set of blocks of which only one may be set at a time.
+)
+
+var kindStrings = [...]string{
+	KindAttr:  "attr",
+	KindBlock: "block",
+	KindEnum:  "enum",
+}
+
+// String returns the name of k.
+func (k Kind) String() string {
+	if int(k) < len(kindStrings) {
+		return kindStrings[k]
+	}
+	return "unknown"
+}
+
+// Field describes an attribute or a block of a Go struct.
+type Field struct {
+	Name     string       // Name of the field. Names of nested blocks are separated by a ".".
+	Kind     Kind         // Kind of the field.
+	Optional bool         // Whether the field may be omitted.
+	Multiple bool         // Whether the block may be set more than once.
+	Labeled  bool         // Whether the block must have a label.
+	Type     reflect.Type // Go type of the field, with pointers dereferenced.
+	Index    []int        // Index of the Go field for reflect.Value.FieldByIndex.
+
+	// Fields of the block, or the blocks which can be set for an enum. Fields
+	// is empty for attributes.
+	Fields []Field
+}
+
+var (
+	goDuration       = reflect.TypeOf(time.Duration(0))
+	goTextMarshaler  = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	goSecret         = reflect.TypeOf(alloytypes.Secret(""))
+	goOptionalSecret = reflect.TypeOf(alloytypes.OptionalSecret{})
+)
+
+// Fields returns the attributes and blocks which t is decoded from. t must be
+// a struct type or a pointer to a struct type; Fields returns nil for any
+// other type or for structs without alloy tags.
+//
+// Attributes and blocks of squashed structs are returned as fields of t.
+func Fields(t reflect.Type) []Field {
+	return fields(t, make(map[reflect.Type]struct{}))
+}
+
+// fields returns the fields of t. Types in visiting are being described by a
+// caller; their fields aren't returned again to support recursive types.
+func fields(t reflect.Type, visiting map[reflect.Type]struct{}) []Field {
+	t = deref(t)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if _, ok := visiting[t]; ok {
+		return nil
+	}
+	visiting[t] = struct{}{}
+	defer delete(visiting, t)
+
+	var res []Field
+	for _, tf := range syntaxtags.Get(t) {
+		if tf.IsLabel() {
+			continue
+		}
+
+		ft := deref(t.FieldByIndex(tf.Index).Type)
+		f := Field{
+			Name:     strings.Join(tf.Name, "."),
+			Optional: tf.IsOptional(),
+			Type:     ft,
+			Index:    tf.Index,
+		}
+
+		switch {
+		case tf.IsAttr():
+			f.Kind = KindAttr
+
+		case tf.IsBlock():
+			f.Kind = KindBlock
+			if ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array {
+				f.Multiple = true
+				f.Type = deref(ft.Elem())
+			}
+			f.Labeled = hasLabel(f.Type)
+			f.Fields = fields(f.Type, visiting)
+
+		case tf.IsEnum():
+			f.Kind = KindEnum
+			f.Multiple = true
+			f.Type = deref(ft.Elem())
+			f.Fields = fields(f.Type, visiting)
+		}
+
+		res = append(res, f)
+	}
+	return res
+}
+
+// TypeName returns the name of the Alloy type which values of the Go type t
+// are decoded from, such as "string", "list(number)" or "map(secret)".
+func TypeName(t reflect.Type) string {
+	inner := deref(t)
+
+	switch {
+	case inner == goDuration:
+		return "duration"
+	case inner == goSecret || inner == goOptionalSecret:
+		return "secret"
+	case reflect.PointerTo(inner).Implements(goTextMarshaler):
+		return "string"
+	}
+
+	switch ty := value.AlloyType(t); ty {
+	case value.TypeArray:
+		return "list(" + TypeName(inner.Elem()) + ")"
+	case value.TypeObject:
+		if inner.Kind() == reflect.Map {
+			return "map(" + TypeName(inner.Elem()) + ")"
+		}
+		return "object"
+	default:
+		return ty.String()
+	}
+}
+
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func hasLabel(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for _, tf := range syntaxtags.Get(t) {
+		if tf.IsLabel() {
+			return true
+		}
+	}
+	return false
+}
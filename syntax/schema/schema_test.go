@@ -0,0 +1,98 @@
+package schema_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/syntax/alloytypes"
+	"github.com/grafana/alloy/syntax/schema"
+	"github.com/stretchr/testify/require"
+)
+
+type testArguments struct {
+	Name     string              `alloy:"name,attr"`
+	Timeout  time.Duration       `alloy:"timeout,attr,optional"`
+	Password alloytypes.Secret   `alloy:"password,attr,optional"`
+	Targets  []map[string]string `alloy:"targets,attr,optional"`
+
+	Common testCommon `alloy:",squash"`
+
+	Client   *testClient `alloy:"client,block,optional"`
+	Rules    []testRule  `alloy:"rule,block,optional"`
+	Stages   []testStage `alloy:"stage,enum,optional"`
+	Nested   *testNested `alloy:"nested.inner,block,optional"`
+	Untagged int
+}
+
+type testCommon struct {
+	Enabled bool `alloy:"enabled,attr,optional"`
+}
+
+type testClient struct {
+	URL string `alloy:"url,attr"`
+}
+
+type testRule struct {
+	Label string `alloy:",label"`
+	Value int    `alloy:"value,attr"`
+}
+
+type testStage struct {
+	Drop  *testClient `alloy:"drop,block,optional"`
+	Match *testClient `alloy:"match,block,optional"`
+}
+
+type testNested struct {
+	Parent *testNested `alloy:"parent,block,optional"`
+}
+
+func TestFields(t *testing.T) {
+	fields := schema.Fields(reflect.TypeOf(&testArguments{}))
+
+	type summary struct {
+		Name     string
+		Kind     schema.Kind
+		Optional bool
+		Multiple bool
+		Labeled  bool
+		Type     string
+		Fields   []string
+	}
+	var actual []summary
+	for _, f := range fields {
+		s := summary{
+			Name:     f.Name,
+			Kind:     f.Kind,
+			Optional: f.Optional,
+			Multiple: f.Multiple,
+			Labeled:  f.Labeled,
+			Type:     schema.TypeName(f.Type),
+		}
+		for _, inner := range f.Fields {
+			s.Fields = append(s.Fields, inner.Name)
+		}
+		actual = append(actual, s)
+	}
+
+	expect := []summary{
+		{Name: "name", Kind: schema.KindAttr, Type: "string"},
+		{Name: "timeout", Kind: schema.KindAttr, Optional: true, Type: "duration"},
+		{Name: "password", Kind: schema.KindAttr, Optional: true, Type: "secret"},
+		{Name: "targets", Kind: schema.KindAttr, Optional: true, Type: "list(map(string))"},
+		{Name: "enabled", Kind: schema.KindAttr, Optional: true, Type: "bool"},
+		{Name: "client", Kind: schema.KindBlock, Optional: true, Type: "object", Fields: []string{"url"}},
+		{Name: "rule", Kind: schema.KindBlock, Optional: true, Multiple: true, Labeled: true, Type: "object", Fields: []string{"value"}},
+		{Name: "stage", Kind: schema.KindEnum, Optional: true, Multiple: true, Type: "object", Fields: []string{"drop", "match"}},
+		{Name: "nested.inner", Kind: schema.KindBlock, Optional: true, Type: "object", Fields: []string{"parent"}},
+	}
+	require.Equal(t, expect, actual)
+
+	// Recursive types are only described once.
+	require.Empty(t, fields[8].Fields[0].Fields)
+}
+
+func TestFields_NotStruct(t *testing.T) {
+	require.Nil(t, schema.Fields(reflect.TypeOf(5)))
+	require.Nil(t, schema.Fields(reflect.TypeOf(struct{ A int }{})))
+}
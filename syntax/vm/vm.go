@@ -423,6 +423,22 @@ func (vm *Evaluator) evaluateExpr(scope *Scope, assoc map[value.Value]ast.Node,
 	case *ast.ParenExpr:
 		return vm.evaluateExpr(scope, assoc, expr.Inner)
 
+	case *ast.ConditionalExpr:
+		cond, err := vm.evaluateExpr(scope, assoc, expr.Condition)
+		if err != nil {
+			return value.Null, err
+		}
+		if cond.Type() != value.TypeBool {
+			return value.Null, value.TypeError{Value: cond, Expected: value.TypeBool}
+		}
+
+		// Only the selected branch is evaluated, so the other branch may refer to
+		// values which would otherwise fail to evaluate.
+		if cond.Bool() {
+			return vm.evaluateExpr(scope, assoc, expr.Then)
+		}
+		return vm.evaluateExpr(scope, assoc, expr.Else)
+
 	case *ast.UnaryExpr:
 		val, err := vm.evaluateExpr(scope, assoc, expr.Value)
 		if err != nil {
@@ -185,6 +185,194 @@ func TestStdlib_StringFunc(t *testing.T) {
 	}
 }
 
+func TestStdlib_Map(t *testing.T) {
+	scope := &vm.Scope{
+		Variables: map[string]any{
+			"labels": map[string]string{"job": "api", "env": "prod", "team": "infra"},
+		},
+	}
+
+	tt := []struct {
+		name   string
+		input  string
+		expect interface{}
+	}{
+		{"keys", `map.keys(labels)`, []string{"env", "job", "team"}},
+		{"keys empty", `map.keys({})`, []string{}},
+		{"values", `map.values(labels)`, []string{"prod", "api", "infra"}},
+		{"merge", `map.merge(labels, {env = "dev"}, {region = "eu"})`, map[string]string{"job": "api", "env": "dev", "team": "infra", "region": "eu"}},
+		{"merge single", `map.merge(labels)`, map[string]string{"job": "api", "env": "prod", "team": "infra"}},
+		{"merge none", `map.merge()`, map[string]string{}},
+		{"pick", `map.pick(labels, ["job", "env", "missing"])`, map[string]string{"job": "api", "env": "prod"}},
+		{"omit", `map.omit(labels, ["job", "missing"])`, map[string]string{"env": "prod", "team": "infra"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := parser.ParseExpression(tc.input)
+			require.NoError(t, err)
+
+			eval := vm.New(expr)
+
+			rv := reflect.New(reflect.TypeOf(tc.expect))
+			require.NoError(t, eval.Evaluate(scope, rv.Interface()))
+			require.Equal(t, tc.expect, rv.Elem().Interface())
+		})
+	}
+}
+
+func TestStdlib_Array(t *testing.T) {
+	scope := &vm.Scope{
+		Variables: map[string]any{
+			"targets": []map[string]string{
+				{"__address__": "a:80", "env": "prod"},
+				{"__address__": "b:80", "env": "dev"},
+				{"__address__": "c:80", "env": "prod"},
+			},
+		},
+	}
+
+	tt := []struct {
+		name   string
+		input  string
+		expect interface{}
+	}{
+		{"filter values", `array.filter([1, 2, 1, 3], 1)`, []int{1, 1}},
+		{"filter objects", `array.filter(targets, {env = "prod"})`, []map[string]string{
+			{"__address__": "a:80", "env": "prod"},
+			{"__address__": "c:80", "env": "prod"},
+		}},
+		{"filter objects no match", `array.filter(targets, {missing = "prod"})`, []map[string]string{}},
+		{"distinct strings", `array.distinct(["a", "b", "a", "c", "b"])`, []string{"a", "b", "c"}},
+		{"distinct numbers", `array.distinct([1, 1.0, 2, 3, 2])`, []int{1, 2, 3}},
+		{"distinct objects", `array.distinct([{a = 1}, {a = 2}, {a = 1}])`, []map[string]int{{"a": 1}, {"a": 2}}},
+		{"flatten", `array.flatten([1, [2, [3, [4]]], [], 5])`, []int{1, 2, 3, 4, 5}},
+		{"contains", `array.contains(["a", "b"], "b")`, true},
+		{"contains number", `array.contains([1, 2], 2.0)`, true},
+		{"not contains", `array.contains(["a", "b"], "c")`, false},
+		{"slice", `array.slice([1, 2, 3, 4], 1, 3)`, []int{2, 3}},
+		{"slice empty", `array.slice([1, 2, 3, 4], 4, 4)`, []int{}},
+		{"length", `array.length(targets)`, 3},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := parser.ParseExpression(tc.input)
+			require.NoError(t, err)
+
+			eval := vm.New(expr)
+
+			rv := reflect.New(reflect.TypeOf(tc.expect))
+			require.NoError(t, eval.Evaluate(scope, rv.Interface()))
+			require.Equal(t, tc.expect, rv.Elem().Interface())
+		})
+	}
+}
+
+func TestStdlib_Encoding(t *testing.T) {
+	tt := []struct {
+		name   string
+		input  string
+		expect interface{}
+	}{
+		{"to_base64", `encoding.to_base64("foobar123!?$*&()'-=@~")`, "Zm9vYmFyMTIzIT8kKiYoKSctPUB+"},
+		{"from_base64", `encoding.from_base64("Zm9vYmFyMTIzIT8kKiYoKSctPUB+")`, "foobar123!?$*&()'-=@~"},
+		{"url_encode", `encoding.url_encode("a b&c=d/e")`, "a+b%26c%3Dd%2Fe"},
+		{"url_decode", `encoding.url_decode("a+b%26c%3Dd%2Fe")`, "a b&c=d/e"},
+		{"sha256", `hash.sha256("foo")`, "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"},
+		{"fnv64", `hash.fnv64("foo")`, int64(6679529947559220599)},
+		{"fnv64 sharding", `hash.fnv64("foo") % 4`, int64(3)},
+		{"fnv64 sharding is positive", `hash.fnv64("foo") % 7 >= 0 && hash.fnv64("bar") % 7 >= 0 && hash.fnv64("baz") % 7 >= 0`, true},
+		{"regex_match", `string.regex_match("api-prod-1", "^api-(prod|dev)-[0-9]+$")`, true},
+		{"regex_match no match", `string.regex_match("db-prod-1", "^api-")`, false},
+		{"regex_replace", `string.regex_replace("api-prod-1", "^(\\w+)-(\\w+)-.*$", "$2/$1")`, "prod/api"},
+		{"regex_find_all", `string.regex_find_all("a1b22c333", "[0-9]+")`, []string{"1", "22", "333"}},
+		{"regex_find_all no match", `string.regex_find_all("abc", "[0-9]+")`, []string{}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := parser.ParseExpression(tc.input)
+			require.NoError(t, err)
+
+			eval := vm.New(expr)
+
+			rv := reflect.New(reflect.TypeOf(tc.expect))
+			require.NoError(t, eval.Evaluate(nil, rv.Interface()))
+			require.Equal(t, tc.expect, rv.Elem().Interface())
+		})
+	}
+}
+
+func TestStdlib_EncodingSecrets(t *testing.T) {
+	scope := &vm.Scope{
+		Variables: map[string]any{
+			"secret":         alloytypes.Secret("foo"),
+			"optionalSecret": alloytypes.OptionalSecret{Value: "foo"},
+		},
+	}
+
+	tt := []struct {
+		name   string
+		input  string
+		expect interface{}
+	}{
+		{"sha256 of secret", `hash.sha256(secret)`, alloytypes.Secret("2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae")},
+		{"sha256 of optional secret", `hash.sha256(optionalSecret)`, "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"},
+		{"fnv64 of secret", `hash.fnv64(secret)`, alloytypes.Secret("6679529947559220599")},
+		{"to_base64 of secret", `encoding.to_base64(secret)`, alloytypes.Secret("Zm9v")},
+		{"url_encode of secret", `encoding.url_encode(secret)`, alloytypes.Secret("foo")},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := parser.ParseExpression(tc.input)
+			require.NoError(t, err)
+
+			eval := vm.New(expr)
+
+			var out interface{}
+			require.NoError(t, eval.Evaluate(scope, &out))
+			require.Equal(t, tc.expect, out)
+		})
+	}
+
+	// A secret can't be turned back into a string by hashing it.
+	expr, err := parser.ParseExpression(`hash.sha256(secret)`)
+	require.NoError(t, err)
+	var out string
+	require.ErrorContains(t, vm.New(expr).Evaluate(scope, &out), "secrets may not be converted into strings")
+}
+
+func TestStdlib_CollectionErrors(t *testing.T) {
+	tt := []struct {
+		name        string
+		input       string
+		expectError string
+	}{
+		{"map.keys of array", `map.keys([1, 2])`, "[1, 2] should be object, got array"},
+		{"map.pick with non-string key", `map.pick({a = 1}, [1])`, "1 should be string, got number"},
+		{"map.omit wrong arg count", `map.omit({a = 1})`, "expected 2 args, got 1"},
+		{"array.length of object", `array.length({})`, "{} should be array, got object"},
+		{"array.slice out of range", `array.slice([1, 2], 1, 3)`, "invalid slice [1:3] of array with 2 elements"},
+		{"array.slice reversed", `array.slice([1, 2], 2, 1)`, "invalid slice [2:1] of array with 2 elements"},
+		{"encoding.from_base64 invalid", `encoding.from_base64("!!")`, "illegal base64 data"},
+		{"string.regex_match invalid pattern", `string.regex_match("foo", "(")`, "error parsing regexp"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := parser.ParseExpression(tc.input)
+			require.NoError(t, err)
+
+			eval := vm.New(expr)
+
+			var out interface{}
+			require.ErrorContains(t, eval.Evaluate(nil, &out), tc.expectError)
+		})
+	}
+}
+
 func BenchmarkConcat(b *testing.B) {
 	// There's a bit of setup work to do here: we want to create a scope holding
 	// a slice of the Person type, which has a fair amount of data in it.
@@ -136,6 +136,13 @@ func TestVM_Evaluate(t *testing.T) {
 		{`!true`, bool(false)},
 		{`!false`, bool(true)},
 		{`-15`, int(-15)},
+
+		// Conditional
+		{`true ? 1 : 2`, int(1)},
+		{`false ? 1 : 2`, int(2)},
+		{`foobar > 40 ? "big" : "small"`, string("big")},
+		{`false ? 1 : true ? 2 : 3`, int(2)},
+		{`true ? [0, 1] : []`, []int{0, 1}},
 	}
 
 	for _, tc := range tt {
@@ -253,6 +260,35 @@ func TestVM_Evaluate_AccessExpr(t *testing.T) {
 	})
 }
 
+func TestVM_Evaluate_ConditionalExpr(t *testing.T) {
+	t.Run("Unselected branch is not evaluated", func(t *testing.T) {
+		expr, err := parser.ParseExpression(`true ? 15 : does_not_exist`)
+		require.NoError(t, err)
+
+		eval := vm.New(expr)
+
+		var actual int
+		require.NoError(t, eval.Evaluate(nil, &actual))
+		require.Equal(t, 15, actual)
+	})
+
+	t.Run("Non-boolean condition", func(t *testing.T) {
+		expr, err := parser.ParseExpression(`1 ? 2 : 3`)
+		require.NoError(t, err)
+
+		eval := vm.New(expr)
+
+		var v interface{}
+		err = eval.Evaluate(nil, &v)
+		require.EqualError(t, err, `1:1: 1 should be bool, got number`)
+	})
+
+	t.Run("Missing colon", func(t *testing.T) {
+		_, err := parser.ParseExpression(`true ? 1`)
+		require.EqualError(t, err, `1:9: expected :, got TERMINATOR`)
+	})
+}
+
 func trimWhitespace(in string) string {
 	f := token.NewFile("")
 